@@ -0,0 +1,758 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	liberrors "git.sr.ht/~shulhan/pakakeh.go/lib/errors"
+	libhttp "git.sr.ht/~shulhan/pakakeh.go/lib/http"
+	libnet "git.sr.ht/~shulhan/pakakeh.go/lib/net"
+)
+
+// List of paths in the administrative HTTP API.
+const (
+	adminPathCaches       = `/api/caches`
+	adminPathCachesSearch = `/api/caches/search`
+	adminPathHosts        = `/api/hosts`
+	adminPathHostsFile    = `/api/hosts/:name`
+	adminPathHostsRecords = `/api/hosts/:name/records`
+	adminPathZones        = `/api/zones`
+	adminPathZone         = `/api/zones/:origin`
+	adminPathZoneRecords  = `/api/zones/:origin/records`
+)
+
+// admin serve the administrative HTTP API for managing the internal
+// zones, hosts files, and external caches of Server.
+type admin struct {
+	srv  *Server
+	http *libhttp.Server
+
+	// token contains the value of ServerOptions.AdminToken.
+	token []byte
+
+	// hostsLocker guard the Server.HostsFiles.
+	hostsLocker sync.Mutex
+}
+
+// adminZone define the zone in the administrative API.
+type adminZone struct {
+	Origin  string              `json:"origin"`
+	Records []MessageJSONRecord `json:"records,omitempty"`
+}
+
+// adminHostsFile define the hosts file in the administrative API.
+type adminHostsFile struct {
+	Name    string              `json:"name"`
+	Records []MessageJSONRecord `json:"records,omitempty"`
+}
+
+// adminAnswer define the cached answer in the administrative API.
+// The Message is empty if the answer has been removed from caches.
+type adminAnswer struct {
+	Message    *MessageJSON `json:"message,omitempty"`
+	Name       string       `json:"name"`
+	ReceivedAt int64        `json:"received_at"`
+	AccessedAt int64        `json:"accessed_at"`
+	Type       RecordType   `json:"type"`
+}
+
+func newAdmin(srv *Server) (adm *admin, err error) {
+	var logp = `newAdmin`
+
+	adm = &admin{
+		srv:   srv,
+		token: []byte(srv.opts.AdminToken),
+	}
+
+	adm.http, err = libhttp.NewServer(libhttp.ServerOptions{
+		Address: srv.opts.AdminAddress,
+	})
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	adm.http.RegisterEvaluator(adm.authorize)
+
+	var listEndpoint = []libhttp.Endpoint{{
+		Method:       libhttp.RequestMethodGet,
+		Path:         adminPathCaches,
+		RequestType:  libhttp.RequestTypeQuery,
+		ResponseType: libhttp.ResponseTypeJSON,
+		Call:         adm.cachesLRU,
+	}, {
+		Method:       libhttp.RequestMethodDelete,
+		Path:         adminPathCaches,
+		RequestType:  libhttp.RequestTypeQuery,
+		ResponseType: libhttp.ResponseTypeJSON,
+		Call:         adm.cachesRemove,
+	}, {
+		Method:       libhttp.RequestMethodGet,
+		Path:         adminPathCachesSearch,
+		RequestType:  libhttp.RequestTypeQuery,
+		ResponseType: libhttp.ResponseTypeJSON,
+		Call:         adm.cachesSearch,
+	}, {
+		Method:       libhttp.RequestMethodGet,
+		Path:         adminPathHosts,
+		ResponseType: libhttp.ResponseTypeJSON,
+		Call:         adm.hostsList,
+	}, {
+		Method:       libhttp.RequestMethodPost,
+		Path:         adminPathHosts,
+		RequestType:  libhttp.RequestTypeJSON,
+		ResponseType: libhttp.ResponseTypeJSON,
+		Call:         adm.hostsCreate,
+	}, {
+		Method:       libhttp.RequestMethodGet,
+		Path:         adminPathHostsFile,
+		RequestType:  libhttp.RequestTypeQuery,
+		ResponseType: libhttp.ResponseTypeJSON,
+		Call:         adm.hostsGet,
+	}, {
+		Method:       libhttp.RequestMethodDelete,
+		Path:         adminPathHostsFile,
+		RequestType:  libhttp.RequestTypeQuery,
+		ResponseType: libhttp.ResponseTypeJSON,
+		Call:         adm.hostsDelete,
+	}, {
+		Method:       libhttp.RequestMethodPost,
+		Path:         adminPathHostsRecords,
+		RequestType:  libhttp.RequestTypeJSON,
+		ResponseType: libhttp.ResponseTypeJSON,
+		Call:         adm.hostsRecordAdd,
+	}, {
+		Method:       libhttp.RequestMethodDelete,
+		Path:         adminPathHostsRecords,
+		RequestType:  libhttp.RequestTypeJSON,
+		ResponseType: libhttp.ResponseTypeJSON,
+		Call:         adm.hostsRecordRemove,
+	}, {
+		Method:       libhttp.RequestMethodGet,
+		Path:         adminPathZones,
+		ResponseType: libhttp.ResponseTypeJSON,
+		Call:         adm.zonesList,
+	}, {
+		Method:       libhttp.RequestMethodPost,
+		Path:         adminPathZones,
+		RequestType:  libhttp.RequestTypeJSON,
+		ResponseType: libhttp.ResponseTypeJSON,
+		Call:         adm.zonesCreate,
+	}, {
+		Method:       libhttp.RequestMethodGet,
+		Path:         adminPathZone,
+		RequestType:  libhttp.RequestTypeQuery,
+		ResponseType: libhttp.ResponseTypeJSON,
+		Call:         adm.zonesGet,
+	}, {
+		Method:       libhttp.RequestMethodDelete,
+		Path:         adminPathZone,
+		RequestType:  libhttp.RequestTypeQuery,
+		ResponseType: libhttp.ResponseTypeJSON,
+		Call:         adm.zonesDelete,
+	}, {
+		Method:       libhttp.RequestMethodPost,
+		Path:         adminPathZoneRecords,
+		RequestType:  libhttp.RequestTypeJSON,
+		ResponseType: libhttp.ResponseTypeJSON,
+		Call:         adm.zonesRecordAdd,
+	}, {
+		Method:       libhttp.RequestMethodDelete,
+		Path:         adminPathZoneRecords,
+		RequestType:  libhttp.RequestTypeJSON,
+		ResponseType: libhttp.ResponseTypeJSON,
+		Call:         adm.zonesRecordRemove,
+	}}
+
+	var ep libhttp.Endpoint
+	for _, ep = range listEndpoint {
+		err = adm.http.RegisterEndpoint(ep)
+		if err != nil {
+			return nil, fmt.Errorf(`%s: %w`, logp, err)
+		}
+	}
+	return adm, nil
+}
+
+// authorize the request using the token in the header
+// "Authorization: Bearer <token>".
+func (adm *admin) authorize(req *http.Request, _ []byte) error {
+	var (
+		hdr      = req.Header.Get(libhttp.HeaderAuthorization)
+		token, _ = strings.CutPrefix(hdr, libhttp.HeaderAuthKeyBearer+` `)
+	)
+	if len(token) == 0 || subtle.ConstantTimeCompare([]byte(token), adm.token) != 1 {
+		return adminError(http.StatusUnauthorized, `ERR_UNAUTHORIZED`,
+			`invalid or missing token`)
+	}
+	return nil
+}
+
+// cachesLRU return the external answers ordered by the least recently
+// used.
+func (adm *admin) cachesLRU(_ *libhttp.EndpointRequest) ([]byte, error) {
+	return adminResponse(newAdminAnswers(adm.srv.Caches.ExternalLRU()))
+}
+
+// cachesRemove remove the external answers by the query parameter
+// "name", and return the removed answers.
+func (adm *admin) cachesRemove(epr *libhttp.EndpointRequest) ([]byte, error) {
+	var names = epr.HTTPRequest.Form[`name`]
+	if len(names) == 0 {
+		return nil, liberrors.InvalidInput(`name`)
+	}
+
+	var x int
+	for x = range len(names) {
+		names[x] = strings.ToLower(strings.TrimSuffix(names[x], `.`))
+	}
+
+	var listAnswer = adm.srv.Caches.ExternalRemoveNames(names)
+	return adminResponse(newAdminAnswers(listAnswer))
+}
+
+// cachesSearch return the external answers whose name match with the
+// regular expression in the query parameter "query".
+func (adm *admin) cachesSearch(epr *libhttp.EndpointRequest) ([]byte, error) {
+	var (
+		query = epr.HTTPRequest.Form.Get(`query`)
+		re    *regexp.Regexp
+		err   error
+	)
+	if len(query) == 0 {
+		return nil, liberrors.InvalidInput(`query`)
+	}
+	re, err = regexp.Compile(query)
+	if err != nil {
+		return nil, liberrors.InvalidInput(`query`)
+	}
+
+	var (
+		listMsg = adm.srv.Caches.ExternalSearch(re)
+		list    = make([]*MessageJSON, 0, len(listMsg))
+		msg     *Message
+	)
+	for _, msg = range listMsg {
+		list = append(list, NewMessageJSON(msg))
+	}
+	sort.Slice(list, func(x, y int) bool {
+		return list[x].Question[0].Name < list[y].Question[0].Name
+	})
+	return adminResponse(list)
+}
+
+// hostsList return the name of hosts files.
+func (adm *admin) hostsList(_ *libhttp.EndpointRequest) ([]byte, error) {
+	adm.hostsLocker.Lock()
+	defer adm.hostsLocker.Unlock()
+
+	var (
+		list  = make([]adminHostsFile, 0, len(adm.srv.HostsFiles))
+		hfile *HostsFile
+	)
+	for _, hfile = range adm.srv.HostsFiles {
+		list = append(list, adminHostsFile{Name: hfile.Name})
+	}
+	sort.Slice(list, func(x, y int) bool {
+		return list[x].Name < list[y].Name
+	})
+	return adminResponse(list)
+}
+
+// hostsCreate create new hosts file inside the
+// ServerOptions.AdminHostsDir.
+func (adm *admin) hostsCreate(epr *libhttp.EndpointRequest) ([]byte, error) {
+	var (
+		req adminHostsFile
+		err error
+	)
+
+	err = json.Unmarshal(epr.RequestBody, &req)
+	if err != nil {
+		return nil, liberrors.InvalidInput(`body`)
+	}
+	if !isValidHostsName(req.Name) {
+		return nil, liberrors.InvalidInput(`name`)
+	}
+	if len(adm.srv.opts.AdminHostsDir) == 0 {
+		return nil, adminError(http.StatusBadRequest, `ERR_NO_HOSTS_DIR`,
+			`hosts directory is not set`)
+	}
+
+	adm.hostsLocker.Lock()
+	defer adm.hostsLocker.Unlock()
+
+	if adm.srv.HostsFiles[req.Name] != nil {
+		return nil, adminError(http.StatusConflict, `ERR_EXIST`,
+			`hosts file %q already exist`, req.Name)
+	}
+
+	var (
+		path  = filepath.Join(adm.srv.opts.AdminHostsDir, req.Name)
+		hfile *HostsFile
+	)
+
+	hfile, err = NewHostsFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if adm.srv.HostsFiles == nil {
+		adm.srv.HostsFiles = make(map[string]*HostsFile)
+	}
+	adm.srv.HostsFiles[hfile.Name] = hfile
+
+	return adminResponse(newAdminHostsFile(hfile))
+}
+
+// hostsGet return the hosts file and its records.
+func (adm *admin) hostsGet(epr *libhttp.EndpointRequest) ([]byte, error) {
+	adm.hostsLocker.Lock()
+	defer adm.hostsLocker.Unlock()
+
+	var hfile, err = adm.hostsFile(epr)
+	if err != nil {
+		return nil, err
+	}
+	return adminResponse(newAdminHostsFile(hfile))
+}
+
+// hostsDelete delete the hosts file and remove its records from internal
+// caches.
+func (adm *admin) hostsDelete(epr *libhttp.EndpointRequest) ([]byte, error) {
+	adm.hostsLocker.Lock()
+	defer adm.hostsLocker.Unlock()
+
+	var hfile, err = adm.hostsFile(epr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = hfile.Delete()
+	if err != nil {
+		return nil, err
+	}
+	adm.srv.Caches.InternalRemoveNames(hfile.Names())
+	delete(adm.srv.HostsFiles, hfile.Name)
+
+	return adminResponse(newAdminHostsFile(hfile))
+}
+
+// hostsRecordAdd add the A or AAAA record into hosts file and internal
+// caches.
+func (adm *admin) hostsRecordAdd(epr *libhttp.EndpointRequest) ([]byte, error) {
+	adm.hostsLocker.Lock()
+	defer adm.hostsLocker.Unlock()
+
+	var hfile, err = adm.hostsFile(epr)
+	if err != nil {
+		return nil, err
+	}
+
+	var rr *ResourceRecord
+
+	rr, err = adminRecord(epr.RequestBody)
+	if err != nil {
+		return nil, err
+	}
+	if rr.Type != RecordTypeA && rr.Type != RecordTypeAAAA {
+		return nil, liberrors.InvalidInput(`type`)
+	}
+	rr.Name = strings.ToLower(rr.Name)
+	if rr.TTL == 0 {
+		rr.TTL = defaultTTL
+	}
+
+	err = hfile.AppendAndSaveRecord(rr)
+	if err != nil {
+		return nil, err
+	}
+	err = adm.srv.Caches.InternalPopulateRecords([]*ResourceRecord{rr},
+		hfile.Path)
+	if err != nil {
+		return nil, err
+	}
+	return adminResponse(newMessageJSONRecord(rr))
+}
+
+// hostsRecordRemove remove the record by its name from hosts file and
+// internal caches.
+func (adm *admin) hostsRecordRemove(epr *libhttp.EndpointRequest) ([]byte, error) {
+	adm.hostsLocker.Lock()
+	defer adm.hostsLocker.Unlock()
+
+	var hfile, err = adm.hostsFile(epr)
+	if err != nil {
+		return nil, err
+	}
+
+	var rec MessageJSONRecord
+
+	err = json.Unmarshal(epr.RequestBody, &rec)
+	if err != nil {
+		return nil, liberrors.InvalidInput(`body`)
+	}
+
+	var (
+		name = strings.ToLower(strings.TrimSuffix(rec.Name, `.`))
+		rr   = hfile.RemoveRecord(name)
+	)
+	if rr == nil {
+		return nil, adminError(http.StatusNotFound, `ERR_NOT_FOUND`,
+			`record %q not found`, rec.Name)
+	}
+
+	err = hfile.Save()
+	if err != nil {
+		return nil, err
+	}
+	_, err = adm.srv.Caches.InternalRemoveRecord(rr)
+	if err != nil {
+		return nil, err
+	}
+	return adminResponse(newMessageJSONRecord(rr))
+}
+
+// hostsFile return the hosts file by the path parameter "name".
+// The caller must hold the hostsLocker.
+func (adm *admin) hostsFile(epr *libhttp.EndpointRequest) (hfile *HostsFile, err error) {
+	var name = epr.HTTPRequest.Form.Get(`name`)
+
+	hfile = adm.srv.HostsFiles[name]
+	if hfile == nil {
+		return nil, adminError(http.StatusNotFound, `ERR_NOT_FOUND`,
+			`hosts file %q not found`, name)
+	}
+	return hfile, nil
+}
+
+// zonesList return the origin of internal zones.
+func (adm *admin) zonesList(_ *libhttp.EndpointRequest) ([]byte, error) {
+	var c = &adm.srv.Caches
+
+	c.Lock()
+	var (
+		list = make([]adminZone, 0, len(c.zone))
+		zone *Zone
+	)
+	for _, zone = range c.zone {
+		list = append(list, adminZone{Origin: zone.Origin})
+	}
+	c.Unlock()
+
+	sort.Slice(list, func(x, y int) bool {
+		return list[x].Origin < list[y].Origin
+	})
+	return adminResponse(list)
+}
+
+// zonesCreate create new internal zone with default SOA and the records
+// in the request.
+// If the ServerOptions.AdminZoneDir is set, the zone is saved into it
+// with origin as the file name.
+func (adm *admin) zonesCreate(epr *libhttp.EndpointRequest) ([]byte, error) {
+	var (
+		req adminZone
+		err error
+	)
+
+	err = json.Unmarshal(epr.RequestBody, &req)
+	if err != nil {
+		return nil, liberrors.InvalidInput(`body`)
+	}
+	if len(req.Origin) == 0 {
+		return nil, liberrors.InvalidInput(`origin`)
+	}
+
+	var (
+		origin = strings.ToLower(toDomainAbsolute(req.Origin))
+		path   string
+	)
+	if !isValidZoneOrigin(origin) {
+		return nil, liberrors.InvalidInput(`origin`)
+	}
+	if adm.srv.Caches.internalZoneByOrigin(origin) != nil {
+		return nil, adminError(http.StatusConflict, `ERR_EXIST`,
+			`zone %q already exist`, origin)
+	}
+	if len(adm.srv.opts.AdminZoneDir) != 0 {
+		var dir = filepath.Clean(adm.srv.opts.AdminZoneDir)
+		path = filepath.Join(dir, strings.TrimSuffix(origin, `.`))
+		if filepath.Dir(path) != dir {
+			return nil, liberrors.InvalidInput(`origin`)
+		}
+	}
+
+	var zone = NewZone(path, origin)
+
+	err = zone.Add(zone.soaRecord())
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		rec MessageJSONRecord
+		rr  *ResourceRecord
+	)
+	for _, rec = range req.Records {
+		rr, err = parseRDataText(rec)
+		if err != nil {
+			return nil, adminError(http.StatusBadRequest,
+				`ERR_INVALID_INPUT`, `%s`, err)
+		}
+		toZoneRecord(rr)
+		if !zone.isInZone(rr.Name) {
+			return nil, liberrors.InvalidInput(`name`)
+		}
+		err = zone.Add(rr)
+		if err != nil {
+			return nil, adminError(http.StatusBadRequest,
+				`ERR_INVALID_INPUT`, `%s`, err)
+		}
+	}
+
+	if len(zone.Path) != 0 {
+		err = zone.Save()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	adm.srv.Caches.internalZoneReplace(nil, zone)
+
+	return adminResponse(newAdminZone(zone))
+}
+
+// zonesGet return the internal zone and its records.
+func (adm *admin) zonesGet(epr *libhttp.EndpointRequest) ([]byte, error) {
+	var zone, err = adm.zone(epr)
+	if err != nil {
+		return nil, err
+	}
+
+	adm.srv.Caches.Lock()
+	var res = newAdminZone(zone)
+	adm.srv.Caches.Unlock()
+
+	return adminResponse(res)
+}
+
+// zonesDelete remove the internal zone and its answers, and delete its
+// file if its exist.
+func (adm *admin) zonesDelete(epr *libhttp.EndpointRequest) ([]byte, error) {
+	var zone, err = adm.zone(epr)
+	if err != nil {
+		return nil, err
+	}
+
+	adm.srv.Caches.internalZoneReplace(zone, nil)
+
+	if len(zone.Path) != 0 {
+		err = zone.Delete()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return adminResponse(adminZone{Origin: zone.Origin})
+}
+
+// zonesRecordAdd add the record into internal zone and replace the
+// internal answers with the updated zone.
+func (adm *admin) zonesRecordAdd(epr *libhttp.EndpointRequest) ([]byte, error) {
+	var zone, err = adm.zone(epr)
+	if err != nil {
+		return nil, err
+	}
+
+	var rr *ResourceRecord
+
+	rr, err = adminRecord(epr.RequestBody)
+	if err != nil {
+		return nil, err
+	}
+	toZoneRecord(rr)
+	if !zone.isInZone(rr.Name) {
+		return nil, liberrors.InvalidInput(`name`)
+	}
+
+	err = adm.srv.Caches.internalZoneUpdate(zone, func() (err error) {
+		err = zone.doAdd(rr)
+		if err != nil || len(zone.Path) == 0 {
+			return err
+		}
+		return zone.Save()
+	})
+	if err != nil {
+		return nil, adminError(http.StatusBadRequest,
+			`ERR_INVALID_INPUT`, `%s`, err)
+	}
+	return adminResponse(newMessageJSONRecord(rr))
+}
+
+// zonesRecordRemove remove the record from internal zone and replace
+// the internal answers with the updated zone.
+func (adm *admin) zonesRecordRemove(epr *libhttp.EndpointRequest) ([]byte, error) {
+	var zone, err = adm.zone(epr)
+	if err != nil {
+		return nil, err
+	}
+
+	var rr *ResourceRecord
+
+	rr, err = adminRecord(epr.RequestBody)
+	if err != nil {
+		return nil, err
+	}
+	toZoneRecord(rr)
+
+	err = zone.Remove(rr)
+	if err != nil {
+		return nil, adminError(http.StatusBadRequest,
+			`ERR_INVALID_INPUT`, `%s`, err)
+	}
+	return adminResponse(newMessageJSONRecord(rr))
+}
+
+// zone return the internal zone by the path parameter "origin".
+func (adm *admin) zone(epr *libhttp.EndpointRequest) (zone *Zone, err error) {
+	var origin = epr.HTTPRequest.Form.Get(`origin`)
+
+	zone = adm.srv.Caches.internalZoneByOrigin(origin)
+	if zone == nil {
+		return nil, adminError(http.StatusNotFound, `ERR_NOT_FOUND`,
+			`zone %q not found`, origin)
+	}
+	return zone, nil
+}
+
+// adminRecord parse the request body as [MessageJSONRecord].
+func adminRecord(body []byte) (rr *ResourceRecord, err error) {
+	var rec MessageJSONRecord
+
+	err = json.Unmarshal(body, &rec)
+	if err != nil {
+		return nil, liberrors.InvalidInput(`body`)
+	}
+	if len(rec.Name) == 0 {
+		return nil, liberrors.InvalidInput(`name`)
+	}
+	if rec.Type == 0 {
+		return nil, liberrors.InvalidInput(`type`)
+	}
+
+	rr, err = parseRDataText(rec)
+	if err != nil {
+		return nil, adminError(http.StatusBadRequest, `ERR_INVALID_INPUT`,
+			`%s`, err)
+	}
+	return rr, nil
+}
+
+// adminError return the error with HTTP status code and name.
+func adminError(code int, name, format string, args ...any) error {
+	return &liberrors.E{
+		Code:    code,
+		Name:    name,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// adminResponse return the data wrapped in [libhttp.EndpointResponse] as
+// JSON.
+func adminResponse(data any) ([]byte, error) {
+	var res = libhttp.EndpointResponse{
+		Data: data,
+	}
+	res.Code = http.StatusOK
+	return json.Marshal(&res)
+}
+
+func newAdminAnswers(listAnswer []*Answer) (list []adminAnswer) {
+	var an *Answer
+
+	list = make([]adminAnswer, 0, len(listAnswer))
+	for _, an = range listAnswer {
+		var res = adminAnswer{
+			Name:       toDomainAbsolute(an.QName),
+			Type:       an.RType,
+			ReceivedAt: an.ReceivedAt,
+			AccessedAt: an.AccessedAt,
+		}
+		if an.Message != nil {
+			res.Message = NewMessageJSON(an.Message)
+		}
+		list = append(list, res)
+	}
+	return list
+}
+
+func newAdminHostsFile(hfile *HostsFile) (res adminHostsFile) {
+	res.Name = hfile.Name
+	res.Records = make([]MessageJSONRecord, 0, len(hfile.Records))
+
+	var rr *ResourceRecord
+	for _, rr = range hfile.Records {
+		res.Records = append(res.Records, newMessageJSONRecord(rr))
+	}
+	return res
+}
+
+// newAdminZone convert the zone into JSON model, with SOA as the first
+// record followed by other records ordered by its name.
+func newAdminZone(zone *Zone) (res adminZone) {
+	res.Origin = zone.Origin
+	res.Records = []MessageJSONRecord{newMessageJSONRecord(zone.soaRecord())}
+
+	var (
+		names = make([]string, 0, len(zone.Records))
+		name  string
+		rr    *ResourceRecord
+	)
+	for name = range zone.Records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name = range names {
+		for _, rr = range zone.Records[name] {
+			res.Records = append(res.Records, newMessageJSONRecord(rr))
+		}
+	}
+	return res
+}
+
+// isValidZoneOrigin return true if the origin is a valid domain name that
+// can be used as the zone file name.
+func isValidZoneOrigin(origin string) bool {
+	origin = strings.TrimSuffix(origin, `.`)
+	if len(origin) == 0 || len(origin) > 253 {
+		return false
+	}
+	var label string
+	for _, label = range strings.Split(origin, `.`) {
+		if len(label) > 63 || !isValidHostsName(label) {
+			return false
+		}
+		if !libnet.IsHostnameValid([]byte(label), false) {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidHostsName return true if the name can be used as the hosts file
+// name.
+func isValidHostsName(name string) bool {
+	if len(name) == 0 || name == `.` || name == `..` {
+		return false
+	}
+	return !strings.ContainsAny(name, `/\`)
+}
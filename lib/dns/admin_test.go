@@ -0,0 +1,349 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestServer_admin(t *testing.T) {
+	var (
+		zoneDir  = t.TempDir()
+		hostsDir = t.TempDir()
+
+		srv *Server
+		err error
+	)
+
+	srv, err = NewServer(&ServerOptions{
+		ListenAddress: `127.0.0.1:5350`,
+		AdminAddress:  `127.0.0.1:5351`,
+		AdminToken:    `s3cret`,
+		AdminZoneDir:  zoneDir,
+		AdminHostsDir: hostsDir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+
+	var doRequest = func(method, target, token, body string) (code int, resBody string) {
+		var (
+			httpReq = httptest.NewRequest(method, target,
+				strings.NewReader(body))
+			rec = httptest.NewRecorder()
+		)
+		if len(token) != 0 {
+			httpReq.Header.Set(`Authorization`, `Bearer `+token)
+		}
+		httpReq.Header.Set(`Content-Type`, `application/json`)
+		srv.admin.http.ServeHTTP(rec, httpReq)
+		return rec.Code, rec.Body.String()
+	}
+
+	var lookup = func(qname string, qtype RecordType) (values []string) {
+		var msg = NewMessage()
+		msg.Question = MessageQuestion{
+			Name:  qname,
+			Type:  qtype,
+			Class: RecordClassIN,
+		}
+		var an = srv.Caches.query(msg)
+		if an == nil {
+			return nil
+		}
+		return testRecordsString(an.Message.Answer)
+	}
+
+	type testCase struct {
+		desc    string
+		method  string
+		target  string
+		body    string
+		expBody string
+		expCode int
+	}
+
+	const expUnauthorized = `{"message":"invalid or missing token","name":"ERR_UNAUTHORIZED","code":401}`
+
+	var code, body = doRequest(http.MethodGet, `/api/zones`, ``, ``)
+	test.Assert(t, `Without token: code`, http.StatusUnauthorized, code)
+	test.Assert(t, `Without token: body`, expUnauthorized, body)
+
+	code, body = doRequest(http.MethodGet, `/api/zones`, `secret`, ``)
+	test.Assert(t, `With invalid token: code`, http.StatusUnauthorized, code)
+	test.Assert(t, `With invalid token: body`, expUnauthorized, body)
+
+	var listCase = []testCase{{
+		desc:    `Create zone`,
+		method:  http.MethodPost,
+		target:  `/api/zones`,
+		body:    `{"origin":"example.test","records":[{"name":"www.example.test.","type":1,"TTL":60,"data":"192.0.2.1"}]}`,
+		expCode: http.StatusOK,
+	}, {
+		desc:    `Create zone that already exist`,
+		method:  http.MethodPost,
+		target:  `/api/zones`,
+		body:    `{"origin":"example.test."}`,
+		expCode: http.StatusConflict,
+		expBody: `{"message":"zone \"example.test.\" already exist","name":"ERR_EXIST","code":409}`,
+	}, {
+		desc:    `Create zone with path traversal`,
+		method:  http.MethodPost,
+		target:  `/api/zones`,
+		body:    `{"origin":"../../etc/passwd"}`,
+		expCode: http.StatusBadRequest,
+		expBody: `{"message":"invalid input: origin","name":"ERR_INVALID_INPUT","code":400}`,
+	}, {
+		desc:    `Create zone with empty label`,
+		method:  http.MethodPost,
+		target:  `/api/zones`,
+		body:    `{"origin":"a..test"}`,
+		expCode: http.StatusBadRequest,
+		expBody: `{"message":"invalid input: origin","name":"ERR_INVALID_INPUT","code":400}`,
+	}, {
+		desc:    `List zones`,
+		method:  http.MethodGet,
+		target:  `/api/zones`,
+		expCode: http.StatusOK,
+		expBody: `{"data":[{"origin":"example.test."}],"code":200}`,
+	}, {
+		desc:    `Add record into zone`,
+		method:  http.MethodPost,
+		target:  `/api/zones/example.test./records`,
+		body:    `{"name":"example.test.","type":15,"TTL":60,"data":"10 mail.example.test."}`,
+		expCode: http.StatusOK,
+		expBody: `{"data":{"name":"example.test.","data":"10 mail.example.test.","TTL":60,"type":15},"code":200}`,
+	}, {
+		desc:    `Add record outside of zone`,
+		method:  http.MethodPost,
+		target:  `/api/zones/example.test./records`,
+		body:    `{"name":"www.other.test.","type":1,"TTL":60,"data":"192.0.2.2"}`,
+		expCode: http.StatusBadRequest,
+		expBody: `{"message":"invalid input: name","name":"ERR_INVALID_INPUT","code":400}`,
+	}, {
+		desc:    `Add record into unknown zone`,
+		method:  http.MethodPost,
+		target:  `/api/zones/other.test./records`,
+		body:    `{"name":"www.other.test.","type":1,"TTL":60,"data":"192.0.2.2"}`,
+		expCode: http.StatusNotFound,
+		expBody: `{"message":"zone \"other.test.\" not found","name":"ERR_NOT_FOUND","code":404}`,
+	}, {
+		desc:    `Create hosts file`,
+		method:  http.MethodPost,
+		target:  `/api/hosts`,
+		body:    `{"name":"office"}`,
+		expCode: http.StatusOK,
+		expBody: `{"data":{"name":"office"},"code":200}`,
+	}, {
+		desc:    `Create hosts file with invalid name`,
+		method:  http.MethodPost,
+		target:  `/api/hosts`,
+		body:    `{"name":"../office"}`,
+		expCode: http.StatusBadRequest,
+		expBody: `{"message":"invalid input: name","name":"ERR_INVALID_INPUT","code":400}`,
+	}, {
+		desc:    `Add record into hosts file`,
+		method:  http.MethodPost,
+		target:  `/api/hosts/office/records`,
+		body:    `{"name":"printer.office","type":1,"data":"10.0.0.9"}`,
+		expCode: http.StatusOK,
+		expBody: `{"data":{"name":"printer.office.","data":"10.0.0.9","TTL":604800,"type":1},"code":200}`,
+	}, {
+		desc:    `Add record with invalid type into hosts file`,
+		method:  http.MethodPost,
+		target:  `/api/hosts/office/records`,
+		body:    `{"name":"printer.office","type":5,"data":"www.office"}`,
+		expCode: http.StatusBadRequest,
+		expBody: `{"message":"invalid input: type","name":"ERR_INVALID_INPUT","code":400}`,
+	}, {
+		desc:    `Get hosts file`,
+		method:  http.MethodGet,
+		target:  `/api/hosts/office`,
+		expCode: http.StatusOK,
+		expBody: `{"data":{"name":"office","records":[{"name":"printer.office.","data":"10.0.0.9","TTL":604800,"type":1}]},"code":200}`,
+	}}
+
+	var c testCase
+	for _, c = range listCase {
+		code, body = doRequest(c.method, c.target, `s3cret`, c.body)
+		test.Assert(t, c.desc+`: code`, c.expCode, code)
+		if len(c.expBody) != 0 {
+			test.Assert(t, c.desc+`: body`, c.expBody, body)
+		}
+	}
+
+	test.Assert(t, `zone answer`, []string{`www.example.test. A 192.0.2.1`},
+		lookup(`www.example.test`, RecordTypeA))
+	test.Assert(t, `zone MX answer`, []string{`example.test. MX`},
+		lookup(`example.test`, RecordTypeMX))
+	test.Assert(t, `hosts answer`, []string{`printer.office A 10.0.0.9`},
+		lookup(`printer.office`, RecordTypeA))
+
+	var content []byte
+
+	content, err = os.ReadFile(filepath.Join(zoneDir, `example.test`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `zone file`, `$ORIGIN example.test.
+@ SOA example.test. root 1691222003 86400 3600 0 3600
+@ 60 IN MX 10 mail
+www 60 IN A 192.0.2.1
+`, string(content))
+
+	content, err = os.ReadFile(filepath.Join(hostsDir, `office`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `hosts file`, "10.0.0.9 printer.office\n", string(content))
+
+	// Remove the records and delete the zone and hosts file.
+
+	listCase = []testCase{{
+		desc:    `Remove record from zone`,
+		method:  http.MethodDelete,
+		target:  `/api/zones/example.test./records`,
+		body:    `{"name":"www.example.test.","type":1,"TTL":60,"data":"192.0.2.1"}`,
+		expCode: http.StatusOK,
+	}, {
+		desc:    `Remove record from hosts file`,
+		method:  http.MethodDelete,
+		target:  `/api/hosts/office/records`,
+		body:    `{"name":"printer.office."}`,
+		expCode: http.StatusOK,
+	}, {
+		desc:    `Remove unknown record from hosts file`,
+		method:  http.MethodDelete,
+		target:  `/api/hosts/office/records`,
+		body:    `{"name":"printer.office."}`,
+		expCode: http.StatusNotFound,
+		expBody: `{"message":"record \"printer.office.\" not found","name":"ERR_NOT_FOUND","code":404}`,
+	}}
+	for _, c = range listCase {
+		code, body = doRequest(c.method, c.target, `s3cret`, c.body)
+		test.Assert(t, c.desc+`: code`, c.expCode, code)
+		if len(c.expBody) != 0 {
+			test.Assert(t, c.desc+`: body`, c.expBody, body)
+		}
+	}
+
+	test.Assert(t, `removed zone answer`, []string(nil),
+		lookup(`www.example.test`, RecordTypeA))
+	test.Assert(t, `removed hosts answer`, []string(nil),
+		lookup(`printer.office`, RecordTypeA))
+
+	code, _ = doRequest(http.MethodDelete, `/api/zones/example.test.`, `s3cret`, ``)
+	test.Assert(t, `Delete zone`, http.StatusOK, code)
+	code, _ = doRequest(http.MethodDelete, `/api/hosts/office`, `s3cret`, ``)
+	test.Assert(t, `Delete hosts file`, http.StatusOK, code)
+
+	test.Assert(t, `deleted zone`, (*Zone)(nil),
+		srv.Caches.internalZoneByOrigin(`example.test.`))
+	test.Assert(t, `deleted zone answer`, []string(nil),
+		lookup(`example.test`, RecordTypeMX))
+
+	_, err = os.Stat(filepath.Join(zoneDir, `example.test`))
+	test.Assert(t, `deleted zone file`, true, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(hostsDir, `office`))
+	test.Assert(t, `deleted hosts file`, true, os.IsNotExist(err))
+}
+
+func TestServer_adminCaches(t *testing.T) {
+	var (
+		srv *Server
+		err error
+	)
+
+	srv, err = NewServer(&ServerOptions{
+		ListenAddress: `127.0.0.1:5350`,
+		AdminAddress:  `127.0.0.1:5351`,
+		AdminToken:    `s3cret`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+
+	var (
+		names = []string{`a.example`, `b.example`, `c.other`}
+		name  string
+	)
+	for _, name = range names {
+		var msg = NewMessage()
+		msg.Header.IsQuery = false
+		msg.Question = MessageQuestion{
+			Name:  name,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+		}
+		msg.Answer = []ResourceRecord{{
+			Name:  name,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+			TTL:   60,
+			Value: `192.0.2.1`,
+		}}
+		msg.Header.ANCount = 1
+		_, err = msg.Pack()
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv.Caches.upsert(newAnswer(msg, false))
+	}
+
+	var doRequest = func(method, target string) (code int, resBody string) {
+		var (
+			httpReq = httptest.NewRequest(method, target, nil)
+			rec     = httptest.NewRecorder()
+		)
+		httpReq.Header.Set(`Authorization`, `Bearer s3cret`)
+		srv.admin.http.ServeHTTP(rec, httpReq)
+		return rec.Code, rec.Body.String()
+	}
+
+	var (
+		code int
+		body string
+	)
+
+	code, body = doRequest(http.MethodGet, `/api/caches/search?query=example$`)
+	test.Assert(t, `search code`, http.StatusOK, code)
+	test.Assert(t, `search`, `{"data":[`+
+		`{"Question":[{"name":"a.example.","type":1}],"Answer":[{"name":"a.example.","data":"192.0.2.1","TTL":60,"type":1}],"Status":0,"TC":false,"RD":true,"RA":false,"AD":false,"CD":false},`+
+		`{"Question":[{"name":"b.example.","type":1}],"Answer":[{"name":"b.example.","data":"192.0.2.1","TTL":60,"type":1}],"Status":0,"TC":false,"RD":true,"RA":false,"AD":false,"CD":false}`+
+		`],"code":200}`, body)
+
+	code, body = doRequest(http.MethodGet, `/api/caches/search?query=(`)
+	test.Assert(t, `search with invalid query`, http.StatusBadRequest, code)
+	test.Assert(t, `search with invalid query`,
+		`{"message":"invalid input: query","name":"ERR_INVALID_INPUT","code":400}`, body)
+
+	code, _ = doRequest(http.MethodDelete, `/api/caches?name=a.example.&name=c.other`)
+	test.Assert(t, `remove code`, http.StatusOK, code)
+
+	var (
+		listAnswer = srv.Caches.ExternalLRU()
+		got        []string
+		an         *Answer
+	)
+	for _, an = range listAnswer {
+		got = append(got, an.QName)
+	}
+	test.Assert(t, `caches after remove`, []string{`b.example`}, got)
+
+	code, body = doRequest(http.MethodGet, `/api/caches`)
+	test.Assert(t, `LRU code`, http.StatusOK, code)
+	test.Assert(t, `LRU has b.example`, true,
+		strings.Contains(body, `"name":"b.example."`))
+	test.Assert(t, `LRU has a.example`, false,
+		strings.Contains(body, `"name":"a.example."`))
+}
@@ -30,6 +30,18 @@ type Answer struct {
 	// This field is used to prune old answer from caches.
 	AccessedAt int64
 
+	// expireAt contains time when the answer is expired, based on the
+	// lowest TTL in the message.
+	expireAt int64
+
+	// hits contains the number of queries answered by this answer since
+	// its received.
+	hits int64
+
+	// prefetchAt contains time when the answer is being prefetched, or
+	// zero if its not.
+	prefetchAt int64
+
 	// RType contains record type, a copy of msg.Question.Type.
 	RType RecordType
 
@@ -57,6 +69,7 @@ func newAnswer(msg *Message, isLocal bool) (an *Answer) {
 	var at = time.Now().Unix()
 	an.ReceivedAt = at
 	an.AccessedAt = at
+	an.expireAt = at + int64(msg.minTTL())
 	if len(msg.Answer) != 0 {
 		an.TTL = msg.Answer[0].TTL
 	}
@@ -99,6 +112,9 @@ func (an *Answer) update(nu *Answer) {
 	if an.ReceivedAt > 0 {
 		an.ReceivedAt = nu.ReceivedAt
 		an.AccessedAt = nu.AccessedAt
+		an.expireAt = nu.expireAt
+		an.hits = 0
+		an.prefetchAt = 0
 	}
 
 	an.Message = nu.Message
@@ -0,0 +1,408 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// List of [Blocklist.Format].
+const (
+	// BlocklistFormatHosts define the list in hosts file format, where
+	// each line is an IP address followed by one or more host names.
+	// The IP address is ignored.
+	// The host names without dot, like "localhost", are ignored.
+	BlocklistFormatHosts = `hosts`
+
+	// BlocklistFormatDomains define the list where each line contains
+	// one domain name.
+	// Text from a "#" character until the end of the line is a comment.
+	BlocklistFormatDomains = `domains`
+
+	// BlocklistFormatRPZ define the list in Response Policy Zone (RPZ)
+	// format, a zone file where the owner name, relative to the zone
+	// origin, is the domain to be blocked.
+	// The supported policy are
+	//
+	//	CNAME .              ; Answer with NXDOMAIN.
+	//	CNAME *.             ; Answer with NODATA.
+	//	CNAME rpz-passthru.  ; Allow the domain.
+	//	A 10.0.0.1           ; Answer with the local address.
+	//	AAAA ::1             ; Answer with the local address.
+	//
+	// Any other policy is ignored and the Action is not used.
+	BlocklistFormatRPZ = `rpz`
+)
+
+// List of [Blocklist.Action].
+const (
+	// BlockActionNXDomain answer the blocked query with response code
+	// RCodeErrName (NXDOMAIN).
+	BlockActionNXDomain = `nxdomain`
+
+	// BlockActionNoData answer the blocked query with empty answer.
+	BlockActionNoData = `nodata`
+
+	// BlockActionSinkhole answer the blocked query with the Sinkhole
+	// addresses.
+	BlockActionSinkhole = `sinkhole`
+)
+
+// blockTTL define the TTL for the sinkhole answer.
+const blockTTL = 60
+
+// Blocklist define a list of domain names that are blocked, or allowed,
+// by the server.
+//
+// Each domain in the list match the query with the same name, while the
+// domain with wildcard prefix "*." match the query for any names under
+// it.
+// If the query name match with more than one domain, the most specific
+// domain is used: the exact name first, then the wildcard with the
+// longest suffix.
+// If the same domain exist in several lists, the allow list take
+// precedence, then the first list.
+//
+// In the INI format, each list is defined in its own "dns" "blocklist"
+// section,
+//
+//	[dns "blocklist"]
+//	name = ads
+//	path = /etc/dns/blocklist/ads.hosts
+//	format = hosts
+//	action = sinkhole
+//	sinkhole = 0.0.0.0
+//
+//	[dns "blocklist"]
+//	path = /etc/dns/blocklist/allow.txt
+//	format = domains
+//	allow = true
+type Blocklist struct {
+	// Name of the list, used to report the hit count.
+	// This field is optional, default to the base name of Path.
+	Name string `ini:"::name"`
+
+	// Path to the list file.
+	Path string `ini:"::path"`
+
+	// Format of the list file, one of [BlocklistFormatHosts],
+	// [BlocklistFormatDomains], or [BlocklistFormatRPZ].
+	// This field is optional, default to "hosts".
+	Format string `ini:"::format"`
+
+	// Action for the blocked query, one of [BlockActionNXDomain],
+	// [BlockActionNoData], or [BlockActionSinkhole].
+	// This field is optional, default to "nxdomain".
+	Action string `ini:"::action"`
+
+	// Sinkhole contains the IPv4 and/or IPv6 addresses for answering
+	// the A and AAAA query, if the Action is "sinkhole".
+	// The query for other types is answered with empty answer.
+	// This field is optional, default to "0.0.0.0" and "::".
+	Sinkhole []string `ini:"::sinkhole"`
+
+	// IsAllow define the list as allow list, an exception for names
+	// in other block lists.
+	IsAllow bool `ini:"::allow"`
+}
+
+// init validate and set the default values.
+func (list *Blocklist) init() (err error) {
+	if len(list.Path) == 0 {
+		return errors.New(`empty path`)
+	}
+	if len(list.Name) == 0 {
+		list.Name = filepath.Base(list.Path)
+	}
+
+	list.Format = strings.ToLower(list.Format)
+	switch list.Format {
+	case ``:
+		list.Format = BlocklistFormatHosts
+	case BlocklistFormatHosts, BlocklistFormatDomains, BlocklistFormatRPZ:
+	default:
+		return fmt.Errorf(`%s: unknown format %q`, list.Name, list.Format)
+	}
+
+	list.Action = strings.ToLower(list.Action)
+	switch list.Action {
+	case ``:
+		list.Action = BlockActionNXDomain
+	case BlockActionNXDomain, BlockActionNoData, BlockActionSinkhole:
+	default:
+		return fmt.Errorf(`%s: unknown action %q`, list.Name, list.Action)
+	}
+
+	if list.Action == BlockActionSinkhole && len(list.Sinkhole) == 0 {
+		list.Sinkhole = []string{`0.0.0.0`, `::`}
+	}
+
+	var addr string
+	for _, addr = range list.Sinkhole {
+		if net.ParseIP(addr) == nil {
+			return fmt.Errorf(`%s: invalid sinkhole address %q`, list.Name, addr)
+		}
+	}
+	return nil
+}
+
+// load read the list file and add each of its domain into blocker.
+func (list *Blocklist) load(bl *blocker) (err error) {
+	var content []byte
+
+	content, err = os.ReadFile(list.Path)
+	if err != nil {
+		return err
+	}
+
+	var (
+		hits = bl.hits[list.Name]
+		rule = &blockRule{
+			hits:    hits,
+			action:  list.Action,
+			addrs:   list.Sinkhole,
+			isAllow: list.IsAllow,
+		}
+		rr     *ResourceRecord
+		line   []byte
+		fields [][]byte
+		name   string
+	)
+	if list.IsAllow {
+		rule.action = ``
+		rule.addrs = nil
+	}
+
+	switch list.Format {
+	case BlocklistFormatHosts:
+		for _, rr = range parse(content) {
+			if !strings.Contains(strings.TrimSuffix(rr.Name, `.`), `.`) {
+				continue
+			}
+			bl.add(rr.Name, rule)
+		}
+
+	case BlocklistFormatDomains:
+		for _, line = range bytes.Split(content, []byte{'\n'}) {
+			line, _, _ = bytes.Cut(line, []byte{'#'})
+			fields = bytes.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			bl.add(string(fields[0]), rule)
+		}
+
+	case BlocklistFormatRPZ:
+		var zone *Zone
+
+		zone, err = ParseZone(content, list.Name, 0)
+		if err != nil {
+			return err
+		}
+		var (
+			origin = `.` + zone.Origin
+			listRR []*ResourceRecord
+		)
+		for name, listRR = range zone.Records {
+			if !strings.HasSuffix(name, origin) {
+				continue
+			}
+			name = strings.TrimSuffix(name, origin)
+			rule = newBlockRuleRPZ(listRR, hits, list.IsAllow)
+			if rule != nil {
+				bl.add(name, rule)
+			}
+		}
+	}
+	return nil
+}
+
+// blockRule define the action for the blocked domain.
+type blockRule struct {
+	// hits is the counter of the list that contains the rule.
+	hits *atomic.Uint64
+
+	action string
+
+	// addrs contains the addresses for sinkhole answer.
+	addrs []string
+
+	isAllow bool
+}
+
+// newBlockRuleRPZ create the rule from RPZ records of the same owner.
+// It return nil if the policy is not supported.
+func newBlockRuleRPZ(listRR []*ResourceRecord, hits *atomic.Uint64, isAllow bool) (rule *blockRule) {
+	var (
+		rr    *ResourceRecord
+		value string
+	)
+
+	rule = &blockRule{
+		hits:    hits,
+		isAllow: isAllow,
+	}
+	for _, rr = range listRR {
+		value, _ = rr.Value.(string)
+		switch rr.Type {
+		case RecordTypeCNAME:
+			switch value {
+			case `.`:
+				rule.action = BlockActionNXDomain
+			case `*.`:
+				rule.action = BlockActionNoData
+			case `rpz-passthru.`:
+				rule.isAllow = true
+			default:
+				return nil
+			}
+			return rule
+		case RecordTypeA, RecordTypeAAAA:
+			rule.action = BlockActionSinkhole
+			rule.addrs = append(rule.addrs, value)
+		}
+	}
+	if len(rule.action) == 0 {
+		return nil
+	}
+	return rule
+}
+
+// response create the answer for blocked query msg.
+func (rule *blockRule) response(msg *Message) (res *Message, err error) {
+	res = &Message{
+		Header: MessageHeader{
+			ID:      msg.Header.ID,
+			IsRD:    msg.Header.IsRD,
+			IsRA:    true,
+			QDCount: 1,
+		},
+		Question: msg.Question,
+	}
+
+	switch rule.action {
+	case BlockActionNXDomain:
+		res.Header.RCode = RCodeErrName
+
+	case BlockActionSinkhole:
+		var (
+			rtype RecordType
+			addr  string
+		)
+		for _, addr = range rule.addrs {
+			rtype = RecordTypeFromAddress([]byte(addr))
+			if rtype != msg.Question.Type {
+				continue
+			}
+			res.Answer = append(res.Answer, ResourceRecord{
+				Name:  msg.Question.Name,
+				Type:  rtype,
+				Class: RecordClassIN,
+				TTL:   blockTTL,
+				Value: addr,
+			})
+		}
+	}
+
+	_, err = res.Pack()
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// blocker contains the domains from all block lists.
+type blocker struct {
+	// names contains the rule for exact domain name.
+	names map[string]*blockRule
+
+	// wildcards contains the rule for domain with wildcard prefix,
+	// indexed by domain without "*.".
+	wildcards map[string]*blockRule
+
+	// hits contains the hit counter for each list, indexed by list
+	// name.
+	hits map[string]*atomic.Uint64
+}
+
+// newBlocker load all of the lists into blocker.
+// The hit counter in prevHits is reused by the list with the same name.
+func newBlocker(lists []Blocklist, prevHits map[string]*atomic.Uint64) (bl *blocker, err error) {
+	var x int
+
+	bl = &blocker{
+		names:     make(map[string]*blockRule),
+		wildcards: make(map[string]*blockRule),
+		hits:      make(map[string]*atomic.Uint64, len(lists)),
+	}
+
+	for x = range len(lists) {
+		var (
+			list = &lists[x]
+			hits = prevHits[list.Name]
+		)
+		if hits == nil {
+			hits = &atomic.Uint64{}
+		}
+		bl.hits[list.Name] = hits
+
+		err = list.load(bl)
+		if err != nil {
+			return nil, fmt.Errorf(`blocklist %s: %w`, list.Name, err)
+		}
+	}
+	return bl, nil
+}
+
+// add the domain name with its rule.
+// The existing rule is replaced only if the new one is allow rule.
+func (bl *blocker) add(name string, rule *blockRule) {
+	var rules = bl.names
+
+	name = strings.TrimSuffix(strings.ToLower(name), `.`)
+	if strings.HasPrefix(name, `*.`) {
+		rules = bl.wildcards
+		name = name[2:]
+	}
+	if len(name) == 0 {
+		return
+	}
+
+	var old = rules[name]
+	if old != nil && (old.isAllow || !rule.isAllow) {
+		return
+	}
+	rules[name] = rule
+}
+
+// match return the most specific rule for qname, or nil if no rules
+// match.
+func (bl *blocker) match(qname string) (rule *blockRule) {
+	qname = strings.TrimSuffix(strings.ToLower(qname), `.`)
+
+	rule = bl.names[qname]
+	if rule != nil {
+		return rule
+	}
+
+	var x int
+	for {
+		x = strings.IndexByte(qname, '.')
+		if x < 0 {
+			return nil
+		}
+		qname = qname[x+1:]
+		rule = bl.wildcards[qname]
+		if rule != nil {
+			return rule
+		}
+	}
+}
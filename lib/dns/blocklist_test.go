@@ -0,0 +1,275 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func testBlocklists() []Blocklist {
+	return []Blocklist{{
+		Path:     `testdata/blocklist/ads.hosts`,
+		Action:   BlockActionSinkhole,
+		Sinkhole: []string{`0.0.0.0`},
+	}, {
+		Name:   `malware`,
+		Path:   `testdata/blocklist/malware.txt`,
+		Format: BlocklistFormatDomains,
+		Action: BlockActionNoData,
+	}, {
+		Path:    `testdata/blocklist/allow.txt`,
+		Format:  BlocklistFormatDomains,
+		IsAllow: true,
+	}, {
+		Path:   `testdata/blocklist/policy.rpz`,
+		Format: BlocklistFormatRPZ,
+	}}
+}
+
+func TestBlocker_match(t *testing.T) {
+	var (
+		opts = ServerOptions{
+			Blocklists: testBlocklists(),
+		}
+		bl  *blocker
+		err error
+	)
+
+	err = opts.initBlocklists()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bl, err = newBlocker(opts.Blocklists, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		qname     string
+		expAction string
+		expAllow  bool
+		expNil    bool
+	}
+
+	var listCase = []testCase{{
+		qname:  `localhost`,
+		expNil: true,
+	}, {
+		qname:     `Ads.Example.Com`,
+		expAction: BlockActionSinkhole,
+	}, {
+		qname:  `sub.ads.example.com`,
+		expNil: true,
+	}, {
+		qname:  `ads.example.net`,
+		expNil: true,
+	}, {
+		qname:     `a.b.ads.example.net`,
+		expAction: BlockActionSinkhole,
+	}, {
+		qname:    `x.good.ads.example.net`,
+		expAllow: true,
+	}, {
+		qname:     `malware.example`,
+		expAction: BlockActionNoData,
+	}, {
+		qname:     `www.malware.example`,
+		expAction: BlockActionNoData,
+	}, {
+		qname:    `good.malware.example`,
+		expAllow: true,
+	}, {
+		qname:     `phishing.example`,
+		expAction: BlockActionNoData,
+	}, {
+		qname:     `nxdomain.example`,
+		expAction: BlockActionNXDomain,
+	}, {
+		qname:     `nodata.example`,
+		expAction: BlockActionNoData,
+	}, {
+		qname:     `www.sinkhole.example`,
+		expAction: BlockActionSinkhole,
+	}, {
+		qname:    `ok.sinkhole.example`,
+		expAllow: true,
+	}, {
+		qname:  `walled.example`,
+		expNil: true,
+	}}
+
+	var (
+		c    testCase
+		rule *blockRule
+	)
+	for _, c = range listCase {
+		rule = bl.match(c.qname)
+		if c.expNil {
+			test.Assert(t, c.qname, true, rule == nil)
+			continue
+		}
+		if rule == nil {
+			t.Fatalf(`%s: expecting rule, got nil`, c.qname)
+		}
+		test.Assert(t, c.qname+` action`, c.expAction, rule.action)
+		test.Assert(t, c.qname+` allow`, c.expAllow, rule.isAllow)
+	}
+}
+
+func TestServer_block(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		file = filepath.Join(dir, `reload.txt`)
+		err  error
+	)
+
+	err = os.WriteFile(file, []byte("reload.example\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var opts = &ServerOptions{
+		Blocklists: testBlocklists(),
+	}
+	opts.Blocklists = append(opts.Blocklists, Blocklist{
+		Name:   `reload`,
+		Path:   file,
+		Format: BlocklistFormatDomains,
+	})
+
+	err = opts.initBlocklists()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var srv = &Server{
+		opts: opts,
+	}
+
+	srv.blocker, err = newBlocker(opts.Blocklists, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		desc      string
+		qname     string
+		expAnswer []string
+		qtype     RecordType
+		expRCode  ResponseCode
+		expBlock  bool
+	}
+
+	var listCase = []testCase{{
+		desc:      `sinkhole A`,
+		qname:     `ads.example.com`,
+		qtype:     RecordTypeA,
+		expBlock:  true,
+		expAnswer: []string{`0.0.0.0`},
+	}, {
+		desc:     `sinkhole AAAA without IPv6 address`,
+		qname:    `ads.example.com`,
+		qtype:    RecordTypeAAAA,
+		expBlock: true,
+	}, {
+		desc:      `RPZ local data AAAA`,
+		qname:     `www.sinkhole.example`,
+		qtype:     RecordTypeAAAA,
+		expBlock:  true,
+		expAnswer: []string{`::1`},
+	}, {
+		desc:     `nxdomain`,
+		qname:    `nxdomain.example`,
+		qtype:    RecordTypeA,
+		expBlock: true,
+		expRCode: RCodeErrName,
+	}, {
+		desc:     `nodata`,
+		qname:    `www.malware.example`,
+		qtype:    RecordTypeMX,
+		expBlock: true,
+	}, {
+		desc:  `allowed`,
+		qname: `good.malware.example`,
+		qtype: RecordTypeA,
+	}, {
+		desc:     `reload`,
+		qname:    `reload.example`,
+		qtype:    RecordTypeA,
+		expBlock: true,
+		expRCode: RCodeErrName,
+	}}
+
+	var (
+		buf bytes.Buffer
+		c   testCase
+		req *request
+		res *Message
+		got []string
+		x   int
+	)
+	for _, c = range listCase {
+		buf.Reset()
+
+		req = newRequest()
+		req.writer = &buf
+		req.message.Header.ID = 1
+		req.message.Question.Name = c.qname
+		req.message.Question.Type = c.qtype
+		req.message.Question.Class = RecordClassIN
+
+		test.Assert(t, c.desc+` block`, c.expBlock, srv.block(req))
+		if !c.expBlock {
+			continue
+		}
+
+		res, err = UnpackMessage(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.Assert(t, c.desc+` RCode`, c.expRCode, res.Header.RCode)
+
+		got = nil
+		for x = range len(res.Answer) {
+			got = append(got, res.Answer[x].Value.(string))
+		}
+		test.Assert(t, c.desc+` answer`, c.expAnswer, got)
+	}
+
+	var expHits = map[string]uint64{
+		`ads.hosts`:  2,
+		`malware`:    1,
+		`allow.txt`:  1,
+		`policy.rpz`: 2,
+		`reload`:     1,
+	}
+	test.Assert(t, `BlocklistHits`, expHits, srv.BlocklistHits())
+
+	// Update the list and reload it.
+	err = os.WriteFile(file, []byte("reload2.example\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = srv.ReloadBlocklists()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req = newRequest()
+	req.writer = &buf
+	req.message.Question.Name = `reload.example`
+	test.Assert(t, `after reload: reload.example`, false, srv.block(req))
+
+	req.message.Question.Name = `reload2.example`
+	test.Assert(t, `after reload: reload2.example`, true, srv.block(req))
+
+	expHits[`reload`] = 2
+	test.Assert(t, `BlocklistHits after reload`, expHits, srv.BlocklistHits())
+}
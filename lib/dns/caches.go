@@ -20,6 +20,10 @@ import (
 
 const (
 	cachesFileFormatV1 = 1
+
+	// cachesSignDelay define the interval to check and refresh the
+	// signatures of internal zones.
+	cachesSignDelay = time.Minute
 )
 
 // Caches of DNS answers.
@@ -48,9 +52,33 @@ type Caches struct {
 
 	debug int
 
+	stats CachesStats
+
 	sync.Mutex
 }
 
+// CachesStats contains the counters of answers that are served or stored
+// through the negative caching and serve-stale.
+type CachesStats struct {
+	// NegativeInsert number of negative answers, name error (NXDOMAIN)
+	// or no data (NODATA), from parent name servers that are stored in
+	// caches.
+	NegativeInsert uint64
+
+	// NegativeHit number of queries that are answered by the cached
+	// negative answers.
+	NegativeHit uint64
+
+	// StaleHit number of queries that are answered by the stale
+	// answers, because the request cannot be forwarded to parent name
+	// servers.
+	StaleHit uint64
+
+	// Prefetch number of popular answers that are refreshed from
+	// parent name servers before they are expired.
+	Prefetch uint64
+}
+
 // cachesFileHeader define the file header when storing caches on storage.
 type cachesFileHeader struct {
 	Version int
@@ -237,6 +265,15 @@ func (c *Caches) query(msg *Message) (an *Answer) {
 	var ans *answers
 
 	c.Lock()
+	defer c.Unlock()
+
+	var zone = c.internalZone(msg.Question.Name)
+	if zone != nil {
+		an = c.authoritative(zone, msg)
+		if an != nil {
+			return an
+		}
+	}
 
 	ans = c.internal[msg.Question.Name]
 	if ans == nil {
@@ -256,38 +293,477 @@ func (c *Caches) query(msg *Message) (an *Answer) {
 	if an.ReceivedAt > 0 {
 		c.lru.MoveToBack(an.el)
 		an.AccessedAt = timeNow().Unix()
+		an.hits++
+
+		if an.Message.isNegative() && !an.Message.IsExpired() {
+			c.stats.NegativeHit++
+		}
 	}
 
 out:
-	c.Unlock()
+	if an == nil && ans == nil {
+		// The name is not exist, it may be the descendant of DNAME
+		// owner.
+		an = c.dnameSynthesize(msg)
+	}
+	return an
+}
 
-	if an == nil {
-		// No answers found in internal and external caches.
-		// If the requested domain is subset of our internal
-		// zone, return answer with error and Authority.
-		var zone = c.internalZone(msg.Question.Name)
-		if zone == nil {
+// authoritative return the answer for msg from the internal zone, as
+// described in RFC 1034 section 4.3.2.
+//
+// The CNAME in the answer is followed, up to maxCNAMEChain, into the same
+// or other internal zone, or into the external caches.
+// If the question name is under the delegation, the answer is a referral
+// with the AA flag unset.
+// If the question name does not exist in the zone, the answer is
+// synthesized from the DNAME in its ancestor, or from the record in
+// internal caches that is not part of the zone (for example from hosts
+// file).
+// It will return nil if the question name does not exist anywhere.
+// The caller must hold the lock.
+func (c *Caches) authoritative(zone *Zone, msg *Message) (an *Answer) {
+	var (
+		qname = msg.Question.Name
+		res   = &Message{
+			Header: MessageHeader{
+				ID:      msg.Header.ID,
+				IsAA:    true,
+				IsRD:    msg.Header.IsRD,
+				QDCount: 1,
+			},
+			Question: msg.Question,
+		}
+		visited = make(map[string]struct{})
+
+		zl  zoneLookup
+		ans *answers
+		n   int
+		ok  bool
+	)
+
+	visited[strings.ToLower(toDomainAbsolute(qname))] = struct{}{}
+
+	for n = 0; n <= maxCNAMEChain; n++ {
+		zl = zone.lookup(qname, msg.Question.Type)
+
+		if n == 0 && zl.rcode == RCodeErrName {
+			an = c.dnameSynthesize(msg)
+			if an != nil {
+				return an
+			}
+			ans = c.internal[strings.TrimSuffix(qname, `.`)]
+			if ans != nil {
+				an, _ = ans.get(msg.Question.Type, msg.Question.Class)
+				if an != nil {
+					return an
+				}
+			}
+		}
+		if zl.isReferral && len(res.Answer) == 0 {
+			res.Header.IsAA = false
+		}
+
+		res.Answer = append(res.Answer, zl.answer...)
+		res.Authority = zl.authority
+		res.Additional = append(res.Additional, zl.additional...)
+		res.Header.RCode = zl.rcode
+
+		if zone.signer != nil && len(res.Answer) == 0 && !zl.isReferral {
+			_ = zone.addDenial(res)
+		}
+
+		if len(zl.target) == 0 {
+			break
+		}
+
+		qname = strings.ToLower(toDomainAbsolute(zl.target))
+		_, ok = visited[qname]
+		if ok {
+			break
+		}
+		visited[qname] = struct{}{}
+
+		zone = c.internalZone(qname)
+		if zone != nil {
+			continue
+		}
+
+		// The CNAME target is outside of internal zones.
+		res.Authority = nil
+		ans = c.external[strings.TrimSuffix(qname, `.`)]
+		if ans != nil {
+			an, _ = ans.get(msg.Question.Type, msg.Question.Class)
+			if an != nil && !an.Message.IsExpired() {
+				res.Answer = append(res.Answer, an.Message.Answer...)
+			}
+		}
+		break
+	}
+
+	res.Header.ANCount = uint16(len(res.Answer))
+	res.Header.NSCount = uint16(len(res.Authority))
+	res.Header.ARCount = uint16(len(res.Additional))
+
+	var err error
+
+	_, err = res.Pack()
+	if err != nil {
+		log.Printf(`dns: authoritative %s: %s`, msg.Question.Name, err)
+		return nil
+	}
+	return newAnswer(res, true)
+}
+
+// dnameSynthesize search the DNAME record in the ancestors of question
+// name and, if its found, return the answer that contains the DNAME and
+// the synthesized CNAME, as described in RFC 6672 section 3.1.
+// If the target of CNAME exist in caches, its records is appended to the
+// answer.
+// The caller must hold the lock.
+func (c *Caches) dnameSynthesize(msg *Message) (an *Answer) {
+	var (
+		qname = msg.Question.Name
+		owner = qname
+
+		ans     *answers
+		anDName *Answer
+		rrDName *ResourceRecord
+		x       int
+	)
+
+	for anDName == nil {
+		x = strings.IndexByte(owner, '.')
+		if x < 0 {
 			return nil
 		}
-		an = &Answer{
-			Message: msg,
+		owner = owner[x+1:]
+		ans = c.internal[owner]
+		if ans == nil {
+			ans = c.external[owner]
+			if ans == nil {
+				continue
+			}
 		}
-		_ = an.Message.AddAuthority(zone.soaRecord())
-		an.Message.SetResponseCode(RCodeErrName)
+		anDName, _ = ans.get(RecordTypeDNAME, msg.Question.Class)
 	}
-	return an
+
+	for x = range anDName.Message.Answer {
+		if anDName.Message.Answer[x].Type == RecordTypeDNAME {
+			rrDName = &anDName.Message.Answer[x]
+			break
+		}
+	}
+	if rrDName == nil {
+		return nil
+	}
+
+	var (
+		target, _ = rrDName.Value.(string)
+		res       = &Message{
+			Header: MessageHeader{
+				ID:      msg.Header.ID,
+				IsAA:    anDName.ReceivedAt == 0,
+				IsRD:    msg.Header.IsRD,
+				QDCount: 1,
+			},
+			Question: msg.Question,
+		}
+		cname string
+	)
+
+	target = strings.TrimSuffix(target, `.`)
+	cname = strings.TrimSuffix(qname[:len(qname)-len(owner)], `.`)
+	if len(target) != 0 {
+		cname += `.` + target
+	}
+	if len(cname) > 253 {
+		// The synthesized name is too long.
+		res.Header.RCode = RCodeYXDomain
+		res.Answer = append(res.Answer, anDName.Message.Answer...)
+		_, _ = res.Pack()
+		return newAnswer(res, true)
+	}
+
+	res.Answer = append(res.Answer, anDName.Message.Answer...)
+	res.Answer = append(res.Answer, ResourceRecord{
+		Name:  qname,
+		Type:  RecordTypeCNAME,
+		Class: rrDName.Class,
+		TTL:   rrDName.TTL,
+		Value: cname,
+	})
+
+	if msg.Question.Type != RecordTypeCNAME {
+		ans = c.internal[cname]
+		if ans == nil {
+			ans = c.external[cname]
+		}
+		if ans != nil {
+			an, _ = ans.get(msg.Question.Type, msg.Question.Class)
+			if an != nil {
+				res.Answer = append(res.Answer, an.Message.Answer...)
+			}
+		}
+	}
+
+	var err error
+
+	_, err = res.Pack()
+	if err != nil {
+		log.Printf(`dns: dnameSynthesize %s: %s`, qname, err)
+		return nil
+	}
+	return newAnswer(res, true)
 }
 
-// internalZone will return the zone if the query name is suffix of one of
-// the Zone Origin.
-func (c *Caches) internalZone(qname string) (zone *Zone) {
-	qname = toDomainAbsolute(qname)
+// stale return the packet of expired external answer for msg, with all of
+// its TTL set to ttl, if the answer has not been expired longer than
+// window, as described in RFC 8767.
+func (c *Caches) stale(msg *Message, window, ttl time.Duration) (packet []byte) {
+	var (
+		now = time.Now().Unix()
+
+		ans *answers
+		an  *Answer
+	)
+
+	c.Lock()
+	defer c.Unlock()
+
+	ans = c.external[msg.Question.Name]
+	if ans == nil {
+		return nil
+	}
+	an, _ = ans.get(msg.Question.Type, msg.Question.Class)
+	if an == nil {
+		return nil
+	}
+	if now-an.expireAt > int64(window.Seconds()) {
+		return nil
+	}
+
+	packet = an.Message.packetWithTTL(uint32(ttl.Seconds()))
+	c.stats.StaleHit++
+
+	return packet
+}
+
+// needPrefetch return true if the external answer has been queried at
+// least minHits times and its remaining TTL is less than threshold
+// fraction of its original TTL.
+// Once its return true, the answer is marked as being prefetched and the
+// next call return false, until the answer is updated or the
+// prefetchTimeout has passed.
+func (c *Caches) needPrefetch(an *Answer, threshold float64, minHits int64) bool {
+	var now = time.Now().Unix()
+
+	c.Lock()
+	defer c.Unlock()
+
+	if an.ReceivedAt == 0 || an.Message == nil {
+		return false
+	}
+	if an.hits < minHits {
+		return false
+	}
+	if now-an.prefetchAt < int64(prefetchTimeout.Seconds()) {
+		return false
+	}
+
+	var (
+		ttl  = an.expireAt - an.ReceivedAt
+		left = an.expireAt - now
+	)
+	if left <= 0 || float64(left) >= threshold*float64(ttl) {
+		return false
+	}
+
+	an.prefetchAt = now
+	c.stats.Prefetch++
+	return true
+}
+
+// Stats return the current counters of caches.
+func (c *Caches) Stats() (stats CachesStats) {
+	c.Lock()
+	stats = c.stats
+	c.Unlock()
+	return stats
+}
+
+// refreshSignatures re-sign the internal zones whose signatures reach the
+// half of its validity.
+func (c *Caches) refreshSignatures() {
+	var zone *Zone
+
+	c.Lock()
+	defer c.Unlock()
+
 	for _, zone = range c.zone {
-		if strings.HasSuffix(qname, `.`+zone.Origin) {
+		c.refreshZoneSignatures(zone)
+	}
+}
+
+// refreshZoneSignatures re-sign the zone if its signatures reach the half
+// of its validity, and replace the internal answers with the new
+// signatures.
+// The caller must hold the lock.
+func (c *Caches) refreshZoneSignatures(zone *Zone) {
+	if zone.signer == nil || zone.signer.refreshAt > timeNow().Unix() {
+		return
+	}
+
+	c.internalZoneRemove(zone)
+	var err = zone.sign()
+	c.internalZoneInsert(zone)
+
+	if err != nil {
+		log.Printf(`dns: refreshSignatures %s: %s`, zone.Origin, err)
+	}
+}
+
+// internalZone return the zone whose origin is the longest suffix of the
+// query name, including the query name itself.
+func (c *Caches) internalZone(qname string) (zone *Zone) {
+	var x int
+
+	qname = strings.ToLower(toDomainAbsolute(qname))
+	for {
+		zone = c.zone[qname]
+		if zone != nil {
 			return zone
 		}
+		x = strings.IndexByte(qname, '.')
+		if x < 0 || x == len(qname)-1 {
+			break
+		}
+		qname = qname[x+1:]
+	}
+	return c.zone[`.`]
+}
+
+// transferRecords return the list of records in the internal zone for zone
+// transfer request msg.
+// For IXFR, the serial is the SOA serial that the client has.
+// It will return false if the question name is not an origin of internal
+// zones.
+func (c *Caches) transferRecords(msg *Message, serial uint32) (list []ResourceRecord, ok bool) {
+	var origin = strings.ToLower(toDomainAbsolute(msg.Question.Name))
+
+	c.Lock()
+	defer c.Unlock()
+
+	var zone = c.zone[origin]
+	if zone == nil {
+		return nil, false
+	}
+
+	c.refreshZoneSignatures(zone)
+
+	if msg.Question.Type == RecordTypeIXFR {
+		list, ok = zone.incrementalRecords(serial)
+		if ok {
+			return list, true
+		}
+	}
+	return zone.transferRecords(), true
+}
+
+// internalZoneByOrigin return the internal zone by its origin.
+func (c *Caches) internalZoneByOrigin(origin string) (zone *Zone) {
+	origin = strings.ToLower(toDomainAbsolute(origin))
+
+	c.Lock()
+	zone = c.zone[origin]
+	c.Unlock()
+
+	return zone
+}
+
+// updateZone apply the dynamic update message msg into internal zone and
+// replace the internal answers with the updated zone messages.
+// If the zone changes, the NOTIFY is sent to the zone Secondaries after
+// the internal answers replaced.
+func (c *Caches) updateZone(zone *Zone, msg *Message) (rcode ResponseCode, err error) {
+	c.Lock()
+	defer c.Unlock()
+
+	var serial = zone.SOA.Serial
+
+	c.internalZoneRemove(zone)
+	rcode, err = zone.update(msg)
+	c.internalZoneInsert(zone)
+
+	if zone.SOA.Serial != serial {
+		zone.notify()
+	}
+	return rcode, err
+}
+
+// internalZoneReplace replace the internal zone old and its answers with
+// the zone nu.
+// If old is nil, the zone nu is added; if nu is nil, the zone old is
+// removed.
+func (c *Caches) internalZoneReplace(old, nu *Zone) {
+	c.Lock()
+	defer c.Unlock()
+
+	if old != nil {
+		c.internalZoneRemove(old)
+		delete(c.zone, old.Origin)
+		old.caches = nil
+	}
+	if nu != nil {
+		c.zone[nu.Origin] = nu
+		nu.caches = c
+		c.internalZoneInsert(nu)
+	}
+}
+
+// internalZoneUpdate run the function fn that modify the zone and replace
+// the internal answers of zone with the updated zone messages.
+func (c *Caches) internalZoneUpdate(zone *Zone, fn func() error) (err error) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.internalZoneRemove(zone)
+	err = fn()
+	c.internalZoneInsert(zone)
+
+	return err
+}
+
+// internalZoneInsert insert the zone messages into internal answers.
+// The caller must hold the lock.
+func (c *Caches) internalZoneInsert(zone *Zone) {
+	var (
+		an  *Answer
+		ans *answers
+		msg *Message
+	)
+	for _, msg = range zone.messages {
+		if len(msg.Answer) == 0 {
+			continue
+		}
+		an = newAnswer(msg, true)
+		ans = c.internal[an.QName]
+		if ans == nil {
+			c.internal[an.QName] = newAnswers(an)
+			continue
+		}
+		ans.upsert(an)
+	}
+}
+
+// internalZoneRemove remove the internal answers of all names in the
+// zone.
+// The caller must hold the lock.
+func (c *Caches) internalZoneRemove(zone *Zone) {
+	var msg *Message
+	for _, msg = range zone.messages {
+		delete(c.internal, strings.TrimSuffix(msg.Question.Name, `.`))
 	}
-	return nil
 }
 
 // InternalPopulate add list of message to internal caches.
@@ -343,7 +819,11 @@ func (c *Caches) InternalPopulateZone(zone *Zone) {
 	if len(zone.Origin) == 0 {
 		return
 	}
+	c.Lock()
 	c.zone[zone.Origin] = zone
+	zone.caches = c
+	c.Unlock()
+
 	c.InternalPopulate(zone.Messages(), zone.Path)
 }
 
@@ -520,6 +1000,7 @@ func (c *Caches) read(r io.Reader) (answers []*Answer, err error) {
 		answer = newAnswer(msg, false)
 		answer.ReceivedAt = item.ReceivedAt
 		answer.AccessedAt = item.AccessedAt
+		answer.expireAt = item.ReceivedAt + int64(msg.minTTL())
 
 		answers = append(answers, answer)
 	}
@@ -552,6 +1033,9 @@ func (c *Caches) upsert(nu *Answer) (an *Answer, inserted bool) {
 			an, inserted = answers.upsert(nu)
 		}
 	} else {
+		if nu.Message.isNegative() {
+			c.stats.NegativeInsert++
+		}
 		answers = c.external[nu.QName]
 		if answers == nil {
 			answers = newAnswers(nu)
@@ -571,7 +1055,8 @@ func (c *Caches) upsert(nu *Answer) (an *Answer, inserted bool) {
 	return an, inserted
 }
 
-// worker for pruning unused caches.
+// worker for pruning unused caches and refreshing the signatures of
+// internal zones.
 //
 // The worker prune process will run based on prune delay and it will remove
 // any cached answer that has not been accessed less than prune threshold
@@ -579,17 +1064,24 @@ func (c *Caches) upsert(nu *Answer) (an *Answer, inserted bool) {
 func (c *Caches) worker(pruneDelay, pruneThreshold time.Duration) {
 	var (
 		pruneTimer = time.NewTimer(pruneDelay)
+		signTicker = time.NewTicker(cachesSignDelay)
 
 		now        time.Time
 		listAnswer []*Answer
 		exp        int64
 	)
 
-	for now = range pruneTimer.C {
-		exp = now.Add(pruneThreshold).Unix()
-		listAnswer = c.prune(exp)
-		if c.debug&DebugLevelCache != 0 {
-			log.Printf(`dns: pruning %d records from cache`, len(listAnswer))
+	for {
+		select {
+		case now = <-pruneTimer.C:
+			exp = now.Add(pruneThreshold).Unix()
+			listAnswer = c.prune(exp)
+			if c.debug&DebugLevelCache != 0 {
+				log.Printf(`dns: pruning %d records from cache`, len(listAnswer))
+			}
+
+		case <-signTicker.C:
+			c.refreshSignatures()
 		}
 	}
 }
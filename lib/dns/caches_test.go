@@ -6,6 +6,8 @@ package dns
 
 import (
 	"bytes"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -342,6 +344,9 @@ func TestCaches_internalZone(t *testing.T) {
 	var listCase = []testCase{{
 		qname: `notmy.internal`,
 		exp:   false,
+	}, {
+		qname: `my.internal`,
+		exp:   true,
 	}, {
 		qname: `sub.my.internal`,
 		exp:   true,
@@ -359,3 +364,479 @@ func TestCaches_internalZone(t *testing.T) {
 		test.Assert(t, c.qname, c.exp, got != nil)
 	}
 }
+
+func TestCaches_dnameSynthesize(t *testing.T) {
+	type testCase struct {
+		desc      string
+		qname     string
+		expAnswer []string
+		qtype     RecordType
+		expRCode  ResponseCode
+	}
+
+	var (
+		zoneData = []byte(`
+frobozz 3600 IN DNAME frobozz-division
+www.frobozz-division 3600 IN A 10.0.0.1
+`)
+		caches = &Caches{}
+
+		zone *Zone
+		err  error
+	)
+
+	zone, err = ParseZone(zoneData, `example.com`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caches.init(0, 0, 0)
+	caches.InternalPopulateZone(zone)
+
+	var (
+		label = strings.Repeat(`a`, 45)
+		long  = strings.Repeat(label+`.`, 5) + `frobozz.example.com`
+	)
+
+	var listCase = []testCase{{
+		desc:  `With target exist`,
+		qname: `www.frobozz.example.com`,
+		qtype: RecordTypeA,
+		expAnswer: []string{
+			`frobozz.example.com. DNAME frobozz-division.example.com.`,
+			`www.frobozz.example.com CNAME www.frobozz-division.example.com`,
+			`www.frobozz-division.example.com. A 10.0.0.1`,
+		},
+	}, {
+		desc:  `With target not exist`,
+		qname: `a.b.frobozz.example.com`,
+		qtype: RecordTypeA,
+		expAnswer: []string{
+			`frobozz.example.com. DNAME frobozz-division.example.com.`,
+			`a.b.frobozz.example.com CNAME a.b.frobozz-division.example.com`,
+		},
+	}, {
+		desc:  `With query CNAME`,
+		qname: `www.frobozz.example.com`,
+		qtype: RecordTypeCNAME,
+		expAnswer: []string{
+			`frobozz.example.com. DNAME frobozz-division.example.com.`,
+			`www.frobozz.example.com CNAME www.frobozz-division.example.com`,
+		},
+	}, {
+		desc:     `With synthesized name too long`,
+		qname:    long,
+		qtype:    RecordTypeA,
+		expRCode: RCodeYXDomain,
+		expAnswer: []string{
+			`frobozz.example.com. DNAME frobozz-division.example.com.`,
+		},
+	}}
+
+	var (
+		c   testCase
+		an  *Answer
+		rr  ResourceRecord
+		got []string
+	)
+	for _, c = range listCase {
+		an = caches.query(&Message{
+			Question: MessageQuestion{
+				Name:  c.qname,
+				Type:  c.qtype,
+				Class: RecordClassIN,
+			},
+		})
+		if an == nil {
+			t.Fatalf(`%s: expecting answer, got nil`, c.desc)
+		}
+
+		got = got[:0]
+		for _, rr = range an.Message.Answer {
+			got = append(got, fmt.Sprintf(`%s %s %v`, rr.Name,
+				RecordTypeNames[rr.Type], rr.Value))
+		}
+		test.Assert(t, c.desc+`: RCode`, c.expRCode, an.Message.Header.RCode)
+		test.Assert(t, c.desc+`: IsAA`, true, an.Message.Header.IsAA)
+		test.Assert(t, c.desc, c.expAnswer, got)
+	}
+}
+
+func TestServer_negativeCache(t *testing.T) {
+	var (
+		zoneData = []byte(`@ SOA ns1 admin 1 3600 900 604800 300
+@ NS ns1
+ns1 A 10.0.0.53
+`)
+		zone *Zone
+		err  error
+	)
+
+	zone, err = ParseZone(zoneData, `negative.test`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var upstream *Server
+
+	upstream, err = NewServer(&ServerOptions{
+		ListenAddress: `127.0.0.1:5320`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream.Caches.InternalPopulateZone(zone)
+
+	go func() {
+		_ = upstream.ListenAndServe()
+	}()
+	t.Cleanup(upstream.Stop)
+
+	var srv *Server
+
+	srv, err = NewServer(&ServerOptions{
+		ListenAddress:       `127.0.0.1:5321`,
+		NameServers:         []string{`udp://127.0.0.1:5320`},
+		NegativeCacheMaxTTL: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		_ = srv.ListenAndServe()
+	}()
+	t.Cleanup(srv.Stop)
+
+	var cl *UDPClient
+
+	cl, err = NewUDPClient(`127.0.0.1:5321`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Close()
+
+	var (
+		q = MessageQuestion{
+			Name: `none.negative.test`,
+			Type: RecordTypeA,
+		}
+
+		res *Message
+		x   int
+	)
+	// Wait until the server forwarders are connected.
+	for x = 0; x < 50; x++ {
+		res, err = cl.Lookup(q, true)
+		if err == nil && res.Header.RCode != RCodeErrServer {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `RCode`, RCodeErrName, res.Header.RCode)
+
+	// The answer is stored after its written to client.
+	for x = 0; x < 50; x++ {
+		if srv.Caches.Stats().NegativeInsert != 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	res, err = cl.Lookup(q, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `cached RCode`, RCodeErrName, res.Header.RCode)
+	test.Assert(t, `cached SOA`, RecordTypeSOA, res.Authority[0].Type)
+	test.Assert(t, `cached TTL <= 60`, true, res.Authority[0].TTL <= 60)
+
+	var exp = CachesStats{
+		NegativeInsert: 1,
+		NegativeHit:    1,
+	}
+	test.Assert(t, `Stats`, exp, srv.Caches.Stats())
+}
+
+func TestServer_serveStale(t *testing.T) {
+	var (
+		srv *Server
+		err error
+	)
+
+	srv, err = NewServer(&ServerOptions{
+		ListenAddress: `127.0.0.1:5322`,
+		StaleWindow:   time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msg = &Message{
+		Header: MessageHeader{
+			ID:      1,
+			QDCount: 1,
+		},
+		Question: MessageQuestion{
+			Name:  `stale.test`,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+		},
+		Answer: []ResourceRecord{{
+			Name:  `stale.test`,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+			Value: `10.0.0.1`,
+		}},
+	}
+	_, err = msg.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var an *Answer
+
+	an, _ = srv.Caches.upsert(newAnswer(msg, false))
+
+	go func() {
+		_ = srv.ListenAndServe()
+	}()
+	t.Cleanup(srv.Stop)
+
+	var cl *UDPClient
+
+	cl, err = NewUDPClient(`127.0.0.1:5322`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Close()
+
+	var (
+		q = MessageQuestion{
+			Name: `stale.test`,
+			Type: RecordTypeA,
+		}
+
+		res *Message
+		x   int
+	)
+	for x = 0; x < 50; x++ {
+		res, err = cl.Lookup(q, false)
+		if err == nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `RCode`, RCodeOK, res.Header.RCode)
+	test.Assert(t, `stale answer`, `10.0.0.1`, res.Answer[0].Value)
+	test.Assert(t, `stale TTL`, uint32(30), res.Answer[0].TTL)
+	test.Assert(t, `StaleHit`, uint64(1), srv.Caches.Stats().StaleHit)
+
+	// The answer has been expired longer than StaleWindow.
+	srv.Caches.Lock()
+	an.expireAt = time.Now().Unix() - 7200
+	srv.Caches.Unlock()
+
+	res, err = cl.Lookup(q, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `expired RCode`, RCodeErrServer, res.Header.RCode)
+}
+
+func TestCaches_needPrefetch(t *testing.T) {
+	type testCase struct {
+		desc       string
+		receivedAt int64
+		expireAt   int64
+		hits       int64
+		prefetchAt int64
+		exp        bool
+	}
+
+	var (
+		now = time.Now().Unix()
+
+		listCase = []testCase{{
+			desc:       `Local answer`,
+			receivedAt: 0,
+			expireAt:   now + 5,
+			hits:       10,
+		}, {
+			desc:       `Not popular`,
+			receivedAt: now - 95,
+			expireAt:   now + 5,
+			hits:       2,
+		}, {
+			desc:       `TTL above threshold`,
+			receivedAt: now - 50,
+			expireAt:   now + 50,
+			hits:       10,
+		}, {
+			desc:       `Expired`,
+			receivedAt: now - 100,
+			expireAt:   now,
+			hits:       10,
+		}, {
+			desc:       `Being prefetched`,
+			receivedAt: now - 95,
+			expireAt:   now + 5,
+			hits:       10,
+			prefetchAt: now - 1,
+		}, {
+			desc:       `Prefetch timeout`,
+			receivedAt: now - 95,
+			expireAt:   now + 5,
+			hits:       10,
+			prefetchAt: now - 60,
+			exp:        true,
+		}, {
+			desc:       `Popular and nearly expired`,
+			receivedAt: now - 95,
+			expireAt:   now + 5,
+			hits:       3,
+			exp:        true,
+		}}
+
+		caches = &Caches{}
+		an     *Answer
+		c      testCase
+		got    bool
+		nexp   uint64
+	)
+
+	for _, c = range listCase {
+		an = &Answer{
+			Message:    NewMessage(),
+			ReceivedAt: c.receivedAt,
+			expireAt:   c.expireAt,
+			hits:       c.hits,
+			prefetchAt: c.prefetchAt,
+		}
+		got = caches.needPrefetch(an, 0.1, 3)
+		test.Assert(t, c.desc, c.exp, got)
+		if got {
+			nexp++
+			// The second call return false while its being
+			// prefetched.
+			test.Assert(t, c.desc+`: second call`, false,
+				caches.needPrefetch(an, 0.1, 3))
+		}
+	}
+	test.Assert(t, `Stats.Prefetch`, nexp, caches.Stats().Prefetch)
+}
+
+func TestServer_prefetch(t *testing.T) {
+	var (
+		zoneData = []byte(`@ SOA ns1 admin 1 3600 900 604800 300
+www A 10.5.0.1
+`)
+
+		upstream *Server
+		zone     *Zone
+		err      error
+	)
+
+	zone, err = ParseZone(zoneData, `prefetch.test`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream, err = NewServer(&ServerOptions{
+		ListenAddress: `127.0.0.1:5345`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream.Caches.InternalPopulateZone(zone)
+	go func() {
+		_ = upstream.ListenAndServe()
+	}()
+	t.Cleanup(upstream.Stop)
+
+	var srv *Server
+
+	srv, err = NewServer(&ServerOptions{
+		ListenAddress:     `127.0.0.1:5346`,
+		NameServers:       []string{`udp://127.0.0.1:5345`},
+		PrefetchThreshold: 0.2,
+		PrefetchMinHits:   2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.ListenAndServe()
+	}()
+	t.Cleanup(srv.Stop)
+
+	var x int
+	for x = 0; x < 50 && !srv.fw.isActive(); x++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	var cl *UDPClient
+
+	cl, err = NewUDPClient(`127.0.0.1:5346`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Close()
+
+	var (
+		q = MessageQuestion{
+			Name: `www.prefetch.test`,
+			Type: RecordTypeA,
+		}
+		res *Message
+	)
+
+	// The first query is forwarded and stored in caches.
+	res, err = cl.Lookup(q, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `Answer`, `10.5.0.1`, res.Answer[0].Value)
+
+	// Make the answer nearly expired, 10 seconds left from 100
+	// seconds.
+	var (
+		now = time.Now().Unix()
+		an  *Answer
+	)
+	srv.Caches.Lock()
+	an, _ = srv.Caches.external[q.Name].get(RecordTypeA, RecordClassIN)
+	an.ReceivedAt = now - 90
+	an.expireAt = now + 10
+	srv.Caches.Unlock()
+
+	// The answer is prefetched on the second hit.
+	for x = range 2 {
+		res, err = cl.Lookup(q, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.Assert(t, `Answer from caches`, `10.5.0.1`, res.Answer[0].Value)
+	}
+
+	var expireAt int64
+	for x = 0; x < 50; x++ {
+		srv.Caches.Lock()
+		expireAt = an.expireAt
+		srv.Caches.Unlock()
+		if expireAt > now+10 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if expireAt <= now+10 {
+		t.Fatalf(`expecting the answer is refreshed, got expireAt %d`, expireAt)
+	}
+	test.Assert(t, `Stats.Prefetch`, uint64(1), srv.Caches.Stats().Prefetch)
+	test.Assert(t, `Forwarder queries`, uint64(2), srv.ForwarderStats()[0].Queries)
+}
@@ -9,8 +9,11 @@
 //   - RFC1035 DOMAIN NAMES - IMPLEMENTATION AND SPECIFICATION
 //   - RFC1886 DNS Extensions to support IP version 6.
 //   - RFC2782 A DNS RR for specifying the location of services (DNS SRV)
+//   - RFC4034 Resource Records for the DNS Security Extensions
+//   - RFC5155 DNS Security (DNSSEC) Hashed Authenticated Denial of Existence
 //   - RFC6891 Extension Mechanisms for DNS (EDNS(0))
 //   - RFC8484 DNS Queries over HTTPS (DoH)
+//   - RFC9156 DNS Query Name Minimisation to Improve Privacy
 //   - RFC9460 Service Binding and Parameter Specification via the DNS (SVCB
 //     and HTTPS Resource Records)
 package dns
@@ -32,6 +35,23 @@ const (
 	// DefaultHTTPPort define default port for DNS over HTTPS.
 	DefaultHTTPPort        uint16        = 443
 	defaultHTTPIdleTimeout time.Duration = 120 * time.Second
+
+	// defaultStaleTTL define the TTL for stale answer, as recommended
+	// by RFC 8767 section 4.
+	defaultStaleTTL = 30 * time.Second
+
+	// Default maximum source prefix length for ECS, as recommended by
+	// RFC 7871 section 11.1.
+	defaultClientSubnetPrefixV4 = 24
+	defaultClientSubnetPrefixV6 = 56
+
+	// Default values for response rate limiting, taken from BIND.
+	defaultRateLimitSlip   = 2
+	defaultRateLimitWindow = 15 * time.Second
+
+	// Default values for cache prefetching.
+	defaultPrefetchMinHits       = 3
+	defaultPrefetchMaxConcurrent = 8
 )
 
 const (
@@ -70,6 +90,9 @@ const (
 	OpCodeQuery  OpCode = iota // A standard query (QUERY)
 	OpCodeIQuery               // An inverse query (IQUERY), obsolete by RFC3425
 	OpCodeStatus               // A server status request (STATUS)
+
+	OpCodeNotify OpCode = 4 // A zone change notification (NOTIFY), RFC 1996.
+	OpCodeUpdate OpCode = 5 // A dynamic update (UPDATE), RFC 2136.
 )
 
 // ResponseCode define response code in message header.
@@ -101,6 +124,29 @@ const (
 	// name server may not wish to perform a particular operation (e.g.,
 	// zone transfer) for particular data.
 	RCodeRefused
+
+	// YXDomain - Some name that ought not to exist, does exist.
+	// Used in dynamic update, RFC 2136.
+	RCodeYXDomain
+
+	// YXRRSet - Some RRset that ought not to exist, does exist.
+	RCodeYXRRSet
+
+	// NXRRSet - Some RRset that ought to exist, does not exist.
+	RCodeNXRRSet
+
+	// NotAuth - The server is not authoritative for the zone named in
+	// the Zone Section, or the request is not authorized (RFC 8945).
+	RCodeNotAuth
+
+	// NotZone - A name used in the Prerequisite or Update Section is not
+	// within the zone denoted by the Zone Section.
+	RCodeNotZone
+
+	// BADCOOKIE - Bad or missing server cookie (RFC 7873).
+	// This is extended response code, the upper 8 bits is stored in
+	// the OPT record.
+	RCodeBadCookie ResponseCode = 23
 )
 
 // rcodeNames contains mapping of response code with their human readable
@@ -112,6 +158,12 @@ var rcodeNames = map[ResponseCode]string{
 	RCodeErrName:        "ERR_NAME",
 	RCodeNotImplemented: "ERR_NOT_IMPLEMENTED",
 	RCodeRefused:        "ERR_REFUSED",
+	RCodeYXDomain:       `ERR_YXDOMAIN`,
+	RCodeYXRRSet:        `ERR_YXRRSET`,
+	RCodeNXRRSet:        `ERR_NXRRSET`,
+	RCodeNotAuth:        `ERR_NOTAUTH`,
+	RCodeNotZone:        `ERR_NOTZONE`,
+	RCodeBadCookie:      `ERR_BADCOOKIE`,
 }
 
 // timeNow return the current time.
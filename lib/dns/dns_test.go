@@ -52,6 +52,7 @@ func runServer() {
 			TLSCertFile:      "testdata/domain.crt",
 			TLSPrivateKey:    "testdata/domain.key",
 			TLSAllowInsecure: true,
+			TransferAllow:    []string{`127.0.0.1`},
 		}
 
 		err error
@@ -0,0 +1,489 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// dnssecName normalize the domain name into lower case without the
+// trailing dot.
+// The root domain is represented by an empty string.
+func dnssecName(name string) string {
+	name = strings.ToLower(name)
+	return strings.TrimSuffix(name, `.`)
+}
+
+// dnssecLabels return the number of labels in the name, excluding the root
+// label and the wildcard label.
+func dnssecLabels(name string) (n int) {
+	name = dnssecName(name)
+	if len(name) == 0 {
+		return 0
+	}
+	n = strings.Count(name, `.`) + 1
+	if strings.HasPrefix(name, `*.`) || name == `*` {
+		n--
+	}
+	return n
+}
+
+// dnssecParent return the parent of name.
+// It will return false if the name is root.
+func dnssecParent(name string) (parent string, ok bool) {
+	name = dnssecName(name)
+	if len(name) == 0 {
+		return ``, false
+	}
+	var x = strings.IndexByte(name, '.')
+	if x < 0 {
+		return ``, true
+	}
+	return name[x+1:], true
+}
+
+// dnssecIsSubdomain return true if the name is equal to or under the zone.
+func dnssecIsSubdomain(name, zone string) bool {
+	name = dnssecName(name)
+	zone = dnssecName(zone)
+	if len(zone) == 0 || name == zone {
+		return true
+	}
+	return strings.HasSuffix(name, `.`+zone)
+}
+
+// dnssecQueryName return the name to be used in question, where root
+// domain is represented by ".".
+func dnssecQueryName(name string) string {
+	if len(name) == 0 {
+		return `.`
+	}
+	return name
+}
+
+// dnssecCompareName compare two domain names using canonical DNS name
+// order, as defined in RFC 4034 section 6.1.
+// It will return -1 if a sort before b, 1 if a sort after b, or 0 if both
+// are equal.
+func dnssecCompareName(a, b string) int {
+	a = dnssecName(a)
+	b = dnssecName(b)
+
+	var labelsA, labelsB []string
+	if len(a) > 0 {
+		labelsA = strings.Split(a, `.`)
+	}
+	if len(b) > 0 {
+		labelsB = strings.Split(b, `.`)
+	}
+
+	var (
+		xa = len(labelsA) - 1
+		xb = len(labelsB) - 1
+		c  int
+	)
+	for ; xa >= 0 && xb >= 0; xa, xb = xa-1, xb-1 {
+		c = strings.Compare(labelsA[xa], labelsB[xb])
+		if c != 0 {
+			return c
+		}
+	}
+	switch {
+	case xa < 0 && xb < 0:
+		return 0
+	case xa < 0:
+		return -1
+	}
+	return 1
+}
+
+// canonicalRData return the RDATA of rr in canonical form, where the
+// domain names in RDATA is in lower case and not compressed, as described in
+// RFC 4034 section 6.2.
+func canonicalRData(rr *ResourceRecord) []byte {
+	var msg = Message{}
+
+	msg.packRData(rr)
+	if len(msg.packet) < 2 {
+		// Unknown type, use the raw RDATA.
+		return rr.rdata
+	}
+	return msg.packet[2:]
+}
+
+// dnssecSignedData return the data to be signed or verified for the RRset,
+// as described in RFC 4034 section 3.1.8.1.
+func dnssecSignedData(rrsig *RDataRRSIG, rrset []ResourceRecord) (data []byte, err error) {
+	if len(rrset) == 0 {
+		return nil, errors.New(`empty RRset`)
+	}
+
+	var (
+		owner  = dnssecName(rrset[0].Name)
+		labels = dnssecLabels(owner)
+	)
+	if int(rrsig.Labels) > labels {
+		return nil, fmt.Errorf(`invalid RRSIG labels %d for %q`, rrsig.Labels, owner)
+	}
+	if int(rrsig.Labels) < labels {
+		// The RRset is the result of wildcard expansion.
+		var list = strings.Split(owner, `.`)
+		owner = `*.` + strings.Join(list[len(list)-int(rrsig.Labels):], `.`)
+	}
+
+	var (
+		wireOwner = packDomainNameCanonical(owner)
+		listRData = make([][]byte, 0, len(rrset))
+		x         int
+	)
+	for x = range rrset {
+		listRData = append(listRData, canonicalRData(&rrset[x]))
+	}
+	slices.SortFunc(listRData, bytes.Compare)
+	listRData = slices.CompactFunc(listRData, bytes.Equal)
+
+	data = rrsig.rdataNoSignature()
+
+	var rdata []byte
+	for _, rdata = range listRData {
+		data = append(data, wireOwner...)
+		data = binary.BigEndian.AppendUint16(data, uint16(rrsig.TypeCovered))
+		data = binary.BigEndian.AppendUint16(data, uint16(rrset[0].Class))
+		data = binary.BigEndian.AppendUint32(data, rrsig.OrigTTL)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(rdata)))
+		data = append(data, rdata...)
+	}
+	return data, nil
+}
+
+// dnssecHash return the hash function used by DNSSEC algorithm.
+// The Ed25519 does not use pre-hashing so it will return zero.
+func dnssecHash(algorithm byte) (hash crypto.Hash, err error) {
+	switch algorithm {
+	case DNSSECAlgoRSASHA1, DNSSECAlgoRSASHA1NSEC3SHA1:
+		return crypto.SHA1, nil
+	case DNSSECAlgoRSASHA256, DNSSECAlgoECDSAP256SHA256:
+		return crypto.SHA256, nil
+	case DNSSECAlgoRSASHA512:
+		return crypto.SHA512, nil
+	case DNSSECAlgoECDSAP384SHA384:
+		return crypto.SHA384, nil
+	case DNSSECAlgoED25519:
+		return 0, nil
+	}
+	return 0, fmt.Errorf(`unsupported algorithm %d`, algorithm)
+}
+
+// dnssecDigest return the hash of data, or the data itself if hash is zero.
+func dnssecDigest(hash crypto.Hash, data []byte) []byte {
+	if hash == 0 {
+		return data
+	}
+	var h = hash.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// dnssecSign sign the RRset using the private key and store the result into
+// rrsig.Signature.
+// All other fields in rrsig must be set before calling this function.
+func dnssecSign(signer crypto.Signer, rrsig *RDataRRSIG, rrset []ResourceRecord) (err error) {
+	var (
+		logp = `dnssecSign`
+
+		hash crypto.Hash
+		data []byte
+	)
+
+	hash, err = dnssecHash(rrsig.Algorithm)
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	data, err = dnssecSignedData(rrsig, rrset)
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	var sig []byte
+
+	sig, err = signer.Sign(rand.Reader, dnssecDigest(hash, data), hash)
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	switch rrsig.Algorithm {
+	case DNSSECAlgoECDSAP256SHA256, DNSSECAlgoECDSAP384SHA384:
+		// Convert the ASN.1 signature into r | s, as described in
+		// RFC 6605 section 4.
+		var rs struct {
+			R *big.Int
+			S *big.Int
+		}
+		_, err = asn1.Unmarshal(sig, &rs)
+		if err != nil {
+			return fmt.Errorf(`%s: %w`, logp, err)
+		}
+		var size = 32
+		if rrsig.Algorithm == DNSSECAlgoECDSAP384SHA384 {
+			size = 48
+		}
+		sig = make([]byte, 2*size)
+		rs.R.FillBytes(sig[:size])
+		rs.S.FillBytes(sig[size:])
+	}
+
+	rrsig.Signature = sig
+
+	return nil
+}
+
+// dnssecVerify verify the signature in rrsig of RRset using the DNSKEY.
+func dnssecVerify(rrsig *RDataRRSIG, dnskey *RDataDNSKEY, rrset []ResourceRecord) (err error) {
+	var (
+		logp = `dnssecVerify`
+
+		hash   crypto.Hash
+		data   []byte
+		pubkey crypto.PublicKey
+	)
+
+	if rrsig.Algorithm != dnskey.Algorithm {
+		return fmt.Errorf(`%s: unmatched algorithm %d`, logp, rrsig.Algorithm)
+	}
+
+	hash, err = dnssecHash(rrsig.Algorithm)
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	pubkey, err = dnskey.publicKey()
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	data, err = dnssecSignedData(rrsig, rrset)
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	var digest = dnssecDigest(hash, data)
+
+	switch key := pubkey.(type) {
+	case *rsa.PublicKey:
+		err = rsa.VerifyPKCS1v15(key, hash, digest, rrsig.Signature)
+		if err != nil {
+			return fmt.Errorf(`%s: %w`, logp, err)
+		}
+
+	case *ecdsa.PublicKey:
+		var size = len(rrsig.Signature) / 2
+		if size == 0 {
+			return fmt.Errorf(`%s: invalid signature length`, logp)
+		}
+		var (
+			r = new(big.Int).SetBytes(rrsig.Signature[:size])
+			s = new(big.Int).SetBytes(rrsig.Signature[size:])
+		)
+		if !ecdsa.Verify(key, digest, r, s) {
+			return fmt.Errorf(`%s: invalid signature`, logp)
+		}
+
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, digest, rrsig.Signature) {
+			return fmt.Errorf(`%s: invalid signature`, logp)
+		}
+	}
+	return nil
+}
+
+// newRDataDNSKEY create new DNSKEY from the public key.
+func newRDataDNSKEY(pubkey crypto.PublicKey, flags uint16) (dnskey *RDataDNSKEY, err error) {
+	dnskey = &RDataDNSKEY{
+		Flags:    flags,
+		Protocol: dnskeyProtocol,
+	}
+
+	switch key := pubkey.(type) {
+	case *rsa.PublicKey:
+		// RFC 3110 section 2.
+		var exp = big.NewInt(int64(key.E)).Bytes()
+		if len(exp) < 256 {
+			dnskey.PublicKey = append(dnskey.PublicKey, byte(len(exp)))
+		} else {
+			dnskey.PublicKey = append(dnskey.PublicKey, 0)
+			dnskey.PublicKey = binary.BigEndian.AppendUint16(dnskey.PublicKey, uint16(len(exp)))
+		}
+		dnskey.PublicKey = append(dnskey.PublicKey, exp...)
+		dnskey.PublicKey = append(dnskey.PublicKey, key.N.Bytes()...)
+		dnskey.Algorithm = DNSSECAlgoRSASHA256
+
+	case *ecdsa.PublicKey:
+		// RFC 6605 section 4, the public key is X | Y.
+		var raw []byte
+		raw, err = key.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		dnskey.PublicKey = raw[1:]
+		switch key.Curve {
+		case elliptic.P256():
+			dnskey.Algorithm = DNSSECAlgoECDSAP256SHA256
+		case elliptic.P384():
+			dnskey.Algorithm = DNSSECAlgoECDSAP384SHA384
+		default:
+			return nil, fmt.Errorf(`unsupported curve %s`, key.Curve.Params().Name)
+		}
+
+	case ed25519.PublicKey:
+		dnskey.PublicKey = slices.Clone(key)
+		dnskey.Algorithm = DNSSECAlgoED25519
+
+	default:
+		return nil, fmt.Errorf(`unsupported public key %T`, pubkey)
+	}
+	return dnskey, nil
+}
+
+// publicKey return the PublicKey as [crypto.PublicKey].
+func (dnskey *RDataDNSKEY) publicKey() (pubkey crypto.PublicKey, err error) {
+	var raw = dnskey.PublicKey
+
+	switch dnskey.Algorithm {
+	case DNSSECAlgoRSASHA1, DNSSECAlgoRSASHA1NSEC3SHA1,
+		DNSSECAlgoRSASHA256, DNSSECAlgoRSASHA512:
+		if len(raw) < 3 {
+			return nil, errors.New(`invalid RSA public key`)
+		}
+		var size = int(raw[0])
+		raw = raw[1:]
+		if size == 0 {
+			size = int(binary.BigEndian.Uint16(raw))
+			raw = raw[2:]
+		}
+		if size == 0 || len(raw) <= size {
+			return nil, errors.New(`invalid RSA public key exponent`)
+		}
+		var exp = new(big.Int).SetBytes(raw[:size])
+		if !exp.IsInt64() || exp.Int64() > 1<<31-1 {
+			return nil, errors.New(`RSA public key exponent too large`)
+		}
+		pubkey = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(raw[size:]),
+			E: int(exp.Int64()),
+		}
+
+	case DNSSECAlgoECDSAP256SHA256, DNSSECAlgoECDSAP384SHA384:
+		var curve = elliptic.P256()
+		if dnskey.Algorithm == DNSSECAlgoECDSAP384SHA384 {
+			curve = elliptic.P384()
+		}
+		pubkey, err = ecdsa.ParseUncompressedPublicKey(curve, append([]byte{4}, raw...))
+		if err != nil {
+			return nil, err
+		}
+
+	case DNSSECAlgoED25519:
+		if len(raw) != ed25519.PublicKeySize {
+			return nil, errors.New(`invalid Ed25519 public key`)
+		}
+		pubkey = ed25519.PublicKey(raw)
+
+	default:
+		return nil, fmt.Errorf(`unsupported algorithm %d`, dnskey.Algorithm)
+	}
+	return pubkey, nil
+}
+
+// newRDataDS create the DS record of DNSKEY with owner name, as described
+// in RFC 4034 section 5.1.4.
+func newRDataDS(owner string, dnskey *RDataDNSKEY, digestType byte) (ds *RDataDS, err error) {
+	var data = packDomainNameCanonical(dnssecName(owner))
+
+	data = append(data, dnskey.rdata()...)
+
+	ds = &RDataDS{
+		KeyTag:     dnskey.KeyTag(),
+		Algorithm:  dnskey.Algorithm,
+		DigestType: digestType,
+	}
+
+	switch digestType {
+	case DSDigestSHA1:
+		var sum = sha1.Sum(data)
+		ds.Digest = sum[:]
+	case DSDigestSHA256:
+		var sum = sha256.Sum256(data)
+		ds.Digest = sum[:]
+	case DSDigestSHA384:
+		var sum = sha512.Sum384(data)
+		ds.Digest = sum[:]
+	default:
+		return nil, fmt.Errorf(`unsupported digest type %d`, digestType)
+	}
+	return ds, nil
+}
+
+// isMatch return true if the DS refer to the DNSKEY with owner name.
+func (ds *RDataDS) isMatch(owner string, dnskey *RDataDNSKEY) bool {
+	if ds.KeyTag != dnskey.KeyTag() || ds.Algorithm != dnskey.Algorithm {
+		return false
+	}
+
+	var (
+		other *RDataDS
+		err   error
+	)
+
+	other, err = newRDataDS(owner, dnskey, ds.DigestType)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ds.Digest, other.Digest)
+}
+
+// isValidAt return true if the signature validity period include the time
+// now, using the serial number arithmetic as described in RFC 4034 section
+// 3.1.5.
+func (rrsig *RDataRRSIG) isValidAt(now uint32) bool {
+	return int32(now-rrsig.Inception) >= 0 && int32(rrsig.Expiration-now) >= 0
+}
+
+// nsec3Hash return the hashed owner name using SHA-1, as described in RFC
+// 5155 section 5.
+func nsec3Hash(name string, salt []byte, iterations uint16) []byte {
+	var (
+		data = packDomainNameCanonical(dnssecName(name))
+		h    = sha1.New()
+		sum  []byte
+	)
+
+	h.Write(data)
+	h.Write(salt)
+	sum = h.Sum(nil)
+
+	for range iterations {
+		h.Reset()
+		h.Write(sum)
+		h.Write(salt)
+		sum = h.Sum(sum[:0])
+	}
+	return sum
+}
@@ -0,0 +1,315 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+)
+
+// dnstapContentType is the content type of Frame Streams for dnstap.
+const dnstapContentType = `protobuf:dnstap.Dnstap`
+
+// dnstapVersion is the value of version field in dnstap message.
+const dnstapVersion = `pakakeh.go/lib/dns`
+
+// List of Frame Streams control frame types.
+const (
+	fstrmControlAccept uint32 = 1
+	fstrmControlStart  uint32 = 2
+	fstrmControlStop   uint32 = 3
+	fstrmControlReady  uint32 = 4
+	fstrmControlFinish uint32 = 5
+
+	fstrmFieldContentType uint32 = 1
+
+	// fstrmControlMaxSize is the maximum size of control frame that
+	// we accept from receiver.
+	fstrmControlMaxSize = 512
+)
+
+// List of field numbers in dnstap protobuf schema.
+const (
+	dnstapFieldVersion = 2
+	dnstapFieldExtra   = 3
+	dnstapFieldMessage = 14
+	dnstapFieldType    = 15
+
+	dnstapMessageFieldType             = 1
+	dnstapMessageFieldSocketFamily     = 2
+	dnstapMessageFieldSocketProtocol   = 3
+	dnstapMessageFieldQueryAddress     = 4
+	dnstapMessageFieldResponseAddress  = 5
+	dnstapMessageFieldQueryPort        = 6
+	dnstapMessageFieldResponsePort     = 7
+	dnstapMessageFieldQueryTimeSec     = 8
+	dnstapMessageFieldQueryTimeNsec    = 9
+	dnstapMessageFieldQueryMessage     = 10
+	dnstapMessageFieldResponseTimeSec  = 12
+	dnstapMessageFieldResponseTimeNsec = 13
+	dnstapMessageFieldResponseMessage  = 14
+)
+
+// List of protobuf wire types.
+const (
+	protobufWireVarint  = 0
+	protobufWireBytes   = 2
+	protobufWireFixed32 = 5
+)
+
+// dnstapTypeMessage is the value of Dnstap.Type for message.
+const dnstapTypeMessage = 1
+
+// dnstapCacheHit is the value of Dnstap.Extra for the client response
+// that is answered from caches.
+const dnstapCacheHit = `cache-hit`
+
+// dnstapMessageTypes map the [QueryLogEvent] type to dnstap
+// Message.Type.
+var dnstapMessageTypes = map[string]uint64{
+	QueryLogClientQuery:       5,
+	QueryLogClientResponse:    6,
+	QueryLogForwarderQuery:    7,
+	QueryLogForwarderResponse: 8,
+}
+
+// dnstapSocketProtocols map the connection type to dnstap
+// SocketProtocol.
+var dnstapSocketProtocols = map[string]uint64{
+	connTypeUDP: 1,
+	connTypeTCP: 2,
+	connTypeDoT: 3,
+	connTypeDoH: 4,
+}
+
+// dnstapWriter write the [QueryLogEvent] as dnstap message using the
+// Frame Streams protocol.
+// If the output is Unix socket, the bidirectional handshake (READY and
+// ACCEPT) is done before the START frame.
+type dnstapWriter struct {
+	out io.ReadWriteCloser
+	w   *bufio.Writer
+
+	// buf is the reusable buffer for encoding the message.
+	buf []byte
+
+	isBidirectional bool
+}
+
+// newDnstapWriter create new dnstap writer and write the START frame.
+func newDnstapWriter(out io.ReadWriteCloser, isBidirectional bool) (dw *dnstapWriter, err error) {
+	var logp = `newDnstapWriter`
+
+	dw = &dnstapWriter{
+		out:             out,
+		w:               bufio.NewWriter(out),
+		isBidirectional: isBidirectional,
+	}
+
+	if isBidirectional {
+		err = dw.writeControl(fstrmControlReady, true)
+		if err != nil {
+			return nil, fmt.Errorf(`%s: %w`, logp, err)
+		}
+		err = dw.readControl(fstrmControlAccept)
+		if err != nil {
+			return nil, fmt.Errorf(`%s: %w`, logp, err)
+		}
+	}
+
+	err = dw.writeControl(fstrmControlStart, true)
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+	return dw, nil
+}
+
+// write the event as data frame.
+func (dw *dnstapWriter) write(ev *QueryLogEvent) (err error) {
+	dw.buf = dnstapPack(dw.buf[:0], ev)
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(dw.buf)))
+
+	_, err = dw.w.Write(size[:])
+	if err != nil {
+		return err
+	}
+	_, err = dw.w.Write(dw.buf)
+	return err
+}
+
+func (dw *dnstapWriter) flush() error {
+	return dw.w.Flush()
+}
+
+// close write the STOP frame, wait for FINISH frame if its
+// bidirectional, and close the output.
+func (dw *dnstapWriter) close() (err error) {
+	err = dw.writeControl(fstrmControlStop, false)
+	if err == nil && dw.isBidirectional {
+		err = dw.readControl(fstrmControlFinish)
+	}
+	var errClose = dw.out.Close()
+	if err != nil {
+		return fmt.Errorf(`dnstap: %w`, err)
+	}
+	return errClose
+}
+
+// writeControl write and flush the control frame with type ctype.
+func (dw *dnstapWriter) writeControl(ctype uint32, withContentType bool) (err error) {
+	var frame []byte
+
+	frame = binary.BigEndian.AppendUint32(frame, ctype)
+	if withContentType {
+		frame = binary.BigEndian.AppendUint32(frame, fstrmFieldContentType)
+		frame = binary.BigEndian.AppendUint32(frame, uint32(len(dnstapContentType)))
+		frame = append(frame, dnstapContentType...)
+	}
+
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[4:], uint32(len(frame)))
+
+	_, err = dw.w.Write(header[:])
+	if err != nil {
+		return err
+	}
+	_, err = dw.w.Write(frame)
+	if err != nil {
+		return err
+	}
+	return dw.w.Flush()
+}
+
+// readControl read the control frame from receiver and check its type.
+func (dw *dnstapWriter) readControl(exp uint32) (err error) {
+	var header [8]byte
+
+	_, err = io.ReadFull(dw.out, header[:])
+	if err != nil {
+		return err
+	}
+	if binary.BigEndian.Uint32(header[:4]) != 0 {
+		return errors.New(`expecting control frame`)
+	}
+
+	var size = binary.BigEndian.Uint32(header[4:])
+	if size < 4 || size > fstrmControlMaxSize {
+		return fmt.Errorf(`invalid control frame length %d`, size)
+	}
+
+	var frame = make([]byte, size)
+	_, err = io.ReadFull(dw.out, frame)
+	if err != nil {
+		return err
+	}
+
+	var got = binary.BigEndian.Uint32(frame)
+	if got != exp {
+		return fmt.Errorf(`expecting control frame type %d, got %d`, exp, got)
+	}
+	return nil
+}
+
+// dnstapPack encode the event into dnstap protobuf message and append it
+// into buf.
+func dnstapPack(buf []byte, ev *QueryLogEvent) []byte {
+	var msg []byte
+
+	msg = protobufAppendVarint(msg, dnstapMessageFieldType,
+		dnstapMessageTypes[ev.Type])
+
+	var family = dnstapSocketFamily(ev.QueryAddress, ev.ResponseAddress)
+	if family != 0 {
+		msg = protobufAppendVarint(msg, dnstapMessageFieldSocketFamily, family)
+	}
+
+	var proto = dnstapSocketProtocols[ev.Protocol]
+	if proto != 0 {
+		msg = protobufAppendVarint(msg, dnstapMessageFieldSocketProtocol, proto)
+	}
+
+	if ev.QueryAddress.IsValid() {
+		msg = protobufAppendBytes(msg, dnstapMessageFieldQueryAddress,
+			ev.QueryAddress.Addr().Unmap().AsSlice())
+	}
+	if ev.ResponseAddress.IsValid() {
+		msg = protobufAppendBytes(msg, dnstapMessageFieldResponseAddress,
+			ev.ResponseAddress.Addr().Unmap().AsSlice())
+	}
+	if ev.QueryAddress.IsValid() {
+		msg = protobufAppendVarint(msg, dnstapMessageFieldQueryPort,
+			uint64(ev.QueryAddress.Port()))
+	}
+	if ev.ResponseAddress.IsValid() {
+		msg = protobufAppendVarint(msg, dnstapMessageFieldResponsePort,
+			uint64(ev.ResponseAddress.Port()))
+	}
+
+	if !ev.QueryTime.IsZero() {
+		msg = protobufAppendVarint(msg, dnstapMessageFieldQueryTimeSec,
+			uint64(ev.QueryTime.Unix()))
+		msg = protobufAppendFixed32(msg, dnstapMessageFieldQueryTimeNsec,
+			uint32(ev.QueryTime.Nanosecond()))
+	}
+	if len(ev.QueryMessage) != 0 {
+		msg = protobufAppendBytes(msg, dnstapMessageFieldQueryMessage,
+			ev.QueryMessage)
+	}
+	if !ev.ResponseTime.IsZero() {
+		msg = protobufAppendVarint(msg, dnstapMessageFieldResponseTimeSec,
+			uint64(ev.ResponseTime.Unix()))
+		msg = protobufAppendFixed32(msg, dnstapMessageFieldResponseTimeNsec,
+			uint32(ev.ResponseTime.Nanosecond()))
+	}
+	if len(ev.ResponseMessage) != 0 {
+		msg = protobufAppendBytes(msg, dnstapMessageFieldResponseMessage,
+			ev.ResponseMessage)
+	}
+
+	buf = protobufAppendBytes(buf, dnstapFieldVersion, []byte(dnstapVersion))
+	if ev.CacheHit {
+		buf = protobufAppendBytes(buf, dnstapFieldExtra, []byte(dnstapCacheHit))
+	}
+	buf = protobufAppendBytes(buf, dnstapFieldMessage, msg)
+	buf = protobufAppendVarint(buf, dnstapFieldType, dnstapTypeMessage)
+	return buf
+}
+
+// dnstapSocketFamily return the dnstap SocketFamily, 1 for INET or 2 for
+// INET6, from the first valid address.
+func dnstapSocketFamily(addrs ...netip.AddrPort) uint64 {
+	var addr netip.AddrPort
+	for _, addr = range addrs {
+		if !addr.IsValid() {
+			continue
+		}
+		if addr.Addr().Unmap().Is4() {
+			return 1
+		}
+		return 2
+	}
+	return 0
+}
+
+func protobufAppendVarint(buf []byte, field int, v uint64) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field<<3|protobufWireVarint))
+	return binary.AppendUvarint(buf, v)
+}
+
+func protobufAppendFixed32(buf []byte, field int, v uint32) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field<<3|protobufWireFixed32))
+	return binary.LittleEndian.AppendUint32(buf, v)
+}
+
+func protobufAppendBytes(buf []byte, field int, v []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field<<3|protobufWireBytes))
+	buf = binary.AppendUvarint(buf, uint64(len(v)))
+	return append(buf, v...)
+}
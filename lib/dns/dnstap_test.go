@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+// protobufFields decode the top level fields in protobuf message.
+// The varint and fixed32 values are returned as uint64 and the
+// length-delimited values as []byte.
+func protobufFields(t *testing.T, msg []byte) (fields map[int]any) {
+	fields = make(map[int]any)
+	for len(msg) > 0 {
+		var tag, n = binary.Uvarint(msg)
+		msg = msg[n:]
+
+		var field = int(tag >> 3)
+		switch tag & 0x7 {
+		case protobufWireVarint:
+			fields[field], n = binary.Uvarint(msg)
+			msg = msg[n:]
+		case protobufWireFixed32:
+			fields[field] = uint64(binary.LittleEndian.Uint32(msg))
+			msg = msg[4:]
+		case protobufWireBytes:
+			var size uint64
+			size, n = binary.Uvarint(msg)
+			msg = msg[n:]
+			fields[field] = msg[:size]
+			msg = msg[size:]
+		default:
+			t.Fatalf(`unknown wire type %d`, tag&0x7)
+		}
+	}
+	return fields
+}
+
+// readFrame read single Frame Streams frame.
+// It return isControl true if its control frame.
+func readFrame(t *testing.T, r io.Reader) (frame []byte, isControl bool) {
+	var (
+		size [4]byte
+		err  error
+	)
+	_, err = io.ReadFull(r, size[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if binary.BigEndian.Uint32(size[:]) == 0 {
+		isControl = true
+		_, err = io.ReadFull(r, size[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	frame = make([]byte, binary.BigEndian.Uint32(size[:]))
+	_, err = io.ReadFull(r, frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return frame, isControl
+}
+
+func TestDnstapWriter(t *testing.T) {
+	var (
+		client, receiver = net.Pipe()
+
+		expContentType = []byte{0, 0, 0, 1, 0, 0, 0, 22}
+		queryAt        = time.Unix(1691222000, 500)
+		ev             = &QueryLogEvent{
+			QueryTime:       queryAt,
+			ResponseTime:    queryAt.Add(time.Millisecond),
+			QueryAddress:    netip.MustParseAddrPort(`192.0.2.1:5353`),
+			Type:            QueryLogClientResponse,
+			Protocol:        connTypeTCP,
+			ResponseMessage: []byte{1, 2, 3},
+			CacheHit:        true,
+		}
+
+		done = make(chan [][]byte, 1)
+	)
+	expContentType = append(expContentType, dnstapContentType...)
+
+	// The receiver side of bidirectional Frame Streams.
+	go func() {
+		defer receiver.Close()
+
+		var (
+			frames [][]byte
+			frame  []byte
+		)
+
+		frame, _ = readFrame(t, receiver)
+		frames = append(frames, frame)
+
+		var accept = binary.BigEndian.AppendUint32(nil, 0)
+		accept = binary.BigEndian.AppendUint32(accept, 4)
+		accept = binary.BigEndian.AppendUint32(accept, fstrmControlAccept)
+		_, _ = receiver.Write(accept)
+
+		// START, data, and STOP.
+		for range 3 {
+			frame, _ = readFrame(t, receiver)
+			frames = append(frames, frame)
+		}
+
+		var finish = binary.BigEndian.AppendUint32(nil, 0)
+		finish = binary.BigEndian.AppendUint32(finish, 4)
+		finish = binary.BigEndian.AppendUint32(finish, fstrmControlFinish)
+		_, _ = receiver.Write(finish)
+
+		done <- frames
+	}()
+
+	var (
+		dw  *dnstapWriter
+		err error
+	)
+
+	dw, err = newDnstapWriter(client, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dw.write(ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dw.flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = dw.close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var frames = <-done
+
+	test.Assert(t, `READY`,
+		append([]byte{0, 0, 0, 4}, expContentType...), frames[0])
+	test.Assert(t, `START`,
+		append([]byte{0, 0, 0, 2}, expContentType...), frames[1])
+	test.Assert(t, `STOP`, []byte{0, 0, 0, 3}, frames[3])
+
+	var dnstap = protobufFields(t, frames[2])
+	test.Assert(t, `Dnstap.version`, []byte(dnstapVersion), dnstap[dnstapFieldVersion])
+	test.Assert(t, `Dnstap.extra`, []byte(dnstapCacheHit), dnstap[dnstapFieldExtra])
+	test.Assert(t, `Dnstap.type`, uint64(dnstapTypeMessage), dnstap[dnstapFieldType])
+
+	var (
+		msg = protobufFields(t, dnstap[dnstapFieldMessage].([]byte))
+		exp = map[int]any{
+			dnstapMessageFieldType:             uint64(6),
+			dnstapMessageFieldSocketFamily:     uint64(1),
+			dnstapMessageFieldSocketProtocol:   uint64(2),
+			dnstapMessageFieldQueryAddress:     []byte{192, 0, 2, 1},
+			dnstapMessageFieldQueryPort:        uint64(5353),
+			dnstapMessageFieldQueryTimeSec:     uint64(1691222000),
+			dnstapMessageFieldQueryTimeNsec:    uint64(500),
+			dnstapMessageFieldResponseTimeSec:  uint64(1691222000),
+			dnstapMessageFieldResponseTimeNsec: uint64(1000500),
+			dnstapMessageFieldResponseMessage:  []byte{1, 2, 3},
+		}
+	)
+	test.Assert(t, `Dnstap.message`, exp, msg)
+}
@@ -8,11 +8,14 @@ import (
 	"bytes"
 	"crypto/tls"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/netip"
 	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -29,6 +32,12 @@ type DoHClient struct {
 	// responded is a channel to signal the underlying receiver that the
 	// response has ready to be send to client.
 	responded chan bool
+	// clientAddr contains the address of client on receiver side.
+	clientAddr netip.AddrPort
+
+	// isJSON is true if the response on receiver side should be
+	// written in the JSON format.
+	isJSON bool
 }
 
 // NewDoHClient will create new DNS client with HTTP connection.
@@ -207,6 +216,84 @@ func (cl *DoHClient) Get(msg *Message) (res *Message, err error) {
 	return res, nil
 }
 
+// QueryJSON send the query to name server using the DNS JSON API and
+// return the response as unpacked message.
+// The query name and type, the CD flag, and the DO bit and client subnet
+// in OPT record, are send as the URL query parameters, for example
+//
+//	GET /resolve?name=example.com&type=AAAA&cd=1&do=1
+//
+// The record data that cannot be parsed is returned as string in the
+// record Value.
+func (cl *DoHClient) QueryJSON(msg *Message) (res *Message, err error) {
+	var (
+		logp   = `QueryJSON`
+		params = cl.addr.Query()
+	)
+
+	params.Set(`name`, msg.Question.Name)
+	params.Set(`type`, strconv.Itoa(int(msg.Question.Type)))
+	if msg.Header.IsCD {
+		params.Set(`cd`, `1`)
+	}
+
+	var opt = msg.opt(false)
+	if opt != nil {
+		if opt.DO {
+			params.Set(`do`, `1`)
+		}
+		var ecs, _ = opt.ClientSubnet()
+		if ecs != nil {
+			params.Set(`edns_client_subnet`,
+				fmt.Sprintf(`%s/%d`, ecs.Address, ecs.SourcePrefix))
+		}
+	}
+
+	var (
+		reqURL  = *cl.addr
+		httpReq *http.Request
+	)
+
+	reqURL.RawQuery = params.Encode()
+
+	httpReq, err = http.NewRequest(http.MethodGet, reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+	httpReq.Header.Set(`Accept`, contentTypeDNSJSON)
+
+	var httpRes *http.Response
+
+	httpRes, err = cl.conn.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	var body []byte
+
+	body, err = io.ReadAll(httpRes.Body)
+	httpRes.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+	if httpRes.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(`%s: %s`, logp, body)
+	}
+
+	var mjson MessageJSON
+
+	err = json.Unmarshal(body, &mjson)
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	res, err = mjson.Message()
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+	return res, nil
+}
+
 // Query send DNS query to name server.  This is an alias to Get method, to
 // make it consistent with other DNS clients.
 func (cl *DoHClient) Query(msg *Message) (*Message, error) {
@@ -238,7 +325,16 @@ func (cl *DoHClient) SetTimeout(t time.Duration) {
 // Write the raw DNS response message to active connection.
 // This method is only used by server to write the response of query to
 // client.
+// If the query is received from DNS JSON API, the packet is converted
+// into [MessageJSON].
 func (cl *DoHClient) Write(packet []byte) (n int, err error) {
+	if cl.isJSON {
+		packet, err = messageJSONPacket(packet)
+		if err != nil {
+			cl.responded <- false
+			return 0, err
+		}
+	}
 	n, err = cl.w.Write(packet)
 	if err != nil {
 		cl.responded <- false
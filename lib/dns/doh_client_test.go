@@ -401,3 +401,67 @@ func TestDoHClient_Get(t *testing.T) {
 		test.Assert(t, "Packet", c.exp.packet, got.packet)
 	}
 }
+
+func TestDoHClient_QueryJSON(t *testing.T) {
+	type testCase struct {
+		desc      string
+		qname     string
+		expAnswer []string
+		qtype     RecordType
+		expRCode  ResponseCode
+	}
+
+	var (
+		cl  *DoHClient
+		err error
+	)
+
+	cl, err = NewDoHClient(`https://127.0.0.1:8443/resolve`, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var listCase = []testCase{{
+		desc:      `With type A`,
+		qname:     `kilabit.info`,
+		qtype:     RecordTypeA,
+		expAnswer: []string{`kilabit.info A 127.0.0.1`},
+	}, {
+		desc:      `With type TXT`,
+		qname:     `kilabit.info`,
+		qtype:     RecordTypeTXT,
+		expAnswer: []string{`kilabit.info TXT This is a test server`},
+	}, {
+		desc:      `With type SOA`,
+		qname:     `kilabit.info`,
+		qtype:     RecordTypeSOA,
+		expAnswer: []string{`kilabit.info SOA`},
+	}, {
+		desc:     `With type not implemented`,
+		qname:    `kilabit.info`,
+		qtype:    0,
+		expRCode: RCodeNotImplemented,
+	}}
+
+	var (
+		c   testCase
+		msg *Message
+		res *Message
+	)
+	for _, c = range listCase {
+		msg = NewMessage()
+		msg.Question = MessageQuestion{
+			Name:  c.qname,
+			Type:  c.qtype,
+			Class: RecordClassIN,
+		}
+
+		res, err = cl.QueryJSON(msg)
+		if err != nil {
+			t.Fatalf(`%s: %s`, c.desc, err)
+		}
+		test.Assert(t, c.desc+`: RCode`, c.expRCode, res.Header.RCode)
+		test.Assert(t, c.desc+`: Answer`, c.expAnswer,
+			testRecordsString(res.Answer))
+	}
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// List of address family in [EDNSClientSubnet].
+const (
+	ecsFamilyIPv4 uint16 = 1
+	ecsFamilyIPv6 uint16 = 2
+)
+
+// EDNSClientSubnet define the EDNS Client Subnet (ECS) option, as
+// described in RFC 7871.
+// It contains the network of the client that initiate the query, so the
+// authoritative name server can return the answer based on client
+// location.
+type EDNSClientSubnet struct {
+	// Address of the client, with the bits after SourcePrefix set to
+	// zero.
+	Address net.IP
+
+	// Family of the Address, 1 for IPv4 or 2 for IPv6.
+	Family uint16
+
+	// SourcePrefix is the number of leftmost significant bits of
+	// Address in query.
+	SourcePrefix byte
+
+	// ScopePrefix is the number of leftmost significant bits of
+	// Address that the response covers.
+	// It MUST be set to 0 in query.
+	ScopePrefix byte
+}
+
+// NewEDNSClientSubnet create the ECS option from ip address, with
+// the address truncated to prefix bits.
+// If the prefix is greater than the length of ip address, it will be set
+// to the maximum length of ip address.
+func NewEDNSClientSubnet(ip net.IP, prefix byte) (ecs *EDNSClientSubnet) {
+	ecs = &EDNSClientSubnet{}
+
+	var ip4 = ip.To4()
+	if ip4 != nil {
+		ecs.Family = ecsFamilyIPv4
+		ip = ip4
+	} else {
+		ecs.Family = ecsFamilyIPv6
+		ip = ip.To16()
+	}
+	if int(prefix) > len(ip)*8 {
+		prefix = byte(len(ip) * 8)
+	}
+	ecs.SourcePrefix = prefix
+	ecs.Address = ip.Mask(net.CIDRMask(int(prefix), len(ip)*8))
+	return ecs
+}
+
+// truncate the Address to maximum prefix bits, if the SourcePrefix is
+// greater than prefix.
+func (ecs *EDNSClientSubnet) truncate(prefix byte) {
+	if ecs.SourcePrefix <= prefix {
+		return
+	}
+	var bits = len(ecs.Address) * 8
+	ecs.SourcePrefix = prefix
+	ecs.Address = ecs.Address.Mask(net.CIDRMask(int(prefix), bits))
+}
+
+// pack the ECS option into OPTION-DATA.
+func (ecs *EDNSClientSubnet) pack() (data []byte) {
+	var n = (int(ecs.SourcePrefix) + 7) / 8
+
+	data = binary.BigEndian.AppendUint16(data, ecs.Family)
+	data = append(data, ecs.SourcePrefix, ecs.ScopePrefix)
+	if n > len(ecs.Address) {
+		n = len(ecs.Address)
+	}
+	data = append(data, ecs.Address[:n]...)
+	return data
+}
+
+// unpack the ECS option from OPTION-DATA.
+func (ecs *EDNSClientSubnet) unpack(data []byte) (err error) {
+	if len(data) < 4 {
+		return errors.New(`ECS: data too short`)
+	}
+
+	ecs.Family = binary.BigEndian.Uint16(data)
+	ecs.SourcePrefix = data[2]
+	ecs.ScopePrefix = data[3]
+	data = data[4:]
+
+	var size int
+	switch ecs.Family {
+	case ecsFamilyIPv4:
+		size = net.IPv4len
+	case ecsFamilyIPv6:
+		size = net.IPv6len
+	default:
+		return fmt.Errorf(`ECS: unknown family %d`, ecs.Family)
+	}
+	if int(ecs.SourcePrefix) > size*8 || int(ecs.ScopePrefix) > size*8 {
+		return fmt.Errorf(`ECS: invalid prefix length %d/%d`,
+			ecs.SourcePrefix, ecs.ScopePrefix)
+	}
+	if len(data) != (int(ecs.SourcePrefix)+7)/8 {
+		return fmt.Errorf(`ECS: invalid address length %d`, len(data))
+	}
+
+	ecs.Address = make(net.IP, size)
+	copy(ecs.Address, data)
+
+	return nil
+}
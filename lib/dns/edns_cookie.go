@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// List of size for [EDNSCookie].
+const (
+	cookieClientSize    = 8
+	cookieServerMinSize = 8
+	cookieServerMaxSize = 32
+
+	// serverCookieSize is the size of server cookie generated by
+	// Server, as described in RFC 9018: version (1 byte), reserved
+	// (3 bytes), timestamp (4 bytes), and hash (8 bytes).
+	serverCookieSize = 16
+
+	serverCookieVersion = 1
+)
+
+// List of lifetime for server cookie, in seconds, as recommended in
+// RFC 9018 section 4.3.
+const (
+	// serverCookieMaxAge is the maximum age of server cookie before its
+	// considered invalid.
+	serverCookieMaxAge = 3600
+
+	// serverCookieRenewAge is the age of server cookie before its
+	// renewed in response.
+	serverCookieRenewAge = 1800
+
+	// serverCookieMaxSkew is the maximum difference of the cookie
+	// timestamp in the future.
+	serverCookieMaxSkew = 300
+)
+
+// EDNSCookie define the DNS Cookie option, as described in RFC 7873.
+type EDNSCookie struct {
+	// Client cookie, 8 bytes.
+	Client []byte
+
+	// Server cookie, between 8 to 32 bytes, or empty if the client
+	// does not know the server cookie yet.
+	Server []byte
+}
+
+// pack the cookie option into OPTION-DATA.
+func (cookie *EDNSCookie) pack() (data []byte) {
+	data = append(data, cookie.Client...)
+	data = append(data, cookie.Server...)
+	return data
+}
+
+// unpack the cookie option from OPTION-DATA.
+func (cookie *EDNSCookie) unpack(data []byte) (err error) {
+	if len(data) != cookieClientSize {
+		if len(data) < cookieClientSize+cookieServerMinSize ||
+			len(data) > cookieClientSize+cookieServerMaxSize {
+			return fmt.Errorf(`COOKIE: invalid length %d`, len(data))
+		}
+	}
+	cookie.Client = append([]byte(nil), data[:cookieClientSize]...)
+	if len(data) > cookieClientSize {
+		cookie.Server = append([]byte(nil), data[cookieClientSize:]...)
+	}
+	return nil
+}
+
+// serverCookie generate the server cookie for client cookie and client
+// IP address at timestamp now, using secret.
+func serverCookie(secret, client []byte, ip net.IP, now uint32) (server []byte) {
+	server = make([]byte, 8, serverCookieSize)
+	server[0] = serverCookieVersion
+	binary.BigEndian.PutUint32(server[4:], now)
+
+	var mac = hmac.New(sha256.New, secret)
+	mac.Write(client)
+	mac.Write(server)
+	mac.Write(ip)
+
+	server = append(server, mac.Sum(nil)[:8]...)
+	return server
+}
+
+// isValidServerCookie return true if the server cookie in cookie is
+// generated by this server and has not been expired.
+// The second return value is true if the server cookie should be renewed.
+func isValidServerCookie(secret []byte, cookie *EDNSCookie, ip net.IP, now uint32) (ok, renew bool) {
+	if len(cookie.Server) != serverCookieSize || cookie.Server[0] != serverCookieVersion {
+		return false, false
+	}
+
+	var ts = binary.BigEndian.Uint32(cookie.Server[4:])
+	if int64(ts) > int64(now)+serverCookieMaxSkew {
+		return false, false
+	}
+	if int64(now)-int64(ts) > serverCookieMaxAge {
+		return false, false
+	}
+
+	var exp = serverCookie(secret, cookie.Client, ip, ts)
+	if !hmac.Equal(exp, cookie.Server) {
+		return false, false
+	}
+	return true, int64(now)-int64(ts) > serverCookieRenewAge
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// List of INFO-CODE for [EDNSExtendedError], as registered in RFC 8914
+// section 5.2.
+const (
+	EDEOther                      uint16 = 0
+	EDEUnsupportedDNSKEYAlgorithm uint16 = 1
+	EDEUnsupportedDSDigestType    uint16 = 2
+	EDEStaleAnswer                uint16 = 3
+	EDEForgedAnswer               uint16 = 4
+	EDEDNSSECIndeterminate        uint16 = 5
+	EDEDNSSECBogus                uint16 = 6
+	EDESignatureExpired           uint16 = 7
+	EDESignatureNotYetValid       uint16 = 8
+	EDEDNSKEYMissing              uint16 = 9
+	EDERRSIGsMissing              uint16 = 10
+	EDENoZoneKeyBitSet            uint16 = 11
+	EDENSECMissing                uint16 = 12
+	EDECachedError                uint16 = 13
+	EDENotReady                   uint16 = 14
+	EDEBlocked                    uint16 = 15
+	EDECensored                   uint16 = 16
+	EDEFiltered                   uint16 = 17
+	EDEProhibited                 uint16 = 18
+	EDEStaleNXDomainAnswer        uint16 = 19
+	EDENotAuthoritative           uint16 = 20
+	EDENotSupported               uint16 = 21
+	EDENoReachableAuthority       uint16 = 22
+	EDENetworkError               uint16 = 23
+	EDEInvalidData                uint16 = 24
+)
+
+// EDNSExtendedError define the Extended DNS Error (EDE) option, as
+// described in RFC 8914.
+// It provide additional information about the cause of DNS error.
+type EDNSExtendedError struct {
+	// ExtraText contains the optional UTF-8 text with additional
+	// information, for human consumption.
+	ExtraText string
+
+	// InfoCode is one of the EDE constants.
+	InfoCode uint16
+}
+
+// pack the EDE option into OPTION-DATA.
+func (ede *EDNSExtendedError) pack() (data []byte) {
+	data = binary.BigEndian.AppendUint16(data, ede.InfoCode)
+	data = append(data, ede.ExtraText...)
+	return data
+}
+
+// unpack the EDE option from OPTION-DATA.
+func (ede *EDNSExtendedError) unpack(data []byte) (err error) {
+	if len(data) < 2 {
+		return errors.New(`EDE: data too short`)
+	}
+	ede.InfoCode = binary.BigEndian.Uint16(data)
+	ede.ExtraText = string(data[2:])
+	return nil
+}
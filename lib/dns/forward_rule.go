@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ForwardRule define the parent name servers for queries on specific
+// domains, also known as conditional forwarding.
+//
+// In the INI format, each rule is defined in its own "dns" "forward"
+// section,
+//
+//	[dns "forward"]
+//	domain = corp.internal
+//	parent = udp://10.1.1.1
+//
+//	[dns "forward"]
+//	domain = *.lan
+//	parent = udp://192.168.1.1
+type ForwardRule struct {
+	primaryUDP []net.Addr
+	primaryTCP []net.Addr
+	primaryDoh []string
+	primaryDot []string
+
+	// suffixes contains the normalized Domains.
+	suffixes []string
+
+	// Domains contains list of domain suffixes that is forwarded to
+	// NameServers.
+	// The domain "corp.internal" match the query for "corp.internal"
+	// and any names under it, for example "host.corp.internal".
+	// The wildcard prefix "*." is optional, so "*.lan" is equal to
+	// "lan".
+	//
+	// If the query name match with more than one rules, the rule with
+	// the longest domain suffix is used.
+	Domains []string `ini:"::domain"`
+
+	// NameServers contains list of parent name servers for Domains,
+	// using the same URI format as in [ServerOptions.NameServers].
+	NameServers []string `ini:"::parent"`
+}
+
+// init normalize the Domains and parse the NameServers.
+func (rule *ForwardRule) init() (err error) {
+	var (
+		suffixes []string
+		domain   string
+		suffix   string
+	)
+
+	rule.suffixes = nil
+
+	for _, domain = range rule.Domains {
+		suffix = strings.ToLower(strings.TrimSpace(domain))
+		suffix = strings.TrimPrefix(suffix, `*.`)
+		suffix = strings.Trim(suffix, `.`)
+		if len(suffix) == 0 {
+			return fmt.Errorf(`invalid domain %q`, domain)
+		}
+		suffixes = append(suffixes, suffix)
+	}
+	if len(suffixes) == 0 {
+		return errors.New(`empty domain`)
+	}
+
+	rule.primaryUDP, rule.primaryTCP, rule.primaryDoh, rule.primaryDot = parseNameServers(rule.NameServers)
+
+	if len(rule.primaryUDP) == 0 && len(rule.primaryDoh) == 0 && len(rule.primaryDot) == 0 {
+		return errors.New(`no valid name servers`)
+	}
+
+	rule.suffixes = suffixes
+
+	return nil
+}
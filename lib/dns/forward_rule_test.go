@@ -0,0 +1,225 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"testing"
+	"time"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/ini"
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestServerOptions_ForwardRules(t *testing.T) {
+	var (
+		rawini = []byte(`
+[dns "server"]
+parent = https://dns.example/dns-query
+
+[dns "forward"]
+domain = corp.internal
+parent = udp://10.1.1.1
+
+[dns "forward"]
+domain = *.lan
+domain = Home.Arpa.
+parent = udp://192.168.1.1
+parent = tcp://192.168.1.2:5353
+`)
+		opts ServerOptions
+		err  error
+	)
+
+	err = ini.Unmarshal(rawini, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expRules = []ForwardRule{{
+		Domains:     []string{`corp.internal`},
+		NameServers: []string{`udp://10.1.1.1`},
+	}, {
+		Domains:     []string{`*.lan`, `Home.Arpa.`},
+		NameServers: []string{`udp://192.168.1.1`, `tcp://192.168.1.2:5353`},
+	}}
+	test.Assert(t, `ForwardRules`, expRules, opts.ForwardRules)
+
+	err = opts.init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	test.Assert(t, `suffixes #0`, []string{`corp.internal`},
+		opts.ForwardRules[0].suffixes)
+	test.Assert(t, `suffixes #1`, []string{`lan`, `home.arpa`},
+		opts.ForwardRules[1].suffixes)
+	test.Assert(t, `primaryTCP #1`, 2, len(opts.ForwardRules[1].primaryTCP))
+
+	type testCase struct {
+		desc     string
+		expError string
+		rule     ForwardRule
+	}
+
+	var listCase = []testCase{{
+		desc: `With empty domain`,
+		rule: ForwardRule{
+			NameServers: []string{`udp://10.1.1.1`},
+		},
+		expError: `dns: invalid forward rule []: empty domain`,
+	}, {
+		desc: `With invalid domain`,
+		rule: ForwardRule{
+			Domains:     []string{`*.`},
+			NameServers: []string{`udp://10.1.1.1`},
+		},
+		expError: `dns: invalid forward rule [*.]: invalid domain "*."`,
+	}, {
+		desc: `With no valid name servers`,
+		rule: ForwardRule{
+			Domains:     []string{`lan`},
+			NameServers: []string{`udp://router.lan`},
+		},
+		expError: `dns: invalid forward rule [lan]: no valid name servers`,
+	}}
+
+	var c testCase
+	for _, c = range listCase {
+		opts = ServerOptions{
+			ForwardRules: []ForwardRule{c.rule},
+		}
+		err = opts.init()
+		test.Assert(t, c.desc, c.expError, err.Error())
+	}
+}
+
+func TestServer_forwardRules(t *testing.T) {
+	var (
+		corpZone = []byte(`@ SOA ns1 admin 1 3600 900 604800 300
+host A 10.1.0.1
+www.dev A 10.1.0.3
+`)
+		devZone = []byte(`@ SOA ns1 admin 1 3600 900 604800 300
+host A 10.2.0.2
+www A 10.2.0.3
+`)
+		upstreamCorp *Server
+		upstreamDev  *Server
+		zone         *Zone
+		err          error
+	)
+
+	zone, err = ParseZone(corpZone, `corp.internal`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstreamCorp, err = NewServer(&ServerOptions{
+		ListenAddress: `127.0.0.1:5330`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstreamCorp.Caches.InternalPopulateZone(zone)
+	go func() {
+		_ = upstreamCorp.ListenAndServe()
+	}()
+	t.Cleanup(upstreamCorp.Stop)
+
+	zone, err = ParseZone(devZone, `dev.corp.internal`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstreamDev, err = NewServer(&ServerOptions{
+		ListenAddress: `127.0.0.1:5331`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstreamDev.Caches.InternalPopulateZone(zone)
+	go func() {
+		_ = upstreamDev.ListenAndServe()
+	}()
+	t.Cleanup(upstreamDev.Stop)
+
+	var srv *Server
+
+	srv, err = NewServer(&ServerOptions{
+		ListenAddress: `127.0.0.1:5332`,
+		ForwardRules: []ForwardRule{{
+			Domains:     []string{`corp.internal`},
+			NameServers: []string{`udp://127.0.0.1:5330`},
+		}, {
+			Domains:     []string{`*.dev.corp.internal`},
+			NameServers: []string{`udp://127.0.0.1:5331`},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.ListenAndServe()
+	}()
+	t.Cleanup(srv.Stop)
+
+	var cl *UDPClient
+
+	cl, err = NewUDPClient(`127.0.0.1:5332`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Close()
+
+	var lookup = func(qname string) (res *Message) {
+		var (
+			q = MessageQuestion{
+				Name: qname,
+				Type: RecordTypeA,
+			}
+			x int
+		)
+		// Wait until the server forwarders are connected.
+		for x = 0; x < 50; x++ {
+			res, err = cl.Lookup(q, true)
+			if err == nil && res.Header.RCode != RCodeErrServer {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	var res = lookup(`host.corp.internal`)
+	test.Assert(t, `host.corp.internal`, `10.1.0.1`, res.Answer[0].Value)
+
+	res = lookup(`HOST.dev.corp.internal`)
+	test.Assert(t, `HOST.dev.corp.internal`, `10.2.0.2`, res.Answer[0].Value)
+
+	// Query that does not match any rules is not forwarded, since
+	// the server does not have NameServers.
+	res, err = cl.Lookup(MessageQuestion{Name: `example.com`, Type: RecordTypeA}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `example.com RCode`, RCodeErrServer, res.Header.RCode)
+
+	// Reload the rules, forward all of corp.internal into upstreamCorp.
+	srv.opts.ForwardRules = srv.opts.ForwardRules[:1]
+	srv.RestartForwarders(nil)
+
+	res = lookup(`www.dev.corp.internal`)
+	test.Assert(t, `www.dev.corp.internal`, `10.1.0.3`, res.Answer[0].Value)
+
+	// The previous answer still exist in caches.
+	var an = srv.Caches.query(&Message{
+		Question: MessageQuestion{
+			Name:  `host.dev.corp.internal`,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+		},
+	})
+	test.Assert(t, `cached answer`, true, an != nil)
+}
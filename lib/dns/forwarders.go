@@ -0,0 +1,236 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+// List of strategy for [ServerOptions.ForwardStrategy].
+const (
+	// ForwardStrategyFastest forward the query to the parent name
+	// server with the lowest smoothed RTT.
+	ForwardStrategyFastest = `fastest`
+
+	// ForwardStrategyRace forward the query to the two fastest parent
+	// name servers at the same time, and use the first answer.
+	ForwardStrategyRace = `race`
+
+	// ForwardStrategyRoundRobin forward the query to each parent name
+	// server in turn.
+	ForwardStrategyRoundRobin = `round-robin`
+)
+
+// forwarders contains the forwarders to the same set of parent name
+// servers.
+type forwarders struct {
+	// prefix for tagging each forwarder in the log.
+	prefix string
+
+	// strategy for selecting the forwarders.
+	strategy string
+
+	// primary contains the UDP, DoH, and DoT forwarders.
+	primary []*upstream
+
+	// tcp contains the TCP forwarders.
+	tcp []*upstream
+
+	udpAddrs []net.Addr
+	tcpAddrs []net.Addr
+	dohAddrs []string
+	dotAddrs []string
+
+	// next is the index of next forwarder for round-robin strategy.
+	next int
+
+	sync.Mutex
+}
+
+func newForwarders(prefix, strategy string, udpAddrs, tcpAddrs []net.Addr,
+	dohAddrs, dotAddrs []string,
+) (fw *forwarders) {
+	fw = &forwarders{
+		prefix:   prefix,
+		strategy: strategy,
+		udpAddrs: udpAddrs,
+		tcpAddrs: tcpAddrs,
+		dohAddrs: dohAddrs,
+		dotAddrs: dotAddrs,
+	}
+
+	var x int
+	for x = range len(udpAddrs) {
+		fw.primary = append(fw.primary,
+			newUpstream(fmt.Sprintf(`%sUDP-%d`, prefix, x),
+				connTypeUDP, udpAddrs[x].String()))
+	}
+	for x = range len(dohAddrs) {
+		fw.primary = append(fw.primary,
+			newUpstream(fmt.Sprintf(`%sDoH-%d`, prefix, x),
+				connTypeDoH, dohAddrs[x]))
+	}
+	for x = range len(dotAddrs) {
+		fw.primary = append(fw.primary,
+			newUpstream(fmt.Sprintf(`%sDoT-%d`, prefix, x),
+				connTypeDoT, dotAddrs[x]))
+	}
+	for x = range len(tcpAddrs) {
+		fw.tcp = append(fw.tcp,
+			newUpstream(fmt.Sprintf(`%sTCP-%d`, prefix, x),
+				connTypeTCP, tcpAddrs[x].String()))
+	}
+	return fw
+}
+
+// isActive return true if at least one forwarder is connected, otherwise
+// it will return false.
+func (fw *forwarders) isActive() bool {
+	var up *upstream
+	for _, up = range fw.primary {
+		if up.connected() {
+			return true
+		}
+	}
+	for _, up = range fw.tcp {
+		if up.connected() {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch put the request into the queue of forwarders selected by
+// strategy.
+// The TCP request is dispatched to the TCP forwarders, and the other to
+// the primary forwarders, or to each other if none of them is connected.
+// If the queue of selected forwarder is full, the request is dispatched
+// to the next connected forwarder.
+// It return false if no forwarders is connected or all of their queues
+// are full.
+func (fw *forwarders) dispatch(req *request) bool {
+	var pool, other = fw.primary, fw.tcp
+	if req.kind == connTypeTCP {
+		pool, other = fw.tcp, fw.primary
+	}
+
+	var ups = fw.pick(pool)
+	if len(ups) == 0 {
+		ups = fw.pick(other)
+		if len(ups) == 0 {
+			return false
+		}
+	}
+
+	var up *upstream
+
+	if len(ups) > 1 {
+		req.race = &requestRace{}
+		req.race.pending.Store(int32(len(ups)))
+
+		for _, up = range ups {
+			if up.enqueue(req) {
+				continue
+			}
+			// The full queue is handled as failed forwarder.
+			if req.claim(false) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if ups[0].enqueue(req) {
+		return true
+	}
+	for _, up = range slices.Concat(pool, other) {
+		if up == ups[0] || !up.connected() {
+			continue
+		}
+		if up.enqueue(req) {
+			return true
+		}
+	}
+	return false
+}
+
+// pick select the forwarders from pool based on strategy.
+// The forwarder that has been taken out of rotation is selected only if
+// all of connected forwarders are down.
+func (fw *forwarders) pick(pool []*upstream) (ups []*upstream) {
+	var (
+		now        = time.Now()
+		candidates []*upstream
+		up         *upstream
+	)
+	for _, up = range pool {
+		if up.isAvailable(now) {
+			candidates = append(candidates, up)
+		}
+	}
+	if len(candidates) == 0 {
+		for _, up = range pool {
+			if up.connected() {
+				candidates = append(candidates, up)
+			}
+		}
+		if len(candidates) == 0 {
+			return nil
+		}
+	}
+
+	switch fw.strategy {
+	case ForwardStrategyRoundRobin:
+		fw.Lock()
+		up = candidates[fw.next%len(candidates)]
+		fw.next++
+		fw.Unlock()
+		return []*upstream{up}
+
+	case ForwardStrategyRace:
+		sortByRTT(candidates)
+		if len(candidates) > 2 {
+			candidates = candidates[:2]
+		}
+		return candidates
+	}
+
+	sortByRTT(candidates)
+	return candidates[:1]
+}
+
+// sortByRTT sort the upstreams by its smoothed RTT, with the unmeasured
+// upstream first, so each of them get measured.
+func sortByRTT(ups []*upstream) {
+	slices.SortStableFunc(ups, func(a, b *upstream) int {
+		var rtta, rttb = a.smoothedRTT(), b.smoothedRTT()
+		switch {
+		case rtta < rttb:
+			return -1
+		case rtta > rttb:
+			return 1
+		}
+		return 0
+	})
+}
+
+// upstreams return all of the primary and TCP forwarders.
+func (fw *forwarders) upstreams() (ups []*upstream) {
+	ups = append(ups, fw.primary...)
+	ups = append(ups, fw.tcp...)
+	return ups
+}
+
+// drain remove all pending requests from the queues.
+func (fw *forwarders) drain() (reqs []*request) {
+	var up *upstream
+	for _, up = range fw.upstreams() {
+		reqs = append(reqs, up.drain()...)
+	}
+	return reqs
+}
@@ -0,0 +1,293 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"testing"
+	"time"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestForwarders_pick(t *testing.T) {
+	type testCase struct {
+		strategy string
+		exp      [][]string
+	}
+
+	var listCase = []testCase{{
+		strategy: ForwardStrategyFastest,
+		exp:      [][]string{{`UDP-2`}, {`UDP-2`}, {`UDP-2`}},
+	}, {
+		strategy: ForwardStrategyRace,
+		exp: [][]string{
+			{`UDP-2`, `DoT-0`},
+			{`UDP-2`, `DoT-0`},
+			{`UDP-2`, `DoT-0`},
+		},
+	}, {
+		strategy: ForwardStrategyRoundRobin,
+		exp:      [][]string{{`UDP-0`}, {`UDP-2`}, {`DoT-0`}},
+	}}
+
+	var (
+		c   testCase
+		fw  *forwarders
+		ups []*upstream
+		got []string
+		x   int
+	)
+	for _, c = range listCase {
+		fw = newForwarders(``, c.strategy, nil, nil, nil,
+			[]string{`1.1.1.1:853`})
+		fw.primary = append(
+			[]*upstream{
+				newUpstream(`UDP-0`, connTypeUDP, `127.0.0.1:53`),
+				newUpstream(`UDP-1`, connTypeUDP, `127.0.0.2:53`),
+				newUpstream(`UDP-2`, connTypeUDP, `127.0.0.3:53`),
+				newUpstream(`UDP-3`, connTypeUDP, `127.0.0.4:53`),
+			},
+			fw.primary...)
+
+		// UDP-1 is not connected and UDP-3 is down.
+		fw.primary[0].setConnected(true)
+		fw.primary[0].succeed(30 * time.Millisecond)
+		fw.primary[2].setConnected(true)
+		fw.primary[2].succeed(10 * time.Millisecond)
+		fw.primary[3].setConnected(true)
+		for range upstreamMaxFails {
+			fw.primary[3].fail(time.Now())
+		}
+		fw.primary[4].setConnected(true)
+		fw.primary[4].succeed(20 * time.Millisecond)
+
+		for x = range len(c.exp) {
+			ups = fw.pick(fw.primary)
+			got = got[:0]
+			for _, up := range ups {
+				got = append(got, up.tag)
+			}
+			test.Assert(t, c.strategy, c.exp[x], got)
+		}
+	}
+
+	// If all connected forwarders are down, pick them anyway.
+	fw = newForwarders(``, ForwardStrategyFastest, nil, nil, nil,
+		[]string{`1.1.1.1:853`})
+	fw.primary[0].setConnected(true)
+	for range upstreamMaxFails {
+		fw.primary[0].fail(time.Now())
+	}
+	ups = fw.pick(fw.primary)
+	test.Assert(t, `All down`, 1, len(ups))
+
+	// No forwarders is connected.
+	fw.primary[0].setConnected(false)
+	ups = fw.pick(fw.primary)
+	test.Assert(t, `Not connected`, 0, len(ups))
+}
+
+func TestForwarders_dispatch(t *testing.T) {
+	var (
+		fw = newForwarders(``, ForwardStrategyFastest,
+			nil, nil, nil, []string{`1.1.1.1:853`, `1.0.0.1:853`})
+
+		req *request
+		x   int
+	)
+
+	fw.primary[0].setConnected(true)
+	fw.primary[0].succeed(10 * time.Millisecond)
+	fw.primary[1].setConnected(true)
+	fw.primary[1].succeed(20 * time.Millisecond)
+
+	// Fill the queue of the fastest forwarder.
+	for x = range upstreamQueueSize {
+		req = newRequest()
+		req.message.Header.ID = uint16(x)
+		test.Assert(t, `dispatch`, true, fw.dispatch(req))
+	}
+	test.Assert(t, `DoT-0 queue`, upstreamQueueSize, len(fw.primary[0].queue))
+
+	// The next request fall back to the other forwarder.
+	req = newRequest()
+	test.Assert(t, `dispatch on full queue`, true, fw.dispatch(req))
+	test.Assert(t, `DoT-1 queue`, 1, len(fw.primary[1].queue))
+
+	// All of the queues are full.
+	for range upstreamQueueSize - 1 {
+		fw.dispatch(newRequest())
+	}
+	test.Assert(t, `dispatch on all full queue`, false,
+		fw.dispatch(newRequest()))
+
+	// The race request is failed if all of the queues are full.
+	fw.strategy = ForwardStrategyRace
+	test.Assert(t, `dispatch race on all full queue`, false,
+		fw.dispatch(newRequest()))
+
+	// The race request is dispatched if one of the queue is not full.
+	_ = fw.primary[1].drain()
+	req = newRequest()
+	test.Assert(t, `dispatch race`, true, fw.dispatch(req))
+	test.Assert(t, `race pending`, int32(1), req.race.pending.Load())
+}
+
+func TestUpstream_stat(t *testing.T) {
+	var (
+		up  = newUpstream(`UDP-0`, connTypeUDP, `127.0.0.1:53`)
+		now = time.Now()
+	)
+
+	up.setConnected(true)
+	up.succeed(80 * time.Millisecond)
+	up.succeed(160 * time.Millisecond)
+	up.fail(now)
+	up.fail(now)
+
+	var exp = ForwarderStat{
+		Name:        `UDP-0`,
+		NameServer:  `127.0.0.1:53`,
+		RTT:         90 * time.Millisecond,
+		Queries:     4,
+		Failures:    2,
+		IsConnected: true,
+	}
+	test.Assert(t, `Two failures`, exp, up.stat(now))
+	test.Assert(t, `FailureRate`, 0.5, exp.FailureRate())
+
+	// The third failures take the upstream out of rotation.
+	up.fail(now)
+	exp.Queries = 5
+	exp.Failures = 3
+	exp.DownUntil = now.Add(upstreamDownTime)
+	test.Assert(t, `Three failures`, exp, up.stat(now))
+	test.Assert(t, `isAvailable`, false, up.isAvailable(now))
+	test.Assert(t, `isAvailable after down time`, true,
+		up.isAvailable(now.Add(upstreamDownTime)))
+
+	// The success put it back into rotation.
+	up.succeed(90 * time.Millisecond)
+	exp.Queries = 6
+	exp.DownUntil = time.Time{}
+	test.Assert(t, `Success`, exp, up.stat(now))
+}
+
+func TestRequest_claim(t *testing.T) {
+	var req = newRequest()
+
+	test.Assert(t, `Not raced`, true, req.claim(false))
+
+	// The first answer win.
+	req.race = &requestRace{}
+	req.race.pending.Store(2)
+	test.Assert(t, `First answer`, true, req.claim(true))
+	test.Assert(t, `Second answer`, false, req.claim(true))
+	test.Assert(t, `Failure after answered`, false, req.claim(false))
+
+	// The failure is handled only if all of them failed.
+	req.race = &requestRace{}
+	req.race.pending.Store(2)
+	test.Assert(t, `First failure`, false, req.claim(false))
+	test.Assert(t, `Second failure`, true, req.claim(false))
+}
+
+func TestServer_ForwarderStats(t *testing.T) {
+	var (
+		zoneData = []byte(`@ SOA ns1 admin 1 3600 900 604800 300
+a A 10.4.0.1
+b A 10.4.0.2
+c A 10.4.0.3
+d A 10.4.0.4
+`)
+		listAddr = []string{`127.0.0.1:5342`, `127.0.0.1:5343`}
+
+		upstream *Server
+		zone     *Zone
+		addr     string
+		err      error
+	)
+
+	zone, err = ParseZone(zoneData, `forward.test`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr = range listAddr {
+		upstream, err = NewServer(&ServerOptions{
+			ListenAddress: addr,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		upstream.Caches.InternalPopulateZone(zone)
+		go func(up *Server) {
+			_ = up.ListenAndServe()
+		}(upstream)
+		t.Cleanup(upstream.Stop)
+	}
+
+	var srv *Server
+
+	srv, err = NewServer(&ServerOptions{
+		ListenAddress: `127.0.0.1:5344`,
+		NameServers: []string{
+			`udp://` + listAddr[0],
+			`udp://` + listAddr[1],
+		},
+		ForwardStrategy: ForwardStrategyRoundRobin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.ListenAndServe()
+	}()
+	t.Cleanup(srv.Stop)
+
+	// Wait until all of the UDP forwarders are connected.
+	var x int
+	for x = 0; x < 50; x++ {
+		if srv.fw.primary[0].connected() && srv.fw.primary[1].connected() {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	var cl *UDPClient
+
+	cl, err = NewUDPClient(`127.0.0.1:5344`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Close()
+
+	var (
+		q   MessageQuestion
+		res *Message
+	)
+	for _, q.Name = range []string{`a`, `b`, `c`, `d`} {
+		q.Name += `.forward.test`
+		q.Type = RecordTypeA
+		res, err = cl.Lookup(q, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.Assert(t, q.Name+` RCode`, RCodeOK, res.Header.RCode)
+	}
+
+	var (
+		stats = srv.ForwarderStats()
+		stat  ForwarderStat
+	)
+	for _, stat = range stats {
+		if stat.Name == `UDP-0` || stat.Name == `UDP-1` {
+			test.Assert(t, stat.Name+` Queries`, uint64(2), stat.Queries)
+			test.Assert(t, stat.Name+` Failures`, uint64(0), stat.Failures)
+			test.Assert(t, stat.Name+` IsConnected`, true, stat.IsConnected)
+			if stat.RTT <= 0 {
+				t.Fatalf(`%s: expecting RTT, got %s`, stat.Name, stat.RTT)
+			}
+		}
+	}
+}
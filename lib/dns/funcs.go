@@ -134,3 +134,62 @@ func reverseByDot(ip []byte) (rev []byte) {
 	}
 	return
 }
+
+// appendCharString append the <character-string> s, a single length octet
+// followed by the string, into packet.
+// The string longer than 255 octets is truncated.
+func appendCharString(packet []byte, s string) []byte {
+	if len(s) > 255 {
+		s = s[:255]
+	}
+	packet = append(packet, byte(len(s)))
+	packet = append(packet, s...)
+	return packet
+}
+
+// unpackCharString unpack the <character-string> from the beginning of
+// rdata.
+// It return the string and the rest of rdata after it.
+func unpackCharString(rdata []byte) (s string, rest []byte, err error) {
+	if len(rdata) == 0 {
+		return ``, nil, errors.New(`missing character-string`)
+	}
+	var size = int(rdata[0])
+	if len(rdata) < 1+size {
+		return ``, nil, fmt.Errorf(`character-string length overflow %d`, size)
+	}
+	s = string(rdata[1 : 1+size])
+	return s, rdata[1+size:], nil
+}
+
+// unquote remove the double quotes that surround the in, and un-escape the
+// backslash quote inside it.
+// If in is not quoted, it will be returned as is.
+func unquote(in []byte) (out []byte) {
+	var size = len(in)
+	if size < 2 || in[0] != '"' || in[size-1] != '"' {
+		return in
+	}
+
+	var (
+		c     byte
+		isEsc bool
+	)
+	out = make([]byte, 0, size-2)
+	for _, c = range in[1 : size-1] {
+		if isEsc {
+			if c != '"' && c != '\\' {
+				out = append(out, '\\')
+			}
+			out = append(out, c)
+			isEsc = false
+			continue
+		}
+		if c == '\\' {
+			isEsc = true
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
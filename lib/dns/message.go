@@ -5,6 +5,7 @@
 package dns
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"net"
@@ -377,7 +378,9 @@ func (msg *Message) packDomainName(dname []byte, doCompress bool) (n int) {
 
 	msg.packet = append(msg.packet, 0)
 	idxCount = len(msg.packet) - 1
-	msg.dnameOff[msg.dname] = uint16(idxCount)
+	if msg.dnameOff != nil {
+		msg.dnameOff[msg.dname] = uint16(idxCount)
+	}
 	n++
 	for ; x < len(dname); x++ {
 		c = dname[x]
@@ -440,7 +443,9 @@ func (msg *Message) packDomainName(dname []byte, doCompress bool) (n int) {
 				break
 			}
 
-			msg.dnameOff[msg.dname] = uint16(idxCount)
+			if msg.dnameOff != nil {
+				msg.dnameOff[msg.dname] = uint16(idxCount)
+			}
 			continue
 		}
 
@@ -458,6 +463,15 @@ func (msg *Message) packDomainName(dname []byte, doCompress bool) (n int) {
 	return n
 }
 
+// packDomainNameCanonical convert domain name into DNS domain-name format,
+// in lower case and without compression, as described in RFC 4034 section
+// 6.2.
+func packDomainNameCanonical(dname string) []byte {
+	var msg = Message{}
+	msg.packDomainName([]byte(dname), false)
+	return msg.packet
+}
+
 func (msg *Message) packQuestion() {
 	msg.packDomainName([]byte(msg.Question.Name), false)
 	msg.packet = binary.BigEndian.AppendUint16(msg.packet,
@@ -476,7 +490,9 @@ func (msg *Message) packRR(rr *ResourceRecord) {
 		msg.packet = append(msg.packet, 0)
 		rrOPT, _ = rr.Value.(*RDataOPT)
 	} else {
-		msg.packDomainName([]byte(rr.Name), true)
+		// The TSIG owner name must not be compressed, RFC 8945
+		// section 4.2.
+		msg.packDomainName([]byte(rr.Name), rr.Type != RecordTypeTSIG)
 	}
 
 	msg.packet = binary.BigEndian.AppendUint16(msg.packet, uint16(rr.Type))
@@ -501,6 +517,12 @@ func (msg *Message) packRR(rr *ResourceRecord) {
 }
 
 func (msg *Message) packRData(rr *ResourceRecord) {
+	if rr.Value == nil && rr.Type != RecordTypeOPT {
+		// The RR with empty RDATA, for example the prerequisite or
+		// deletion in dynamic update, RFC 2136.
+		msg.packet = binary.BigEndian.AppendUint16(msg.packet, 0)
+		return
+	}
 	switch rr.Type {
 	case RecordTypeA:
 		msg.packA(rr)
@@ -540,10 +562,36 @@ func (msg *Message) packRData(rr *ResourceRecord) {
 		msg.packAAAA(rr)
 	case RecordTypeOPT:
 		msg.packOPT(rr)
+	case RecordTypeDS:
+		msg.packDS(rr)
+	case RecordTypeRRSIG:
+		msg.packRRSIG(rr)
+	case RecordTypeNSEC:
+		msg.packNSEC(rr)
+	case RecordTypeDNSKEY:
+		msg.packDNSKEY(rr)
+	case RecordTypeNSEC3:
+		msg.packNSEC3(rr)
+	case RecordTypeNSEC3PARAM:
+		msg.packNSEC3PARAM(rr)
 	case RecordTypeSVCB:
 		msg.packSVCB(rr)
 	case RecordTypeHTTPS:
 		msg.packHTTPS(rr)
+	case RecordTypeTSIG:
+		msg.packTSIG(rr)
+	case RecordTypeNAPTR:
+		msg.packNAPTR(rr)
+	case RecordTypeSSHFP:
+		msg.packSSHFP(rr)
+	case RecordTypeTLSA:
+		msg.packTLSA(rr)
+	case RecordTypeURI:
+		msg.packURI(rr)
+	case RecordTypeCAA:
+		msg.packCAA(rr)
+	case RecordTypeDNAME:
+		msg.packDNAME(rr)
 	}
 }
 
@@ -744,6 +792,275 @@ func (msg *Message) packOPT(rr *ResourceRecord) {
 	msg.packet = append(msg.packet, rdata...)
 }
 
+// packDNAME pack the DNAME target without compression, as required by
+// RFC 6672 section 2.5.
+func (msg *Message) packDNAME(rr *ResourceRecord) {
+	var (
+		off       = uint(len(msg.packet))
+		rrText, _ = rr.Value.(string)
+
+		n int
+	)
+
+	// Reserve two octets for rdlength
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, 0)
+
+	n = msg.packDomainName([]byte(rrText), false)
+	binary.BigEndian.PutUint16(msg.packet[off:], uint16(n))
+}
+
+func (msg *Message) packDS(rr *ResourceRecord) {
+	var (
+		ds *RDataDS
+		ok bool
+	)
+
+	ds, ok = rr.Value.(*RDataDS)
+	if !ok {
+		return
+	}
+
+	// Reserve two octets for rdlength.
+	var off = uint(len(msg.packet))
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, 0)
+
+	var n = ds.pack(msg)
+
+	// Write rdlength.
+	binary.BigEndian.PutUint16(msg.packet[off:], uint16(n))
+}
+
+func (msg *Message) packTSIG(rr *ResourceRecord) {
+	var (
+		tsig *RDataTSIG
+		ok   bool
+	)
+
+	tsig, ok = rr.Value.(*RDataTSIG)
+	if !ok {
+		return
+	}
+
+	// Reserve two octets for rdlength.
+	var off = uint(len(msg.packet))
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, 0)
+
+	var n = tsig.pack(msg)
+
+	// Write rdlength.
+	binary.BigEndian.PutUint16(msg.packet[off:], uint16(n))
+}
+
+func (msg *Message) packRRSIG(rr *ResourceRecord) {
+	var (
+		rrsig *RDataRRSIG
+		ok    bool
+	)
+
+	rrsig, ok = rr.Value.(*RDataRRSIG)
+	if !ok {
+		return
+	}
+
+	// Reserve two octets for rdlength.
+	var off = uint(len(msg.packet))
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, 0)
+
+	var n = rrsig.pack(msg)
+
+	// Write rdlength.
+	binary.BigEndian.PutUint16(msg.packet[off:], uint16(n))
+}
+
+func (msg *Message) packNSEC(rr *ResourceRecord) {
+	var (
+		nsec *RDataNSEC
+		ok   bool
+	)
+
+	nsec, ok = rr.Value.(*RDataNSEC)
+	if !ok {
+		return
+	}
+
+	// Reserve two octets for rdlength.
+	var off = uint(len(msg.packet))
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, 0)
+
+	var n = nsec.pack(msg)
+
+	// Write rdlength.
+	binary.BigEndian.PutUint16(msg.packet[off:], uint16(n))
+}
+
+func (msg *Message) packDNSKEY(rr *ResourceRecord) {
+	var (
+		dnskey *RDataDNSKEY
+		ok     bool
+	)
+
+	dnskey, ok = rr.Value.(*RDataDNSKEY)
+	if !ok {
+		return
+	}
+
+	// Reserve two octets for rdlength.
+	var off = uint(len(msg.packet))
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, 0)
+
+	var n = dnskey.pack(msg)
+
+	// Write rdlength.
+	binary.BigEndian.PutUint16(msg.packet[off:], uint16(n))
+}
+
+func (msg *Message) packNSEC3(rr *ResourceRecord) {
+	var (
+		nsec3 *RDataNSEC3
+		ok    bool
+	)
+
+	nsec3, ok = rr.Value.(*RDataNSEC3)
+	if !ok {
+		return
+	}
+
+	// Reserve two octets for rdlength.
+	var off = uint(len(msg.packet))
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, 0)
+
+	var n = nsec3.pack(msg)
+
+	// Write rdlength.
+	binary.BigEndian.PutUint16(msg.packet[off:], uint16(n))
+}
+
+func (msg *Message) packNSEC3PARAM(rr *ResourceRecord) {
+	var (
+		param *RDataNSEC3PARAM
+		ok    bool
+	)
+
+	param, ok = rr.Value.(*RDataNSEC3PARAM)
+	if !ok {
+		return
+	}
+
+	// Reserve two octets for rdlength.
+	var off = uint(len(msg.packet))
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, 0)
+
+	var n = param.pack(msg)
+
+	// Write rdlength.
+	binary.BigEndian.PutUint16(msg.packet[off:], uint16(n))
+}
+
+func (msg *Message) packNAPTR(rr *ResourceRecord) {
+	var (
+		naptr *RDataNAPTR
+		ok    bool
+	)
+
+	naptr, ok = rr.Value.(*RDataNAPTR)
+	if !ok {
+		return
+	}
+
+	// Reserve two octets for rdlength.
+	var off = uint(len(msg.packet))
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, 0)
+
+	var n = naptr.pack(msg)
+
+	// Write rdlength.
+	binary.BigEndian.PutUint16(msg.packet[off:], uint16(n))
+}
+
+func (msg *Message) packSSHFP(rr *ResourceRecord) {
+	var (
+		sshfp *RDataSSHFP
+		ok    bool
+	)
+
+	sshfp, ok = rr.Value.(*RDataSSHFP)
+	if !ok {
+		return
+	}
+
+	// Reserve two octets for rdlength.
+	var off = uint(len(msg.packet))
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, 0)
+
+	var n = sshfp.pack(msg)
+
+	// Write rdlength.
+	binary.BigEndian.PutUint16(msg.packet[off:], uint16(n))
+}
+
+func (msg *Message) packTLSA(rr *ResourceRecord) {
+	var (
+		tlsa *RDataTLSA
+		ok   bool
+	)
+
+	tlsa, ok = rr.Value.(*RDataTLSA)
+	if !ok {
+		return
+	}
+
+	// Reserve two octets for rdlength.
+	var off = uint(len(msg.packet))
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, 0)
+
+	var n = tlsa.pack(msg)
+
+	// Write rdlength.
+	binary.BigEndian.PutUint16(msg.packet[off:], uint16(n))
+}
+
+func (msg *Message) packURI(rr *ResourceRecord) {
+	var (
+		uri *RDataURI
+		ok  bool
+	)
+
+	uri, ok = rr.Value.(*RDataURI)
+	if !ok {
+		return
+	}
+
+	// Reserve two octets for rdlength.
+	var off = uint(len(msg.packet))
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, 0)
+
+	var n = uri.pack(msg)
+
+	// Write rdlength.
+	binary.BigEndian.PutUint16(msg.packet[off:], uint16(n))
+}
+
+func (msg *Message) packCAA(rr *ResourceRecord) {
+	var (
+		caa *RDataCAA
+		ok  bool
+	)
+
+	caa, ok = rr.Value.(*RDataCAA)
+	if !ok {
+		return
+	}
+
+	// Reserve two octets for rdlength.
+	var off = uint(len(msg.packet))
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, 0)
+
+	var n = caa.pack(msg)
+
+	// Write rdlength.
+	binary.BigEndian.PutUint16(msg.packet[off:], uint16(n))
+}
+
 func (msg *Message) packSVCB(rr *ResourceRecord) {
 	var (
 		svcb *RDataSVCB
@@ -868,6 +1185,121 @@ func (msg *Message) IsExpired() bool {
 	return false
 }
 
+// minTTL return the lowest TTL of resource records in the answer, or in the
+// authority if the answer is empty.
+func (msg *Message) minTTL() (ttl uint32) {
+	var (
+		list = msg.Answer
+		x    int
+	)
+	if len(list) == 0 {
+		list = msg.Authority
+	}
+	for x = range len(list) {
+		if x == 0 || list[x].TTL < ttl {
+			ttl = list[x].TTL
+		}
+	}
+	return ttl
+}
+
+// isNegative return true if the message is negative response, a name error
+// (NXDOMAIN) or no data (NODATA) with SOA record in the authority, as
+// described in RFC 2308 section 2.
+func (msg *Message) isNegative() bool {
+	switch msg.Header.RCode {
+	case RCodeErrName:
+	case RCodeOK:
+		if len(msg.Answer) != 0 {
+			return false
+		}
+	default:
+		return false
+	}
+	return msg.authoritySOA() != nil
+}
+
+// authoritySOA return the first SOA record in the authority section.
+func (msg *Message) authoritySOA() (rr *ResourceRecord) {
+	var x int
+	for x = range len(msg.Authority) {
+		if msg.Authority[x].Type == RecordTypeSOA {
+			return &msg.Authority[x]
+		}
+	}
+	return nil
+}
+
+// setNegativeTTL set the TTL of SOA record in the authority to the minimum
+// of its TTL and the SOA MINIMUM field, but no more than maxTTL, as
+// described in RFC 2308 section 5.
+func (msg *Message) setNegativeTTL(maxTTL uint32) {
+	var rr = msg.authoritySOA()
+	if rr == nil {
+		return
+	}
+
+	var soa, ok = rr.Value.(*RDataSOA)
+	if ok && soa.Minimum < rr.TTL {
+		rr.TTL = soa.Minimum
+	}
+	if rr.TTL > maxTTL {
+		rr.TTL = maxTTL
+	}
+	if int(rr.idxTTL)+4 <= len(msg.packet) {
+		binary.BigEndian.PutUint32(msg.packet[rr.idxTTL:], rr.TTL)
+	}
+}
+
+// opt return the OPT record in the additional section.
+// If the OPT record is not exist and isCreate is true, new OPT record is
+// added with UDP payload size set to maxUDPPacketSize, otherwise it will
+// return nil.
+func (msg *Message) opt(isCreate bool) (opt *RDataOPT) {
+	var (
+		x  int
+		ok bool
+	)
+	for x = range len(msg.Additional) {
+		opt, ok = msg.Additional[x].Value.(*RDataOPT)
+		if ok {
+			return opt
+		}
+	}
+	if !isCreate {
+		return nil
+	}
+	opt = &RDataOPT{}
+	msg.Additional = append(msg.Additional, ResourceRecord{
+		Type:  RecordTypeOPT,
+		Class: RecordClass(maxUDPPacketSize),
+		Value: opt,
+	})
+	return opt
+}
+
+// packetWithTTL return the copy of packet with TTL of all resource records,
+// except OPT, set to ttl.
+func (msg *Message) packetWithTTL(ttl uint32) (packet []byte) {
+	packet = bytes.Clone(msg.packet)
+
+	var (
+		list []ResourceRecord
+		rr   *ResourceRecord
+		x    int
+	)
+	for _, list = range [][]ResourceRecord{msg.Answer, msg.Authority, msg.Additional} {
+		for x = range len(list) {
+			rr = &list[x]
+			if rr.Type == RecordTypeOPT {
+				continue
+			}
+			binary.BigEndian.PutUint32(packet[rr.idxTTL:], ttl)
+		}
+	}
+	return packet
+}
+
 // Pack convert message into datagram packet.  The result of packing
 // a message will be saved in Packet field and returned.
 func (msg *Message) Pack() ([]byte, error) {
@@ -888,11 +1320,6 @@ func (msg *Message) Pack() ([]byte, error) {
 
 	msg.packQuestion()
 
-	if msg.Header.IsQuery {
-		msg.dnameOff = nil
-		return msg.packet, nil
-	}
-
 	for x = range len(msg.Answer) {
 		msg.packRR(&msg.Answer[x])
 	}
@@ -945,6 +1372,19 @@ func (msg *Message) SetAuthorativeAnswer(isAA bool) {
 	}
 }
 
+// SetAuthenticData set the Authentic Data (AD) flag in header and in
+// packet.
+func (msg *Message) SetAuthenticData(isAD bool) {
+	msg.Header.IsAD = isAD
+	if len(msg.packet) > 3 {
+		if isAD {
+			msg.packet[3] |= headerIsAD
+		} else {
+			msg.packet[3] &^= headerIsAD
+		}
+	}
+}
+
 // SetID in section header and in packet.
 func (msg *Message) SetID(id uint16) {
 	msg.Header.ID = id
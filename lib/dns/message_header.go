@@ -18,6 +18,8 @@ const (
 	headerIsTC       byte = 0x02 // 0000.0010
 	headerIsRD       byte = 0x01 // 0000.0001
 	headerIsRA       byte = 0x80 //          1000.0000
+	headerIsAD       byte = 0x20 //          0010.0000
+	headerIsCD       byte = 0x10 //          0001.0000
 	headerMaskRCode  byte = 0x0F //          0000.1111
 )
 
@@ -80,6 +82,21 @@ type MessageHeader struct {
 	//
 	IsRA bool
 
+	//
+	// Authentic Data - this bit is set in a response by the security
+	// aware name server to indicates that all the data in the answer and
+	// authority sections has been validated, as defined in RFC 4035
+	// section 3.2.3.
+	//
+	IsAD bool
+
+	//
+	// Checking Disabled - this bit may be set in a query to indicate
+	// that the requester will accept non-verified data, as defined in
+	// RFC 4035 section 3.2.2.
+	//
+	IsCD bool
+
 	//
 	// Response code - this 4 bit field is set as part of responses.
 	//
@@ -109,6 +126,8 @@ func (hdr *MessageHeader) Reset() {
 	hdr.IsTC = false
 	hdr.IsRD = true
 	hdr.IsRA = false
+	hdr.IsAD = false
+	hdr.IsCD = false
 	hdr.RCode = RCodeOK
 	hdr.QDCount = 1
 	hdr.ANCount = 0
@@ -137,6 +156,12 @@ func (hdr *MessageHeader) pack() []byte {
 	if hdr.IsRD {
 		b0 |= headerIsRD
 	}
+	if hdr.IsAD {
+		b1 |= headerIsAD
+	}
+	if hdr.IsCD {
+		b1 |= headerIsCD
+	}
 
 	if !hdr.IsQuery {
 		if hdr.IsAA {
@@ -168,11 +193,13 @@ func (hdr *MessageHeader) unpack(packet []byte) (err error) {
 		return errors.New(`header too small`)
 	}
 	hdr.Op = OpCode((packet[2] & headerMaskOpCode) >> 3)
-	if hdr.Op < 0 || hdr.Op > OpCodeStatus {
+	switch hdr.Op {
+	case OpCodeQuery, OpCodeIQuery, OpCodeStatus, OpCodeNotify, OpCodeUpdate:
+	default:
 		return fmt.Errorf(`unknown op code=%d`, hdr.Op)
 	}
 	hdr.RCode = ResponseCode(headerMaskRCode & packet[3])
-	if hdr.RCode < 0 || hdr.RCode > RCodeRefused {
+	if hdr.RCode > RCodeNotZone {
 		return fmt.Errorf(`unknown response code=%d`, hdr.RCode)
 	}
 
@@ -183,6 +210,8 @@ func (hdr *MessageHeader) unpack(packet []byte) (err error) {
 	hdr.IsTC = packet[2]&headerIsTC == headerIsTC
 	hdr.IsRD = packet[2]&headerIsRD == headerIsRD
 	hdr.IsRA = packet[3]&headerIsRA == headerIsRA
+	hdr.IsAD = packet[3]&headerIsAD == headerIsAD
+	hdr.IsCD = packet[3]&headerIsCD == headerIsCD
 
 	hdr.QDCount = binary.BigEndian.Uint16(packet[4:])
 	hdr.ANCount = binary.BigEndian.Uint16(packet[6:])
@@ -0,0 +1,405 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// contentTypeDNSJSON define the HTTP content type for DNS JSON API.
+const contentTypeDNSJSON = `application/dns-json`
+
+// MessageJSON define the DNS message in the JSON format of DNS over HTTPS
+// API, as used by Google Public DNS and Cloudflare.
+//
+// Example of response in JSON,
+//
+//	{
+//	  "Status": 0,
+//	  "TC": false,
+//	  "RD": true,
+//	  "RA": true,
+//	  "AD": false,
+//	  "CD": false,
+//	  "Question": [{"name": "example.com.", "type": 1}],
+//	  "Answer": [{
+//	    "name": "example.com.",
+//	    "type": 1,
+//	    "TTL": 3600,
+//	    "data": "93.184.216.34"
+//	  }]
+//	}
+type MessageJSON struct {
+	// Comment contains the additional information, for example the
+	// extended DNS error.
+	Comment string `json:"Comment,omitempty"`
+
+	// EDNSClientSubnet contains the EDNS client subnet in the query or
+	// response, in CIDR notation.
+	EDNSClientSubnet string `json:"edns_client_subnet,omitempty"`
+
+	Question   []MessageJSONQuestion `json:"Question"`
+	Answer     []MessageJSONRecord   `json:"Answer,omitempty"`
+	Authority  []MessageJSONRecord   `json:"Authority,omitempty"`
+	Additional []MessageJSONRecord   `json:"Additional,omitempty"`
+
+	// Status contains the response code.
+	Status ResponseCode `json:"Status"`
+
+	// TC is true if the response is truncated.
+	TC bool `json:"TC"`
+
+	// RD is true if the recursion is desired.
+	RD bool `json:"RD"`
+
+	// RA is true if the recursion is available.
+	RA bool `json:"RA"`
+
+	// AD is true if all of the records in the response has been
+	// validated using DNSSEC.
+	AD bool `json:"AD"`
+
+	// CD is true if the client ask to disable the DNSSEC validation.
+	CD bool `json:"CD"`
+}
+
+// MessageJSONQuestion define the question in [MessageJSON].
+type MessageJSONQuestion struct {
+	Name string     `json:"name"`
+	Type RecordType `json:"type"`
+}
+
+// MessageJSONRecord define the resource record in [MessageJSON].
+// The Data contains the record data in the zone file format, for example
+// "10 mail.example.com." for MX, or "1 . alpn=h2,h3" for HTTPS.
+type MessageJSONRecord struct {
+	Name string     `json:"name"`
+	Data string     `json:"data"`
+	TTL  uint32     `json:"TTL"`
+	Type RecordType `json:"type"`
+}
+
+// NewMessageJSON convert the message into JSON format.
+// The OPT record is not included in the Additional, but its EDNS client
+// subnet and extended error is converted into EDNSClientSubnet and
+// Comment.
+func NewMessageJSON(msg *Message) (mjson *MessageJSON) {
+	mjson = &MessageJSON{
+		Status: msg.Header.RCode,
+		TC:     msg.Header.IsTC,
+		RD:     msg.Header.IsRD,
+		RA:     msg.Header.IsRA,
+		AD:     msg.Header.IsAD,
+		CD:     msg.Header.IsCD,
+		Question: []MessageJSONQuestion{{
+			Name: toDomainAbsolute(msg.Question.Name),
+			Type: msg.Question.Type,
+		}},
+		Answer:    newMessageJSONRecords(msg.Answer),
+		Authority: newMessageJSONRecords(msg.Authority),
+	}
+
+	var (
+		rr *ResourceRecord
+		x  int
+	)
+	for x = range len(msg.Additional) {
+		rr = &msg.Additional[x]
+		if rr.Type != RecordTypeOPT {
+			mjson.Additional = append(mjson.Additional,
+				newMessageJSONRecord(rr))
+			continue
+		}
+
+		var opt, _ = rr.Value.(*RDataOPT)
+		if opt == nil {
+			continue
+		}
+
+		var ecs, _ = opt.ClientSubnet()
+		if ecs != nil {
+			mjson.EDNSClientSubnet = fmt.Sprintf(`%s/%d`, ecs.Address,
+				ecs.SourcePrefix)
+		}
+
+		var (
+			listEDE, _ = opt.ExtendedErrors()
+			ede        EDNSExtendedError
+		)
+		for _, ede = range listEDE {
+			if len(mjson.Comment) != 0 {
+				mjson.Comment += `; `
+			}
+			mjson.Comment += fmt.Sprintf(`EDE(%d): %s`, ede.InfoCode,
+				ede.ExtraText)
+		}
+	}
+	return mjson
+}
+
+// messageJSONPacket convert the packed DNS message into JSON.
+func messageJSONPacket(packet []byte) (out []byte, err error) {
+	var msg *Message
+
+	msg, err = UnpackMessage(packet)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(NewMessageJSON(msg))
+}
+
+func newMessageJSONRecords(list []ResourceRecord) (records []MessageJSONRecord) {
+	var x int
+	for x = range len(list) {
+		records = append(records, newMessageJSONRecord(&list[x]))
+	}
+	return records
+}
+
+func newMessageJSONRecord(rr *ResourceRecord) MessageJSONRecord {
+	return MessageJSONRecord{
+		Name: toDomainAbsolute(rr.Name),
+		Type: rr.Type,
+		TTL:  rr.TTL,
+		Data: rdataText(rr),
+	}
+}
+
+// Message convert the JSON message into [Message].
+// The record data that cannot be parsed is stored as string in the record
+// Value.
+func (mjson *MessageJSON) Message() (msg *Message, err error) {
+	var logp = `Message`
+
+	if len(mjson.Question) == 0 {
+		return nil, fmt.Errorf(`%s: empty question`, logp)
+	}
+
+	msg = &Message{
+		Header: MessageHeader{
+			RCode:   mjson.Status,
+			IsTC:    mjson.TC,
+			IsRD:    mjson.RD,
+			IsRA:    mjson.RA,
+			IsAD:    mjson.AD,
+			IsCD:    mjson.CD,
+			QDCount: 1,
+		},
+		Question: MessageQuestion{
+			Name:  strings.TrimSuffix(mjson.Question[0].Name, `.`),
+			Type:  mjson.Question[0].Type,
+			Class: RecordClassIN,
+		},
+		dnameOff: make(map[string]uint16),
+	}
+
+	msg.Answer, err = messageJSONRecords(mjson.Answer)
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+	msg.Authority, err = messageJSONRecords(mjson.Authority)
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+	msg.Additional, err = messageJSONRecords(mjson.Additional)
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	msg.Header.ANCount = uint16(len(msg.Answer))
+	msg.Header.NSCount = uint16(len(msg.Authority))
+	msg.Header.ARCount = uint16(len(msg.Additional))
+
+	return msg, nil
+}
+
+func messageJSONRecords(records []MessageJSONRecord) (list []ResourceRecord, err error) {
+	var (
+		rec MessageJSONRecord
+		rr  *ResourceRecord
+	)
+	for _, rec = range records {
+		rr, err = parseRDataText(rec)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *rr)
+	}
+	return list, nil
+}
+
+// rdataText return the record data in the zone file format.
+func rdataText(rr *ResourceRecord) string {
+	switch v := rr.Value.(type) {
+	case string:
+		switch rr.Type {
+		case RecordTypeTXT:
+			return fmt.Sprintf(`%q`, v)
+		case RecordTypeNS, RecordTypeCNAME, RecordTypePTR,
+			RecordTypeMB, RecordTypeMG, RecordTypeMR,
+			RecordTypeDNAME:
+			return toDomainAbsolute(v)
+		}
+		return v
+	case *RDataSOA:
+		return fmt.Sprintf(`%s %s %d %d %d %d %d`,
+			toDomainAbsolute(v.MName), toDomainAbsolute(v.RName),
+			v.Serial, v.Refresh, v.Retry, v.Expire, v.Minimum)
+	case *RDataMX:
+		return fmt.Sprintf(`%d %s`, v.Preference,
+			toDomainAbsolute(v.Exchange))
+	case *RDataSRV:
+		return fmt.Sprintf(`%d %d %d %s`, v.Priority, v.Weight,
+			v.Port, toDomainAbsolute(v.Target))
+	case *RDataMINFO:
+		return fmt.Sprintf(`%s %s`, toDomainAbsolute(v.RMailBox),
+			toDomainAbsolute(v.EmailBox))
+	case *RDataHINFO:
+		return fmt.Sprintf(`%q %q`, v.CPU, v.OS)
+	case *RDataWKS:
+		return fmt.Sprintf(`%s %d %s`, net.IP(v.Address), v.Protocol,
+			v.BitMap)
+	case io.WriterTo:
+		// The zone format of RDATA is prefixed with its type.
+		var buf bytes.Buffer
+		_, _ = v.WriteTo(&buf)
+		var text = strings.TrimSpace(buf.String())
+		var x = strings.IndexByte(text, ' ')
+		if x < 0 {
+			return ``
+		}
+		return text[x+1:]
+	}
+	return ``
+}
+
+// parseRDataText parse the record in JSON format into ResourceRecord, using
+// the zone parser.
+// The record with type that is not supported by zone parser is returned
+// with the data as its Value.
+func parseRDataText(rec MessageJSONRecord) (rr *ResourceRecord, err error) {
+	rr = &ResourceRecord{
+		Name:  strings.TrimSuffix(rec.Name, `.`),
+		Type:  rec.Type,
+		Class: RecordClassIN,
+		TTL:   rec.TTL,
+		Value: rec.Data,
+	}
+
+	switch rec.Type {
+	case RecordTypeA, RecordTypeAAAA:
+		return rr, nil
+	case RecordTypeNS, RecordTypeCNAME, RecordTypePTR, RecordTypeMB,
+		RecordTypeMG, RecordTypeMR, RecordTypeDNAME:
+		rr.Value = strings.TrimSuffix(rec.Data, `.`)
+		return rr, nil
+	case RecordTypeSOA:
+		// The zone parser require the SOA to be followed by new
+		// line, so we parse it directly.
+		var soa = &RDataSOA{}
+		_, err = fmt.Sscanf(rec.Data, `%s %s %d %d %d %d %d`,
+			&soa.MName, &soa.RName, &soa.Serial, &soa.Refresh,
+			&soa.Retry, &soa.Expire, &soa.Minimum)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid data for %s SOA: %w`,
+				rec.Name, err)
+		}
+		soa.MName = strings.TrimSuffix(soa.MName, `.`)
+		soa.RName = strings.TrimSuffix(soa.RName, `.`)
+		rr.Value = soa
+		return rr, nil
+	}
+
+	var name, ok = RecordTypeNames[rec.Type]
+	if !ok {
+		return rr, nil
+	}
+
+	var (
+		zone = NewZone(``, `.`)
+		line = fmt.Sprintf("%s %d IN %s %s\n",
+			toDomainAbsolute(rec.Name), rec.TTL, name, rec.Data)
+		zp = newZoneParser([]byte(line), zone)
+	)
+
+	zone.SOA.Minimum = 0
+
+	err = zp.parse()
+	if err != nil {
+		return nil, fmt.Errorf(`invalid data for %s %s: %w`,
+			rec.Name, name, err)
+	}
+	if len(zone.messages) == 0 || len(zone.messages[0].Answer) == 0 {
+		return nil, errors.New(`invalid data for ` + rec.Name + ` ` + name)
+	}
+
+	rr.Value = zone.messages[0].Answer[0].Value
+	return rr, nil
+}
+
+// parseMessageJSONQuery create the query message from the parameters of
+// DNS JSON API request,
+//
+//   - name: the query name, required,
+//   - type: the query type, as mnemonic or number, default to "A",
+//   - cd: if its true, set the checking disabled (CD) flag,
+//   - do: if its true, set the DNSSEC OK (DO) bit in OPT record,
+//   - edns_client_subnet: the client subnet, in CIDR notation, to be
+//     sent in OPT record.
+func parseMessageJSONQuery(params url.Values) (msg *Message, err error) {
+	var name = strings.TrimSpace(params.Get(`name`))
+	if len(name) == 0 || len(name) > 253 {
+		return nil, fmt.Errorf(`invalid name %q`, name)
+	}
+
+	msg = NewMessage()
+	msg.Question.Name = strings.TrimSuffix(name, `.`)
+
+	var v = params.Get(`type`)
+	if len(v) != 0 {
+		var n uint64
+
+		n, err = strconv.ParseUint(v, 10, 16)
+		if err == nil {
+			msg.Question.Type = RecordType(n)
+		} else {
+			msg.Question.Type, err = recordTypeParse(v)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	msg.Header.IsCD, _ = strconv.ParseBool(params.Get(`cd`))
+
+	var isDO, _ = strconv.ParseBool(params.Get(`do`))
+	if isDO {
+		msg.opt(true).DO = true
+	}
+
+	v = params.Get(`edns_client_subnet`)
+	if len(v) != 0 {
+		var ipnet = parseNetwork(v)
+		if ipnet == nil {
+			return nil, fmt.Errorf(`invalid edns_client_subnet %q`, v)
+		}
+		var prefix, _ = ipnet.Mask.Size()
+		msg.opt(true).SetClientSubnet(NewEDNSClientSubnet(ipnet.IP,
+			byte(prefix)))
+	}
+
+	_, err = msg.Pack()
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
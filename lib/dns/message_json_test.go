@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestParseRDataText(t *testing.T) {
+	var listCase = []MessageJSONRecord{{
+		Type: RecordTypeA,
+		Data: `192.0.2.1`,
+	}, {
+		Type: RecordTypeAAAA,
+		Data: `2001:db8::1`,
+	}, {
+		Type: RecordTypeCNAME,
+		Data: `www.example.com.`,
+	}, {
+		Type: RecordTypeSOA,
+		Data: `ns1.example.com. admin.example.com. 1 3600 900 604800 300`,
+	}, {
+		Type: RecordTypeMX,
+		Data: `10 mail.example.com.`,
+	}, {
+		Type: RecordTypeTXT,
+		Data: `"v=spf1 -all"`,
+	}, {
+		Type: RecordTypeSRV,
+		Data: `0 5 443 www.example.com.`,
+	}, {
+		Type: RecordTypeHTTPS,
+		Data: `1 . alpn=h2,h3`,
+	}, {
+		Type: RecordTypeSVCB,
+		Data: `16 foo.example.com. port=53`,
+	}}
+
+	var (
+		rec MessageJSONRecord
+		rr  *ResourceRecord
+		err error
+	)
+	for _, rec = range listCase {
+		rec.Name = `example.com.`
+		rec.TTL = 60
+
+		rr, err = parseRDataText(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.Assert(t, RecordTypeNames[rec.Type], rec, newMessageJSONRecord(rr))
+	}
+}
+
+func TestNewMessageJSON(t *testing.T) {
+	var msg = NewMessage()
+
+	msg.Header.IsQuery = false
+	msg.Header.IsRA = true
+	msg.Header.RCode = RCodeErrName
+	msg.Question = MessageQuestion{
+		Name:  `none.example.com`,
+		Type:  RecordTypeA,
+		Class: RecordClassIN,
+	}
+	msg.Authority = []ResourceRecord{{
+		Name:  `example.com`,
+		Type:  RecordTypeSOA,
+		Class: RecordClassIN,
+		TTL:   300,
+		Value: &RDataSOA{
+			MName:   `ns1.example.com`,
+			RName:   `admin.example.com`,
+			Serial:  1,
+			Refresh: 3600,
+			Retry:   900,
+			Expire:  604800,
+			Minimum: 300,
+		},
+	}}
+
+	var opt = msg.opt(true)
+	opt.SetClientSubnet(NewEDNSClientSubnet(net.ParseIP(`192.0.2.0`), 24))
+	opt.AddExtendedError(EDNSExtendedError{
+		InfoCode:  EDEBlocked,
+		ExtraText: `blocked`,
+	})
+
+	var (
+		got []byte
+		err error
+	)
+
+	got, err = json.MarshalIndent(NewMessageJSON(msg), ``, `  `)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var exp = `{
+  "Comment": "EDE(15): blocked",
+  "edns_client_subnet": "192.0.2.0/24",
+  "Question": [
+    {
+      "name": "none.example.com.",
+      "type": 1
+    }
+  ],
+  "Authority": [
+    {
+      "name": "example.com.",
+      "data": "ns1.example.com. admin.example.com. 1 3600 900 604800 300",
+      "TTL": 300,
+      "type": 6
+    }
+  ],
+  "Status": 3,
+  "TC": false,
+  "RD": true,
+  "RA": true,
+  "AD": false,
+  "CD": false
+}`
+	test.Assert(t, `NewMessageJSON`, exp, string(got))
+}
+
+func TestServer_handleDoHJSON(t *testing.T) {
+	var srv = &Server{}
+
+	type testCase struct {
+		desc    string
+		method  string
+		target  string
+		expBody string
+		expCode int
+	}
+
+	var listCase = []testCase{{
+		desc:    `With POST`,
+		method:  http.MethodPost,
+		target:  `/resolve?name=example.com`,
+		expCode: http.StatusMethodNotAllowed,
+	}, {
+		desc:    `Without name`,
+		method:  http.MethodGet,
+		target:  `/resolve`,
+		expCode: http.StatusBadRequest,
+		expBody: "invalid name \"\"\n",
+	}, {
+		desc:    `With unknown type`,
+		method:  http.MethodGet,
+		target:  `/resolve?name=example.com&type=XYZ`,
+		expCode: http.StatusBadRequest,
+		expBody: "unknown record type \"XYZ\"\n",
+	}, {
+		desc:    `With invalid subnet`,
+		method:  http.MethodGet,
+		target:  `/resolve?name=example.com&edns_client_subnet=x`,
+		expCode: http.StatusBadRequest,
+		expBody: "invalid edns_client_subnet \"x\"\n",
+	}}
+
+	var c testCase
+	for _, c = range listCase {
+		var (
+			httpReq = httptest.NewRequest(c.method, c.target, nil)
+			rec     = httptest.NewRecorder()
+		)
+		httpReq.Header.Set(`Accept`, contentTypeDNSJSON)
+
+		srv.ServeHTTP(rec, httpReq)
+
+		test.Assert(t, c.desc+`: status`, c.expCode, rec.Code)
+		test.Assert(t, c.desc+`: body`, c.expBody, rec.Body.String())
+	}
+}
@@ -0,0 +1,22 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import "sync"
+
+// prefetchWriter is the writer for prefetch request.
+// The response is discarded, since its already stored in caches, and the
+// done channel is closed once the response is written.
+type prefetchWriter struct {
+	done chan struct{}
+	once sync.Once
+}
+
+// Write close the done channel and discard the packet.
+func (w *prefetchWriter) Write(packet []byte) (n int, err error) {
+	w.once.Do(func() {
+		close(w.done)
+	})
+	return len(packet), nil
+}
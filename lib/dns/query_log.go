@@ -0,0 +1,412 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// List of format for [ServerOptions.QueryLogFormat].
+const (
+	QueryLogFormatDnstap = `dnstap`
+	QueryLogFormatJSON   = `json`
+	QueryLogFormatText   = `text`
+)
+
+// List of event type in [QueryLogEvent], as defined in dnstap.
+const (
+	QueryLogClientQuery       = `CLIENT_QUERY`
+	QueryLogClientResponse    = `CLIENT_RESPONSE`
+	QueryLogForwarderQuery    = `FORWARDER_QUERY`
+	QueryLogForwarderResponse = `FORWARDER_RESPONSE`
+)
+
+// queryLogUnixPrefix is the prefix of [ServerOptions.QueryLog] for
+// writing the query log to Unix socket.
+const queryLogUnixPrefix = `unix:`
+
+// queryLogQueueSize define the maximum number of events waiting to be
+// written.
+// The event is dropped if the queue is full, so the slow writer does not
+// block the server.
+const queryLogQueueSize = 1024
+
+// QueryLogEvent contains single query or response that is logged by
+// server.
+type QueryLogEvent struct {
+	// QueryTime is the time when the query is received from client or
+	// sent to parent name server.
+	QueryTime time.Time
+
+	// ResponseTime is the time when the response is sent to client or
+	// received from parent name server.
+	ResponseTime time.Time `json:",omitzero"`
+
+	// QueryAddress is the address of client that send the query.
+	QueryAddress netip.AddrPort `json:",omitzero"`
+
+	// ResponseAddress is the address of parent name server.
+	ResponseAddress netip.AddrPort `json:",omitzero"`
+
+	// Type of event, one of the QueryLog constants, for example
+	// "CLIENT_QUERY".
+	Type string
+
+	// Protocol of connection, one of "UDP", "TCP", "DoT", or "DoH".
+	Protocol string
+
+	QName string
+	QType string
+
+	// RCode is the response code of response.
+	RCode string `json:",omitempty"`
+
+	// NameServer is the parent name server for forwarder events.
+	NameServer string `json:",omitempty"`
+
+	// QueryMessage and ResponseMessage contains the raw DNS message.
+	QueryMessage    []byte `json:"-"`
+	ResponseMessage []byte `json:"-"`
+
+	// Elapsed is the duration between QueryTime and ResponseTime.
+	Elapsed time.Duration `json:",omitempty"`
+
+	// ID of message.
+	ID uint16
+
+	// CacheHit is true if the response to client is answered from
+	// caches, including the internal zones and hosts.
+	CacheHit bool `json:",omitempty"`
+}
+
+// queryLogSink define the writer for query log in specific format.
+type queryLogSink interface {
+	write(ev *QueryLogEvent) error
+	flush() error
+	close() error
+}
+
+// queryLogger write the query log events asynchronously.
+type queryLogger struct {
+	sink    queryLogSink
+	eventq  chan *QueryLogEvent
+	stopq   chan struct{}
+	done    chan struct{}
+	dropped atomic.Uint64
+}
+
+// newQueryLogger open the [ServerOptions.QueryLog] and start writing the
+// events in [ServerOptions.QueryLogFormat].
+// It return nil if the QueryLog is empty.
+func newQueryLogger(opts *ServerOptions) (ql *queryLogger, err error) {
+	if len(opts.QueryLog) == 0 {
+		return nil, nil
+	}
+
+	var (
+		logp = `newQueryLogger`
+
+		out          io.ReadWriteCloser
+		isUnixSocket bool
+	)
+
+	if strings.HasPrefix(opts.QueryLog, queryLogUnixPrefix) {
+		var path = strings.TrimPrefix(opts.QueryLog, queryLogUnixPrefix)
+		out, err = net.Dial(`unix`, path)
+		isUnixSocket = true
+	} else {
+		out, err = os.OpenFile(opts.QueryLog,
+			os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	ql = &queryLogger{
+		eventq: make(chan *QueryLogEvent, queryLogQueueSize),
+		stopq:  make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	switch opts.QueryLogFormat {
+	case QueryLogFormatJSON:
+		ql.sink = newQueryLogJSON(out)
+	case QueryLogFormatText:
+		ql.sink = newQueryLogText(out)
+	default:
+		ql.sink, err = newDnstapWriter(out, isUnixSocket)
+		if err != nil {
+			_ = out.Close()
+			return nil, fmt.Errorf(`%s: %w`, logp, err)
+		}
+	}
+
+	go ql.run()
+
+	return ql, nil
+}
+
+// run write the events from queue until the logger is closed.
+func (ql *queryLogger) run() {
+	var (
+		ev       *QueryLogEvent
+		err      error
+		isBroken bool
+	)
+	for {
+		select {
+		case ev = <-ql.eventq:
+			if isBroken {
+				continue
+			}
+			err = ql.sink.write(ev)
+			if err == nil && len(ql.eventq) == 0 {
+				err = ql.sink.flush()
+			}
+			if err != nil {
+				log.Printf(`dns: query log: %s`, err)
+				isBroken = true
+			}
+
+		case <-ql.stopq:
+			ql.drain(isBroken)
+			err = ql.sink.close()
+			if err != nil {
+				log.Printf(`dns: query log: %s`, err)
+			}
+			close(ql.done)
+			return
+		}
+	}
+}
+
+// drain write the remaining events in queue.
+func (ql *queryLogger) drain(isBroken bool) {
+	var ev *QueryLogEvent
+	for {
+		select {
+		case ev = <-ql.eventq:
+			if !isBroken {
+				isBroken = ql.sink.write(ev) != nil
+			}
+		default:
+			return
+		}
+	}
+}
+
+// close stop writing the events and close the sink.
+func (ql *queryLogger) close() {
+	if ql == nil {
+		return
+	}
+	close(ql.stopq)
+	<-ql.done
+
+	var dropped = ql.dropped.Load()
+	if dropped > 0 {
+		log.Printf(`dns: query log: %d events dropped`, dropped)
+	}
+}
+
+// push the event into queue, or drop it if the queue is full.
+func (ql *queryLogger) push(ev *QueryLogEvent) {
+	select {
+	case ql.eventq <- ev:
+	default:
+		ql.dropped.Add(1)
+	}
+}
+
+// clientQuery log the query received from client.
+func (ql *queryLogger) clientQuery(req *request) {
+	if ql == nil {
+		return
+	}
+	var ev = newQueryLogEvent(QueryLogClientQuery, req)
+	ev.QueryMessage = slices.Clone(req.message.packet)
+	ql.push(ev)
+}
+
+// clientResponse log the response packet sent to client.
+func (ql *queryLogger) clientResponse(req *request, packet []byte) {
+	if ql == nil {
+		return
+	}
+	var ev = newQueryLogEvent(QueryLogClientResponse, req)
+	ev.ResponseTime = time.Now()
+	ev.Elapsed = ev.ResponseTime.Sub(ev.QueryTime)
+	ev.ResponseMessage = slices.Clone(packet)
+	ev.CacheHit = req.cacheHit
+	if len(packet) >= sectionHeaderSize {
+		var rcode = ResponseCode(packet[3]&0x0F) | ResponseCode(req.extRCode)<<4
+		ev.RCode = rcodeName(rcode)
+	}
+	ql.push(ev)
+}
+
+// forwarder log the query sent to parent name server at time queryAt,
+// and its response, if its not nil.
+func (ql *queryLogger) forwarder(req *request, proto, nameserver string, queryAt time.Time, res *Message) {
+	if ql == nil {
+		return
+	}
+
+	var ev = newQueryLogEvent(QueryLogForwarderQuery, req)
+	ev.Protocol = proto
+	ev.QueryTime = queryAt
+	ev.QueryAddress = netip.AddrPort{}
+	ev.QueryMessage = slices.Clone(req.message.packet)
+	ev.NameServer = nameserver
+	ev.ResponseAddress, _ = netip.ParseAddrPort(nameserver)
+	ql.push(ev)
+
+	if res == nil {
+		return
+	}
+
+	var evres = *ev
+	evres.Type = QueryLogForwarderResponse
+	evres.QueryMessage = nil
+	evres.ResponseTime = time.Now()
+	evres.Elapsed = evres.ResponseTime.Sub(queryAt)
+	evres.ResponseMessage = slices.Clone(res.packet)
+	evres.RCode = rcodeName(res.Header.RCode)
+	ql.push(&evres)
+}
+
+// newQueryLogEvent create new event with the query and client
+// information from req.
+func newQueryLogEvent(kind string, req *request) (ev *QueryLogEvent) {
+	ev = &QueryLogEvent{
+		QueryTime:    req.startAt,
+		Type:         kind,
+		Protocol:     req.kind,
+		QName:        req.message.Question.Name,
+		QType:        RecordTypeNames[req.message.Question.Type],
+		QueryAddress: req.remoteAddr(),
+		ID:           req.message.Header.ID,
+	}
+	if len(ev.QType) == 0 {
+		ev.QType = fmt.Sprintf(`TYPE%d`, req.message.Question.Type)
+	}
+	return ev
+}
+
+// rcodeName return the human readable name of rcode.
+func rcodeName(rcode ResponseCode) string {
+	var name = rcodeNames[rcode]
+	if len(name) == 0 {
+		name = fmt.Sprintf(`RCODE%d`, rcode)
+	}
+	return name
+}
+
+// queryLogJSON write each event as single line JSON object.
+type queryLogJSON struct {
+	out io.WriteCloser
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func newQueryLogJSON(out io.WriteCloser) (sink *queryLogJSON) {
+	sink = &queryLogJSON{
+		out: out,
+		w:   bufio.NewWriter(out),
+	}
+	sink.enc = json.NewEncoder(sink.w)
+	return sink
+}
+
+func (sink *queryLogJSON) write(ev *QueryLogEvent) error {
+	return sink.enc.Encode(ev)
+}
+
+func (sink *queryLogJSON) flush() error {
+	return sink.w.Flush()
+}
+
+func (sink *queryLogJSON) close() (err error) {
+	err = sink.w.Flush()
+	if err != nil {
+		_ = sink.out.Close()
+		return err
+	}
+	return sink.out.Close()
+}
+
+// queryLogText write each event as single line text, with the following
+// fields separated by space,
+//
+//	QUERY_TIME TYPE PROTOCOL ADDRESS ID QNAME QTYPE RCODE ELAPSED CACHE
+//
+// The ADDRESS is the client address for client events or the parent name
+// server for forwarder events.
+// The empty field is written as "-".
+type queryLogText struct {
+	out io.WriteCloser
+	w   *bufio.Writer
+}
+
+func newQueryLogText(out io.WriteCloser) (sink *queryLogText) {
+	sink = &queryLogText{
+		out: out,
+		w:   bufio.NewWriter(out),
+	}
+	return sink
+}
+
+func (sink *queryLogText) write(ev *QueryLogEvent) (err error) {
+	var (
+		addr    = `-`
+		rcode   = `-`
+		elapsed = `-`
+		cache   = `-`
+	)
+	switch {
+	case len(ev.NameServer) != 0:
+		addr = ev.NameServer
+	case ev.QueryAddress.IsValid():
+		addr = ev.QueryAddress.String()
+	}
+	if len(ev.RCode) != 0 {
+		rcode = ev.RCode
+	}
+	if !ev.ResponseTime.IsZero() {
+		elapsed = ev.Elapsed.String()
+	}
+	if ev.CacheHit {
+		cache = `cache-hit`
+	}
+
+	_, err = fmt.Fprintf(sink.w, "%s %s %s %s %d %s %s %s %s %s\n",
+		ev.QueryTime.UTC().Format(time.RFC3339Nano), ev.Type,
+		ev.Protocol, addr, ev.ID, ev.QName, ev.QType, rcode, elapsed,
+		cache)
+	return err
+}
+
+func (sink *queryLogText) flush() error {
+	return sink.w.Flush()
+}
+
+func (sink *queryLogText) close() (err error) {
+	err = sink.w.Flush()
+	if err != nil {
+		_ = sink.out.Close()
+		return err
+	}
+	return sink.out.Close()
+}
@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+// nopWriteCloser wrap the bytes.Buffer as io.ReadWriteCloser.
+type nopWriteCloser struct {
+	bytes.Buffer
+}
+
+func (nop *nopWriteCloser) Close() error {
+	return nil
+}
+
+func TestQueryLogText_write(t *testing.T) {
+	type testCase struct {
+		exp string
+		ev  QueryLogEvent
+	}
+
+	var (
+		queryAt = time.Date(2026, 1, 2, 3, 4, 5, 600000000, time.UTC)
+
+		listCase = []testCase{{
+			ev: QueryLogEvent{
+				QueryTime:    queryAt,
+				Type:         QueryLogClientQuery,
+				Protocol:     connTypeUDP,
+				QName:        `kilabit.info`,
+				QType:        `A`,
+				QueryAddress: netip.MustParseAddrPort(`127.0.0.1:5353`),
+				ID:           1,
+			},
+			exp: "2026-01-02T03:04:05.6Z CLIENT_QUERY UDP 127.0.0.1:5353 1 kilabit.info A - - -\n",
+		}, {
+			ev: QueryLogEvent{
+				QueryTime:    queryAt,
+				ResponseTime: queryAt.Add(2 * time.Millisecond),
+				Type:         QueryLogClientResponse,
+				Protocol:     connTypeDoT,
+				QName:        `kilabit.info`,
+				QType:        `AAAA`,
+				RCode:        `OK`,
+				QueryAddress: netip.MustParseAddrPort(`[::1]:5353`),
+				Elapsed:      2 * time.Millisecond,
+				ID:           2,
+				CacheHit:     true,
+			},
+			exp: "2026-01-02T03:04:05.6Z CLIENT_RESPONSE DoT [::1]:5353 2 kilabit.info AAAA OK 2ms cache-hit\n",
+		}, {
+			ev: QueryLogEvent{
+				QueryTime:    queryAt,
+				ResponseTime: queryAt.Add(time.Second),
+				Type:         QueryLogForwarderResponse,
+				Protocol:     connTypeDoH,
+				QName:        `kilabit.info`,
+				QType:        `A`,
+				RCode:        `ERR_NAME`,
+				NameServer:   `https://1.1.1.1/dns-query`,
+				Elapsed:      time.Second,
+				ID:           3,
+			},
+			exp: "2026-01-02T03:04:05.6Z FORWARDER_RESPONSE DoH https://1.1.1.1/dns-query 3 kilabit.info A ERR_NAME 1s -\n",
+		}}
+
+		out  = &nopWriteCloser{}
+		sink = newQueryLogText(out)
+
+		c   testCase
+		err error
+	)
+
+	for _, c = range listCase {
+		out.Reset()
+		err = sink.write(&c.ev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = sink.flush()
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.Assert(t, c.ev.Type, c.exp, out.String())
+	}
+}
+
+func TestServer_queryLog(t *testing.T) {
+	var (
+		zoneData = []byte(`@ SOA ns1 admin 1 3600 900 604800 300
+www A 10.3.0.1
+`)
+		logFile = filepath.Join(t.TempDir(), `query.log`)
+
+		upstream *Server
+		zone     *Zone
+		err      error
+	)
+
+	zone, err = ParseZone(zoneData, `querylog.test`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream, err = NewServer(&ServerOptions{
+		ListenAddress: `127.0.0.1:5340`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream.Caches.InternalPopulateZone(zone)
+	go func() {
+		_ = upstream.ListenAndServe()
+	}()
+	t.Cleanup(upstream.Stop)
+
+	var srv *Server
+
+	srv, err = NewServer(&ServerOptions{
+		ListenAddress:  `127.0.0.1:5341`,
+		NameServers:    []string{`udp://127.0.0.1:5340`},
+		QueryLog:       logFile,
+		QueryLogFormat: QueryLogFormatJSON,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_ = srv.ListenAndServe()
+	}()
+
+	// Wait until the server forwarders are connected.
+	var x int
+	for x = 0; x < 50 && !srv.fw.isActive(); x++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	var cl *UDPClient
+
+	cl, err = NewUDPClient(`127.0.0.1:5341`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Close()
+
+	var (
+		q = MessageQuestion{
+			Name: `www.querylog.test`,
+			Type: RecordTypeA,
+		}
+		res *Message
+	)
+
+	// The first query is forwarded, the second one is answered from
+	// caches.
+	for x = range 2 {
+		res, err = cl.Lookup(q, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.Assert(t, `Answer`, `10.3.0.1`, res.Answer[0].Value)
+	}
+
+	srv.Stop()
+
+	var rawlog []byte
+
+	rawlog, err = os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		dec = json.NewDecoder(bytes.NewReader(rawlog))
+
+		got []QueryLogEvent
+		ev  QueryLogEvent
+	)
+	for dec.More() {
+		ev = QueryLogEvent{}
+		err = dec.Decode(&ev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.QueryTime.IsZero() {
+			t.Fatalf(`%s: empty QueryTime`, ev.Type)
+		}
+		if ev.Type == QueryLogClientQuery || ev.Type == QueryLogClientResponse {
+			if !ev.QueryAddress.IsValid() {
+				t.Fatalf(`%s: empty QueryAddress`, ev.Type)
+			}
+		}
+		// Clear the fields that changes on each run.
+		ev.QueryTime = time.Time{}
+		ev.ResponseTime = time.Time{}
+		ev.QueryAddress = netip.AddrPort{}
+		ev.Elapsed = 0
+		ev.ID = 0
+		got = append(got, ev)
+	}
+
+	var (
+		nameServer = `127.0.0.1:5340`
+		exp        = []QueryLogEvent{{
+			Type:     QueryLogClientQuery,
+			Protocol: connTypeUDP,
+			QName:    q.Name,
+			QType:    `A`,
+		}, {
+			Type:            QueryLogForwarderQuery,
+			Protocol:        connTypeUDP,
+			QName:           q.Name,
+			QType:           `A`,
+			NameServer:      nameServer,
+			ResponseAddress: netip.MustParseAddrPort(nameServer),
+		}, {
+			Type:            QueryLogForwarderResponse,
+			Protocol:        connTypeUDP,
+			QName:           q.Name,
+			QType:           `A`,
+			RCode:           `OK`,
+			NameServer:      nameServer,
+			ResponseAddress: netip.MustParseAddrPort(nameServer),
+		}, {
+			Type:     QueryLogClientResponse,
+			Protocol: connTypeUDP,
+			QName:    q.Name,
+			QType:    `A`,
+			RCode:    `OK`,
+		}, {
+			Type:     QueryLogClientQuery,
+			Protocol: connTypeUDP,
+			QName:    q.Name,
+			QType:    `A`,
+		}, {
+			Type:     QueryLogClientResponse,
+			Protocol: connTypeUDP,
+			QName:    q.Name,
+			QType:    `A`,
+			RCode:    `OK`,
+			CacheHit: true,
+		}}
+	)
+	test.Assert(t, `QueryLogEvent`, exp, got)
+}
@@ -0,0 +1,319 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"encoding/binary"
+	"log"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// List of response kind for response rate limiting.
+const (
+	RateLimitKindAnswer   = `answer`
+	RateLimitKindNoData   = `nodata`
+	RateLimitKindNXDomain = `nxdomain`
+	RateLimitKindError    = `error`
+)
+
+// RateLimitStats contains the statistics of response rate limiting.
+type RateLimitStats struct {
+	// Limited contains the list of client network, and its responses,
+	// that has been limited and still tracked by server, sorted by
+	// client network.
+	// The entry is removed once the client does not receive any
+	// response in the window duration.
+	Limited []RateLimitEntry
+
+	// Responses is the total number of responses that is checked.
+	Responses uint64
+
+	// Dropped is the total number of responses that is dropped.
+	Dropped uint64
+
+	// Slipped is the total number of responses that is replaced with
+	// truncated response.
+	Slipped uint64
+}
+
+// RateLimitEntry contains the statistic of limited responses to the
+// client network.
+type RateLimitEntry struct {
+	// Client network in CIDR notation, for example "192.0.2.0/24".
+	Client string
+
+	// Kind of response, one of the RateLimitKind constants.
+	Kind string
+
+	// Name of the query for answer, or the zone for no data and name
+	// error.
+	// It is empty for error or if the zone is unknown.
+	Name string
+
+	// Type of the query for answer.
+	Type RecordType
+
+	// Dropped is the number of responses that is dropped.
+	Dropped uint64
+
+	// Slipped is the number of responses that is replaced with
+	// truncated response.
+	Slipped uint64
+}
+
+// rateLimitKey define the identical responses to the client network.
+type rateLimitKey struct {
+	client string
+	kind   string
+	name   string
+	qtype  RecordType
+}
+
+// rateLimitBucket contains the credit of responses for single
+// rateLimitKey.
+type rateLimitBucket struct {
+	// last is the Unix time, in seconds, when the credit is updated.
+	last int64
+
+	// credit is the number of responses that can be sent.
+	// Each second the credit is increased by rate, up to rate, and
+	// each response decrease it by one, down to -(window*rate).
+	credit int64
+
+	dropped uint64
+	slipped uint64
+}
+
+// rateLimiter implement the Response Rate Limiting (RRL) on UDP, as
+// described in BIND 9.
+type rateLimiter struct {
+	buckets map[rateLimitKey]*rateLimitBucket
+
+	maskV4 net.IPMask
+	maskV6 net.IPMask
+
+	rate   int64
+	window int64
+	slip   int64
+
+	// debug is the server Debug level.
+	debug int
+
+	// lastPrune is the Unix time, in seconds, when the idle buckets
+	// are removed.
+	lastPrune int64
+
+	responses uint64
+	dropped   uint64
+	slipped   uint64
+
+	sync.Mutex
+}
+
+// newRateLimiter create new rateLimiter from server options.
+func newRateLimiter(opts *ServerOptions) (rl *rateLimiter) {
+	rl = &rateLimiter{
+		buckets: make(map[rateLimitKey]*rateLimitBucket),
+		maskV4:  net.CIDRMask(int(opts.RateLimitPrefixV4), 32),
+		maskV6:  net.CIDRMask(int(opts.RateLimitPrefixV6), 128),
+		rate:    int64(opts.RateLimitResponses),
+		window:  int64(opts.RateLimitWindow.Seconds()),
+		slip:    int64(opts.RateLimitSlip),
+		debug:   opts.Debug,
+	}
+	return rl
+}
+
+// limit check the response packet to the ip address.
+// It return the packet itself if its within the limit, the truncated
+// packet if its slipped, or nil if its dropped.
+func (rl *rateLimiter) limit(ip net.IP, qname string, qtype RecordType, packet []byte) []byte {
+	if ip == nil || len(packet) < sectionHeaderSize {
+		return packet
+	}
+
+	var (
+		key = rl.key(ip, qname, qtype, packet)
+		now = timeNow().Unix()
+	)
+
+	rl.Lock()
+	defer rl.Unlock()
+
+	rl.responses++
+	rl.prune(now)
+
+	var bucket = rl.buckets[key]
+	if bucket == nil {
+		bucket = &rateLimitBucket{
+			last:   now,
+			credit: rl.rate,
+		}
+		rl.buckets[key] = bucket
+	} else if now > bucket.last {
+		bucket.credit += (now - bucket.last) * rl.rate
+		if bucket.credit > rl.rate {
+			bucket.credit = rl.rate
+		}
+		bucket.last = now
+	}
+
+	bucket.credit--
+	if bucket.credit < -rl.window*rl.rate {
+		bucket.credit = -rl.window * rl.rate
+	}
+	if bucket.credit >= 0 {
+		return packet
+	}
+
+	if rl.debug&DebugLevelCache != 0 && bucket.dropped+bucket.slipped == 0 {
+		log.Printf(`dns: rate limit %s %s %s %s`, key.client,
+			key.kind, key.name, RecordTypeNames[key.qtype])
+	}
+
+	var nlimited = bucket.dropped + bucket.slipped + 1
+	if rl.slip > 0 && nlimited%uint64(rl.slip) == 0 {
+		var truncated = truncateResponse(packet)
+		if truncated != nil {
+			bucket.slipped++
+			rl.slipped++
+			return truncated
+		}
+	}
+	bucket.dropped++
+	rl.dropped++
+	return nil
+}
+
+// key return the rateLimitKey for response packet to the ip address.
+func (rl *rateLimiter) key(ip net.IP, qname string, qtype RecordType, packet []byte) (key rateLimitKey) {
+	var ipnet = net.IPNet{}
+
+	ipnet.IP = ip.To4()
+	if ipnet.IP != nil {
+		ipnet.Mask = rl.maskV4
+	} else {
+		ipnet.IP = ip.To16()
+		ipnet.Mask = rl.maskV6
+	}
+	ipnet.IP = ipnet.IP.Mask(ipnet.Mask)
+	key.client = ipnet.String()
+
+	var (
+		rcode   = ResponseCode(packet[3] & 0x0F)
+		ancount = binary.BigEndian.Uint16(packet[6:])
+	)
+
+	switch {
+	case rcode == RCodeOK && ancount > 0:
+		key.kind = RateLimitKindAnswer
+		key.name = strings.ToLower(qname)
+		key.qtype = qtype
+	case rcode == RCodeOK:
+		key.kind = RateLimitKindNoData
+		key.name = negativeZone(packet)
+	case rcode == RCodeErrName:
+		key.kind = RateLimitKindNXDomain
+		key.name = negativeZone(packet)
+	default:
+		key.kind = RateLimitKindError
+	}
+	return key
+}
+
+// prune remove the buckets that has not been used in the window
+// duration.
+func (rl *rateLimiter) prune(now int64) {
+	if now-rl.lastPrune < rl.window {
+		return
+	}
+	for key, bucket := range rl.buckets {
+		if now-bucket.last >= rl.window {
+			delete(rl.buckets, key)
+		}
+	}
+	rl.lastPrune = now
+}
+
+// stats return the current statistics of rate limiter.
+func (rl *rateLimiter) stats() (stats RateLimitStats) {
+	rl.Lock()
+	defer rl.Unlock()
+
+	stats.Responses = rl.responses
+	stats.Dropped = rl.dropped
+	stats.Slipped = rl.slipped
+
+	for key, bucket := range rl.buckets {
+		if bucket.dropped+bucket.slipped == 0 {
+			continue
+		}
+		stats.Limited = append(stats.Limited, RateLimitEntry{
+			Client:  key.client,
+			Kind:    key.kind,
+			Name:    key.name,
+			Type:    key.qtype,
+			Dropped: bucket.dropped,
+			Slipped: bucket.slipped,
+		})
+	}
+	slices.SortFunc(stats.Limited, func(a, b RateLimitEntry) int {
+		var c = strings.Compare(a.Client, b.Client)
+		if c != 0 {
+			return c
+		}
+		c = strings.Compare(a.Kind, b.Kind)
+		if c != 0 {
+			return c
+		}
+		c = strings.Compare(a.Name, b.Name)
+		if c != 0 {
+			return c
+		}
+		return int(a.Type) - int(b.Type)
+	})
+	return stats
+}
+
+// negativeZone return the owner of SOA record in the authority section
+// of negative response packet, or empty string if its not exist.
+func negativeZone(packet []byte) string {
+	var msg, err = UnpackMessage(packet)
+	if err != nil {
+		return ``
+	}
+	var rr ResourceRecord
+	for _, rr = range msg.Authority {
+		if rr.Type == RecordTypeSOA {
+			return strings.ToLower(rr.Name)
+		}
+	}
+	return ``
+}
+
+// truncateResponse return the copy of response packet with only header
+// and question section, and with the TC flag set.
+func truncateResponse(packet []byte) []byte {
+	var (
+		msg = &Message{
+			packet: slices.Clone(packet),
+		}
+		err error
+	)
+
+	err = msg.UnpackHeaderQuestion()
+	if err != nil {
+		return nil
+	}
+	msg.Header.IsTC = true
+
+	_, err = msg.Pack()
+	if err != nil {
+		return nil
+	}
+	return msg.packet
+}
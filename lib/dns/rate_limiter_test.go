@@ -0,0 +1,249 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestRateLimiter_limit(t *testing.T) {
+	type testCase struct {
+		desc   string
+		qname  string
+		expect string
+		ip     net.IP
+	}
+
+	const (
+		limitPass  = `pass`
+		limitDrop  = `drop`
+		limitSlip  = `slip`
+		qnameFirst = `kilabit.info`
+	)
+
+	var (
+		opts = &ServerOptions{
+			RateLimitResponses: 2,
+			RateLimitWindow:    2 * time.Second,
+		}
+		msg = NewMessage()
+
+		packet []byte
+		err    error
+	)
+
+	opts.initRateLimit()
+
+	msg.Header.ID = 1234
+	msg.Header.IsQuery = false
+	msg.Question.Name = qnameFirst
+	msg.Answer = []ResourceRecord{{
+		Name:  qnameFirst,
+		Type:  RecordTypeA,
+		Class: RecordClassIN,
+		TTL:   60,
+		Value: `127.0.0.1`,
+	}}
+	packet, err = msg.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		rl       = newRateLimiter(opts)
+		clientA  = net.ParseIP(`192.0.2.1`)
+		clientA2 = net.ParseIP(`192.0.2.200`)
+		clientB  = net.ParseIP(`198.51.100.1`)
+	)
+
+	var listCase = []testCase{{
+		desc:   `First response`,
+		ip:     clientA,
+		expect: limitPass,
+	}, {
+		desc:   `Second response from the same network`,
+		ip:     clientA2,
+		expect: limitPass,
+	}, {
+		desc:   `Third response`,
+		ip:     clientA,
+		expect: limitDrop,
+	}, {
+		desc:   `Fourth response`,
+		ip:     clientA,
+		expect: limitSlip,
+	}, {
+		desc:   `Fifth response`,
+		ip:     clientA,
+		expect: limitDrop,
+	}, {
+		desc:   `Other network`,
+		ip:     clientB,
+		expect: limitPass,
+	}, {
+		desc:   `Other query name`,
+		ip:     clientA,
+		qname:  `www.kilabit.info`,
+		expect: limitPass,
+	}}
+
+	var (
+		c   testCase
+		got []byte
+		res *Message
+	)
+	for _, c = range listCase {
+		if len(c.qname) == 0 {
+			c.qname = qnameFirst
+		}
+		got = rl.limit(c.ip, c.qname, RecordTypeA, packet)
+
+		switch c.expect {
+		case limitPass:
+			test.Assert(t, c.desc, packet, got)
+		case limitDrop:
+			test.Assert(t, c.desc, []byte(nil), got)
+		case limitSlip:
+			res, err = UnpackMessage(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.Assert(t, c.desc+`: ID`, msg.Header.ID, res.Header.ID)
+			test.Assert(t, c.desc+`: IsTC`, true, res.Header.IsTC)
+			test.Assert(t, c.desc+`: Answer`, 0, len(res.Answer))
+			test.Assert(t, c.desc+`: Question`, msg.Question, res.Question)
+		}
+	}
+
+	var expStats = RateLimitStats{
+		Limited: []RateLimitEntry{{
+			Client:  `192.0.2.0/24`,
+			Kind:    RateLimitKindAnswer,
+			Name:    qnameFirst,
+			Type:    RecordTypeA,
+			Dropped: 2,
+			Slipped: 1,
+		}},
+		Responses: 7,
+		Dropped:   2,
+		Slipped:   1,
+	}
+	test.Assert(t, `stats`, expStats, rl.stats())
+
+	// The credit of client is -3, one second later the credit is -1,
+	// and the fourth limited response is truncated.
+	var key = rl.key(clientA, qnameFirst, RecordTypeA, packet)
+	rl.buckets[key].last--
+	got = rl.limit(clientA, qnameFirst, RecordTypeA, packet)
+	test.Assert(t, `After one second`, true, got != nil && len(got) < len(packet))
+
+	// Two seconds later the credit is back to the rate.
+	rl.buckets[key].last -= 2
+	got = rl.limit(clientA, qnameFirst, RecordTypeA, packet)
+	test.Assert(t, `After two seconds`, packet, got)
+}
+
+func TestRateLimiter_key(t *testing.T) {
+	type testCase struct {
+		desc   string
+		ip     net.IP
+		exp    rateLimitKey
+		rcode  ResponseCode
+		answer bool
+		soa    bool
+	}
+
+	var listCase = []testCase{{
+		desc:   `Answer`,
+		ip:     net.ParseIP(`192.0.2.77`),
+		answer: true,
+		exp: rateLimitKey{
+			client: `192.0.2.0/24`,
+			kind:   RateLimitKindAnswer,
+			name:   `www.kilabit.info`,
+			qtype:  RecordTypeA,
+		},
+	}, {
+		desc: `No data`,
+		ip:   net.ParseIP(`2001:db8:1:2ff:3::1`),
+		soa:  true,
+		exp: rateLimitKey{
+			client: `2001:db8:1:200::/56`,
+			kind:   RateLimitKindNoData,
+			name:   `kilabit.info`,
+		},
+	}, {
+		desc:  `Name error`,
+		ip:    net.ParseIP(`192.0.2.77`),
+		rcode: RCodeErrName,
+		soa:   true,
+		exp: rateLimitKey{
+			client: `192.0.2.0/24`,
+			kind:   RateLimitKindNXDomain,
+			name:   `kilabit.info`,
+		},
+	}, {
+		desc:  `Server error`,
+		ip:    net.ParseIP(`192.0.2.77`),
+		rcode: RCodeErrServer,
+		exp: rateLimitKey{
+			client: `192.0.2.0/24`,
+			kind:   RateLimitKindError,
+		},
+	}}
+
+	var (
+		opts = &ServerOptions{
+			RateLimitResponses: 5,
+		}
+
+		rl     *rateLimiter
+		msg    *Message
+		c      testCase
+		packet []byte
+		err    error
+	)
+
+	opts.initRateLimit()
+	rl = newRateLimiter(opts)
+
+	for _, c = range listCase {
+		msg = NewMessage()
+		msg.Header.IsQuery = false
+		msg.Header.RCode = c.rcode
+		msg.Question.Name = `WWW.kilabit.info`
+		if c.answer {
+			msg.Answer = []ResourceRecord{{
+				Name:  `www.kilabit.info`,
+				Type:  RecordTypeA,
+				Class: RecordClassIN,
+				TTL:   60,
+				Value: `127.0.0.1`,
+			}}
+		}
+		if c.soa {
+			msg.Authority = []ResourceRecord{{
+				Name:  `kilabit.info`,
+				Type:  RecordTypeSOA,
+				Class: RecordClassIN,
+				TTL:   60,
+				Value: &RDataSOA{
+					MName: `ns.kilabit.info`,
+					RName: `admin.kilabit.info`,
+				},
+			}}
+		}
+		packet, err = msg.Pack()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got = rl.key(c.ip, msg.Question.Name, RecordTypeA, packet)
+		test.Assert(t, c.desc, c.exp, got)
+	}
+}
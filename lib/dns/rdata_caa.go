@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// CAAFlagCritical the Issuer Critical flag in [RDataCAA.Flags].
+// If its set, the CA that does not understand the Tag must not issue the
+// certificate.
+const CAAFlagCritical byte = 128
+
+// RDataCAA the resource record for type 257 [CAA RR].
+// The CAA record specify the Certification Authorities (CAs) that are
+// allowed to issue certificates for the domain.
+// Format of CAA RDATA,
+//
+//	+------------+------------+
+//	| Flags      | Tag Length | 1 and 1 octets.
+//	+------------+------------+
+//	/ Tag                     /
+//	+-------------------------+
+//	/ Value                   /
+//	+-------------------------+
+//
+// In zone file, the Value is represented as quoted string,
+//
+//	example.com. CAA 0 issue "letsencrypt.org"
+//
+// [CAA RR]: https://datatracker.ietf.org/doc/html/rfc8659#section-4.1
+type RDataCAA struct {
+	// Tag contains the property identifier, for example "issue",
+	// "issuewild", or "iodef".
+	Tag string
+
+	// Value contains the value of property.
+	Value string
+
+	// Flags contains the CAA flags, see CAAFlagCritical.
+	Flags byte
+}
+
+// WriteTo write the CAA record as zone format to out.
+func (caa *RDataCAA) WriteTo(out io.Writer) (_ int64, err error) {
+	var n int
+
+	n, err = fmt.Fprintf(out, "CAA %d %s %q\n", caa.Flags, caa.Tag, caa.Value)
+
+	return int64(n), err
+}
+
+func (caa *RDataCAA) pack(msg *Message) (n int) {
+	n = len(msg.packet)
+	msg.packet = append(msg.packet, caa.Flags)
+	msg.packet = appendCharString(msg.packet, caa.Tag)
+	msg.packet = append(msg.packet, caa.Value...)
+	return len(msg.packet) - n
+}
+
+// parse the CAA RDATA from list of zone tokens.
+func (caa *RDataCAA) parse(fields [][]byte) (err error) {
+	var logp = `parse`
+
+	if len(fields) != 3 {
+		return fmt.Errorf(`%s: invalid CAA RDATA, expecting 3 fields got %d`,
+			logp, len(fields))
+	}
+
+	var v uint64
+
+	v, err = strconv.ParseUint(string(fields[0]), 10, 8)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Flags %q`, logp, fields[0])
+	}
+	caa.Flags = byte(v)
+
+	if len(fields[1]) > 255 {
+		return fmt.Errorf(`%s: Tag too long`, logp)
+	}
+	caa.Tag = string(fields[1])
+	caa.Value = string(unquote(fields[2]))
+
+	return nil
+}
+
+func (caa *RDataCAA) unpack(rdata []byte) (err error) {
+	if len(rdata) < 2 {
+		return fmt.Errorf(`invalid CAA length %d`, len(rdata))
+	}
+	caa.Flags = rdata[0]
+
+	caa.Tag, rdata, err = unpackCharString(rdata[1:])
+	if err != nil {
+		return fmt.Errorf(`invalid CAA Tag: %w`, err)
+	}
+	caa.Value = string(rdata)
+	return nil
+}
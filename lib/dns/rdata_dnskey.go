@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// List of DNSSEC algorithm numbers, as registered in
+// [DNS Security Algorithm Numbers].
+//
+// [DNS Security Algorithm Numbers]: https://www.iana.org/assignments/dns-sec-alg-numbers/dns-sec-alg-numbers.xhtml
+const (
+	DNSSECAlgoRSASHA1          byte = 5
+	DNSSECAlgoRSASHA1NSEC3SHA1 byte = 7
+	DNSSECAlgoRSASHA256        byte = 8
+	DNSSECAlgoRSASHA512        byte = 10
+	DNSSECAlgoECDSAP256SHA256  byte = 13
+	DNSSECAlgoECDSAP384SHA384  byte = 14
+	DNSSECAlgoED25519          byte = 15
+)
+
+// List of known flags in DNSKEY.
+const (
+	// DNSKEYFlagZone indicates that the DNSKEY record holds a DNS zone
+	// key (bit 7).
+	DNSKEYFlagZone uint16 = 0x0100
+
+	// DNSKEYFlagRevoke indicates that the key has been revoked, as
+	// defined in RFC 5011 (bit 8).
+	DNSKEYFlagRevoke uint16 = 0x0080
+
+	// DNSKEYFlagSEP indicates that the key is Secure Entry Point, or
+	// commonly known as Key Signing Key (bit 15).
+	DNSKEYFlagSEP uint16 = 0x0001
+)
+
+// dnskeyProtocol the only valid value for DNSKEY Protocol field.
+const dnskeyProtocol byte = 3
+
+// RDataDNSKEY the resource record for type 48 [DNSKEY RR].
+// Format of DNSKEY RDATA,
+//
+//	+-----------+----------+-----------+
+//	| Flags     | Protocol | Algorithm | 2, 1, and 1 octets.
+//	+-----------+----------+-----------+
+//	/ Public Key                       /
+//	/                                  /
+//	+----------------------------------+
+//
+// In zone file, the Public Key is represented as Base64 encoding,
+//
+//	example.com. 86400 IN DNSKEY 256 3 5 ( AQPSKmynfzW4kyBv015MUG2DeIQ3
+//	                                       Cbl+BBZH4b/0PY1kxkmvHjcZc8no
+//	                                       ... )
+//
+// [DNSKEY RR]: https://datatracker.ietf.org/doc/html/rfc4034#section-2
+type RDataDNSKEY struct {
+	// PublicKey contains the raw, decoded, public key material.
+	PublicKey []byte
+
+	// Flags contains the bit flags of the key, see DNSKEYFlagZone,
+	// DNSKEYFlagRevoke, and DNSKEYFlagSEP.
+	Flags uint16
+
+	// Protocol MUST have value 3.
+	Protocol byte
+
+	// Algorithm identifies the public key's cryptographic algorithm.
+	Algorithm byte
+}
+
+// IsSEP return true if the key has the Secure Entry Point flag set.
+func (dnskey *RDataDNSKEY) IsSEP() bool {
+	return dnskey.Flags&DNSKEYFlagSEP == DNSKEYFlagSEP
+}
+
+// IsZoneKey return true if the key has the Zone Key flag set.
+func (dnskey *RDataDNSKEY) IsZoneKey() bool {
+	return dnskey.Flags&DNSKEYFlagZone == DNSKEYFlagZone
+}
+
+// KeyTag return the key tag of DNSKEY as computed in
+// [RFC 4034 Appendix B].
+//
+// [RFC 4034 Appendix B]: https://datatracker.ietf.org/doc/html/rfc4034#appendix-B
+func (dnskey *RDataDNSKEY) KeyTag() uint16 {
+	var rdata = dnskey.rdata()
+
+	if dnskey.Algorithm == 1 {
+		// RSA/MD5 use the most significant 16 bits of the least
+		// significant 24 bits of modulus.
+		if len(rdata) < 4 {
+			return 0
+		}
+		return binary.BigEndian.Uint16(rdata[len(rdata)-3:])
+	}
+
+	var (
+		ac uint32
+		x  int
+	)
+	for x = range len(rdata) {
+		if x&1 == 1 {
+			ac += uint32(rdata[x])
+		} else {
+			ac += uint32(rdata[x]) << 8
+		}
+	}
+	ac += (ac >> 16) & 0xFFFF
+	return uint16(ac & 0xFFFF)
+}
+
+// WriteTo write the DNSKEY record as zone format to out.
+func (dnskey *RDataDNSKEY) WriteTo(out io.Writer) (_ int64, err error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "DNSKEY %d %d %d %s\n", dnskey.Flags,
+		dnskey.Protocol, dnskey.Algorithm,
+		base64.StdEncoding.EncodeToString(dnskey.PublicKey))
+
+	var n int
+
+	n, err = out.Write(buf.Bytes())
+
+	return int64(n), err
+}
+
+func (dnskey *RDataDNSKEY) initAndValidate() error {
+	if dnskey.Protocol == 0 {
+		dnskey.Protocol = dnskeyProtocol
+	}
+	if dnskey.Protocol != dnskeyProtocol {
+		return fmt.Errorf(`invalid DNSKEY protocol %d`, dnskey.Protocol)
+	}
+	if len(dnskey.PublicKey) == 0 {
+		return errors.New(`empty DNSKEY public key`)
+	}
+	return nil
+}
+
+func (dnskey *RDataDNSKEY) pack(msg *Message) (n int) {
+	var rdata = dnskey.rdata()
+	msg.packet = append(msg.packet, rdata...)
+	return len(rdata)
+}
+
+// parse the DNSKEY RDATA from list of zone tokens.
+func (dnskey *RDataDNSKEY) parse(fields [][]byte) (err error) {
+	var logp = `parse`
+
+	if len(fields) < 4 {
+		return fmt.Errorf(`%s: incomplete DNSKEY RDATA`, logp)
+	}
+
+	var v uint64
+
+	v, err = strconv.ParseUint(string(fields[0]), 10, 16)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Flags %q`, logp, fields[0])
+	}
+	dnskey.Flags = uint16(v)
+
+	v, err = strconv.ParseUint(string(fields[1]), 10, 8)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Protocol %q`, logp, fields[1])
+	}
+	dnskey.Protocol = byte(v)
+
+	v, err = strconv.ParseUint(string(fields[2]), 10, 8)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Algorithm %q`, logp, fields[2])
+	}
+	dnskey.Algorithm = byte(v)
+
+	dnskey.PublicKey, err = base64.StdEncoding.DecodeString(string(bytes.Join(fields[3:], nil)))
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Public Key: %w`, logp, err)
+	}
+
+	return dnskey.initAndValidate()
+}
+
+// rdata return the DNSKEY in wire format.
+func (dnskey *RDataDNSKEY) rdata() (rdata []byte) {
+	rdata = make([]byte, 0, 4+len(dnskey.PublicKey))
+	rdata = binary.BigEndian.AppendUint16(rdata, dnskey.Flags)
+	rdata = append(rdata, dnskey.Protocol, dnskey.Algorithm)
+	rdata = append(rdata, dnskey.PublicKey...)
+	return rdata
+}
+
+func (dnskey *RDataDNSKEY) unpack(rdata []byte) (err error) {
+	if len(rdata) < 4 {
+		return fmt.Errorf(`invalid DNSKEY length %d`, len(rdata))
+	}
+	dnskey.Flags = binary.BigEndian.Uint16(rdata)
+	dnskey.Protocol = rdata[2]
+	dnskey.Algorithm = rdata[3]
+	dnskey.PublicKey = bytes.Clone(rdata[4:])
+	return nil
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// List of DS digest types, as registered in [DS RR Type Digest Algorithms].
+//
+// [DS RR Type Digest Algorithms]: https://www.iana.org/assignments/ds-rr-types/ds-rr-types.xhtml
+const (
+	DSDigestSHA1   byte = 1
+	DSDigestSHA256 byte = 2
+	DSDigestSHA384 byte = 4
+)
+
+// RDataDS the resource record for type 43 [DS RR].
+// The DS record refers to a DNSKEY record in the child zone and it is
+// stored in the parent zone.
+// Format of DS RDATA,
+//
+//	+---------+-----------+-------------+
+//	| Key Tag | Algorithm | Digest Type | 2, 1, and 1 octets.
+//	+---------+-----------+-------------+
+//	/ Digest                            /
+//	/                                   /
+//	+-----------------------------------+
+//
+// In zone file, the Digest is represented as hexadecimal digits,
+//
+//	dskey.example.com. 86400 IN DS 60485 5 1 ( 2BB183AF5F22588179A53B0A
+//	                                           98631FAD1A292118 )
+//
+// [DS RR]: https://datatracker.ietf.org/doc/html/rfc4034#section-5
+type RDataDS struct {
+	// Digest contains the raw digest of DNSKEY owner name and its
+	// RDATA.
+	Digest []byte
+
+	// KeyTag contains the key tag of the DNSKEY that is referred by
+	// this record.
+	KeyTag uint16
+
+	// Algorithm contains the algorithm of DNSKEY.
+	Algorithm byte
+
+	// DigestType identifies the algorithm used to construct the
+	// Digest.
+	DigestType byte
+}
+
+// WriteTo write the DS record as zone format to out.
+func (ds *RDataDS) WriteTo(out io.Writer) (_ int64, err error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "DS %d %d %d %s\n", ds.KeyTag, ds.Algorithm,
+		ds.DigestType, strings.ToUpper(hex.EncodeToString(ds.Digest)))
+
+	var n int
+
+	n, err = out.Write(buf.Bytes())
+
+	return int64(n), err
+}
+
+func (ds *RDataDS) pack(msg *Message) (n int) {
+	n = len(msg.packet)
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, ds.KeyTag)
+	msg.packet = append(msg.packet, ds.Algorithm, ds.DigestType)
+	msg.packet = append(msg.packet, ds.Digest...)
+	return len(msg.packet) - n
+}
+
+// parse the DS RDATA from list of zone tokens.
+func (ds *RDataDS) parse(fields [][]byte) (err error) {
+	var logp = `parse`
+
+	if len(fields) < 4 {
+		return fmt.Errorf(`%s: incomplete DS RDATA`, logp)
+	}
+
+	var v uint64
+
+	v, err = strconv.ParseUint(string(fields[0]), 10, 16)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Key Tag %q`, logp, fields[0])
+	}
+	ds.KeyTag = uint16(v)
+
+	v, err = strconv.ParseUint(string(fields[1]), 10, 8)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Algorithm %q`, logp, fields[1])
+	}
+	ds.Algorithm = byte(v)
+
+	v, err = strconv.ParseUint(string(fields[2]), 10, 8)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Digest Type %q`, logp, fields[2])
+	}
+	ds.DigestType = byte(v)
+
+	ds.Digest, err = hex.DecodeString(string(bytes.Join(fields[3:], nil)))
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Digest: %w`, logp, err)
+	}
+	return nil
+}
+
+func (ds *RDataDS) unpack(rdata []byte) (err error) {
+	if len(rdata) < 4 {
+		return fmt.Errorf(`invalid DS length %d`, len(rdata))
+	}
+	ds.KeyTag = binary.BigEndian.Uint16(rdata)
+	ds.Algorithm = rdata[2]
+	ds.DigestType = rdata[3]
+	ds.Digest = bytes.Clone(rdata[4:])
+	return nil
+}
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// RDataNAPTR the resource record for type 35 [NAPTR RR].
+// The NAPTR record contains the rule, in the form of regular expression,
+// to rewrite the domain name into URI or other domain name for service
+// discovery.
+// Format of NAPTR RDATA,
+//
+//	+-------------+-------------+
+//	| ORDER       | PREFERENCE  | 2 and 2 octets.
+//	+-------------+-------------+
+//	/ FLAGS                     / <character-string>
+//	+---------------------------+
+//	/ SERVICES                  / <character-string>
+//	+---------------------------+
+//	/ REGEXP                    / <character-string>
+//	+---------------------------+
+//	/ REPLACEMENT               / <domain-name>
+//	+---------------------------+
+//
+// In zone file, the FLAGS, SERVICES, and REGEXP are represented as quoted
+// string,
+//
+//	example.com. NAPTR 100 10 "S" "SIP+D2U" "" _sip._udp.example.com.
+//
+// [NAPTR RR]: https://datatracker.ietf.org/doc/html/rfc3403#section-4.1
+type RDataNAPTR struct {
+	// Flags contains the flags to control the rewriting, for example
+	// "S", "A", "U", or "P".
+	Flags string
+
+	// Services contains the service parameters.
+	Services string
+
+	// Regexp contains the substitution expression that applied to the
+	// original string to construct the next domain name to lookup.
+	Regexp string
+
+	// Replacement contains the next domain name to query.
+	// It is "." if the Regexp is used.
+	Replacement string
+
+	// Order contains the order in which the NAPTR records must be
+	// processed, lower value first.
+	Order uint16
+
+	// Preference contains the order in which the NAPTR records with
+	// equal Order should be processed.
+	Preference uint16
+}
+
+// WriteTo write the NAPTR record as zone format to out.
+func (naptr *RDataNAPTR) WriteTo(out io.Writer) (_ int64, err error) {
+	var (
+		replacement = toDomainAbsolute(naptr.Replacement)
+		n           int
+	)
+
+	n, err = fmt.Fprintf(out, "NAPTR %d %d %q %q %q %s\n", naptr.Order,
+		naptr.Preference, naptr.Flags, naptr.Services, naptr.Regexp,
+		replacement)
+
+	return int64(n), err
+}
+
+func (naptr *RDataNAPTR) pack(msg *Message) (n int) {
+	n = len(msg.packet)
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, naptr.Order)
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, naptr.Preference)
+	msg.packet = appendCharString(msg.packet, naptr.Flags)
+	msg.packet = appendCharString(msg.packet, naptr.Services)
+	msg.packet = appendCharString(msg.packet, naptr.Regexp)
+
+	// The Replacement must not be compressed, RFC 3403 section 4.1.
+	msg.packDomainName([]byte(naptr.Replacement), false)
+
+	return len(msg.packet) - n
+}
+
+// parse the NAPTR RDATA from list of zone tokens.
+func (naptr *RDataNAPTR) parse(zp *zoneParser, fields [][]byte) (err error) {
+	var logp = `parse`
+
+	if len(fields) != 6 {
+		return fmt.Errorf(`%s: invalid NAPTR RDATA, expecting 6 fields got %d`,
+			logp, len(fields))
+	}
+
+	var v uint64
+
+	v, err = strconv.ParseUint(string(fields[0]), 10, 16)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Order %q`, logp, fields[0])
+	}
+	naptr.Order = uint16(v)
+
+	v, err = strconv.ParseUint(string(fields[1]), 10, 16)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Preference %q`, logp, fields[1])
+	}
+	naptr.Preference = uint16(v)
+
+	naptr.Flags = string(unquote(fields[2]))
+	naptr.Services = string(unquote(fields[3]))
+	naptr.Regexp = string(unquote(fields[4]))
+	naptr.Replacement = zp.generateDomainName(fields[5])
+
+	return nil
+}
+
+func (naptr *RDataNAPTR) unpack(packet []byte, start, end uint) (err error) {
+	var (
+		logp  = `unpack`
+		rdata = packet[start:end]
+	)
+
+	if len(rdata) < 4 {
+		return fmt.Errorf(`%s: invalid NAPTR length %d`, logp, len(rdata))
+	}
+	naptr.Order = binary.BigEndian.Uint16(rdata)
+	naptr.Preference = binary.BigEndian.Uint16(rdata[2:])
+	rdata = rdata[4:]
+
+	naptr.Flags, rdata, err = unpackCharString(rdata)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid NAPTR Flags: %w`, logp, err)
+	}
+	naptr.Services, rdata, err = unpackCharString(rdata)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid NAPTR Services: %w`, logp, err)
+	}
+	naptr.Regexp, rdata, err = unpackCharString(rdata)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid NAPTR Regexp: %w`, logp, err)
+	}
+
+	naptr.Replacement, _, err = unpackDomainName(packet, end-uint(len(rdata)))
+	if err != nil {
+		return fmt.Errorf(`%s: invalid NAPTR Replacement: %w`, logp, err)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// RDataNSEC the resource record for type 47 [NSEC RR].
+// The NSEC record lists the next owner name, in the canonical ordering of
+// the zone, that contains authoritative data, and the set of RR types
+// present at the NSEC RR's owner name.
+// Format of NSEC RDATA,
+//
+//	+---------------------+
+//	/ Next Domain Name    /
+//	/                     /
+//	+---------------------+
+//	/ Type Bit Maps       /
+//	/                     /
+//	+---------------------+
+//
+// The Next Domain Name is not compressed.
+//
+// In zone file, the Type Bit Maps is represented as a sequence of RR type
+// mnemonics,
+//
+//	alfa.example.com. 86400 IN NSEC host.example.com. (
+//	                                A MX RRSIG NSEC TYPE1234 )
+//
+// [NSEC RR]: https://datatracker.ietf.org/doc/html/rfc4034#section-4
+type RDataNSEC struct {
+	// NextDomain contains the next owner name in canonical ordering of
+	// the zone.
+	NextDomain string
+
+	// Types contains the list of RR types that exist at the owner name,
+	// sorted in ascending order.
+	Types []RecordType
+}
+
+// WriteTo write the NSEC record as zone format to out.
+func (nsec *RDataNSEC) WriteTo(out io.Writer) (_ int64, err error) {
+	var buf bytes.Buffer
+
+	buf.WriteString(`NSEC `)
+	buf.WriteString(toDomainAbsolute(nsec.NextDomain))
+	writeTypeBitMaps(&buf, nsec.Types)
+	buf.WriteByte('\n')
+
+	var n int
+
+	n, err = out.Write(buf.Bytes())
+
+	return int64(n), err
+}
+
+func (nsec *RDataNSEC) pack(msg *Message) (n int) {
+	n = len(msg.packet)
+	msg.packet = append(msg.packet, packDomainNameCanonical(nsec.NextDomain)...)
+	msg.packet = append(msg.packet, packTypeBitMaps(nsec.Types)...)
+	return len(msg.packet) - n
+}
+
+// parse the NSEC RDATA from list of zone tokens.
+func (nsec *RDataNSEC) parse(zp *zoneParser, fields [][]byte) (err error) {
+	var logp = `parse`
+
+	if len(fields) == 0 {
+		return fmt.Errorf(`%s: incomplete NSEC RDATA`, logp)
+	}
+
+	nsec.NextDomain = zp.generateDomainName(fields[0])
+
+	nsec.Types, err = parseTypeBitMaps(fields[1:])
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+	return nil
+}
+
+func (nsec *RDataNSEC) unpack(packet []byte, start, end uint) (err error) {
+	var (
+		logp = `unpack`
+		x    uint
+	)
+
+	nsec.NextDomain, x, err = unpackDomainName(packet, start)
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+	if x > end {
+		return fmt.Errorf(`%s: Next Domain Name overflow`, logp)
+	}
+
+	nsec.Types, err = unpackTypeBitMaps(packet[x:end])
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+	return nil
+}
+
+// packTypeBitMaps encode list of RR types into Type Bit Maps field of
+// NSEC and NSEC3, as described in RFC 4034 section 4.1.2.
+//
+// The RR type space is split into 256 window blocks, each representing the
+// low-order 8 bits of the 16-bit RR type space.
+// Each block that has at least one active RR type is encoded using a
+// single octet window number, followed by a single octet bitmap length,
+// followed by up to 32 octets of bitmap.
+func packTypeBitMaps(types []RecordType) (out []byte) {
+	if len(types) == 0 {
+		return nil
+	}
+
+	var sorted = slices.Clone(types)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	var (
+		bitmap [32]byte
+		window = -1
+		maxIdx int
+		rtype  RecordType
+	)
+	for _, rtype = range sorted {
+		var (
+			curWindow = int(rtype >> 8)
+			low       = int(rtype & 0xFF)
+		)
+		if curWindow != window {
+			if window >= 0 {
+				out = append(out, byte(window), byte(maxIdx+1))
+				out = append(out, bitmap[:maxIdx+1]...)
+			}
+			bitmap = [32]byte{}
+			window = curWindow
+			maxIdx = 0
+		}
+		bitmap[low/8] |= 0x80 >> (low % 8)
+		if low/8 > maxIdx {
+			maxIdx = low / 8
+		}
+	}
+	out = append(out, byte(window), byte(maxIdx+1))
+	out = append(out, bitmap[:maxIdx+1]...)
+	return out
+}
+
+// unpackTypeBitMaps decode the Type Bit Maps field of NSEC and NSEC3 into
+// list of RR types.
+func unpackTypeBitMaps(rdata []byte) (types []RecordType, err error) {
+	var lastWindow = -1
+
+	for len(rdata) > 0 {
+		if len(rdata) < 2 {
+			return nil, fmt.Errorf(`invalid type bit maps length %d`, len(rdata))
+		}
+
+		var (
+			window = int(rdata[0])
+			size   = int(rdata[1])
+		)
+		if window <= lastWindow {
+			return nil, fmt.Errorf(`invalid type bit maps window order %d`, window)
+		}
+		if size == 0 || size > 32 {
+			return nil, fmt.Errorf(`invalid type bit maps window length %d`, size)
+		}
+		rdata = rdata[2:]
+		if len(rdata) < size {
+			return nil, fmt.Errorf(`invalid type bit maps window %d length %d`, window, size)
+		}
+
+		var (
+			x   int
+			bit int
+		)
+		for x = range size {
+			for bit = range 8 {
+				if rdata[x]&(0x80>>bit) == 0 {
+					continue
+				}
+				types = append(types, RecordType(window<<8|x*8+bit))
+			}
+		}
+		rdata = rdata[size:]
+		lastWindow = window
+	}
+	return types, nil
+}
+
+// parseTypeBitMaps parse list of RR type mnemonics in zone file.
+func parseTypeBitMaps(fields [][]byte) (types []RecordType, err error) {
+	var (
+		field []byte
+		rtype RecordType
+	)
+	for _, field = range fields {
+		rtype, err = recordTypeParse(string(field))
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, rtype)
+	}
+	slices.Sort(types)
+	types = slices.Compact(types)
+	return types, nil
+}
+
+// writeTypeBitMaps write the list of RR type mnemonics, each prefixed with
+// single space.
+func writeTypeBitMaps(buf *bytes.Buffer, types []RecordType) {
+	var rtype RecordType
+	for _, rtype = range types {
+		buf.WriteByte(' ')
+		buf.WriteString(recordTypeName(rtype))
+	}
+}
@@ -0,0 +1,215 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// NSEC3HashSHA1 the only defined hash algorithm for NSEC3 and NSEC3PARAM.
+const NSEC3HashSHA1 byte = 1
+
+// NSEC3FlagOptOut indicates that the NSEC3 record may cover unsigned
+// delegations.
+const NSEC3FlagOptOut byte = 0x01
+
+// nsec3Encoding define the "Base 32 Encoding with Extended Hex Alphabet"
+// without padding, used by NSEC3 Next Hashed Owner Name and by hashed owner
+// name label.
+var nsec3Encoding = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+// RDataNSEC3 the resource record for type 50 [NSEC3 RR].
+// The NSEC3 record provides authenticated denial of existence using hashed
+// owner names.
+// Format of NSEC3 RDATA,
+//
+//	+-----------+-------+------------+
+//	| Hash Alg. | Flags | Iterations | 1, 1, and 2 octets.
+//	+-----------+-------+------------+
+//	| Salt Length | Salt             / 1 octet, followed by Salt.
+//	+-------------+                  /
+//	+--------------------------------+
+//	| Hash Length | Next Hashed      / 1 octet, followed by hash.
+//	+-------------+ Owner Name       /
+//	+--------------------------------+
+//	/ Type Bit Maps                  /
+//	+--------------------------------+
+//
+// In zone file, the Salt is represented as hexadecimal digits or "-" if
+// its empty, and the Next Hashed Owner Name is represented as unpadded
+// Base32 with extended hex alphabet,
+//
+//	2t7b4g4vsa5smi47k61mv5bv1a22bojr.example. 3600 IN NSEC3 1 1 12 aabbccdd (
+//	                         2vptu5timamqttgl4luu9kg21e0aor3s A RRSIG )
+//
+// [NSEC3 RR]: https://datatracker.ietf.org/doc/html/rfc5155#section-3
+type RDataNSEC3 struct {
+	// Salt contains the raw salt appended to the owner name before
+	// hashing.
+	Salt []byte
+
+	// NextHashedOwner contains the raw hash of the next owner name in
+	// hash order of the zone.
+	NextHashedOwner []byte
+
+	// Types contains the list of RR types that exist at the original
+	// owner name, sorted in ascending order.
+	Types []RecordType
+
+	// Iterations define the number of additional times the hash function
+	// has been performed.
+	Iterations uint16
+
+	// HashAlgorithm identifies the cryptographic hash algorithm used to
+	// construct the hash.
+	HashAlgorithm byte
+
+	// Flags contains the NSEC3 flags, see NSEC3FlagOptOut.
+	Flags byte
+}
+
+// IsOptOut return true if the Opt-Out flag is set.
+func (nsec3 *RDataNSEC3) IsOptOut() bool {
+	return nsec3.Flags&NSEC3FlagOptOut == NSEC3FlagOptOut
+}
+
+// WriteTo write the NSEC3 record as zone format to out.
+func (nsec3 *RDataNSEC3) WriteTo(out io.Writer) (_ int64, err error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, `NSEC3 %d %d %d %s %s`, nsec3.HashAlgorithm,
+		nsec3.Flags, nsec3.Iterations, nsec3FormatSalt(nsec3.Salt),
+		strings.ToLower(nsec3Encoding.EncodeToString(nsec3.NextHashedOwner)))
+	writeTypeBitMaps(&buf, nsec3.Types)
+	buf.WriteByte('\n')
+
+	var n int
+
+	n, err = out.Write(buf.Bytes())
+
+	return int64(n), err
+}
+
+func (nsec3 *RDataNSEC3) pack(msg *Message) (n int) {
+	n = len(msg.packet)
+	msg.packet = append(msg.packet, nsec3.HashAlgorithm, nsec3.Flags)
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, nsec3.Iterations)
+	msg.packet = append(msg.packet, byte(len(nsec3.Salt)))
+	msg.packet = append(msg.packet, nsec3.Salt...)
+	msg.packet = append(msg.packet, byte(len(nsec3.NextHashedOwner)))
+	msg.packet = append(msg.packet, nsec3.NextHashedOwner...)
+	msg.packet = append(msg.packet, packTypeBitMaps(nsec3.Types)...)
+	return len(msg.packet) - n
+}
+
+// parse the NSEC3 RDATA from list of zone tokens.
+func (nsec3 *RDataNSEC3) parse(fields [][]byte) (err error) {
+	var logp = `parse`
+
+	if len(fields) < 5 {
+		return fmt.Errorf(`%s: incomplete NSEC3 RDATA`, logp)
+	}
+
+	nsec3.HashAlgorithm, nsec3.Flags, nsec3.Iterations, nsec3.Salt, err = nsec3ParseParams(fields[:4])
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	var next = strings.ToUpper(string(fields[4]))
+
+	nsec3.NextHashedOwner, err = nsec3Encoding.DecodeString(next)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Next Hashed Owner Name %q`, logp, fields[4])
+	}
+
+	nsec3.Types, err = parseTypeBitMaps(fields[5:])
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+	return nil
+}
+
+func (nsec3 *RDataNSEC3) unpack(rdata []byte) (err error) {
+	var logp = `unpack`
+
+	if len(rdata) < 5 {
+		return fmt.Errorf(`%s: invalid NSEC3 length %d`, logp, len(rdata))
+	}
+
+	nsec3.HashAlgorithm = rdata[0]
+	nsec3.Flags = rdata[1]
+	nsec3.Iterations = binary.BigEndian.Uint16(rdata[2:])
+
+	var size = int(rdata[4])
+	rdata = rdata[5:]
+	if len(rdata) < size+1 {
+		return fmt.Errorf(`%s: invalid Salt length %d`, logp, size)
+	}
+	nsec3.Salt = bytes.Clone(rdata[:size])
+	rdata = rdata[size:]
+
+	size = int(rdata[0])
+	rdata = rdata[1:]
+	if size == 0 || len(rdata) < size {
+		return fmt.Errorf(`%s: invalid Hash length %d`, logp, size)
+	}
+	nsec3.NextHashedOwner = bytes.Clone(rdata[:size])
+	rdata = rdata[size:]
+
+	nsec3.Types, err = unpackTypeBitMaps(rdata)
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+	return nil
+}
+
+// nsec3FormatSalt return the salt in hexadecimal or "-" if its empty.
+func nsec3FormatSalt(salt []byte) string {
+	if len(salt) == 0 {
+		return `-`
+	}
+	return strings.ToUpper(hex.EncodeToString(salt))
+}
+
+// nsec3ParseParams parse the Hash Algorithm, Flags, Iterations, and Salt
+// fields from zone file, shared by NSEC3 and NSEC3PARAM.
+func nsec3ParseParams(fields [][]byte) (hashAlg, flags byte, iterations uint16, salt []byte, err error) {
+	var v uint64
+
+	v, err = strconv.ParseUint(string(fields[0]), 10, 8)
+	if err != nil {
+		return 0, 0, 0, nil, fmt.Errorf(`invalid Hash Algorithm %q`, fields[0])
+	}
+	hashAlg = byte(v)
+
+	v, err = strconv.ParseUint(string(fields[1]), 10, 8)
+	if err != nil {
+		return 0, 0, 0, nil, fmt.Errorf(`invalid Flags %q`, fields[1])
+	}
+	flags = byte(v)
+
+	v, err = strconv.ParseUint(string(fields[2]), 10, 16)
+	if err != nil {
+		return 0, 0, 0, nil, fmt.Errorf(`invalid Iterations %q`, fields[2])
+	}
+	iterations = uint16(v)
+
+	if string(fields[3]) != `-` {
+		salt, err = hex.DecodeString(string(fields[3]))
+		if err != nil {
+			return 0, 0, 0, nil, fmt.Errorf(`invalid Salt %q`, fields[3])
+		}
+		if len(salt) > 255 {
+			return 0, 0, 0, nil, fmt.Errorf(`Salt length %d is too long`, len(salt))
+		}
+	}
+	return hashAlg, flags, iterations, salt, nil
+}
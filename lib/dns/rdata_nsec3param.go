@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// RDataNSEC3PARAM the resource record for type 51 [NSEC3PARAM RR].
+// The NSEC3PARAM record contains the NSEC3 parameters needed by
+// authoritative server to calculate hashed owner names.
+// Format of NSEC3PARAM RDATA,
+//
+//	+-----------+-------+------------+
+//	| Hash Alg. | Flags | Iterations | 1, 1, and 2 octets.
+//	+-----------+-------+------------+
+//	| Salt Length | Salt             / 1 octet, followed by Salt.
+//	+-------------+                  /
+//	+--------------------------------+
+//
+// In zone file,
+//
+//	example. 3600 IN NSEC3PARAM 1 0 12 aabbccdd
+//
+// [NSEC3PARAM RR]: https://datatracker.ietf.org/doc/html/rfc5155#section-4
+type RDataNSEC3PARAM struct {
+	// Salt contains the raw salt appended to the owner name before
+	// hashing.
+	Salt []byte
+
+	// Iterations define the number of additional times the hash function
+	// has been performed.
+	Iterations uint16
+
+	// HashAlgorithm identifies the cryptographic hash algorithm used to
+	// construct the hash.
+	HashAlgorithm byte
+
+	// Flags MUST be zero in zone, it is reserved for future use.
+	Flags byte
+}
+
+// WriteTo write the NSEC3PARAM record as zone format to out.
+func (param *RDataNSEC3PARAM) WriteTo(out io.Writer) (_ int64, err error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "NSEC3PARAM %d %d %d %s\n", param.HashAlgorithm,
+		param.Flags, param.Iterations, nsec3FormatSalt(param.Salt))
+
+	var n int
+
+	n, err = out.Write(buf.Bytes())
+
+	return int64(n), err
+}
+
+func (param *RDataNSEC3PARAM) pack(msg *Message) (n int) {
+	n = len(msg.packet)
+	msg.packet = append(msg.packet, param.HashAlgorithm, param.Flags)
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, param.Iterations)
+	msg.packet = append(msg.packet, byte(len(param.Salt)))
+	msg.packet = append(msg.packet, param.Salt...)
+	return len(msg.packet) - n
+}
+
+// parse the NSEC3PARAM RDATA from list of zone tokens.
+func (param *RDataNSEC3PARAM) parse(fields [][]byte) (err error) {
+	var logp = `parse`
+
+	if len(fields) != 4 {
+		return fmt.Errorf(`%s: invalid NSEC3PARAM RDATA`, logp)
+	}
+
+	param.HashAlgorithm, param.Flags, param.Iterations, param.Salt, err = nsec3ParseParams(fields)
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+	return nil
+}
+
+func (param *RDataNSEC3PARAM) unpack(rdata []byte) (err error) {
+	if len(rdata) < 5 {
+		return fmt.Errorf(`invalid NSEC3PARAM length %d`, len(rdata))
+	}
+
+	param.HashAlgorithm = rdata[0]
+	param.Flags = rdata[1]
+	param.Iterations = binary.BigEndian.Uint16(rdata[2:])
+
+	var size = int(rdata[4])
+	rdata = rdata[5:]
+	if len(rdata) < size {
+		return fmt.Errorf(`invalid NSEC3PARAM Salt length %d`, size)
+	}
+	param.Salt = bytes.Clone(rdata[:size])
+	return nil
+}
@@ -10,6 +10,18 @@ import (
 	"strings"
 )
 
+// List of option code in [RDataOPTVar].
+const (
+	OptionCodeClientSubnet  uint16 = 8  // RFC 7871.
+	OptionCodeCookie        uint16 = 10 // RFC 7873.
+	OptionCodePadding       uint16 = 12 // RFC 7830.
+	OptionCodeExtendedError uint16 = 15 // RFC 8914.
+)
+
+// paddingBlockSize is the block size for padding the response, as
+// recommended by RFC 8467 section 4.1.
+const paddingBlockSize = 468
+
 // RDataOPT define format of RDATA for OPT.
 //
 // The extended RCODE and flags, which OPT stores in the RR Time to Live
@@ -85,3 +97,127 @@ func (opt *RDataOPT) unpack(rdlen int, packet []byte) (err error) {
 	}
 	return nil
 }
+
+// ClientSubnet return the EDNS Client Subnet option, or nil if its not
+// exist.
+func (opt *RDataOPT) ClientSubnet() (ecs *EDNSClientSubnet, err error) {
+	var optvar = opt.get(OptionCodeClientSubnet)
+	if optvar == nil {
+		return nil, nil
+	}
+	ecs = &EDNSClientSubnet{}
+	err = ecs.unpack(optvar.Data)
+	if err != nil {
+		return nil, err
+	}
+	return ecs, nil
+}
+
+// SetClientSubnet set the EDNS Client Subnet option.
+// If ecs is nil, the option is removed.
+func (opt *RDataOPT) SetClientSubnet(ecs *EDNSClientSubnet) {
+	if ecs == nil {
+		opt.remove(OptionCodeClientSubnet)
+		return
+	}
+	opt.set(OptionCodeClientSubnet, ecs.pack())
+}
+
+// Cookie return the DNS Cookie option, or nil if its not exist.
+func (opt *RDataOPT) Cookie() (cookie *EDNSCookie, err error) {
+	var optvar = opt.get(OptionCodeCookie)
+	if optvar == nil {
+		return nil, nil
+	}
+	cookie = &EDNSCookie{}
+	err = cookie.unpack(optvar.Data)
+	if err != nil {
+		return nil, err
+	}
+	return cookie, nil
+}
+
+// SetCookie set the DNS Cookie option.
+// If cookie is nil, the option is removed.
+func (opt *RDataOPT) SetCookie(cookie *EDNSCookie) {
+	if cookie == nil {
+		opt.remove(OptionCodeCookie)
+		return
+	}
+	opt.set(OptionCodeCookie, cookie.pack())
+}
+
+// ExtendedErrors return all of the Extended DNS Error options.
+func (opt *RDataOPT) ExtendedErrors() (list []EDNSExtendedError, err error) {
+	var optvar RDataOPTVar
+	for _, optvar = range opt.ListVar {
+		if optvar.Code != OptionCodeExtendedError {
+			continue
+		}
+		var ede EDNSExtendedError
+		err = ede.unpack(optvar.Data)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, ede)
+	}
+	return list, nil
+}
+
+// AddExtendedError add the Extended DNS Error option.
+// Unlike other options, the EDE option can be added more than once.
+func (opt *RDataOPT) AddExtendedError(ede EDNSExtendedError) {
+	opt.ListVar = append(opt.ListVar, RDataOPTVar{
+		Code: OptionCodeExtendedError,
+		Data: ede.pack(),
+	})
+}
+
+// SetPadding set the Padding option with n zero octets.
+// If n is less than zero, the option is removed.
+func (opt *RDataOPT) SetPadding(n int) {
+	if n < 0 {
+		opt.remove(OptionCodePadding)
+		return
+	}
+	opt.set(OptionCodePadding, make([]byte, n))
+}
+
+// get return the first option with the code, or nil if its not exist.
+func (opt *RDataOPT) get(code uint16) (optvar *RDataOPTVar) {
+	var x int
+	for x = range len(opt.ListVar) {
+		if opt.ListVar[x].Code == code {
+			return &opt.ListVar[x]
+		}
+	}
+	return nil
+}
+
+// remove all options with the code.
+func (opt *RDataOPT) remove(code uint16) {
+	var (
+		list = opt.ListVar[:0]
+		x    int
+	)
+	for x = range len(opt.ListVar) {
+		if opt.ListVar[x].Code != code {
+			list = append(list, opt.ListVar[x])
+		}
+	}
+	opt.ListVar = list
+}
+
+// set replace the option data with the same code, or add new option if
+// its not exist.
+func (opt *RDataOPT) set(code uint16, data []byte) {
+	var optvar = opt.get(code)
+	if optvar != nil {
+		optvar.Data = data
+		return
+	}
+	opt.ListVar = append(opt.ListVar, RDataOPTVar{
+		Code: code,
+		Data: data,
+	})
+}
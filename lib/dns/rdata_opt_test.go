@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"net"
+	"testing"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestRDataOPT_options(t *testing.T) {
+	var (
+		ecs = NewEDNSClientSubnet(net.ParseIP(`192.0.2.77`), 22)
+
+		cookie = &EDNSCookie{
+			Client: []byte(`12345678`),
+			Server: []byte(`0123456789abcdef`),
+		}
+		ede = EDNSExtendedError{
+			InfoCode:  EDEBlocked,
+			ExtraText: `blocked`,
+		}
+		msg = &Message{
+			Header: MessageHeader{
+				ID:      1,
+				QDCount: 1,
+			},
+			Question: MessageQuestion{
+				Name:  `kilabit.info`,
+				Type:  RecordTypeA,
+				Class: RecordClassIN,
+			},
+		}
+		opt = msg.opt(true)
+		err error
+	)
+
+	test.Assert(t, `ECS address`, `192.0.0.0`, ecs.Address.String())
+
+	opt.SetClientSubnet(ecs)
+	opt.SetCookie(cookie)
+	opt.AddExtendedError(ede)
+	opt.AddExtendedError(EDNSExtendedError{InfoCode: EDEStaleAnswer})
+	opt.SetPadding(10)
+
+	_, err = msg.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got *Message
+
+	got, err = UnpackMessage(msg.packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		gotOPT    = got.opt(false)
+		gotECS    *EDNSClientSubnet
+		gotCookie *EDNSCookie
+		gotEDE    []EDNSExtendedError
+	)
+
+	gotECS, err = gotOPT.ClientSubnet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `ClientSubnet`, ecs, gotECS)
+
+	gotCookie, err = gotOPT.Cookie()
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `Cookie`, cookie, gotCookie)
+
+	gotEDE, err = gotOPT.ExtendedErrors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expEDE = []EDNSExtendedError{ede, {InfoCode: EDEStaleAnswer}}
+	test.Assert(t, `ExtendedErrors`, expEDE, gotEDE)
+
+	test.Assert(t, `Padding`, make([]byte, 10),
+		gotOPT.get(OptionCodePadding).Data)
+
+	gotOPT.SetClientSubnet(nil)
+	gotOPT.SetCookie(nil)
+	gotOPT.SetPadding(-1)
+	test.Assert(t, `ListVar after removed`, 2, len(gotOPT.ListVar))
+}
+
+func TestEDNSClientSubnet_unpack(t *testing.T) {
+	type testCase struct {
+		desc     string
+		expError string
+		data     []byte
+	}
+
+	var listCase = []testCase{{
+		desc:     `With short data`,
+		data:     []byte{0, 1, 24},
+		expError: `ECS: data too short`,
+	}, {
+		desc:     `With unknown family`,
+		data:     []byte{0, 3, 0, 0},
+		expError: `ECS: unknown family 3`,
+	}, {
+		desc:     `With invalid prefix`,
+		data:     []byte{0, 1, 33, 0},
+		expError: `ECS: invalid prefix length 33/0`,
+	}, {
+		desc:     `With invalid address length`,
+		data:     []byte{0, 1, 24, 0, 192, 0},
+		expError: `ECS: invalid address length 2`,
+	}}
+
+	var (
+		c   testCase
+		ecs EDNSClientSubnet
+		err error
+	)
+	for _, c = range listCase {
+		err = ecs.unpack(c.data)
+		test.Assert(t, c.desc, c.expError, err.Error())
+	}
+}
@@ -0,0 +1,247 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// rrsigTimeLayout define the time format of RRSIG Signature Expiration and
+// Inception in zone file, YYYYMMDDHHmmSS in UTC.
+const rrsigTimeLayout = `20060102150405`
+
+// RDataRRSIG the resource record for type 46 [RRSIG RR].
+// The RRSIG record contains the signature of RRset with the same owner
+// name, class, and type.
+// Format of RRSIG RDATA,
+//
+//	+--------------+-----------+--------+
+//	| Type Covered | Algorithm | Labels | 2, 1, and 1 octets.
+//	+--------------+-----------+--------+
+//	| Original TTL                      | 4 octets.
+//	+-----------------------------------+
+//	| Signature Expiration              | 4 octets.
+//	+-----------------------------------+
+//	| Signature Inception               | 4 octets.
+//	+---------+-------------------------+
+//	| Key Tag |                         / 2 octets.
+//	+---------+     Signer's Name       /
+//	/                                   /
+//	+-----------------------------------+
+//	/             Signature             /
+//	/                                   /
+//	+-----------------------------------+
+//
+// The Signer's Name is not compressed.
+//
+// In zone file, the Signature Expiration and Inception is represented in
+// the form of YYYYMMDDHHmmSS in UTC, and the Signature is represented as
+// Base64 encoding,
+//
+//	host.example.com. 86400 IN RRSIG A 5 3 86400 20030322173103 (
+//	                                 20030220173103 2642 example.com.
+//	                                 oJB1W6WNGv+ldvQ3WDG0MQkg5IEhjRip8WTr
+//	                                 ... )
+//
+// [RRSIG RR]: https://datatracker.ietf.org/doc/html/rfc4034#section-3
+type RDataRRSIG struct {
+	// SignerName contains the owner name of DNSKEY that validate this
+	// signature.
+	SignerName string
+
+	// Signature contains the raw, decoded, cryptographic signature.
+	Signature []byte
+
+	// OrigTTL contains the TTL of the covered RRset as it appears in
+	// the authoritative zone.
+	OrigTTL uint32
+
+	// Expiration contains the time, in seconds since epoch, after which
+	// the signature is no longer valid.
+	Expiration uint32
+
+	// Inception contains the time, in seconds since epoch, before which
+	// the signature is not valid yet.
+	Inception uint32
+
+	// KeyTag contains the key tag of DNSKEY that validate this
+	// signature.
+	KeyTag uint16
+
+	// TypeCovered contains the type of RRset that is covered by this
+	// signature.
+	TypeCovered RecordType
+
+	// Algorithm contains the cryptographic algorithm used to create the
+	// signature.
+	Algorithm byte
+
+	// Labels contains the number of labels in the original RRSIG owner
+	// name, excluding the root label and the wildcard label.
+	Labels byte
+}
+
+// WriteTo write the RRSIG record as zone format to out.
+func (rrsig *RDataRRSIG) WriteTo(out io.Writer) (_ int64, err error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "RRSIG %s %d %d %d %s %s %d %s %s\n",
+		recordTypeName(rrsig.TypeCovered), rrsig.Algorithm,
+		rrsig.Labels, rrsig.OrigTTL,
+		rrsigFormatTime(rrsig.Expiration),
+		rrsigFormatTime(rrsig.Inception),
+		rrsig.KeyTag, toDomainAbsolute(rrsig.SignerName),
+		base64.StdEncoding.EncodeToString(rrsig.Signature))
+
+	var n int
+
+	n, err = out.Write(buf.Bytes())
+
+	return int64(n), err
+}
+
+func (rrsig *RDataRRSIG) pack(msg *Message) (n int) {
+	n = len(msg.packet)
+	msg.packet = append(msg.packet, rrsig.rdataNoSignature()...)
+	msg.packet = append(msg.packet, rrsig.Signature...)
+	return len(msg.packet) - n
+}
+
+// parse the RRSIG RDATA from list of zone tokens.
+func (rrsig *RDataRRSIG) parse(zp *zoneParser, fields [][]byte) (err error) {
+	var logp = `parse`
+
+	if len(fields) < 9 {
+		return fmt.Errorf(`%s: incomplete RRSIG RDATA`, logp)
+	}
+
+	rrsig.TypeCovered, err = recordTypeParse(string(fields[0]))
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Type Covered: %w`, logp, err)
+	}
+
+	var v uint64
+
+	v, err = strconv.ParseUint(string(fields[1]), 10, 8)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Algorithm %q`, logp, fields[1])
+	}
+	rrsig.Algorithm = byte(v)
+
+	v, err = strconv.ParseUint(string(fields[2]), 10, 8)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Labels %q`, logp, fields[2])
+	}
+	rrsig.Labels = byte(v)
+
+	v, err = strconv.ParseUint(string(fields[3]), 10, 32)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Original TTL %q`, logp, fields[3])
+	}
+	rrsig.OrigTTL = uint32(v)
+
+	rrsig.Expiration, err = rrsigParseTime(string(fields[4]))
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Signature Expiration: %w`, logp, err)
+	}
+
+	rrsig.Inception, err = rrsigParseTime(string(fields[5]))
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Signature Inception: %w`, logp, err)
+	}
+
+	v, err = strconv.ParseUint(string(fields[6]), 10, 16)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Key Tag %q`, logp, fields[6])
+	}
+	rrsig.KeyTag = uint16(v)
+
+	rrsig.SignerName = zp.generateDomainName(fields[7])
+
+	rrsig.Signature, err = base64.StdEncoding.DecodeString(string(bytes.Join(fields[8:], nil)))
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Signature: %w`, logp, err)
+	}
+	return nil
+}
+
+// rdataNoSignature return the RRSIG RDATA in wire format without the
+// Signature field, with Signer's Name in canonical form.
+// The result is the prefix of data to be signed, as described in
+// RFC 4034 section 3.1.8.1.
+func (rrsig *RDataRRSIG) rdataNoSignature() (rdata []byte) {
+	rdata = binary.BigEndian.AppendUint16(rdata, uint16(rrsig.TypeCovered))
+	rdata = append(rdata, rrsig.Algorithm, rrsig.Labels)
+	rdata = binary.BigEndian.AppendUint32(rdata, rrsig.OrigTTL)
+	rdata = binary.BigEndian.AppendUint32(rdata, rrsig.Expiration)
+	rdata = binary.BigEndian.AppendUint32(rdata, rrsig.Inception)
+	rdata = binary.BigEndian.AppendUint16(rdata, rrsig.KeyTag)
+	rdata = append(rdata, packDomainNameCanonical(rrsig.SignerName)...)
+	return rdata
+}
+
+func (rrsig *RDataRRSIG) unpack(packet []byte, start, end uint) (err error) {
+	var logp = `unpack`
+
+	if end-start < 18 {
+		return fmt.Errorf(`%s: invalid RRSIG length %d`, logp, end-start)
+	}
+
+	var rdata = packet[start:end]
+
+	rrsig.TypeCovered = RecordType(binary.BigEndian.Uint16(rdata))
+	rrsig.Algorithm = rdata[2]
+	rrsig.Labels = rdata[3]
+	rrsig.OrigTTL = binary.BigEndian.Uint32(rdata[4:])
+	rrsig.Expiration = binary.BigEndian.Uint32(rdata[8:])
+	rrsig.Inception = binary.BigEndian.Uint32(rdata[12:])
+	rrsig.KeyTag = binary.BigEndian.Uint16(rdata[16:])
+
+	var x uint
+
+	rrsig.SignerName, x, err = unpackDomainName(packet, start+18)
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+	if x > end {
+		return fmt.Errorf(`%s: Signer's Name overflow`, logp)
+	}
+
+	rrsig.Signature = bytes.Clone(packet[x:end])
+
+	return nil
+}
+
+// rrsigFormatTime format the signature time in YYYYMMDDHHmmSS.
+func rrsigFormatTime(epoch uint32) string {
+	return time.Unix(int64(epoch), 0).UTC().Format(rrsigTimeLayout)
+}
+
+// rrsigParseTime parse the signature time in zone file, either in the form
+// of YYYYMMDDHHmmSS or as decimal number of seconds since epoch.
+func rrsigParseTime(v string) (epoch uint32, err error) {
+	if len(v) == len(rrsigTimeLayout) {
+		var t time.Time
+
+		t, err = time.Parse(rrsigTimeLayout, v)
+		if err != nil {
+			return 0, fmt.Errorf(`invalid time %q`, v)
+		}
+		return uint32(t.Unix()), nil
+	}
+
+	var u64 uint64
+
+	u64, err = strconv.ParseUint(v, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf(`invalid time %q`, v)
+	}
+	return uint32(u64), nil
+}
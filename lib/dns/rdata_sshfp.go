@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// RDataSSHFP the resource record for type 44 [SSHFP RR].
+// The SSHFP record contains the fingerprint of SSH host key, to verify
+// the host key when connecting to the host.
+// Format of SSHFP RDATA,
+//
+//	+-----------+---------+
+//	| Algorithm | FP Type | 1 and 1 octets.
+//	+-----------+---------+
+//	/ Fingerprint         /
+//	+---------------------+
+//
+// In zone file, the Fingerprint is represented as hexadecimal digits,
+//
+//	host.example. SSHFP 2 1 123456789abcdef67890123456789abcdef67890
+//
+// [SSHFP RR]: https://datatracker.ietf.org/doc/html/rfc4255#section-3.1
+type RDataSSHFP struct {
+	// Fingerprint contains the fingerprint of the host key.
+	Fingerprint []byte
+
+	// Algorithm contains the algorithm of host key, for example 1 for
+	// RSA, 3 for ECDSA, and 4 for Ed25519.
+	Algorithm byte
+
+	// FPType contains the algorithm used to generate the Fingerprint,
+	// 1 for SHA-1 and 2 for SHA-256.
+	FPType byte
+}
+
+// WriteTo write the SSHFP record as zone format to out.
+func (sshfp *RDataSSHFP) WriteTo(out io.Writer) (_ int64, err error) {
+	var n int
+
+	n, err = fmt.Fprintf(out, "SSHFP %d %d %s\n", sshfp.Algorithm,
+		sshfp.FPType, strings.ToUpper(hex.EncodeToString(sshfp.Fingerprint)))
+
+	return int64(n), err
+}
+
+func (sshfp *RDataSSHFP) pack(msg *Message) (n int) {
+	n = len(msg.packet)
+	msg.packet = append(msg.packet, sshfp.Algorithm, sshfp.FPType)
+	msg.packet = append(msg.packet, sshfp.Fingerprint...)
+	return len(msg.packet) - n
+}
+
+// parse the SSHFP RDATA from list of zone tokens.
+func (sshfp *RDataSSHFP) parse(fields [][]byte) (err error) {
+	var logp = `parse`
+
+	if len(fields) < 3 {
+		return fmt.Errorf(`%s: incomplete SSHFP RDATA`, logp)
+	}
+
+	var v uint64
+
+	v, err = strconv.ParseUint(string(fields[0]), 10, 8)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Algorithm %q`, logp, fields[0])
+	}
+	sshfp.Algorithm = byte(v)
+
+	v, err = strconv.ParseUint(string(fields[1]), 10, 8)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Fingerprint Type %q`, logp, fields[1])
+	}
+	sshfp.FPType = byte(v)
+
+	sshfp.Fingerprint, err = hex.DecodeString(string(bytes.Join(fields[2:], nil)))
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Fingerprint: %w`, logp, err)
+	}
+	return nil
+}
+
+func (sshfp *RDataSSHFP) unpack(rdata []byte) (err error) {
+	if len(rdata) < 2 {
+		return fmt.Errorf(`invalid SSHFP length %d`, len(rdata))
+	}
+	sshfp.Algorithm = rdata[0]
+	sshfp.FPType = rdata[1]
+	sshfp.Fingerprint = bytes.Clone(rdata[2:])
+	return nil
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// RDataTLSA the resource record for type 52 [TLSA RR].
+// The TLSA record associate the TLS server certificate or public key with
+// the domain name where the record is found, also known as DANE.
+// Format of TLSA RDATA,
+//
+//	+-------------+----------+---------------+
+//	| Cert. Usage | Selector | Matching Type | 1, 1, and 1 octets.
+//	+-------------+----------+---------------+
+//	/ Certificate Association Data           /
+//	+----------------------------------------+
+//
+// In zone file, the Certificate Association Data is represented as
+// hexadecimal digits,
+//
+//	_443._tcp.www.example.com. TLSA 0 0 1 ( d2abde240d7cd3ee6b4b28c54df034b9
+//	                                       7983a1d16e8a410e4561cb106618e971 )
+//
+// [TLSA RR]: https://datatracker.ietf.org/doc/html/rfc6698#section-2.1
+type RDataTLSA struct {
+	// CertData contains the Certificate Association Data, the raw
+	// certificate, public key, or its hash, depends on Selector and
+	// MatchingType.
+	CertData []byte
+
+	// Usage contains the provided association that will be used to
+	// match the certificate presented in the TLS handshake.
+	Usage byte
+
+	// Selector specifies which part of the TLS certificate presented
+	// by the server will be matched, 0 for full certificate and 1 for
+	// SubjectPublicKeyInfo.
+	Selector byte
+
+	// MatchingType specifies how the certificate association is
+	// presented, 0 for exact match, 1 for SHA-256, and 2 for SHA-512.
+	MatchingType byte
+}
+
+// WriteTo write the TLSA record as zone format to out.
+func (tlsa *RDataTLSA) WriteTo(out io.Writer) (_ int64, err error) {
+	var n int
+
+	n, err = fmt.Fprintf(out, "TLSA %d %d %d %s\n", tlsa.Usage,
+		tlsa.Selector, tlsa.MatchingType,
+		strings.ToUpper(hex.EncodeToString(tlsa.CertData)))
+
+	return int64(n), err
+}
+
+func (tlsa *RDataTLSA) pack(msg *Message) (n int) {
+	n = len(msg.packet)
+	msg.packet = append(msg.packet, tlsa.Usage, tlsa.Selector, tlsa.MatchingType)
+	msg.packet = append(msg.packet, tlsa.CertData...)
+	return len(msg.packet) - n
+}
+
+// parse the TLSA RDATA from list of zone tokens.
+func (tlsa *RDataTLSA) parse(fields [][]byte) (err error) {
+	var logp = `parse`
+
+	if len(fields) < 4 {
+		return fmt.Errorf(`%s: incomplete TLSA RDATA`, logp)
+	}
+
+	var v uint64
+
+	v, err = strconv.ParseUint(string(fields[0]), 10, 8)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Certificate Usage %q`, logp, fields[0])
+	}
+	tlsa.Usage = byte(v)
+
+	v, err = strconv.ParseUint(string(fields[1]), 10, 8)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Selector %q`, logp, fields[1])
+	}
+	tlsa.Selector = byte(v)
+
+	v, err = strconv.ParseUint(string(fields[2]), 10, 8)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Matching Type %q`, logp, fields[2])
+	}
+	tlsa.MatchingType = byte(v)
+
+	tlsa.CertData, err = hex.DecodeString(string(bytes.Join(fields[3:], nil)))
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Certificate Association Data: %w`, logp, err)
+	}
+	return nil
+}
+
+func (tlsa *RDataTLSA) unpack(rdata []byte) (err error) {
+	if len(rdata) < 3 {
+		return fmt.Errorf(`invalid TLSA length %d`, len(rdata))
+	}
+	tlsa.Usage = rdata[0]
+	tlsa.Selector = rdata[1]
+	tlsa.MatchingType = rdata[2]
+	tlsa.CertData = bytes.Clone(rdata[3:])
+	return nil
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// RDataTSIG the resource record for type 250 [TSIG RR].
+// The TSIG record is a meta-RR that is added as the last record in the
+// additional section of message, to authenticate the message using shared
+// secret key.
+// Format of TSIG RDATA,
+//
+//	+-----------------------------+
+//	/ Algorithm Name              /
+//	+-----------------------------+
+//	| Time Signed                 | 6 octets.
+//	+--------------+--------------+
+//	| Fudge        | MAC Size     | 2 and 2 octets.
+//	+--------------+--------------+
+//	/ MAC                         /
+//	+--------------+--------------+
+//	| Original ID  | Error        | 2 and 2 octets.
+//	+--------------+--------------+
+//	| Other Len    |              | 2 octets.
+//	+--------------+              |
+//	/ Other Data                  /
+//	+-----------------------------+
+//
+// [TSIG RR]: https://datatracker.ietf.org/doc/html/rfc8945#section-4.2
+type RDataTSIG struct {
+	// Algorithm contains the name of algorithm used to generate the
+	// MAC, for example "hmac-sha256".
+	Algorithm string
+
+	// MAC contains the message authentication code.
+	MAC []byte
+
+	// OtherData contains the server time when the Error is
+	// TSIGErrBadTime, otherwise its empty.
+	OtherData []byte
+
+	// TimeSigned contains the time when the message signed, in seconds
+	// since epoch.
+	// Only the lower 48 bits is used.
+	TimeSigned uint64
+
+	// Fudge contains the number of seconds of error permitted in
+	// TimeSigned.
+	Fudge uint16
+
+	// OriginalID contains the ID of message when its signed.
+	OriginalID uint16
+
+	// Error contains the extended response code of TSIG.
+	Error uint16
+}
+
+func (tsig *RDataTSIG) pack(msg *Message) (n int) {
+	n = len(msg.packet)
+	msg.packDomainName([]byte(tsig.Algorithm), false)
+	msg.packet = tsig.packTimers(msg.packet)
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, uint16(len(tsig.MAC)))
+	msg.packet = append(msg.packet, tsig.MAC...)
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, tsig.OriginalID)
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, tsig.Error)
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, uint16(len(tsig.OtherData)))
+	msg.packet = append(msg.packet, tsig.OtherData...)
+	return len(msg.packet) - n
+}
+
+// packTimers append the Time Signed and Fudge into out.
+func (tsig *RDataTSIG) packTimers(out []byte) []byte {
+	out = binary.BigEndian.AppendUint16(out, uint16(tsig.TimeSigned>>32))
+	out = binary.BigEndian.AppendUint32(out, uint32(tsig.TimeSigned))
+	out = binary.BigEndian.AppendUint16(out, tsig.Fudge)
+	return out
+}
+
+func (tsig *RDataTSIG) unpack(packet []byte, start, end uint) (err error) {
+	var logp = `unpack`
+
+	tsig.Algorithm, start, err = unpackDomainName(packet, start)
+	if err != nil {
+		return fmt.Errorf(`%s: TSIG: %w`, logp, err)
+	}
+
+	var rdata = packet[start:end]
+	if len(rdata) < 16 {
+		return fmt.Errorf(`%s: TSIG: invalid length %d`, logp, len(rdata))
+	}
+
+	tsig.TimeSigned = uint64(binary.BigEndian.Uint16(rdata))<<32 |
+		uint64(binary.BigEndian.Uint32(rdata[2:]))
+	tsig.Fudge = binary.BigEndian.Uint16(rdata[6:])
+
+	var size = int(binary.BigEndian.Uint16(rdata[8:]))
+	rdata = rdata[10:]
+	if len(rdata) < size+6 {
+		return fmt.Errorf(`%s: TSIG: invalid MAC size %d`, logp, size)
+	}
+	tsig.MAC = bytes.Clone(rdata[:size])
+	rdata = rdata[size:]
+
+	tsig.OriginalID = binary.BigEndian.Uint16(rdata)
+	tsig.Error = binary.BigEndian.Uint16(rdata[2:])
+
+	size = int(binary.BigEndian.Uint16(rdata[4:]))
+	rdata = rdata[6:]
+	if len(rdata) < size {
+		return fmt.Errorf(`%s: TSIG: invalid other length %d`, logp, size)
+	}
+	if size > 0 {
+		tsig.OtherData = bytes.Clone(rdata[:size])
+	}
+	return nil
+}
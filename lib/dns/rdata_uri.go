@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// RDataURI the resource record for type 256 [URI RR].
+// The URI record publish the mapping from the service name into URI.
+// Format of URI RDATA,
+//
+//	+-------------+-------------+
+//	| Priority    | Weight      | 2 and 2 octets.
+//	+-------------+-------------+
+//	/ Target                    /
+//	+---------------------------+
+//
+// In zone file, the Target is represented as quoted string,
+//
+//	_ftp._tcp.example.com. URI 10 1 "ftp://ftp1.example.com/public"
+//
+// [URI RR]: https://datatracker.ietf.org/doc/html/rfc7553#section-4.5
+type RDataURI struct {
+	// Target contains the URI, as specified in RFC 3986.
+	Target string
+
+	// Priority contains the priority of Target, lower value means
+	// more preferred.
+	Priority uint16
+
+	// Weight contains the relative weight for Target with the same
+	// Priority.
+	Weight uint16
+}
+
+// WriteTo write the URI record as zone format to out.
+func (uri *RDataURI) WriteTo(out io.Writer) (_ int64, err error) {
+	var n int
+
+	n, err = fmt.Fprintf(out, "URI %d %d %q\n", uri.Priority, uri.Weight,
+		uri.Target)
+
+	return int64(n), err
+}
+
+func (uri *RDataURI) pack(msg *Message) (n int) {
+	n = len(msg.packet)
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, uri.Priority)
+	msg.packet = binary.BigEndian.AppendUint16(msg.packet, uri.Weight)
+	msg.packet = append(msg.packet, uri.Target...)
+	return len(msg.packet) - n
+}
+
+// parse the URI RDATA from list of zone tokens.
+func (uri *RDataURI) parse(fields [][]byte) (err error) {
+	var logp = `parse`
+
+	if len(fields) != 3 {
+		return fmt.Errorf(`%s: invalid URI RDATA, expecting 3 fields got %d`,
+			logp, len(fields))
+	}
+
+	var v uint64
+
+	v, err = strconv.ParseUint(string(fields[0]), 10, 16)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Priority %q`, logp, fields[0])
+	}
+	uri.Priority = uint16(v)
+
+	v, err = strconv.ParseUint(string(fields[1]), 10, 16)
+	if err != nil {
+		return fmt.Errorf(`%s: invalid Weight %q`, logp, fields[1])
+	}
+	uri.Weight = uint16(v)
+
+	uri.Target = string(unquote(fields[2]))
+	if len(uri.Target) == 0 {
+		return fmt.Errorf(`%s: empty URI Target`, logp)
+	}
+	return nil
+}
+
+func (uri *RDataURI) unpack(rdata []byte) (err error) {
+	if len(rdata) < 5 {
+		return fmt.Errorf(`invalid URI length %d`, len(rdata))
+	}
+	uri.Priority = binary.BigEndian.Uint16(rdata)
+	uri.Weight = binary.BigEndian.Uint16(rdata[2:])
+	uri.Target = string(rdata[4:])
+	return nil
+}
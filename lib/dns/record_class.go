@@ -13,7 +13,8 @@ const (
 	RecordClassCH                      // The CHAOS class
 	RecordClassHS                      // Hesiod [Dyer 87]
 
-	RecordClassANY RecordClass = 255 // Any class
+	RecordClassNONE RecordClass = 254 // None class, RFC 2136.
+	RecordClassANY  RecordClass = 255 // Any class
 )
 
 // RecordClasses contains a mapping between string representation of record
@@ -3,7 +3,12 @@
 
 package dns
 
-import "net"
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
 
 // RecordType A two octet code which specifies the type of the record.
 type RecordType uint16
@@ -28,77 +33,120 @@ const (
 	RecordTypeMX                      // 15 - Mail exchange
 	RecordTypeTXT                     // 16 - Text strings
 
-	RecordTypeAAAA RecordType = 28 // IPv6 address
-	RecordTypeSRV  RecordType = 33 // A SRV RR for locating service.
-	RecordTypeOPT  RecordType = 41 // An OPT pseudo-RR (sometimes called a meta-RR)
+	RecordTypeAAAA       RecordType = 28 // IPv6 address
+	RecordTypeSRV        RecordType = 33 // A SRV RR for locating service.
+	RecordTypeNAPTR      RecordType = 35 // Naming Authority Pointer, RFC 3403.
+	RecordTypeDNAME      RecordType = 39 // Delegation name, RFC 6672.
+	RecordTypeOPT        RecordType = 41 // An OPT pseudo-RR (sometimes called a meta-RR)
+	RecordTypeDS         RecordType = 43 // Delegation Signer, RFC 4034.
+	RecordTypeSSHFP      RecordType = 44 // SSH key fingerprint, RFC 4255.
+	RecordTypeRRSIG      RecordType = 46 // Resource Record Signature, RFC 4034.
+	RecordTypeNSEC       RecordType = 47 // Next Secure, RFC 4034.
+	RecordTypeDNSKEY     RecordType = 48 // DNS public key, RFC 4034.
+	RecordTypeNSEC3      RecordType = 50 // Hashed Next Secure, RFC 5155.
+	RecordTypeNSEC3PARAM RecordType = 51 // NSEC3 parameters, RFC 5155.
+	RecordTypeTLSA       RecordType = 52 // TLSA certificate association, RFC 6698.
 
 	RecordTypeSVCB  RecordType = 64 // RFC 9460.
 	RecordTypeHTTPS RecordType = 65 // RFC 9460.
 
+	RecordTypeTSIG  RecordType = 250 // Transaction signature, RFC 8945.
+	RecordTypeIXFR  RecordType = 251 // A request for incremental transfer of a zone, RFC 1995.
 	RecordTypeAXFR  RecordType = 252 // A request for a transfer of an entire zone
 	RecordTypeMAILB RecordType = 253 // A request for mailbox-related records (MB, MG or MR)
 	RecordTypeMAILA RecordType = 254 // A request for mail agent RRs (Obsolete - see MX)
 	RecordTypeANY   RecordType = 255 // A request for all records.
+
+	RecordTypeURI RecordType = 256 // Uniform Resource Identifier, RFC 7553.
+	RecordTypeCAA RecordType = 257 // Certification Authority Authorization, RFC 8659.
 )
 
 // RecordTypes contains a mapping between string representation of DNS record
 // type with their numeric value, ordered by key alphabetically.
 var RecordTypes = map[string]RecordType{
-	"A":     RecordTypeA,
-	"AAAA":  RecordTypeAAAA,
-	`ANY`:   RecordTypeANY,
-	"AXFR":  RecordTypeAXFR,
-	"CNAME": RecordTypeCNAME,
-	"HINFO": RecordTypeHINFO,
-	`HTTPS`: RecordTypeHTTPS,
-	"MAILA": RecordTypeMAILA,
-	"MAILB": RecordTypeMAILB,
-	"MB":    RecordTypeMB,
-	"MD":    RecordTypeMD,
-	"MF":    RecordTypeMF,
-	"MG":    RecordTypeMG,
-	"MINFO": RecordTypeMINFO,
-	"MR":    RecordTypeMR,
-	"MX":    RecordTypeMX,
-	"NS":    RecordTypeNS,
-	"NULL":  RecordTypeNULL,
-	"OPT":   RecordTypeOPT,
-	"PTR":   RecordTypePTR,
-	"SOA":   RecordTypeSOA,
-	`SVCB`:  RecordTypeSVCB,
-	"SRV":   RecordTypeSRV,
-	"TXT":   RecordTypeTXT,
-	"WKS":   RecordTypeWKS,
+	"A":          RecordTypeA,
+	"AAAA":       RecordTypeAAAA,
+	`ANY`:        RecordTypeANY,
+	"AXFR":       RecordTypeAXFR,
+	`CAA`:        RecordTypeCAA,
+	"CNAME":      RecordTypeCNAME,
+	`DNAME`:      RecordTypeDNAME,
+	`DNSKEY`:     RecordTypeDNSKEY,
+	`DS`:         RecordTypeDS,
+	"HINFO":      RecordTypeHINFO,
+	`HTTPS`:      RecordTypeHTTPS,
+	`IXFR`:       RecordTypeIXFR,
+	"MAILA":      RecordTypeMAILA,
+	"MAILB":      RecordTypeMAILB,
+	"MB":         RecordTypeMB,
+	"MD":         RecordTypeMD,
+	"MF":         RecordTypeMF,
+	"MG":         RecordTypeMG,
+	"MINFO":      RecordTypeMINFO,
+	"MR":         RecordTypeMR,
+	"MX":         RecordTypeMX,
+	`NAPTR`:      RecordTypeNAPTR,
+	"NS":         RecordTypeNS,
+	`NSEC`:       RecordTypeNSEC,
+	`NSEC3`:      RecordTypeNSEC3,
+	`NSEC3PARAM`: RecordTypeNSEC3PARAM,
+	"NULL":       RecordTypeNULL,
+	"OPT":        RecordTypeOPT,
+	"PTR":        RecordTypePTR,
+	`RRSIG`:      RecordTypeRRSIG,
+	"SOA":        RecordTypeSOA,
+	`SSHFP`:      RecordTypeSSHFP,
+	`SVCB`:       RecordTypeSVCB,
+	"SRV":        RecordTypeSRV,
+	`TLSA`:       RecordTypeTLSA,
+	`TSIG`:       RecordTypeTSIG,
+	"TXT":        RecordTypeTXT,
+	`URI`:        RecordTypeURI,
+	"WKS":        RecordTypeWKS,
 }
 
 // RecordTypeNames contains mapping between record type and and their string
 // representation, ordered alphabetically.
 var RecordTypeNames = map[RecordType]string{
-	RecordTypeA:     "A",
-	RecordTypeAAAA:  "AAAA",
-	RecordTypeANY:   `ANY`,
-	RecordTypeAXFR:  "AXFR",
-	RecordTypeCNAME: "CNAME",
-	RecordTypeHINFO: "HINFO",
-	RecordTypeHTTPS: `HTTPS`,
-	RecordTypeMAILA: "MAILA",
-	RecordTypeMAILB: "MAILB",
-	RecordTypeMB:    "MB",
-	RecordTypeMD:    "MD",
-	RecordTypeMF:    "MF",
-	RecordTypeMG:    "MG",
-	RecordTypeMINFO: "MINFO",
-	RecordTypeMR:    "MR",
-	RecordTypeMX:    "MX",
-	RecordTypeNS:    "NS",
-	RecordTypeNULL:  "NULL",
-	RecordTypeOPT:   "OPT",
-	RecordTypePTR:   "PTR",
-	RecordTypeSOA:   "SOA",
-	RecordTypeSVCB:  `SVCB`,
-	RecordTypeSRV:   "SRV",
-	RecordTypeTXT:   "TXT",
-	RecordTypeWKS:   "WKS",
+	RecordTypeA:          "A",
+	RecordTypeAAAA:       "AAAA",
+	RecordTypeANY:        `ANY`,
+	RecordTypeAXFR:       "AXFR",
+	RecordTypeCAA:        `CAA`,
+	RecordTypeCNAME:      "CNAME",
+	RecordTypeDNAME:      `DNAME`,
+	RecordTypeDNSKEY:     `DNSKEY`,
+	RecordTypeDS:         `DS`,
+	RecordTypeHINFO:      "HINFO",
+	RecordTypeHTTPS:      `HTTPS`,
+	RecordTypeIXFR:       `IXFR`,
+	RecordTypeMAILA:      "MAILA",
+	RecordTypeMAILB:      "MAILB",
+	RecordTypeMB:         "MB",
+	RecordTypeMD:         "MD",
+	RecordTypeMF:         "MF",
+	RecordTypeMG:         "MG",
+	RecordTypeMINFO:      "MINFO",
+	RecordTypeMR:         "MR",
+	RecordTypeMX:         "MX",
+	RecordTypeNAPTR:      `NAPTR`,
+	RecordTypeNS:         "NS",
+	RecordTypeNSEC:       `NSEC`,
+	RecordTypeNSEC3:      `NSEC3`,
+	RecordTypeNSEC3PARAM: `NSEC3PARAM`,
+	RecordTypeNULL:       "NULL",
+	RecordTypeOPT:        "OPT",
+	RecordTypePTR:        "PTR",
+	RecordTypeRRSIG:      `RRSIG`,
+	RecordTypeSOA:        "SOA",
+	RecordTypeSSHFP:      `SSHFP`,
+	RecordTypeSVCB:       `SVCB`,
+	RecordTypeSRV:        "SRV",
+	RecordTypeTLSA:       `TLSA`,
+	RecordTypeTSIG:       `TSIG`,
+	RecordTypeTXT:        "TXT",
+	RecordTypeURI:        `URI`,
+	RecordTypeWKS:        "WKS",
 }
 
 // RecordTypeFromAddress return RecordTypeA or RecordTypeAAAA if addr is valid
@@ -123,3 +171,41 @@ func RecordTypeFromAddress(addr []byte) (rtype RecordType) {
 	}
 	return 0
 }
+
+// recordTypeName return the mnemonic of record type, or "TYPEnnn" as
+// defined in RFC 3597 if the type is unknown.
+func recordTypeName(rtype RecordType) string {
+	var (
+		name string
+		ok   bool
+	)
+	name, ok = RecordTypeNames[rtype]
+	if ok {
+		return name
+	}
+	return `TYPE` + strconv.FormatUint(uint64(rtype), 10)
+}
+
+// recordTypeParse parse the record type mnemonic or the generic "TYPEnnn"
+// format into RecordType.
+func recordTypeParse(name string) (rtype RecordType, err error) {
+	var ok bool
+
+	name = strings.ToUpper(name)
+
+	rtype, ok = RecordTypes[name]
+	if ok {
+		return rtype, nil
+	}
+	if !strings.HasPrefix(name, `TYPE`) {
+		return 0, fmt.Errorf(`unknown record type %q`, name)
+	}
+
+	var v uint64
+
+	v, err = strconv.ParseUint(name[4:], 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf(`unknown record type %q`, name)
+	}
+	return RecordType(v), nil
+}
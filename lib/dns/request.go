@@ -7,6 +7,10 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
+	"net/netip"
+	"slices"
+	"sync/atomic"
 	"time"
 )
 
@@ -39,9 +43,50 @@ type request struct {
 	// startAt set the start time the request received by server.
 	startAt time.Time
 
+	// opt contains the OPT record from query, or nil if the query does
+	// not have it.
+	opt *RDataOPT
+
+	// cookie contains the client and server cookie for response.
+	cookie *EDNSCookie
+
+	// ede contains the extended error for response.
+	ede *EDNSExtendedError
+
+	// rl contains the rate limiter for response, only set on UDP
+	// if the response rate limiting is enabled.
+	rl *rateLimiter
+
+	// ql contains the query logger, or nil if its disabled.
+	ql *queryLogger
+
+	// view contains the view that match with the client address, or
+	// nil if the request is answered using the server caches.
+	view *view
+
+	// race is not nil if the request is forwarded to multiple
+	// forwarders at the same time.
+	race *requestRace
+
 	// kind define the connection type that this request is belong to:
 	// DOH, DOT, TCP, or UDP.
 	kind string
+
+	// extRCode contains the upper 8 bits of extended response code.
+	extRCode byte
+
+	// cacheHit is true if the request is answered from caches.
+	cacheHit bool
+}
+
+// requestRace contains the state of request that is forwarded to
+// multiple forwarders at the same time.
+type requestRace struct {
+	// pending is the number of forwarders that has not failed.
+	pending atomic.Int32
+
+	// done is true if the request has been answered.
+	done atomic.Bool
 }
 
 // newRequest create and initialize request.
@@ -59,15 +104,238 @@ func (req *request) String() string {
 		RecordTypeNames[req.message.Question.Type])
 }
 
+// remoteIP return the IP address of client that send the request.
+// It will return nil if the address is unknown.
+func (req *request) remoteIP() net.IP {
+	switch w := req.writer.(type) {
+	case *DoHClient:
+		if w.clientAddr.IsValid() {
+			return net.IP(w.clientAddr.Addr().Unmap().AsSlice())
+		}
+	case *TCPClient:
+		var addr, _ = w.conn.RemoteAddr().(*net.TCPAddr)
+		if addr != nil {
+			return addr.IP
+		}
+	case *UDPClient:
+		if w.addr != nil {
+			return w.addr.IP
+		}
+	}
+	return nil
+}
+
+// claim return true if the forwarder, that has received the response
+// (isAnswered is true) or failed, should handle the request.
+// For request that is forwarded to multiple forwarders, only the first
+// answer is handled, and the failure is handled only if all of the
+// forwarders has failed.
+func (req *request) claim(isAnswered bool) bool {
+	if req.race == nil {
+		return true
+	}
+	if isAnswered {
+		return req.race.done.CompareAndSwap(false, true)
+	}
+	return req.race.pending.Add(-1) == 0 && !req.race.done.Load()
+}
+
+// remoteAddr return the IP address and port of client that send the
+// request.
+// It will return zero value if the address is unknown.
+func (req *request) remoteAddr() (addr netip.AddrPort) {
+	switch w := req.writer.(type) {
+	case *DoHClient:
+		return w.clientAddr
+	case *TCPClient:
+		var tcpAddr, _ = w.conn.RemoteAddr().(*net.TCPAddr)
+		if tcpAddr != nil {
+			return tcpAddr.AddrPort()
+		}
+	case *UDPClient:
+		if w.addr != nil {
+			return w.addr.AddrPort()
+		}
+	}
+	return addr
+}
+
 // error set the request message as an error.
 func (req *request) error(rcode ResponseCode) {
 	var err error
 
 	req.message.SetQuery(false)
 	req.message.SetResponseCode(rcode)
+	req.extRCode = byte(rcode >> 4)
 
-	_, err = req.writer.Write(req.message.packet)
+	_, err = req.write(req.message.packet)
 	if err != nil {
 		log.Println("dns: request.error:", err.Error())
 	}
 }
+
+// setForwardOPT set the OPT record on request message before its
+// forwarded to parent name servers.
+//
+// If isDO is true, the DNSSEC OK (DO) bit is set, so the parent name
+// server return the RRSIG records along with the answer.
+// The DNS cookie and padding from client are removed, since they are
+// only valid between client and this server.
+// If the [ServerOptions.ClientSubnet] is enabled, the ECS option from
+// client is truncated or, if its not exist, new ECS option is added from
+// the client address; otherwise the ECS option is removed.
+// If the request does not have OPT record, new one will be added only if
+// required.
+func (req *request) setForwardOPT(opts *ServerOptions, isDO bool) (err error) {
+	if req.opt == nil && !isDO && !opts.ClientSubnet {
+		return nil
+	}
+
+	var (
+		logp = `setForwardOPT`
+
+		msg *Message
+		opt *RDataOPT
+		ecs *EDNSClientSubnet
+	)
+
+	msg, err = UnpackMessage(req.message.packet)
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	if opts.ClientSubnet {
+		opt = msg.opt(true)
+		ecs, err = opt.ClientSubnet()
+		if err != nil {
+			return fmt.Errorf(`%s: %w`, logp, err)
+		}
+		if ecs == nil {
+			var ip = req.remoteIP()
+			if ip != nil {
+				ecs = NewEDNSClientSubnet(ip, opts.ClientSubnetPrefixV4)
+				if ecs.Family == ecsFamilyIPv6 {
+					ecs = NewEDNSClientSubnet(ip, opts.ClientSubnetPrefixV6)
+				}
+			}
+		} else if ecs.Family == ecsFamilyIPv4 {
+			ecs.truncate(opts.ClientSubnetPrefixV4)
+		} else {
+			ecs.truncate(opts.ClientSubnetPrefixV6)
+		}
+		opt.SetClientSubnet(ecs)
+	} else {
+		opt = msg.opt(isDO)
+		if opt != nil {
+			opt.SetClientSubnet(nil)
+		}
+	}
+	if opt != nil {
+		opt.SetCookie(nil)
+		opt.SetPadding(-1)
+		if isDO {
+			opt.DO = true
+		}
+	}
+
+	_, err = msg.Pack()
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+	req.message = msg
+	return nil
+}
+
+// write the response packet to client.
+// If the query has OPT record, the OPT record in the response is
+// rewritten to contains the DNS cookie, the extended error, and the
+// padding for query through DoT or DoH, for this request.
+func (req *request) write(packet []byte) (n int, err error) {
+	if req.rl != nil {
+		packet = req.rl.limit(req.remoteIP(), req.message.Question.Name,
+			req.message.Question.Type, packet)
+		if packet == nil {
+			return 0, nil
+		}
+	}
+	if req.opt != nil {
+		packet = req.packResponseOPT(packet)
+	}
+	n, err = req.writer.Write(packet)
+	req.ql.clientResponse(req, packet)
+	return n, err
+}
+
+// packResponseOPT rewrite the OPT record in response packet based on the
+// OPT record in the query.
+// On fail, or if the response is signed with TSIG, it will return the
+// original packet.
+func (req *request) packResponseOPT(packet []byte) []byte {
+	var (
+		logp = `packResponseOPT`
+
+		res *Message
+		err error
+	)
+
+	// The packet may be shared with cached answer, so we unpack the
+	// copy of it to prevent Pack overwrite the original packet.
+	res, err = UnpackMessage(slices.Clone(packet))
+	if err != nil {
+		log.Printf(`%s: %s`, logp, err)
+		return packet
+	}
+	if len(res.Additional) > 0 &&
+		res.Additional[len(res.Additional)-1].Type == RecordTypeTSIG {
+		return packet
+	}
+
+	var (
+		opt    = res.opt(true)
+		reqECS *EDNSClientSubnet
+		resECS *EDNSClientSubnet
+	)
+
+	// The cookie and padding from parent name server is only valid
+	// between this server and parent name server.
+	opt.SetCookie(req.cookie)
+	opt.SetPadding(-1)
+
+	// Echo the ECS option from query, with the scope prefix from the
+	// parent name server response.
+	reqECS, _ = req.opt.ClientSubnet()
+	if reqECS != nil {
+		resECS, _ = opt.ClientSubnet()
+		if resECS != nil {
+			reqECS.ScopePrefix = resECS.ScopePrefix
+		}
+	}
+	opt.SetClientSubnet(reqECS)
+
+	if req.extRCode != 0 {
+		opt.ExtRCode = req.extRCode
+	}
+	if req.ede != nil {
+		opt.AddExtendedError(*req.ede)
+	}
+
+	_, err = res.Pack()
+	if err != nil {
+		log.Printf(`%s: %s`, logp, err)
+		return packet
+	}
+
+	if req.kind == connTypeDoT || req.kind == connTypeDoH {
+		if req.opt.get(OptionCodePadding) != nil {
+			// The option code and length take 4 octets.
+			var size = len(res.packet) + 4
+			opt.SetPadding((paddingBlockSize - size%paddingBlockSize) % paddingBlockSize)
+			_, err = res.Pack()
+			if err != nil {
+				log.Printf(`%s: %s`, logp, err)
+				return packet
+			}
+		}
+	}
+	return res.packet
+}
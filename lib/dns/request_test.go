@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"net"
+	"testing"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestRequest_setForwardOPT(t *testing.T) {
+	type testCase struct {
+		writer     *UDPClient
+		desc       string
+		additional []ResourceRecord
+		expListVar []RDataOPTVar
+		expClass   RecordClass
+		isECS      bool
+		isDO       bool
+	}
+
+	var (
+		cookie = &EDNSCookie{
+			Client: []byte(`12345678`),
+		}
+		ecs32 = NewEDNSClientSubnet(net.ParseIP(`192.0.2.77`), 32)
+		ecs24 = NewEDNSClientSubnet(net.ParseIP(`192.0.2.77`), 24)
+		ecs56 = NewEDNSClientSubnet(net.ParseIP(`2001:db8::1`), 56)
+
+		optClient = &RDataOPT{}
+	)
+
+	optClient.SetCookie(cookie)
+	optClient.SetClientSubnet(ecs32)
+	optClient.SetPadding(8)
+
+	var listCase = []testCase{{
+		desc:     `Without OPT`,
+		isDO:     true,
+		expClass: RecordClass(maxUDPPacketSize),
+	}, {
+		desc: `With OPT`,
+		additional: []ResourceRecord{{
+			Type:  RecordTypeOPT,
+			Class: 4096,
+			Value: &RDataOPT{},
+		}},
+		isDO:     true,
+		expClass: 4096,
+	}, {
+		desc: `With options and ECS disabled`,
+		additional: []ResourceRecord{{
+			Type:  RecordTypeOPT,
+			Class: 4096,
+			Value: optClient,
+		}},
+		expClass: 4096,
+	}, {
+		desc: `With options and ECS enabled`,
+		additional: []ResourceRecord{{
+			Type:  RecordTypeOPT,
+			Class: 4096,
+			Value: optClient,
+		}},
+		isECS:    true,
+		expClass: 4096,
+		expListVar: []RDataOPTVar{{
+			Code: OptionCodeClientSubnet,
+			Data: ecs24.pack(),
+		}},
+	}, {
+		desc: `Without OPT and ECS enabled`,
+		writer: &UDPClient{
+			addr: &net.UDPAddr{IP: net.ParseIP(`2001:db8::1`)},
+		},
+		isECS:    true,
+		expClass: RecordClass(maxUDPPacketSize),
+		expListVar: []RDataOPTVar{{
+			Code: OptionCodeClientSubnet,
+			Data: ecs56.pack(),
+		}},
+	}}
+
+	var (
+		opts = &ServerOptions{}
+
+		c   testCase
+		req *request
+		got *Message
+		err error
+	)
+
+	err = opts.init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c = range listCase {
+		req = newRequest()
+		if c.writer != nil {
+			req.writer = c.writer
+		}
+		req.message.Header.ID = 1234
+		req.message.Question.Name = `kilabit.info`
+		req.message.Question.Type = RecordTypeA
+		req.message.Question.Class = RecordClassIN
+		req.message.Additional = c.additional
+		_, err = req.message.Pack()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(c.additional) != 0 {
+			req.opt = optClient
+		}
+
+		opts.ClientSubnet = c.isECS
+
+		err = req.setForwardOPT(opts, c.isDO)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err = UnpackMessage(req.message.packet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		test.Assert(t, c.desc+`: ID`, uint16(1234), got.Header.ID)
+		test.Assert(t, c.desc+`: Additional`, 1, len(got.Additional))
+
+		var opt = got.Additional[0].Value.(*RDataOPT)
+		test.Assert(t, c.desc+`: DO`, c.isDO, opt.DO)
+		test.Assert(t, c.desc+`: Class`, c.expClass, got.Additional[0].Class)
+		test.Assert(t, c.desc+`: ListVar`, c.expListVar, opt.ListVar)
+	}
+}
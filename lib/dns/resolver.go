@@ -0,0 +1,659 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	libnet "git.sr.ht/~shulhan/pakakeh.go/lib/net"
+)
+
+const (
+	// resolverTimeout define the maximum time to wait for the response
+	// from each authoritative name server.
+	resolverTimeout = 3 * time.Second
+
+	// resolverLameTTL define how long the lame name server is not used
+	// for its zone.
+	resolverLameTTL = 10 * time.Minute
+
+	// maxReferrals define the maximum number of referrals, and
+	// minimised queries, that are followed for single name.
+	maxReferrals = 32
+
+	// maxMinimiseCount define the maximum number of labels that are
+	// added one by one in QNAME minimisation, as recommended by RFC
+	// 9156 section 2.3.
+	maxMinimiseCount = 10
+
+	// maxResolveDepth define the maximum depth of resolving the address
+	// of name servers that does not have glue.
+	maxResolveDepth = 4
+)
+
+// defaultRootHints contains the IPv4 address of the root name servers,
+// from https://www.internic.net/domain/named.root.
+var defaultRootHints = []string{
+	`198.41.0.4`,
+	`170.247.170.2`,
+	`192.33.4.12`,
+	`199.7.91.13`,
+	`192.203.230.10`,
+	`192.5.5.241`,
+	`192.112.36.4`,
+	`198.97.190.53`,
+	`192.36.148.17`,
+	`192.58.128.30`,
+	`193.0.14.129`,
+	`199.7.83.42`,
+	`202.12.27.33`,
+}
+
+// errResolverLame define an error when the response from name server is
+// neither an authoritative answer nor a referral to the sub zone.
+var errResolverLame = errors.New(`lame delegation`)
+
+// delegation contains the address of name servers for zone.
+type delegation struct {
+	// zone contains the name of zone, in lower case and absolute.
+	zone string
+
+	// servers contains the address, in "ip:port" format, of zone
+	// name servers.
+	servers []string
+
+	// expireAt contains time when the delegation is expired, based on
+	// the TTL of NS records, or zero for the root hints.
+	expireAt int64
+}
+
+// resolver resolve the query iteratively, start from the root name
+// servers, by following the referrals, as described in RFC 1034 section
+// 5.3.3.
+//
+// The query name is minimised, as described in RFC 9156, so each name
+// server only see the next label below its zone.
+// The delegations, including the address of name servers from glue, are
+// cached until their NS records expired.
+// The name server that does not answer, or answer without authority, is
+// marked as lame for its zone for resolverLameTTL.
+//
+// The resolver implement the [Client] interface, so it can be used by
+// validator to lookup the DNSKEY and DS records.
+type resolver struct {
+	root *delegation
+
+	// delegations contains the cached delegations, indexed by zone.
+	delegations map[string]*delegation
+
+	// lame contains the time when the lame name server can be used
+	// again, indexed by zone and server address.
+	lame map[string]int64
+
+	timeout time.Duration
+
+	// port define the port of name servers from delegations.
+	port uint16
+
+	debug int
+
+	sync.Mutex
+}
+
+// newResolver create new resolver using the root hints, list of IP
+// address with optional port.
+func newResolver(rootHints []string, debug int) (r *resolver) {
+	r = &resolver{
+		root: &delegation{
+			zone:    `.`,
+			servers: rootHints,
+		},
+		delegations: make(map[string]*delegation),
+		lame:        make(map[string]int64),
+		timeout:     resolverTimeout,
+		port:        DefaultPort,
+		debug:       debug,
+	}
+	return r
+}
+
+// parseRootHints parse each IP address, with optional port, in list.
+func parseRootHints(list []string) (hints []string, err error) {
+	var (
+		hint string
+		ip   net.IP
+		port uint16
+	)
+	for _, hint = range list {
+		_, ip, port = libnet.ParseIPPort(strings.TrimSpace(hint), DefaultPort)
+		if ip == nil {
+			return nil, fmt.Errorf(`invalid root hint %q`, hint)
+		}
+		hints = append(hints, net.JoinHostPort(ip.String(),
+			strconv.Itoa(int(port))))
+	}
+	return hints, nil
+}
+
+// Close does nothing, its exist to implement the [Client] interface.
+func (r *resolver) Close() error {
+	return nil
+}
+
+// Lookup resolve the question iteratively.
+func (r *resolver) Lookup(q MessageQuestion, allowRecursion bool) (res *Message, err error) {
+	if q.Type == 0 {
+		q.Type = RecordTypeA
+	}
+	if q.Class == 0 {
+		q.Class = RecordClassIN
+	}
+
+	var msg = NewMessage()
+
+	msg.Header.ID = getNextID()
+	msg.Header.IsRD = allowRecursion
+	msg.Question = q
+
+	_, err = msg.Pack()
+	if err != nil {
+		return nil, fmt.Errorf(`Lookup: %w`, err)
+	}
+	return r.Query(msg)
+}
+
+// Query resolve the question in req iteratively and return the response
+// with recursion available flag set.
+// If the req has OPT record with DNSSEC OK bit, the queries to name
+// servers also set the DO bit, so the response contains the RRSIG
+// records.
+func (r *resolver) Query(req *Message) (res *Message, err error) {
+	var (
+		isDO bool
+		opt  = req.opt(false)
+	)
+	if opt != nil {
+		isDO = opt.DO
+	}
+
+	res, err = r.resolve(req.Question.Name, req.Question.Type, isDO, 0)
+	if err != nil {
+		return nil, fmt.Errorf(`Query %s %s: %w`, req.Question.Name,
+			recordTypeName(req.Question.Type), err)
+	}
+
+	res.Header = MessageHeader{
+		ID:      req.Header.ID,
+		IsRD:    req.Header.IsRD,
+		IsRA:    true,
+		RCode:   res.Header.RCode,
+		QDCount: 1,
+		ANCount: uint16(len(res.Answer)),
+		NSCount: uint16(len(res.Authority)),
+		ARCount: uint16(len(res.Additional)),
+	}
+	res.Question = req.Question
+
+	_, err = res.Pack()
+	if err != nil {
+		return nil, fmt.Errorf(`Query: %w`, err)
+	}
+	return res, nil
+}
+
+// RemoteAddr return the first address of root hints.
+func (r *resolver) RemoteAddr() string {
+	if len(r.root.servers) == 0 {
+		return ``
+	}
+	return r.root.servers[0]
+}
+
+// SetRemoteAddr set the root hints to single address.
+func (r *resolver) SetRemoteAddr(addr string) (err error) {
+	var hints []string
+
+	hints, err = parseRootHints([]string{addr})
+	if err != nil {
+		return err
+	}
+
+	r.Lock()
+	r.root = &delegation{
+		zone:    `.`,
+		servers: hints,
+	}
+	r.Unlock()
+	return nil
+}
+
+// SetTimeout set the timeout for each query to name server.
+func (r *resolver) SetTimeout(t time.Duration) {
+	r.timeout = t
+}
+
+// resolve the qname and qtype, following the CNAME in the answer into
+// other zones.
+// The depth is the number of nested resolve for the address of name
+// servers.
+func (r *resolver) resolve(qname string, qtype RecordType, isDO bool, depth int) (res *Message, err error) {
+	var (
+		visited = make(map[string]struct{})
+
+		answer []ResourceRecord
+		target string
+		n      int
+		ok     bool
+	)
+
+	qname = strings.ToLower(toDomainAbsolute(qname))
+
+	for n = 0; n <= maxCNAMEChain; n++ {
+		visited[qname] = struct{}{}
+
+		res, err = r.iterate(qname, qtype, isDO, depth)
+		if err != nil {
+			return nil, err
+		}
+		answer = append(answer, res.Answer...)
+
+		if res.Header.RCode != RCodeOK || qtype == RecordTypeCNAME {
+			break
+		}
+
+		target = cnameTarget(res.Answer, qname, qtype)
+		if len(target) == 0 {
+			break
+		}
+		_, ok = visited[target]
+		if ok {
+			break
+		}
+		qname = target
+	}
+
+	res.Answer = answer
+	return res, nil
+}
+
+// iterate resolve the qname and qtype by querying the name servers of the
+// closest known delegation and following the referrals.
+func (r *resolver) iterate(qname string, qtype RecordType, isDO bool, depth int) (res *Message, err error) {
+	var (
+		start = qname
+
+		dlg       *delegation
+		minimised string
+		sname     string
+		stype     RecordType
+		n         int
+	)
+
+	if qtype == RecordTypeDS && qname != `.` {
+		// The DS records is answered by the parent zone.
+		start, _ = dnssecParent(qname)
+		start = toDomainAbsolute(start)
+	}
+	dlg = r.closest(start)
+	minimised = dlg.zone
+
+	for n = 0; n < maxReferrals; n++ {
+		sname, stype = qname, qtype
+		if minimised != qname && labelCount(minimised) < maxMinimiseCount {
+			sname = nextLabel(qname, minimised)
+			if sname != qname {
+				stype = RecordTypeA
+			}
+		}
+
+		res, err = r.queryZone(dlg, sname, stype, isDO, depth)
+		if err != nil {
+			return nil, err
+		}
+
+		if !res.Header.IsAA {
+			dlg, err = r.referral(dlg, res, isDO, depth)
+			if err != nil {
+				return nil, err
+			}
+			minimised = dlg.zone
+			continue
+		}
+		if sname == qname {
+			return res, nil
+		}
+		if res.Header.RCode == RCodeErrName {
+			// Nothing exist below the non-existent name, as
+			// described in RFC 8020.
+			return res, nil
+		}
+
+		// The minimised name exist in the same zone.
+		minimised = sname
+	}
+	return nil, errors.New(`too many referrals`)
+}
+
+// queryZone send the query to each name servers of delegation dlg,
+// skipping the lame one, until one of them return an authoritative answer
+// or a referral to sub zone.
+func (r *resolver) queryZone(dlg *delegation, qname string, qtype RecordType, isDO bool, depth int) (res *Message, err error) {
+	var (
+		now = time.Now().Unix()
+
+		server string
+		key    string
+	)
+
+	for _, server = range dlg.servers {
+		key = dlg.zone + ` ` + server
+
+		r.Lock()
+		var lameUntil = r.lame[key]
+		r.Unlock()
+
+		if lameUntil > now {
+			continue
+		}
+
+		res, err = r.query(server, qname, qtype, isDO)
+		if err == nil {
+			err = checkReferral(dlg.zone, qname, res)
+		}
+		if err == nil {
+			return res, nil
+		}
+
+		if r.debug&DebugLevelCache != 0 {
+			log.Printf(`! RESOLVER %s %s %s: %s`, dlg.zone, server,
+				qname, err)
+		}
+
+		r.Lock()
+		r.lame[key] = now + int64(resolverLameTTL.Seconds())
+		r.Unlock()
+	}
+	if dlg.zone != `.` {
+		// Forget the delegation, so the next query start from the
+		// parent zone.
+		r.Lock()
+		delete(r.delegations, dlg.zone)
+		r.Unlock()
+	}
+	return nil, fmt.Errorf(`no usable name servers for zone %q`, dlg.zone)
+}
+
+// query send the query to server using UDP, or TCP if the response is
+// truncated.
+func (r *resolver) query(server, qname string, qtype RecordType, isDO bool) (res *Message, err error) {
+	var msg = NewMessage()
+
+	msg.Header.ID = getNextID()
+	msg.Header.IsRD = false
+	msg.Question.Name = dnssecQueryName(strings.TrimSuffix(qname, `.`))
+	msg.Question.Type = qtype
+	msg.Additional = []ResourceRecord{{
+		Type:  RecordTypeOPT,
+		Class: RecordClass(maxUDPPacketSize),
+		Value: &RDataOPT{DO: isDO},
+	}}
+
+	_, err = msg.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	var udp *UDPClient
+
+	udp, err = NewUDPClient(server)
+	if err != nil {
+		return nil, err
+	}
+	udp.SetTimeout(r.timeout)
+	res, err = udp.Query(msg)
+	_ = udp.Close()
+	if err != nil {
+		return nil, err
+	}
+	if !res.Header.IsTC {
+		return res, nil
+	}
+
+	var tcp *TCPClient
+
+	tcp, err = NewTCPClient(server)
+	if err != nil {
+		return nil, err
+	}
+	tcp.SetTimeout(r.timeout)
+	res, err = tcp.Query(msg)
+	_ = tcp.Close()
+	return res, err
+}
+
+// checkReferral return nil if the response res, from the name server of
+// zone, is an authoritative answer or a referral to the sub zone that
+// contains qname.
+func checkReferral(zone, qname string, res *Message) error {
+	switch res.Header.RCode {
+	case RCodeOK, RCodeErrName:
+	default:
+		return fmt.Errorf(`%w: response code %s`, errResolverLame,
+			rcodeNames[res.Header.RCode])
+	}
+	if res.Header.IsAA {
+		return nil
+	}
+
+	var (
+		rr  *ResourceRecord
+		cut string
+		x   int
+	)
+	for x = range res.Authority {
+		rr = &res.Authority[x]
+		if rr.Type != RecordTypeNS {
+			continue
+		}
+		cut = strings.ToLower(toDomainAbsolute(rr.Name))
+		if cut != zone && dnssecIsSubdomain(cut, zone) &&
+			dnssecIsSubdomain(qname, cut) {
+			return nil
+		}
+		return fmt.Errorf(`%w: referral to %s`, errResolverLame, cut)
+	}
+	return fmt.Errorf(`%w: not authoritative`, errResolverLame)
+}
+
+// referral create and cache the delegation from the referral response
+// res, received from the name servers of parent.
+// The address of name servers is taken from the glue, in the additional
+// section, that is inside the parent zone, or, if there is none, by
+// resolving their names.
+func (r *resolver) referral(parent *delegation, res *Message, isDO bool, depth int) (dlg *delegation, err error) {
+	var (
+		names = make(map[string]struct{})
+
+		rr     *ResourceRecord
+		name   string
+		minTTL uint32
+		x      int
+		ok     bool
+	)
+
+	dlg = &delegation{}
+
+	for x = range res.Authority {
+		rr = &res.Authority[x]
+		if rr.Type != RecordTypeNS {
+			continue
+		}
+		if len(dlg.zone) == 0 {
+			dlg.zone = strings.ToLower(toDomainAbsolute(rr.Name))
+			minTTL = rr.TTL
+		}
+		if rr.TTL < minTTL {
+			minTTL = rr.TTL
+		}
+		name, _ = rr.Value.(string)
+		names[strings.ToLower(toDomainAbsolute(name))] = struct{}{}
+	}
+
+	for x = range res.Additional {
+		rr = &res.Additional[x]
+		if rr.Type != RecordTypeA && rr.Type != RecordTypeAAAA {
+			continue
+		}
+		name = strings.ToLower(toDomainAbsolute(rr.Name))
+		_, ok = names[name]
+		if !ok || !dnssecIsSubdomain(name, parent.zone) {
+			// Ignore the glue outside of parent zone.
+			continue
+		}
+		dlg.addServer(rr.Value.(string), r.port, rr.Type)
+	}
+
+	if len(dlg.servers) == 0 && depth < maxResolveDepth {
+		var resAddr *Message
+		for name = range names {
+			resAddr, err = r.resolve(name, RecordTypeA, isDO, depth+1)
+			if err != nil {
+				continue
+			}
+			for x = range resAddr.Answer {
+				rr = &resAddr.Answer[x]
+				if rr.Type == RecordTypeA {
+					dlg.addServer(rr.Value.(string), r.port, rr.Type)
+				}
+			}
+			if len(dlg.servers) != 0 {
+				break
+			}
+		}
+	}
+	if len(dlg.servers) == 0 {
+		return nil, fmt.Errorf(`no address for name servers of zone %q`, dlg.zone)
+	}
+
+	dlg.expireAt = time.Now().Unix() + int64(minTTL)
+
+	r.Lock()
+	r.delegations[dlg.zone] = dlg
+	r.Unlock()
+
+	if r.debug&DebugLevelCache != 0 {
+		log.Printf(`+ RESOLVER %s %v`, dlg.zone, dlg.servers)
+	}
+	return dlg, nil
+}
+
+// closest return the cached delegation of zone that is the closest
+// ancestor of qname, or the root hints if there is none.
+func (r *resolver) closest(qname string) (dlg *delegation) {
+	var (
+		now = time.Now().Unix()
+		x   int
+	)
+
+	r.Lock()
+	defer r.Unlock()
+
+	for {
+		dlg = r.delegations[qname]
+		if dlg != nil {
+			if dlg.expireAt > now {
+				return dlg
+			}
+			delete(r.delegations, qname)
+		}
+		x = strings.IndexByte(qname, '.')
+		if x < 0 || x == len(qname)-1 {
+			break
+		}
+		qname = qname[x+1:]
+	}
+	return r.root
+}
+
+// addServer add the IP address with port into the delegation servers.
+// The IPv4 address is put before IPv6 address.
+func (dlg *delegation) addServer(ip string, port uint16, rtype RecordType) {
+	var addr = net.JoinHostPort(ip, strconv.Itoa(int(port)))
+	if rtype == RecordTypeA {
+		dlg.servers = append([]string{addr}, dlg.servers...)
+		return
+	}
+	dlg.servers = append(dlg.servers, addr)
+}
+
+// cnameTarget follow the CNAME chain of qname in the answer and return
+// the last target, if the answer does not contains record with type
+// qtype for it.
+func cnameTarget(answer []ResourceRecord, qname string, qtype RecordType) string {
+	var (
+		name = qname
+
+		rr    *ResourceRecord
+		n     int
+		x     int
+		found bool
+	)
+	for n = 0; n <= maxCNAMEChain; n++ {
+		found = false
+		for x = range answer {
+			rr = &answer[x]
+			if !strings.EqualFold(toDomainAbsolute(rr.Name), name) {
+				continue
+			}
+			if rr.Type == qtype {
+				return ``
+			}
+			if rr.Type == RecordTypeCNAME {
+				var target, _ = rr.Value.(string)
+				name = strings.ToLower(toDomainAbsolute(target))
+				found = true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	if name == qname {
+		return ``
+	}
+	return name
+}
+
+// labelCount return the number of labels in the absolute name.
+func labelCount(name string) int {
+	if name == `.` {
+		return 0
+	}
+	return strings.Count(name, `.`)
+}
+
+// nextLabel return the name with one more label from qname below zone.
+// For example, the next label of "a.b.example.com." below "com." is
+// "example.com.".
+func nextLabel(qname, zone string) string {
+	var prefix = qname
+	if zone != `.` {
+		prefix = strings.TrimSuffix(qname, `.`+zone)
+	} else {
+		prefix = strings.TrimSuffix(qname, `.`)
+	}
+	var x = strings.LastIndexByte(prefix, '.')
+	if x < 0 {
+		return qname
+	}
+	return qname[x+1:]
+}
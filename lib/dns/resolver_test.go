@@ -0,0 +1,256 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestNextLabel(t *testing.T) {
+	type testCase struct {
+		qname string
+		zone  string
+		exp   string
+	}
+
+	var listCase = []testCase{{
+		qname: `a.b.example.com.`,
+		zone:  `.`,
+		exp:   `com.`,
+	}, {
+		qname: `a.b.example.com.`,
+		zone:  `com.`,
+		exp:   `example.com.`,
+	}, {
+		qname: `a.b.example.com.`,
+		zone:  `b.example.com.`,
+		exp:   `a.b.example.com.`,
+	}, {
+		qname: `com.`,
+		zone:  `.`,
+		exp:   `com.`,
+	}}
+
+	var c testCase
+	for _, c = range listCase {
+		test.Assert(t, c.qname+` below `+c.zone, c.exp,
+			nextLabel(c.qname, c.zone))
+	}
+}
+
+// newTestAuthServer create and run the stand-in authoritative name
+// server at address, that serve the zones.
+func newTestAuthServer(t *testing.T, address, queryLog string, zones map[string]string) {
+	var (
+		srv *Server
+		err error
+	)
+
+	srv, err = NewServer(&ServerOptions{
+		ListenAddress:  address,
+		QueryLog:       queryLog,
+		QueryLogFormat: QueryLogFormatJSON,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		origin string
+		data   string
+		zone   *Zone
+	)
+	for origin, data = range zones {
+		zone, err = ParseZone([]byte(data), origin, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv.Caches.InternalPopulateZone(zone)
+	}
+
+	go func() {
+		_ = srv.ListenAndServe()
+	}()
+	t.Cleanup(srv.Stop)
+}
+
+func TestServer_recursive(t *testing.T) {
+	var rootLog = filepath.Join(t.TempDir(), `root.log`)
+
+	newTestAuthServer(t, `127.0.0.2:5349`, rootLog, map[string]string{
+		`.`: `. SOA a.root.test. admin.test. 1 3600 900 604800 300
+. NS a.root.test.
+test. NS ns1.test.
+ns1.test. A 127.0.0.3
+`,
+	})
+	newTestAuthServer(t, `127.0.0.3:5349`, ``, map[string]string{
+		`test`: `@ SOA ns1 admin 1 3600 900 604800 300
+@ NS ns1
+ns1 A 127.0.0.3
+example NS ns1.example
+example NS lame.example
+ns1.example A 127.0.0.4
+lame.example A 127.0.0.5
+other NS ns.example.test.
+`,
+	})
+	newTestAuthServer(t, `127.0.0.4:5349`, ``, map[string]string{
+		`example.test`: `@ SOA ns1 admin 1 3600 900 604800 300
+@ NS ns1
+ns1 A 127.0.0.4
+ns A 127.0.0.6
+api A 10.0.0.1
+a.b.c A 10.0.0.2
+www CNAME web.other.test.
+`,
+	})
+	newTestAuthServer(t, `127.0.0.6:5349`, ``, map[string]string{
+		`other.test`: `@ SOA ns.example.test. admin 1 3600 900 604800 300
+@ NS ns.example.test.
+web A 10.0.0.3
+`,
+	})
+
+	// The lame name server, that does not have any zones.
+	newTestAuthServer(t, `127.0.0.5:5349`, ``, nil)
+
+	var (
+		srv *Server
+		err error
+	)
+
+	srv, err = NewServer(&ServerOptions{
+		ListenAddress: `127.0.0.1:5349`,
+		Recursive:     true,
+		RootHints:     []string{`127.0.0.2:5349`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.resolver.port = 5349
+	srv.resolver.timeout = time.Second
+
+	go func() {
+		_ = srv.ListenAndServe()
+	}()
+	t.Cleanup(srv.Stop)
+
+	var cl *UDPClient
+
+	cl, err = NewUDPClient(`127.0.0.1:5349`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Close()
+
+	type testCase struct {
+		desc      string
+		qname     string
+		expAnswer []string
+		qtype     RecordType
+		expRCode  ResponseCode
+	}
+
+	var listCase = []testCase{{
+		desc:      `With lame delegation`,
+		qname:     `api.example.test`,
+		qtype:     RecordTypeA,
+		expAnswer: []string{`api.example.test A 10.0.0.1`},
+	}, {
+		desc:      `With minimised empty non-terminal`,
+		qname:     `a.b.c.example.test`,
+		qtype:     RecordTypeA,
+		expAnswer: []string{`a.b.c.example.test A 10.0.0.2`},
+	}, {
+		desc:  `With CNAME into other zone without glue`,
+		qname: `www.example.test`,
+		qtype: RecordTypeA,
+		expAnswer: []string{
+			`www.example.test CNAME web.other.test`,
+			`web.other.test A 10.0.0.3`,
+		},
+	}, {
+		desc:     `With name not exist`,
+		qname:    `none.example.test`,
+		qtype:    RecordTypeA,
+		expRCode: RCodeErrName,
+	}}
+
+	var (
+		c   testCase
+		res *Message
+	)
+	for _, c = range listCase {
+		res, err = cl.Lookup(MessageQuestion{Name: c.qname, Type: c.qtype}, true)
+		if err != nil {
+			t.Fatalf(`%s: %s`, c.desc, err)
+		}
+		test.Assert(t, c.desc+`: RCode`, c.expRCode, res.Header.RCode)
+		test.Assert(t, c.desc+`: IsRA`, true, res.Header.IsRA)
+		test.Assert(t, c.desc+`: Answer`, c.expAnswer,
+			testRecordsString(res.Answer))
+	}
+
+	var (
+		r     = srv.resolver
+		isSet bool
+	)
+	r.Lock()
+	_, isSet = r.lame[`example.test. 127.0.0.5:5349`]
+	test.Assert(t, `lame server`, true, isSet)
+	test.Assert(t, `delegation example.test.`, []string{`127.0.0.4:5349`,
+		`127.0.0.5:5349`}, sortedServers(r.delegations[`example.test.`]))
+	test.Assert(t, `delegation other.test.`, []string{`127.0.0.6:5349`},
+		r.delegations[`other.test.`].servers)
+	r.Unlock()
+
+	// The root name server only receive the minimised query for
+	// "test.", since the next queries start from the cached delegation.
+	var (
+		f    *os.File
+		ev   QueryLogEvent
+		seen = make(map[string]struct{})
+	)
+
+	// Wait until the root server flush its query log.
+	time.Sleep(200 * time.Millisecond)
+
+	f, err = os.Open(rootLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var scanner = bufio.NewScanner(f)
+	for scanner.Scan() {
+		err = json.Unmarshal(scanner.Bytes(), &ev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[ev.QName+` `+ev.QType] = struct{}{}
+	}
+	test.Assert(t, `root queries`, map[string]struct{}{
+		`test A`: {},
+	}, seen)
+}
+
+// sortedServers return the servers of delegation in IPv4 order.
+func sortedServers(dlg *delegation) (servers []string) {
+	if dlg == nil {
+		return nil
+	}
+	servers = append(servers, dlg.servers...)
+	if len(servers) == 2 && servers[0] > servers[1] {
+		servers[0], servers[1] = servers[1], servers[0]
+	}
+	return servers
+}
@@ -96,7 +96,7 @@ func (rr *ResourceRecord) initAndValidate() (err error) {
 		}
 
 	case RecordTypeNS, RecordTypeCNAME, RecordTypeMB, RecordTypeMG,
-		RecordTypeMR, RecordTypeNULL, RecordTypePTR:
+		RecordTypeMR, RecordTypeNULL, RecordTypePTR, RecordTypeDNAME:
 
 		v, ok = rr.Value.(string)
 		if !ok {
@@ -178,6 +178,66 @@ func (rr *ResourceRecord) initAndValidate() (err error) {
 		if !ok {
 			return fmt.Errorf("%s: expecting %s got %T", logp, rtype, rr.Value)
 		}
+	case RecordTypeDS:
+		_, ok = rr.Value.(*RDataDS)
+		if !ok {
+			return fmt.Errorf("%s: expecting %s got %T", logp, rtype, rr.Value)
+		}
+	case RecordTypeRRSIG:
+		_, ok = rr.Value.(*RDataRRSIG)
+		if !ok {
+			return fmt.Errorf("%s: expecting %s got %T", logp, rtype, rr.Value)
+		}
+	case RecordTypeNSEC:
+		_, ok = rr.Value.(*RDataNSEC)
+		if !ok {
+			return fmt.Errorf("%s: expecting %s got %T", logp, rtype, rr.Value)
+		}
+	case RecordTypeDNSKEY:
+		var dnskey *RDataDNSKEY
+		dnskey, ok = rr.Value.(*RDataDNSKEY)
+		if !ok {
+			return fmt.Errorf("%s: expecting %s got %T", logp, rtype, rr.Value)
+		}
+		err = dnskey.initAndValidate()
+		if err != nil {
+			return fmt.Errorf("%s: %w", logp, err)
+		}
+	case RecordTypeNSEC3:
+		_, ok = rr.Value.(*RDataNSEC3)
+		if !ok {
+			return fmt.Errorf("%s: expecting %s got %T", logp, rtype, rr.Value)
+		}
+	case RecordTypeNSEC3PARAM:
+		_, ok = rr.Value.(*RDataNSEC3PARAM)
+		if !ok {
+			return fmt.Errorf("%s: expecting %s got %T", logp, rtype, rr.Value)
+		}
+	case RecordTypeNAPTR:
+		_, ok = rr.Value.(*RDataNAPTR)
+		if !ok {
+			return fmt.Errorf("%s: expecting %s got %T", logp, rtype, rr.Value)
+		}
+	case RecordTypeSSHFP:
+		_, ok = rr.Value.(*RDataSSHFP)
+		if !ok {
+			return fmt.Errorf("%s: expecting %s got %T", logp, rtype, rr.Value)
+		}
+	case RecordTypeTLSA:
+		_, ok = rr.Value.(*RDataTLSA)
+		if !ok {
+			return fmt.Errorf("%s: expecting %s got %T", logp, rtype, rr.Value)
+		}
+	case RecordTypeURI:
+		_, ok = rr.Value.(*RDataURI)
+		if !ok {
+			return fmt.Errorf("%s: expecting %s got %T", logp, rtype, rr.Value)
+		}
+	case RecordTypeCAA:
+		_, ok = rr.Value.(*RDataCAA)
+		if !ok {
+			return fmt.Errorf("%s: expecting %s got %T", logp, rtype, rr.Value)
+		}
 	}
 	return nil
 }
@@ -214,6 +274,12 @@ func (rr *ResourceRecord) unpack(packet []byte, startIdx uint) (x uint, err erro
 
 	rr.rdata = append(rr.rdata, packet[x:lenXRdata]...)
 
+	if rr.rdlen == 0 && rr.Type != RecordTypeOPT &&
+		(rr.Class == RecordClassANY || rr.Class == RecordClassNONE) {
+		// The RR with empty RDATA in dynamic update, RFC 2136.
+		return x, nil
+	}
+
 	err = rr.unpackRData(packet, x)
 	if err != nil {
 		return x, fmt.Errorf("%s: %w", logp, err)
@@ -397,12 +463,88 @@ func (rr *ResourceRecord) unpackRData(packet []byte, startIdx uint) (err error)
 	case RecordTypeOPT:
 		return rr.unpackOPT(packet, startIdx)
 
+	case RecordTypeDS:
+		var ds = &RDataDS{}
+		rr.Value = ds
+		endIdx = startIdx + uint(rr.rdlen)
+		return ds.unpack(packet[startIdx:endIdx])
+
+	case RecordTypeRRSIG:
+		var rrsig = &RDataRRSIG{}
+		rr.Value = rrsig
+		endIdx = startIdx + uint(rr.rdlen)
+		return rrsig.unpack(packet, startIdx, endIdx)
+
+	case RecordTypeNSEC:
+		var nsec = &RDataNSEC{}
+		rr.Value = nsec
+		endIdx = startIdx + uint(rr.rdlen)
+		return nsec.unpack(packet, startIdx, endIdx)
+
+	case RecordTypeDNSKEY:
+		var dnskey = &RDataDNSKEY{}
+		rr.Value = dnskey
+		endIdx = startIdx + uint(rr.rdlen)
+		return dnskey.unpack(packet[startIdx:endIdx])
+
+	case RecordTypeNSEC3:
+		var nsec3 = &RDataNSEC3{}
+		rr.Value = nsec3
+		endIdx = startIdx + uint(rr.rdlen)
+		return nsec3.unpack(packet[startIdx:endIdx])
+
+	case RecordTypeNSEC3PARAM:
+		var param = &RDataNSEC3PARAM{}
+		rr.Value = param
+		endIdx = startIdx + uint(rr.rdlen)
+		return param.unpack(packet[startIdx:endIdx])
+
 	case RecordTypeSVCB:
 		return rr.unpackSVCB(packet, startIdx)
 
 	case RecordTypeHTTPS:
 		return rr.unpackHTTPS(packet, startIdx)
 
+	case RecordTypeTSIG:
+		var tsig = &RDataTSIG{}
+		rr.Value = tsig
+		endIdx = startIdx + uint(rr.rdlen)
+		return tsig.unpack(packet, startIdx, endIdx)
+
+	case RecordTypeNAPTR:
+		var naptr = &RDataNAPTR{}
+		rr.Value = naptr
+		endIdx = startIdx + uint(rr.rdlen)
+		return naptr.unpack(packet, startIdx, endIdx)
+
+	case RecordTypeDNAME:
+		rr.Value, _, err = unpackDomainName(packet, startIdx)
+		return err
+
+	case RecordTypeSSHFP:
+		var sshfp = &RDataSSHFP{}
+		rr.Value = sshfp
+		endIdx = startIdx + uint(rr.rdlen)
+		return sshfp.unpack(packet[startIdx:endIdx])
+
+	case RecordTypeTLSA:
+		var tlsa = &RDataTLSA{}
+		rr.Value = tlsa
+		endIdx = startIdx + uint(rr.rdlen)
+		return tlsa.unpack(packet[startIdx:endIdx])
+
+	case RecordTypeURI:
+		var uri = &RDataURI{}
+		rr.Value = uri
+		endIdx = startIdx + uint(rr.rdlen)
+		return uri.unpack(packet[startIdx:endIdx])
+
+	case RecordTypeCAA:
+		var caa = &RDataCAA{}
+		rr.Value = caa
+		endIdx = startIdx + uint(rr.rdlen)
+		return caa.unpack(packet[startIdx:endIdx])
+
 	default:
 		log.Printf("= Unknown query type: %d\n", rr.Type)
 	}
@@ -7,20 +7,30 @@ package dns
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/tls"
 	"encoding/base64"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"net/http"
+	"net/netip"
+	"strings"
 	"sync"
 	"time"
+
+	libhttp "git.sr.ht/~shulhan/pakakeh.go/lib/http"
 )
 
 const (
 	aliveInterval = 10 * time.Second
+
+	// prefetchTimeout define the maximum time to wait for the prefetched
+	// answer from parent name servers.
+	prefetchTimeout = 10 * time.Second
 )
 
 // Server defines DNS server.
@@ -47,6 +57,39 @@ const (
 // The list.List store external answers, ordered by accessed time,
 // it is used to prune least frequently accessed answers.
 //
+// # Administrative API
+//
+// If [ServerOptions.AdminAddress] is set, server serve the HTTP API for
+// managing the internal zones, hosts files, and external caches.
+// Each request must contains the header
+// "Authorization: Bearer <ServerOptions.AdminToken>".
+// The response is a JSON object with the result in field "data", or
+// with the HTTP status code in field "code" and the error in field
+// "message".
+// Each record in request and response use the same format as in
+// [MessageJSON], for example,
+//
+//	{"name": "www.example.com.", "type": 1, "TTL": 3600, "data": "192.0.2.1"}
+//
+// List of endpoints,
+//
+//	GET    /api/caches                  list external caches, ordered by the least recently used
+//	DELETE /api/caches?name=<name>      remove external caches by names
+//	GET    /api/caches/search?query=<regex>
+//	                                    search external caches by name
+//	GET    /api/hosts                   list hosts files
+//	POST   /api/hosts                   create hosts file, with body {"name": <name>}
+//	GET    /api/hosts/:name             get hosts file and its records
+//	DELETE /api/hosts/:name             delete hosts file
+//	POST   /api/hosts/:name/records     add record, A or AAAA, into hosts file
+//	DELETE /api/hosts/:name/records     remove record by name from hosts file
+//	GET    /api/zones                   list internal zones
+//	POST   /api/zones                   create zone, with body {"origin": <origin>, "records": [...]}
+//	GET    /api/zones/:origin           get zone and its records
+//	DELETE /api/zones/:origin           delete zone
+//	POST   /api/zones/:origin/records   add record into zone
+//	DELETE /api/zones/:origin/records   remove record from zone
+//
 // # Debugging
 //
 // If [ServerOptions.Debug] is set to value [DebugLevelCache],
@@ -62,6 +105,8 @@ const (
 //	- : answer is pruned from caches
 //	+ : new answer is added to caches
 //	# : the expired answer is renewed and updated in caches
+//	x : request is blocked by block list
+//	^ : the popular answer is prefetched before its expired
 //
 // Following the prefix is
 //
@@ -70,7 +115,11 @@ const (
 // where each field is, in order,
 //   - SOURCE: source of connection (UDP, TCP, DoH, or DoT)
 //   - FWD_NAME: forwarder name (for example, UDP-0 means request handled by
-//     the first parent name server using UDP) or "-" if its from cache
+//     the first parent name server using UDP) or "-" if its from cache.
+//     The forwarder of [ForwardRule] is prefixed with its first domain,
+//     for example "corp.internal/UDP-0".
+//     The query that is resolved iteratively, if
+//     [ServerOptions.Recursive] is enabled, use "RESOLVER".
 //   - NS: parent name server address for forwarded query or "-" if its from
 //     cache
 //   - MSG_ID: message ID
@@ -82,20 +131,64 @@ const (
 //   - AVG_ELAPSED: average elapsed time per-forwarder
 type Server struct {
 	HostsFiles  map[string]*HostsFile
-	Caches      Caches
 	opts        *ServerOptions
+	validator   *validator
 	tlsConfig   *tls.Config
 	udp         *net.UDPConn
 	tcp         *net.TCPListener
 	doh         *http.Server
 	dot         net.Listener
 	requestq    chan *request
-	primaryq    chan *request
-	tcpq        chan *request
 	errListener chan error
-	fwStoppers  []chan bool
-	fwn         int
-	fwLocker    sync.Mutex
+
+	// fw contains the forwarders for NameServers.
+	fw *forwarders
+
+	// fwSuffixes contains the forwarders of ForwardRules, indexed by
+	// domain suffix.
+	fwSuffixes map[string]*forwarders
+
+	// fwRules contains the forwarders for each of ForwardRules.
+	fwRules []*forwarders
+
+	// fwViews contains the forwarders of Views that has NameServers.
+	fwViews map[*view]*forwarders
+
+	// views contains the split-horizon views from Views.
+	views []*view
+
+	// resolver contains the iterative resolver, or nil if the
+	// Recursive option is disabled.
+	resolver *resolver
+
+	// admin contains the administrative HTTP API, or nil if the
+	// AdminAddress option is empty.
+	admin *admin
+
+	// blocker contains the domains from Blocklists.
+	blocker *blocker
+
+	// rl contains the response rate limiter for UDP, or nil if its
+	// disabled.
+	rl *rateLimiter
+
+	// ql contains the query logger, or nil if its disabled.
+	ql *queryLogger
+
+	// prefetchq limit the number of prefetching in progress, or nil if
+	// the cache prefetching is disabled.
+	prefetchq chan struct{}
+
+	// secondaries contains the secondary zones, indexed by its origin.
+	secondaries map[string]*secondaryZone
+
+	fwStoppers []chan bool
+
+	Caches Caches
+
+	fwLocker        sync.Mutex
+	secondaryLocker sync.Mutex
+	blockLocker     sync.Mutex
 }
 
 // NewServer create and initialize DNS server.
@@ -106,10 +199,9 @@ func NewServer(opts *ServerOptions) (srv *Server, err error) {
 	}
 
 	srv = &Server{
-		opts:     opts,
-		requestq: make(chan *request, 512),
-		primaryq: make(chan *request, 512),
-		tcpq:     make(chan *request, 512),
+		opts:        opts,
+		requestq:    make(chan *request, 512),
+		secondaries: make(map[string]*secondaryZone),
 	}
 
 	var (
@@ -147,6 +239,56 @@ func NewServer(opts *ServerOptions) (srv *Server, err error) {
 		}
 	}
 
+	if opts.DNSSECValidate {
+		srv.validator = newValidator(opts.trustAnchors)
+	}
+	if opts.Recursive {
+		srv.resolver = newResolver(opts.rootHints, opts.Debug)
+	}
+	if len(opts.AdminAddress) != 0 {
+		srv.admin, err = newAdmin(srv)
+		if err != nil {
+			return nil, fmt.Errorf(`dns: %w`, err)
+		}
+	}
+
+	var x int
+	for x = range len(opts.Views) {
+		var v *view
+		v, err = newView(&opts.Views[x], opts)
+		if err != nil {
+			return nil, fmt.Errorf(`dns: view %q: %w`, opts.Views[x].Name, err)
+		}
+		srv.views = append(srv.views, v)
+	}
+
+	srv.initForwarders()
+
+	if len(opts.cookieSecret) == 0 {
+		opts.cookieSecret = make([]byte, 16)
+		_, err = rand.Read(opts.cookieSecret)
+		if err != nil {
+			return nil, fmt.Errorf(`dns: %w`, err)
+		}
+	}
+
+	srv.blocker, err = newBlocker(opts.Blocklists, nil)
+	if err != nil {
+		return nil, fmt.Errorf(`dns: %w`, err)
+	}
+
+	if opts.RateLimitResponses > 0 {
+		srv.rl = newRateLimiter(opts)
+	}
+	if opts.PrefetchThreshold > 0 {
+		srv.prefetchq = make(chan struct{}, opts.PrefetchMaxConcurrent)
+	}
+
+	srv.ql, err = newQueryLogger(opts)
+	if err != nil {
+		return nil, fmt.Errorf(`dns: %w`, err)
+	}
+
 	srv.errListener = make(chan error, 1)
 	srv.Caches.init(opts.PruneDelay, opts.PruneThreshold, opts.Debug)
 
@@ -175,18 +317,169 @@ func isResponseValid(req *request, res *Message) bool {
 	return true
 }
 
+// AddSecondaryZone maintain the secondary copy of zone origin that is
+// transferred from the primary name server at address "ip[:port]".
+//
+// The zone is transferred using AXFR and then refreshed based on the
+// Refresh, Retry, and Expire timers in its SOA, or immediately when the
+// server receive the NOTIFY message for the zone from the primary.
+// Once the zone is expired, it will not be served until the next
+// successful transfer.
+//
+// If file is not empty, the zone is loaded from and saved into it.
+// The secondary zone is stopped when the server stopped.
+func (srv *Server) AddSecondaryZone(origin, primary, file string) (err error) {
+	var sec *secondaryZone
+
+	sec, err = newSecondaryZone(&srv.Caches, origin, primary, file)
+	if err != nil {
+		return fmt.Errorf(`AddSecondaryZone: %w`, err)
+	}
+
+	srv.secondaryLocker.Lock()
+	var old = srv.secondaries[sec.origin]
+	if old != nil {
+		old.stop()
+	}
+	srv.secondaries[sec.origin] = sec
+	srv.secondaryLocker.Unlock()
+
+	go sec.run()
+
+	return nil
+}
+
 // RestartForwarders stop and start new forwarders with new nameserver address
 // and protocol.
 // Empty nameservers means server will run without forwarding request.
+//
+// The forward rules are reloaded from [ServerOptions.ForwardRules], so
+// any changes on it take effect after this call.
+// The caches are not affected by restarting the forwarders.
 func (srv *Server) RestartForwarders(nameServers []string) {
 	log.Printf(`dns: RestartForwarders: %s`, nameServers)
 
+	var (
+		old []*forwarders
+		fw  *forwarders
+		req *request
+		err error
+	)
+
 	srv.opts.NameServers = nameServers
 
 	srv.opts.initNameServers()
 
+	err = srv.opts.initForwardRules()
+	if err != nil {
+		log.Printf(`dns: RestartForwarders: %s`, err)
+	}
+
 	srv.stopAllForwarders()
+	old = srv.initForwarders()
 	srv.startAllForwarders()
+
+	// Move the pending requests on the previous forwarders into the
+	// new one.
+	for _, fw = range old {
+		for _, req = range fw.drain() {
+			if req.race != nil {
+				if req.claim(false) {
+					srv.serveStale(req)
+				}
+				continue
+			}
+			srv.forward(srv.forwardersFor(req), req)
+		}
+	}
+}
+
+// ReloadBlocklists reload all of the lists in [ServerOptions.Blocklists].
+// The hit count of each list is kept as long as its name does not
+// changes.
+// On fail, the server keep using the previous lists.
+func (srv *Server) ReloadBlocklists() (err error) {
+	var (
+		logp = `ReloadBlocklists`
+		bl   *blocker
+	)
+
+	err = srv.opts.initBlocklists()
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	srv.blockLocker.Lock()
+	var prevHits = srv.blocker.hits
+	srv.blockLocker.Unlock()
+
+	bl, err = newBlocker(srv.opts.Blocklists, prevHits)
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	srv.blockLocker.Lock()
+	srv.blocker = bl
+	srv.blockLocker.Unlock()
+
+	return nil
+}
+
+// BlocklistHits return the number of queries that match with each list
+// in [ServerOptions.Blocklists], indexed by list name.
+func (srv *Server) BlocklistHits() (hits map[string]uint64) {
+	srv.blockLocker.Lock()
+	defer srv.blockLocker.Unlock()
+
+	hits = make(map[string]uint64, len(srv.blocker.hits))
+	for name, counter := range srv.blocker.hits {
+		hits[name] = counter.Load()
+	}
+	return hits
+}
+
+// ViewCaches return the caches of view with the given name, or nil if
+// the view does not exist.
+// The caches can be used to populate the view internal zones and hosts,
+// in addition to its HostsDir and ZoneDir.
+func (srv *Server) ViewCaches(name string) *Caches {
+	var v *view
+	for _, v = range srv.views {
+		if v.opts.Name == name {
+			return &v.caches
+		}
+	}
+	return nil
+}
+
+// RateLimitStats return the statistics of response rate limiting on
+// UDP.
+// It return empty statistics if the
+// [ServerOptions.RateLimitResponses] is not set.
+func (srv *Server) RateLimitStats() (stats RateLimitStats) {
+	if srv.rl == nil {
+		return stats
+	}
+	return srv.rl.stats()
+}
+
+// ForwarderStats return the statistics of each forwarder, the
+// connection to parent name servers in [ServerOptions.NameServers] and
+// [ServerOptions.ForwardRules].
+func (srv *Server) ForwarderStats() (stats []ForwarderStat) {
+	var (
+		now = time.Now()
+
+		fw *forwarders
+		up *upstream
+	)
+
+	for _, fw = range srv.allForwarders() {
+		for _, up = range fw.upstreams() {
+			stats = append(stats, up.stat(now))
+		}
+	}
+	return stats
 }
 
 // ListenAndServe start listening and serve queries from clients.
@@ -200,6 +493,9 @@ func (srv *Server) ListenAndServe() (err error) {
 	if srv.opts.HTTPPort > 0 {
 		go srv.serveDoH()
 	}
+	if srv.admin != nil {
+		go srv.serveAdmin()
+	}
 	go srv.serveTCP()
 	go srv.serveUDP()
 
@@ -213,6 +509,8 @@ func (srv *Server) Stop() {
 	)
 
 	srv.stopAllForwarders()
+	srv.stopSecondaries()
+	srv.ql.close()
 
 	err = srv.udp.Close()
 	if err != nil {
@@ -237,6 +535,24 @@ func (srv *Server) Stop() {
 		}
 		srv.doh = nil
 	}
+	if srv.admin != nil {
+		err = srv.admin.http.Stop(0)
+		if err != nil {
+			log.Println("dns: error when closing admin: " + err.Error())
+		}
+	}
+}
+
+// serveAdmin serve the administrative HTTP API.
+func (srv *Server) serveAdmin() {
+	var logp = `serveAdmin`
+
+	log.Printf(`%s: listening at %s`, logp, srv.opts.AdminAddress)
+
+	var err = srv.admin.http.Start()
+	if err != nil {
+		srv.errListener <- fmt.Errorf(`dns: error on admin: %w`, err)
+	}
 }
 
 // serveDoH listen for request over HTTPS using certificate and key
@@ -250,6 +566,7 @@ func (srv *Server) serveDoH() {
 	var mux = http.NewServeMux()
 
 	mux.Handle(`/dns-query`, srv)
+	mux.Handle(`/resolve`, srv)
 
 	srv.doh = &http.Server{
 		Addr:              addr,
@@ -388,6 +705,7 @@ func (srv *Server) serveUDP() {
 			conn:    srv.udp,
 			addr:    raddr,
 		}
+		req.rl = srv.rl
 
 		err = req.message.UnpackHeaderQuestion()
 		if err != nil {
@@ -401,9 +719,20 @@ func (srv *Server) serveUDP() {
 }
 
 // ServeHTTP the main handle for DNS-over-HTTPS.
+//
+// The request with header "Accept: application/dns-json", or with query
+// parameter "name", is handled as DNS JSON API, see [MessageJSON] and
+// [DoHClient.QueryJSON] for its parameters.
 func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	const acceptDNSMessage = `application/dns-message`
-	var hdrAcceptValue = r.Header.Get(`Accept`)
+	var (
+		hdrAcceptValue = r.Header.Get(`Accept`)
+		q              = r.URL.Query()
+	)
+	if hdrAcceptValue == contentTypeDNSJSON || (q.Has(`name`) && !q.Has(`dns`)) {
+		srv.handleDoHJSON(w, r)
+		return
+	}
 	if hdrAcceptValue != acceptDNSMessage {
 		w.WriteHeader(http.StatusUnsupportedMediaType)
 		return
@@ -444,7 +773,35 @@ func (srv *Server) handleDoHGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	srv.handleDoHRequest(raw, w)
+	srv.handleDoHRequest(raw, w, r, false)
+}
+
+// handleDoHJSON handle the DNS JSON API request, with the following
+// format,
+//
+//	GET /resolve?name=<name>[&type=<type>][&cd=<bool>][&do=<bool>][&edns_client_subnet=<cidr>]
+//
+// The response is written as [MessageJSON].
+func (srv *Server) handleDoHJSON(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var (
+		msg *Message
+		err error
+	)
+
+	msg, err = parseMessageJSONQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set(`Content-Type`, contentTypeDNSJSON)
+
+	srv.handleDoHRequest(msg.packet, w, r, true)
 }
 
 func (srv *Server) handleDoHPost(w http.ResponseWriter, r *http.Request) {
@@ -459,16 +816,21 @@ func (srv *Server) handleDoHPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	srv.handleDoHRequest(raw, w)
+	srv.handleDoHRequest(raw, w, r, false)
 }
 
-func (srv *Server) handleDoHRequest(raw []byte, w http.ResponseWriter) {
+// handleDoHRequest pass the raw DNS query to the request queue and wait
+// for its response.
+// If isJSON is true, the response is written in the JSON format.
+func (srv *Server) handleDoHRequest(raw []byte, w http.ResponseWriter, r *http.Request, isJSON bool) {
 	var (
 		logp = `handleDoHRequest`
 		req  = newRequest()
 		cl   = &DoHClient{
-			w:         w,
-			responded: make(chan bool, 1),
+			w:          w,
+			responded:  make(chan bool, 1),
+			clientAddr: srv.dohClientAddr(r),
+			isJSON:     isJSON,
 		}
 
 		err error
@@ -490,28 +852,29 @@ func (srv *Server) handleDoHRequest(raw []byte, w http.ResponseWriter) {
 	cl.waitResponse()
 }
 
-// hasForwarders will return true if server run at least one forwarder,
-// otherwise it will return false.
-func (srv *Server) hasForwarders() (ok bool) {
-	srv.fwLocker.Lock()
-	ok = (srv.fwn > 0)
-	srv.fwLocker.Unlock()
-	return
-}
-
-func (srv *Server) decForwarder() {
-	srv.fwLocker.Lock()
-	srv.fwn--
-	if srv.fwn <= 0 {
-		srv.fwn = 0
+// dohClientAddr return the address of DoH client.
+// If the server is behind proxy, the client address is taken from the
+// HTTP header "X-Real-IP" or "X-Forwarded-For", set by the proxy.
+func (srv *Server) dohClientAddr(r *http.Request) (addr netip.AddrPort) {
+	var (
+		raddr = r.RemoteAddr
+		err   error
+	)
+	if srv.opts.DoHBehindProxy {
+		raddr = libhttp.IPAddressOfRequest(r.Header, r.RemoteAddr)
+	}
+	addr, err = netip.ParseAddrPort(raddr)
+	if err == nil {
+		return addr
 	}
-	srv.fwLocker.Unlock()
-}
 
-func (srv *Server) incForwarder() {
-	srv.fwLocker.Lock()
-	srv.fwn++
-	srv.fwLocker.Unlock()
+	var ip netip.Addr
+
+	ip, err = netip.ParseAddr(raddr)
+	if err != nil {
+		return addr
+	}
+	return netip.AddrPortFrom(ip, 0)
 }
 
 func (srv *Server) serveTCPClient(logp string, cl *TCPClient, kind string) {
@@ -559,7 +922,11 @@ func (srv *Server) isImplemented(msg *Message) bool {
 	switch msg.Question.Type {
 	case RecordTypeAAAA, RecordTypeSRV, RecordTypeOPT, RecordTypeAXFR,
 		RecordTypeMAILB, RecordTypeMAILA, RecordTypeANY,
-		RecordTypeSVCB, RecordTypeHTTPS:
+		RecordTypeSVCB, RecordTypeHTTPS, RecordTypeDS, RecordTypeRRSIG,
+		RecordTypeNSEC, RecordTypeDNSKEY, RecordTypeNSEC3,
+		RecordTypeNSEC3PARAM, RecordTypeIXFR, RecordTypeNAPTR,
+		RecordTypeDNAME, RecordTypeSSHFP, RecordTypeTLSA, RecordTypeURI,
+		RecordTypeCAA:
 		return true
 	}
 
@@ -571,6 +938,7 @@ func (srv *Server) isImplemented(msg *Message) bool {
 // processRequest from client.
 func (srv *Server) processRequest() {
 	var (
+		fw  *forwarders
 		an  *Answer
 		res *Message
 		req *request
@@ -578,49 +946,75 @@ func (srv *Server) processRequest() {
 	)
 
 	for req = range srv.requestq {
+		req.ql = srv.ql
+		srv.ql.clientQuery(req)
+
 		if !srv.isImplemented(req.message) {
 			req.error(RCodeNotImplemented)
 			continue
 		}
+		if !srv.initEDNS(req) {
+			continue
+		}
 
 		if srv.opts.Debug&DebugLevelCache != 0 {
 			log.Printf(`> %s - - %s - - -`, req.kind, req.String())
 		}
 
-		an = srv.Caches.query(req.message)
+		switch req.message.Header.Op {
+		case OpCodeNotify:
+			srv.serveNotify(req)
+			continue
+		case OpCodeUpdate:
+			go srv.serveUpdate(req)
+			continue
+		}
+
+		switch req.message.Question.Type {
+		case RecordTypeAXFR, RecordTypeIXFR:
+			go srv.serveTransfer(req)
+			continue
+		}
+
+		if srv.block(req) {
+			continue
+		}
+
+		req.view = srv.viewFor(req.remoteIP())
+		fw = srv.forwardersFor(req)
+
+		an = srv.cachesFor(req).query(req.message)
 		if an == nil {
 			switch {
-			case srv.hasForwarders():
-				if req.kind == connTypeTCP {
-					srv.tcpq <- req
-				} else {
-					srv.primaryq <- req
-				}
+			case srv.isRecursive(fw):
+				go srv.resolve(req)
+			case fw.isActive():
+				srv.forward(fw, req)
 			default:
 				if srv.opts.Debug&DebugLevelCache != 0 {
 					log.Printf(`* %s - - %s - - -: no active forwarders`,
 						req.kind, req.String())
 				}
-				req.error(RCodeErrServer)
+				srv.noForwarders(req)
 			}
 			continue
 		}
 
 		if an.Message.IsExpired() {
 			switch {
-			case srv.hasForwarders():
-				if req.kind == connTypeTCP {
-					srv.tcpq <- req
-				} else {
-					srv.primaryq <- req
-				}
+			case srv.isRecursive(fw):
+				go srv.resolve(req)
+			case fw.isActive():
+				srv.forward(fw, req)
 
+			case srv.serveStale(req):
+				// The stale answer has been sent.
 			default:
 				if srv.opts.Debug&DebugLevelCache != 0 {
 					log.Printf(`* %s - - %s - - -: answer is expired and no active forwarders`,
 						req.kind, req.String())
 				}
-				req.error(RCodeErrServer)
+				srv.noForwarders(req)
 			}
 			continue
 		}
@@ -628,8 +1022,9 @@ func (srv *Server) processRequest() {
 		an.Message.SetID(req.message.Header.ID)
 		an.updateTTL()
 		res = an.Message
+		req.cacheHit = true
 
-		_, err = req.writer.Write(res.packet)
+		_, err = req.write(res.packet)
 		if err != nil {
 			log.Printf(`! %s - - %s - - -: %s`, req.kind, an.String(), err)
 			continue
@@ -638,115 +1033,763 @@ func (srv *Server) processRequest() {
 		if srv.opts.Debug&DebugLevelCache != 0 {
 			log.Printf(`< %s - - %s - - -`, req.kind, an.String())
 		}
+
+		if srv.prefetchq != nil {
+			srv.prefetch(fw, req.view, an)
+		}
 	}
 }
 
-func (srv *Server) processResponse(req *request, res *Message) (an *Answer, inserted bool, err error) {
-	if !isResponseValid(req, res) {
-		req.error(RCodeErrServer)
-		return nil, false, errors.New(`invalid response`)
+// serveTransfer serve the zone transfer request, AXFR or IXFR, of internal
+// zone.
+// The request is refused if the client address is not allowed by
+// [ServerOptions.TransferAllow], if the zone is not found, or if the AXFR
+// is requested through UDP.
+// The IXFR through UDP is answered with the current SOA only, as
+// described in RFC 1995 section 2, so client can retry using TCP.
+func (srv *Server) serveTransfer(req *request) {
+	var (
+		ip = req.remoteIP()
+
+		msg *Message
+		err error
+	)
+
+	if !srv.opts.isTransferAllowed(ip) {
+		log.Printf(`! %s - - %s - - -: transfer is not allowed from %s`,
+			req.kind, req.String(), ip)
+		req.error(RCodeRefused)
+		return
+	}
+
+	var isUDP = req.kind == connTypeUDP
+	if isUDP && req.message.Question.Type == RecordTypeAXFR {
+		req.error(RCodeRefused)
+		return
 	}
 
-	_, err = req.writer.Write(res.packet)
+	msg, err = UnpackMessage(req.message.packet)
 	if err != nil {
-		return nil, false, err
+		log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
+		req.error(RCodeErrFormat)
+		return
 	}
 
-	if res.Header.RCode != 0 {
-		err = errors.New(`response has error code ` + rcodeNames[res.Header.RCode])
-		return nil, false, err
+	var serial uint32
+	if msg.Question.Type == RecordTypeIXFR {
+		var soa *RDataSOA
+		if len(msg.Authority) > 0 {
+			soa, _ = msg.Authority[0].Value.(*RDataSOA)
+		}
+		if soa == nil {
+			req.error(RCodeErrFormat)
+			return
+		}
+		serial = soa.Serial
 	}
-	if res.Header.IsTC {
-		err = errors.New(`response truncated`)
-		return nil, false, err
+
+	var list, ok = srv.Caches.transferRecords(msg, serial)
+	if !ok {
+		req.error(RCodeRefused)
+		return
 	}
-	if res.Header.ANCount == 0 && res.Question.Type == RecordTypeA {
-		// Ignore empty answers only for query type A (IPv4).
-		//
-		// The use case if one use and switch between two different
-		// networks with internal zone, frequently.
-		// For example, if on network Y they have domain MY.Y and
-		// current connection is X, request to MY.Y will return an
-		// empty answers.
-		// Once they connect to Y again, any request to MY.Y will not
-		// be possible because caches contains empty answer for MY.Y.
-		//
-		// Some domains that still have an empty answer for AAAA(28)
-		// and HTTPS(65) will get cached.
-		err = errors.New(`empty RR answer`)
-		return nil, false, err
+	if isUDP && len(list) > 1 {
+		list = list[:1]
 	}
 
-	an = newAnswer(res, false)
+	var msgs []*Message
 
-	if srv.opts.OnAnswerReceived != nil {
-		srv.opts.OnAnswerReceived(an)
+	msgs, err = transferMessages(msg, list)
+	if err != nil {
+		log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
+		req.error(RCodeErrServer)
+		return
+	}
+	for _, msg = range msgs {
+		_, err = req.write(msg.packet)
+		if err != nil {
+			log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
+			return
+		}
 	}
+	if srv.opts.Debug&DebugLevelCache != 0 {
+		log.Printf(`< %s - - %s - - -: %d records in %d messages`,
+			req.kind, req.String(), len(list), len(msgs))
+	}
+}
 
-	an, inserted = srv.Caches.upsert(an)
+// serveNotify serve the NOTIFY message, as described in RFC 1996, from the
+// primary name server of secondary zone.
+// The message is refused if its not coming from the primary address.
+func (srv *Server) serveNotify(req *request) {
+	var origin = strings.ToLower(toDomainAbsolute(req.message.Question.Name))
 
-	return an, inserted, nil
-}
+	srv.secondaryLocker.Lock()
+	var sec = srv.secondaries[origin]
+	srv.secondaryLocker.Unlock()
 
-func (srv *Server) startAllForwarders() {
-	srv.fwStoppers = nil
+	if sec == nil {
+		log.Printf(`! %s - - %s - - -: notify for unknown zone`,
+			req.kind, req.String())
+		req.error(RCodeNotAuth)
+		return
+	}
+
+	var ip = req.remoteIP()
+	if !sec.primaryIP.Equal(ip) {
+		log.Printf(`! %s - - %s - - -: notify is not allowed from %s`,
+			req.kind, req.String(), ip)
+		req.error(RCodeRefused)
+		return
+	}
 
 	var (
-		tag        string
-		nameserver string
-		x          int
+		res = &Message{
+			Header: MessageHeader{
+				ID:      req.message.Header.ID,
+				Op:      OpCodeNotify,
+				IsAA:    true,
+				QDCount: 1,
+			},
+			Question: req.message.Question,
+		}
+		err error
 	)
 
-	for x = range len(srv.opts.primaryUDP) {
-		tag = fmt.Sprintf(`UDP-%d`, x)
-		nameserver = srv.opts.primaryUDP[x].String()
-		go srv.udpForwarder(tag, nameserver)
-	}
-	for x = range len(srv.opts.primaryTCP) {
-		tag = fmt.Sprintf(`TCP-%d`, x)
-		nameserver = srv.opts.primaryTCP[x].String()
-		go srv.tcpForwarder(tag, nameserver)
+	_, err = res.Pack()
+	if err != nil {
+		log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
+		req.error(RCodeErrServer)
+		return
 	}
-	for x = range len(srv.opts.primaryDoh) {
-		tag = fmt.Sprintf(`DoH-%d`, x)
-		nameserver = srv.opts.primaryDoh[x]
-		go srv.dohForwarder(tag, nameserver)
+	_, err = req.write(res.packet)
+	if err != nil {
+		log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
 	}
-	for x = range len(srv.opts.primaryDot) {
-		tag = fmt.Sprintf(`DoT-%d`, x)
-		nameserver = srv.opts.primaryDot[x]
-		go srv.tlsForwarder(tag, nameserver)
+
+	sec.notified()
+
+	if srv.opts.Debug&DebugLevelCache != 0 {
+		log.Printf(`< %s - - %s - - -: notify`, req.kind, req.String())
 	}
 }
 
-func (srv *Server) dohForwarder(tag, nameserver string) {
+// serveUpdate serve the dynamic update request on internal zone, as
+// described in RFC 2136.
+// The request must be signed using one of the TSIG key in the zone, set
+// using [Zone.SetTSIGKeys].
+// The response is signed using the same key.
+func (srv *Server) serveUpdate(req *request) {
 	var (
-		stopper = srv.newStopper()
-
-		forwarder *DoHClient
-		ticker    *time.Ticker
-		req       *request
-		res       *Message
-		err       error
-		isRunning bool
-		ok        bool
+		msg *Message
+		err error
 	)
 
-	defer func() {
-		log.Printf(`%s: forwarder for %s has been stopped`, tag, nameserver)
-	}()
+	msg, err = UnpackMessage(req.message.packet)
+	if err != nil {
+		log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
+		req.error(RCodeErrFormat)
+		return
+	}
+	if msg.Header.QDCount != 1 || msg.Question.Type != RecordTypeSOA {
+		req.error(RCodeErrFormat)
+		return
+	}
 
-	var totalElapsed int64
-	var totalQuery int64
+	var zone = srv.Caches.internalZoneByOrigin(msg.Question.Name)
+	if zone == nil {
+		req.error(RCodeNotAuth)
+		return
+	}
+
+	var rrTSIG, tsig = msg.tsig()
+	if tsig == nil || len(zone.tsigKeys) == 0 {
+		log.Printf(`! %s - - %s - - -: unsigned update`, req.kind, req.String())
+		req.error(RCodeRefused)
+		return
+	}
+
+	var res = &Message{
+		Header: MessageHeader{
+			ID:      msg.Header.ID,
+			Op:      OpCodeUpdate,
+			QDCount: 1,
+		},
+		Question: msg.Question,
+	}
+
+	var (
+		key = zone.tsigKey(rrTSIG.Name)
+
+		reqMAC []byte
+	)
+	if key == nil {
+		err = ErrTSIGBadKey
+	} else {
+		reqMAC, err = key.Verify(msg, nil)
+	}
+	if err != nil {
+		log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
+
+		res.Header.RCode = RCodeNotAuth
+		switch {
+		case errors.Is(err, ErrTSIGBadTime):
+			_, err = key.sign(res, reqMAC, TSIGErrBadTime)
+		case errors.Is(err, ErrTSIGBadKey):
+			err = res.tsigError(rrTSIG.Name, tsig, TSIGErrBadKey)
+		default:
+			err = res.tsigError(rrTSIG.Name, tsig, TSIGErrBadSig)
+		}
+	} else {
+		res.Header.RCode, err = srv.Caches.updateZone(zone, msg)
+		if err != nil {
+			log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
+		}
+		_, err = key.sign(res, reqMAC, 0)
+	}
+	if err != nil {
+		log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
+		req.error(RCodeErrServer)
+		return
+	}
+
+	_, err = req.write(res.packet)
+	if err != nil {
+		log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
+		return
+	}
+	if srv.opts.Debug&DebugLevelCache != 0 {
+		log.Printf(`< %s - - %s - - -: update %s`, req.kind,
+			req.String(), rcodeNames[res.Header.RCode])
+	}
+}
+
+// forwardersFor return the forwarders for the request.
+// If the request view has NameServers, it return the view forwarders.
+// Otherwise, it return the forwarders of ForwardRules with the longest
+// domain suffix that match with query name, or the forwarders of
+// NameServers if no rules match.
+func (srv *Server) forwardersFor(req *request) (fw *forwarders) {
+	var (
+		qname = req.message.Question.Name
+		x     int
+	)
+
+	srv.fwLocker.Lock()
+	defer srv.fwLocker.Unlock()
+
+	if req.view != nil {
+		fw = srv.fwViews[req.view]
+		if fw != nil {
+			return fw
+		}
+	}
+	if len(srv.fwSuffixes) == 0 {
+		return srv.fw
+	}
+
+	qname = strings.ToLower(qname)
+	for len(qname) > 0 {
+		fw = srv.fwSuffixes[qname]
+		if fw != nil {
+			return fw
+		}
+		x = strings.IndexByte(qname, '.')
+		if x < 0 {
+			break
+		}
+		qname = qname[x+1:]
+	}
+	return srv.fw
+}
+
+// forward the request to the parent name servers.
+func (srv *Server) forward(fw *forwarders, req *request) {
+	var err = req.setForwardOPT(srv.opts, srv.validator != nil)
+	if err != nil {
+		log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
+		req.error(RCodeErrFormat)
+		return
+	}
+	if !fw.dispatch(req) {
+		if srv.opts.Debug&DebugLevelCache != 0 {
+			log.Printf(`* %s - - %s - - -: no active forwarders`,
+				req.kind, req.String())
+		}
+		srv.noForwarders(req)
+	}
+}
+
+// isRecursive return true if the query that use the forwarders fw should
+// be resolved iteratively, that is if the Recursive option is enabled and
+// fw is the forwarders of NameServers.
+func (srv *Server) isRecursive(fw *forwarders) bool {
+	if srv.resolver == nil {
+		return false
+	}
+	srv.fwLocker.Lock()
+	var isDefault = fw == srv.fw
+	srv.fwLocker.Unlock()
+	return isDefault
+}
+
+// resolve the request iteratively from the root name servers, and write
+// the response back to client.
+// If the resolution failed, the request is replied with the stale answer,
+// if its exist, or with SERVFAIL.
+func (srv *Server) resolve(req *request) {
+	var err = req.setForwardOPT(srv.opts, srv.validator != nil)
+	if err != nil {
+		log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
+		req.error(RCodeErrFormat)
+		return
+	}
+
+	var (
+		queryAt = time.Now()
+		res     *Message
+	)
+
+	res, err = srv.resolver.Query(req.message)
+
+	srv.ql.forwarder(req, connTypeUDP, srv.resolver.RemoteAddr(), queryAt, res)
+
+	if err != nil {
+		log.Printf(`! %s RESOLVER - %s - - -: %s`, req.kind,
+			req.String(), err)
+		if !srv.serveStale(req) {
+			req.ede = &EDNSExtendedError{
+				InfoCode:  EDENoReachableAuthority,
+				ExtraText: err.Error(),
+			}
+			req.error(RCodeErrServer)
+		}
+		return
+	}
+
+	var (
+		an         *Answer
+		isInserted bool
+	)
+
+	an, isInserted, err = srv.processResponse(req, res, srv.resolver)
+	var elapsed = time.Since(req.startAt)
+	if err != nil {
+		log.Printf(`! %s RESOLVER - %s %v - -: %s`, req.kind,
+			req.String(), elapsed, err)
+		return
+	}
+	if srv.opts.Debug&DebugLevelCache != 0 {
+		if isInserted {
+			log.Printf(`+ %s RESOLVER - %s %v - -`, req.kind,
+				an.String(), elapsed)
+		} else {
+			log.Printf(`# %s RESOLVER - %s %v - -`, req.kind,
+				an.String(), elapsed)
+		}
+	}
+}
+
+// noForwarders reply the request with SERVFAIL, since there is no active
+// forwarders to resolve it.
+func (srv *Server) noForwarders(req *request) {
+	req.ede = &EDNSExtendedError{
+		InfoCode:  EDENoReachableAuthority,
+		ExtraText: `no active forwarders`,
+	}
+	req.error(RCodeErrServer)
+}
+
+// prefetch refresh the popular answer, whose TTL is nearly expired, from
+// parent name servers in the background, so the next clients does not
+// need to wait for the forwarded query.
+// The answer is skipped if the number of prefetching in progress has
+// reached the PrefetchMaxConcurrent.
+func (srv *Server) prefetch(fw *forwarders, v *view, an *Answer) {
+	select {
+	case srv.prefetchq <- struct{}{}:
+	default:
+		return
+	}
+
+	var (
+		req = newRequest()
+		w   = &prefetchWriter{
+			done: make(chan struct{}),
+		}
+	)
+
+	req.view = v
+
+	if !srv.cachesFor(req).needPrefetch(an, srv.opts.PrefetchThreshold,
+		int64(srv.opts.PrefetchMinHits)) {
+		<-srv.prefetchq
+		return
+	}
+
+	req.writer = w
+	req.kind = connTypeUDP
+	req.message.Header.ID = getNextID()
+	req.message.Question.Name = an.QName
+	req.message.Question.Type = an.RType
+	req.message.Question.Class = an.RClass
+
+	var _, err = req.message.Pack()
+	if err != nil {
+		log.Printf(`! %s - - %s - - -: prefetch: %s`, req.kind,
+			req.String(), err)
+		<-srv.prefetchq
+		return
+	}
+
+	if srv.opts.Debug&DebugLevelCache != 0 {
+		log.Printf(`^ %s - - %s - - -: prefetch`, req.kind, an.String())
+	}
+
+	go func() {
+		defer func() {
+			<-srv.prefetchq
+		}()
+		if srv.isRecursive(fw) {
+			srv.resolve(req)
+			return
+		}
+		if !fw.isActive() {
+			return
+		}
+		srv.forward(fw, req)
+		select {
+		case <-w.done:
+		case <-time.After(prefetchTimeout):
+		}
+	}()
+}
+
+// viewFor return the first view whose networks contains the client ip
+// address, or nil if no views match.
+func (srv *Server) viewFor(ip net.IP) *view {
+	if ip == nil {
+		return nil
+	}
+	var v *view
+	for _, v = range srv.views {
+		if v.opts.match(ip) {
+			return v
+		}
+	}
+	return nil
+}
+
+// cachesFor return the caches of request view, or the server caches if
+// the request does not have view.
+func (srv *Server) cachesFor(req *request) *Caches {
+	if req.view != nil {
+		return &req.view.caches
+	}
+	return &srv.Caches
+}
+
+// block reply the request based on the rule in block lists.
+// It return false if the query name is not blocked.
+func (srv *Server) block(req *request) bool {
+	var rule *blockRule
+
+	srv.blockLocker.Lock()
+	rule = srv.blocker.match(req.message.Question.Name)
+	srv.blockLocker.Unlock()
+
+	if rule == nil {
+		return false
+	}
+	rule.hits.Add(1)
+	if rule.isAllow {
+		return false
+	}
+
+	req.ede = &EDNSExtendedError{InfoCode: EDEBlocked}
+
+	var (
+		res *Message
+		err error
+	)
+
+	res, err = rule.response(req.message)
+	if err != nil {
+		log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
+		req.error(RCodeErrServer)
+		return true
+	}
+
+	_, err = req.write(res.packet)
+	if err != nil {
+		log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
+		return true
+	}
+
+	if srv.opts.Debug&DebugLevelCache != 0 {
+		log.Printf(`x %s - - %s - - -: %s`, req.kind, req.String(), rule.action)
+	}
+	return true
+}
+
+// serveStale reply the request with the expired answer from caches, as
+// described in RFC 8767.
+// It return false if the serve-stale is disabled or there is no stale
+// answer for the request.
+func (srv *Server) serveStale(req *request) bool {
+	if srv.opts.StaleWindow <= 0 {
+		return false
+	}
+
+	var packet = srv.cachesFor(req).stale(req.message, srv.opts.StaleWindow,
+		srv.opts.StaleTTL)
+	if packet == nil {
+		return false
+	}
+
+	binary.BigEndian.PutUint16(packet, req.message.Header.ID)
+
+	req.ede = &EDNSExtendedError{InfoCode: EDEStaleAnswer}
+	req.cacheHit = true
+
+	var _, err = req.write(packet)
+	if err != nil {
+		log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
+		return true
+	}
+	if srv.opts.Debug&DebugLevelCache != 0 {
+		log.Printf(`~ %s - - %s - - -: stale answer`, req.kind,
+			req.String())
+	}
+	return true
+}
+
+// processResponse validate the response from forwarder cl, write it to the
+// client, and store it in the caches.
+func (srv *Server) processResponse(req *request, res *Message, cl Client) (an *Answer, inserted bool, err error) {
+	if !isResponseValid(req, res) {
+		req.error(RCodeErrServer)
+		return nil, false, errors.New(`invalid response`)
+	}
+
+	if srv.validator != nil && !res.Header.IsTC &&
+		(res.Header.RCode == RCodeOK || res.Header.RCode == RCodeErrName) {
+		var isSecure bool
+
+		isSecure, err = srv.validator.validate(cl, res)
+		if err != nil && req.message.Header.IsCD {
+			// The client disable the checking, so pass the bogus
+			// response as is but do not store it in the caches,
+			// as described in RFC 4035 section 3.2.2.
+			res.SetAuthenticData(false)
+
+			var errWrite error
+
+			_, errWrite = req.write(res.packet)
+			if errWrite != nil {
+				return nil, false, errWrite
+			}
+			return nil, false, fmt.Errorf(`bogus response with checking disabled: %w`, err)
+		}
+		if err != nil {
+			req.ede = &EDNSExtendedError{
+				InfoCode:  EDEDNSSECBogus,
+				ExtraText: err.Error(),
+			}
+			req.error(RCodeErrServer)
+			return nil, false, fmt.Errorf(`bogus response: %w`, err)
+		}
+		res.SetAuthenticData(isSecure)
+	}
+
+	_, err = req.write(res.packet)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var opt = res.opt(false)
+	if opt != nil {
+		var ecs, _ = opt.ClientSubnet()
+		if ecs != nil && ecs.ScopePrefix > 0 {
+			return nil, false, errors.New(`answer with ECS scope is not cached`)
+		}
+	}
+
+	var isNegative = srv.opts.NegativeCacheMaxTTL > 0 && res.isNegative()
+
+	if res.Header.RCode != 0 && !isNegative {
+		err = errors.New(`response has error code ` + rcodeNames[res.Header.RCode])
+		return nil, false, err
+	}
+	if res.Header.IsTC {
+		err = errors.New(`response truncated`)
+		return nil, false, err
+	}
+	if isNegative {
+		// The negative answer is cached with the TTL from SOA in
+		// the authority, so it will not stay forever.
+		res.setNegativeTTL(uint32(srv.opts.NegativeCacheMaxTTL.Seconds()))
+	} else if res.Header.ANCount == 0 && res.Question.Type == RecordTypeA {
+		// Ignore empty answers only for query type A (IPv4).
+		//
+		// The use case if one use and switch between two different
+		// networks with internal zone, frequently.
+		// For example, if on network Y they have domain MY.Y and
+		// current connection is X, request to MY.Y will return an
+		// empty answers.
+		// Once they connect to Y again, any request to MY.Y will not
+		// be possible because caches contains empty answer for MY.Y.
+		//
+		// Some domains that still have an empty answer for AAAA(28)
+		// and HTTPS(65) will get cached.
+		err = errors.New(`empty RR answer`)
+		return nil, false, err
+	}
+
+	an = newAnswer(res, false)
+
+	if srv.opts.OnAnswerReceived != nil {
+		srv.opts.OnAnswerReceived(an)
+	}
+
+	an, inserted = srv.cachesFor(req).upsert(an)
+
+	return an, inserted, nil
+}
+
+// initForwarders create the forwarders for NameServers and for each of
+// ForwardRules.
+// It return the previous forwarders.
+func (srv *Server) initForwarders() (old []*forwarders) {
+	var (
+		fwSuffixes = make(map[string]*forwarders)
+		fwDefault  = newForwarders(``, srv.opts.ForwardStrategy,
+			srv.opts.primaryUDP, srv.opts.primaryTCP,
+			srv.opts.primaryDoh, srv.opts.primaryDot)
+
+		fwViews = make(map[*view]*forwarders, len(srv.views))
+
+		fwRules []*forwarders
+		rule    *ForwardRule
+		fw      *forwarders
+		v       *view
+		suffix  string
+		x       int
+	)
+
+	for x = range len(srv.opts.ForwardRules) {
+		rule = &srv.opts.ForwardRules[x]
+		if len(rule.suffixes) == 0 {
+			// The rule is not initialized or invalid.
+			continue
+		}
+		fw = newForwarders(rule.suffixes[0]+`/`,
+			srv.opts.ForwardStrategy, rule.primaryUDP,
+			rule.primaryTCP, rule.primaryDoh, rule.primaryDot)
+		for _, suffix = range rule.suffixes {
+			fwSuffixes[suffix] = fw
+		}
+		fwRules = append(fwRules, fw)
+	}
+
+	for _, v = range srv.views {
+		if len(v.opts.NameServers) == 0 {
+			continue
+		}
+		fwViews[v] = newForwarders(v.opts.Name+`/`,
+			srv.opts.ForwardStrategy, v.opts.primaryUDP,
+			v.opts.primaryTCP, v.opts.primaryDoh, v.opts.primaryDot)
+	}
+
+	srv.fwLocker.Lock()
+	if srv.fw != nil {
+		old = append(old, srv.fw)
+		old = append(old, srv.fwRules...)
+		for _, fw = range srv.fwViews {
+			old = append(old, fw)
+		}
+	}
+	srv.fw = fwDefault
+	srv.fwSuffixes = fwSuffixes
+	srv.fwRules = fwRules
+	srv.fwViews = fwViews
+	srv.fwLocker.Unlock()
+
+	return old
+}
+
+func (srv *Server) startAllForwarders() {
+	var fw *forwarders
+
+	srv.fwLocker.Lock()
+	srv.fwStoppers = nil
+	srv.fwLocker.Unlock()
+
+	for _, fw = range srv.allForwarders() {
+		srv.startForwarders(fw)
+	}
+}
+
+// allForwarders return the forwarders for NameServers, ForwardRules, and
+// Views.
+func (srv *Server) allForwarders() (list []*forwarders) {
+	var v *view
+
+	srv.fwLocker.Lock()
+	list = append(list, srv.fw)
+	list = append(list, srv.fwRules...)
+	for _, v = range srv.views {
+		if srv.fwViews[v] != nil {
+			list = append(list, srv.fwViews[v])
+		}
+	}
+	srv.fwLocker.Unlock()
+
+	return list
+}
+
+// startForwarders start the forwarder for each parent name servers in
+// fw that consume the requests from fw queues.
+func (srv *Server) startForwarders(fw *forwarders) {
+	var up *upstream
+	for _, up = range fw.primary {
+		switch up.proto {
+		case connTypeUDP:
+			go srv.udpForwarder(fw, up)
+		case connTypeDoH:
+			go srv.dohForwarder(fw, up)
+		case connTypeDoT:
+			go srv.tlsForwarder(fw, up)
+		}
+	}
+	for _, up = range fw.tcp {
+		go srv.tcpForwarder(up)
+	}
+}
+
+func (srv *Server) dohForwarder(fw *forwarders, up *upstream) {
+	var (
+		stopper = srv.newStopper()
+
+		forwarder *DoHClient
+		ticker    *time.Ticker
+		req       *request
+		err       error
+		isRunning bool
+	)
+
+	defer func() {
+		log.Printf(`%s: forwarder for %s has been stopped`, up.tag, up.nameserver)
+	}()
 
 	for {
-		forwarder, err = NewDoHClient(nameserver, false)
+		forwarder, err = NewDoHClient(up.nameserver, false)
 		if err != nil {
-			log.Printf(`%s: failed to connect to %s: %s`, tag, nameserver, err)
+			log.Printf(`%s: failed to connect to %s: %s`, up.tag, up.nameserver, err)
 
 			select {
 			case <-stopper:
-				srv.stopForwarder(nil)
+				srv.stopForwarder(up, nil)
 				return
 			default:
 				time.Sleep(3 * time.Second)
@@ -754,101 +1797,56 @@ func (srv *Server) dohForwarder(tag, nameserver string) {
 			continue
 		}
 
-		log.Printf(`%s: connected to namesever %s`, tag, nameserver)
+		log.Printf(`%s: connected to namesever %s`, up.tag, up.nameserver)
 
-		srv.incForwarder()
+		up.setConnected(true)
 
 		isRunning = true
 		ticker = time.NewTicker(aliveInterval)
 		for isRunning {
 			select {
-			case req, ok = <-srv.primaryq:
-				if !ok {
-					log.Printf(`%s: primary queue has been closed`,
-						tag)
-					srv.stopForwarder(forwarder)
-					return
-				}
-				res, err = forwarder.Query(req.message)
-				if err != nil {
-					log.Printf(`! %s %s %s %s - - -: forward failed %s`,
-						req.kind, tag, nameserver,
-						req.String(), err)
-					if !errors.Is(err, errInvalidMessage) {
-						isRunning = false
-					}
-					continue
-				}
-
-				an, isInserted, err := srv.processResponse(req, res)
-				elapsed := time.Since(req.startAt)
-				totalElapsed += int64(elapsed)
-				totalQuery++
-				avgElapsed := time.Duration(totalElapsed / totalQuery)
-				if err != nil {
-					log.Printf(`! %s %s %s %s %v %d %v: %s`,
-						req.kind, tag, nameserver,
-						req.String(),
-						elapsed, totalQuery, avgElapsed, err)
-					continue
-				}
-				if srv.opts.Debug&DebugLevelCache != 0 {
-					if isInserted {
-						log.Printf(`+ %s %s %s %s %v %d %v`,
-							req.kind,
-							tag, nameserver, an.String(),
-							elapsed, totalQuery, avgElapsed)
-					} else {
-						log.Printf(`# %s %s %s %s %v %d %v`,
-							req.kind,
-							tag, nameserver, an.String(),
-							elapsed, totalQuery, avgElapsed)
-					}
-				}
+			case req = <-up.queue:
+				isRunning = srv.forwardTo(up, forwarder, req)
 
 			case <-ticker.C:
 				if srv.opts.Debug&DebugLevelConnPacket != 0 {
-					log.Printf(`%s: alive`, tag)
+					log.Printf(`%s: alive`, up.tag)
 				}
 			case <-stopper:
-				srv.stopForwarder(forwarder)
+				srv.stopForwarder(up, forwarder)
 				return
 			}
 		}
 
-		log.Printf(`%s: reconnect to nameserver %s`, tag, nameserver)
-		srv.stopForwarder(forwarder)
+		log.Printf(`%s: reconnect to nameserver %s`, up.tag, up.nameserver)
+		srv.stopForwarder(up, forwarder)
+		srv.redispatch(fw, up)
 	}
 }
 
-func (srv *Server) tlsForwarder(tag, nameserver string) {
+func (srv *Server) tlsForwarder(fw *forwarders, up *upstream) {
 	var (
 		stopper = srv.newStopper()
 
 		forwarder *DoTClient
 		ticker    *time.Ticker
 		req       *request
-		res       *Message
 		err       error
 		isRunning bool
-		ok        bool
 	)
 
 	defer func() {
-		log.Printf(`%s: forwarder for %s has been stopped`, tag, nameserver)
+		log.Printf(`%s: forwarder for %s has been stopped`, up.tag, up.nameserver)
 	}()
 
-	var totalElapsed int64
-	var totalQuery int64
-
 	for {
-		forwarder, err = NewDoTClient(nameserver, srv.opts.TLSAllowInsecure)
+		forwarder, err = NewDoTClient(up.nameserver, srv.opts.TLSAllowInsecure)
 		if err != nil {
-			log.Printf(`%s: failed to connect to %s: %s`, tag, nameserver, err)
+			log.Printf(`%s: failed to connect to %s: %s`, up.tag, up.nameserver, err)
 
 			select {
 			case <-stopper:
-				srv.stopForwarder(nil)
+				srv.stopForwarder(up, nil)
 				return
 			default:
 				time.Sleep(3 * time.Second)
@@ -856,147 +1854,73 @@ func (srv *Server) tlsForwarder(tag, nameserver string) {
 			continue
 		}
 
-		log.Printf(`%s: connected to nameserver %s`, tag, nameserver)
+		log.Printf(`%s: connected to nameserver %s`, up.tag, up.nameserver)
 
-		srv.incForwarder()
+		up.setConnected(true)
 
 		isRunning = true
 		ticker = time.NewTicker(aliveInterval)
 		for isRunning {
 			select {
-			case req, ok = <-srv.primaryq:
-				if !ok {
-					log.Printf(`%s: primary queue has been closed`, tag)
-					srv.stopForwarder(forwarder)
-					return
-				}
-
-				res, err = forwarder.Query(req.message)
-				if err != nil {
-					log.Printf(`! %s %s %s %s - - -: forward failed %s`,
-						req.kind, tag, nameserver,
-						req.String(), err)
-					if !errors.Is(err, errInvalidMessage) {
-						isRunning = false
-					}
-					continue
-				}
-
-				an, isInserted, err := srv.processResponse(req, res)
-				elapsed := time.Since(req.startAt)
-				totalElapsed += int64(elapsed)
-				totalQuery++
-				avgElapsed := time.Duration(totalElapsed / totalQuery)
-				if err != nil {
-					log.Printf(`! %s %s %s %s %v %d %v: %s`,
-						req.kind, tag, nameserver,
-						req.String(),
-						elapsed, totalQuery, avgElapsed, err)
-					continue
-				}
-				if srv.opts.Debug&DebugLevelCache != 0 {
-					if isInserted {
-						log.Printf(`+ %s %s %s %s %v %d %v`,
-							req.kind,
-							tag, nameserver, an.String(),
-							elapsed, totalQuery, avgElapsed)
-					} else {
-						log.Printf(`# %s %s %s %s %v %d %v`,
-							req.kind,
-							tag, nameserver, an.String(),
-							elapsed, totalQuery, avgElapsed)
-					}
-				}
+			case req = <-up.queue:
+				isRunning = srv.forwardTo(up, forwarder, req)
 
 			case <-ticker.C:
 				if srv.opts.Debug&DebugLevelConnPacket != 0 {
-					log.Printf(`%s: alive`, tag)
+					log.Printf(`%s: alive`, up.tag)
 				}
 			case <-stopper:
-				srv.stopForwarder(forwarder)
+				srv.stopForwarder(up, forwarder)
 				return
 			}
 		}
 
-		log.Printf(`%s: reconnect to nameserver %s`, tag, nameserver)
-		srv.stopForwarder(forwarder)
+		log.Printf(`%s: reconnect to nameserver %s`, up.tag, up.nameserver)
+		srv.stopForwarder(up, forwarder)
+		srv.redispatch(fw, up)
 	}
 }
 
-func (srv *Server) tcpForwarder(tag, nameserver string) {
+func (srv *Server) tcpForwarder(up *upstream) {
 	var (
 		stopper = srv.newStopper()
 
 		ticker *time.Ticker
 		cl     *TCPClient
 		req    *request
-		res    *Message
 		err    error
-		ok     bool
 	)
 
-	log.Printf(`%s: starting forwarder for %s`, tag, nameserver)
+	log.Printf(`%s: starting forwarder for %s`, up.tag, up.nameserver)
 
-	srv.incForwarder()
+	up.setConnected(true)
 
 	defer func() {
-		srv.decForwarder()
-		log.Printf(`%s: forwarder for %s has been stopped`, tag, nameserver)
+		up.setConnected(false)
+		log.Printf(`%s: forwarder for %s has been stopped`, up.tag, up.nameserver)
 	}()
 
-	var totalElapsed int64
-	var totalQuery int64
-
 	ticker = time.NewTicker(aliveInterval)
 	for {
 		select {
-		case req, ok = <-srv.tcpq:
-			if !ok {
-				log.Printf(`%s: primary queue has been closed`, tag)
-				return
-			}
-
-			cl, err = NewTCPClient(nameserver)
+		case req = <-up.queue:
+			cl, err = NewTCPClient(up.nameserver)
 			if err != nil {
 				log.Printf(`%s: failed to connect to %s: %s`,
-					tag, nameserver, err)
+					up.tag, up.nameserver, err)
+				up.fail(time.Now())
+				if req.claim(false) {
+					srv.serveStale(req)
+				}
 				continue
 			}
 
-			res, err = cl.Query(req.message)
+			srv.forwardTo(up, cl, req)
 			cl.Close()
-			if err != nil {
-				log.Printf(`! %s %s %s %s - - -: forward failed %s`,
-					req.kind, tag, nameserver, req.String(), err)
-				continue
-			}
-
-			an, isInserted, err := srv.processResponse(req, res)
-			elapsed := time.Since(req.startAt)
-			totalElapsed += int64(elapsed)
-			totalQuery++
-			avgElapsed := time.Duration(totalElapsed / totalQuery)
-			if err != nil {
-				log.Printf(`! %s %s %s %s %v %d %v: %s`,
-					req.kind, tag, nameserver, req.String(),
-					elapsed, totalQuery, avgElapsed, err)
-				continue
-			}
-			if srv.opts.Debug&DebugLevelCache != 0 {
-				if isInserted {
-					log.Printf(`+ %s %s %s %s %v %d %v`,
-						req.kind, tag, nameserver, an.String(),
-						elapsed, totalQuery, avgElapsed)
-				} else {
-					log.Printf(`# %s %s %s %s %v %d %v`,
-						req.kind, tag, nameserver, an.String(),
-						elapsed, totalQuery, avgElapsed)
-				}
-			}
 
 		case <-ticker.C:
 			if srv.opts.Debug&DebugLevelConnPacket != 0 {
-				log.Printf(`%s: alive`, tag)
+				log.Printf(`%s: alive`, up.tag)
 			}
 		case <-stopper:
 			return
@@ -1004,38 +1928,31 @@ func (srv *Server) tcpForwarder(tag, nameserver string) {
 	}
 }
 
-// udpForwarder create a UDP client that consume request from queue
-// and forward it to parent name server.
-func (srv *Server) udpForwarder(tag, nameserver string) {
+func (srv *Server) udpForwarder(fw *forwarders, up *upstream) {
 	var (
 		stopper = srv.newStopper()
 
 		forwarder *UDPClient
 		ticker    *time.Ticker
 		req       *request
-		res       *Message
 		err       error
 		isRunning bool
-		ok        bool
 	)
 
 	defer func() {
-		log.Printf(`%s: forwarder for %s has been stopped`, tag, nameserver)
+		log.Printf(`%s: forwarder for %s has been stopped`, up.tag, up.nameserver)
 	}()
 
-	var totalElapsed int64
-	var totalQuery int64
-
 	// The first loop handle broken connection.
 	for {
-		forwarder, err = NewUDPClient(nameserver)
+		forwarder, err = NewUDPClient(up.nameserver)
 		if err != nil {
 			log.Printf(`%s: failed to connect to %s: %s`,
-				tag, nameserver, err)
+				up.tag, up.nameserver, err)
 
 			select {
 			case <-stopper:
-				srv.stopForwarder(nil)
+				srv.stopForwarder(up, nil)
 				return
 			default:
 				time.Sleep(3 * time.Second)
@@ -1043,91 +1960,139 @@ func (srv *Server) udpForwarder(tag, nameserver string) {
 			continue
 		}
 
-		log.Printf(`%s: connected to %s`, tag, nameserver)
+		log.Printf(`%s: connected to %s`, up.tag, up.nameserver)
 
-		srv.incForwarder()
+		up.setConnected(true)
 
 		// The second loop consume the forward queue.
 		isRunning = true
 		ticker = time.NewTicker(aliveInterval)
 		for isRunning {
 			select {
-			case req, ok = <-srv.primaryq:
-				if !ok {
-					log.Printf(`%s: primary queue has been closed`, tag)
-					srv.stopForwarder(forwarder)
-					return
-				}
-
-				res, err = forwarder.Query(req.message)
-				if err != nil {
-					log.Printf(`! %s %s %s %s - - -: forward failed %s`,
-						req.kind, tag, nameserver,
-						req.String(), err)
-					if !errors.Is(err, errInvalidMessage) {
-						isRunning = false
-					}
-					continue
-				}
-
-				an, isInserted, err := srv.processResponse(req, res)
-				elapsed := time.Since(req.startAt)
-				totalElapsed += int64(elapsed)
-				totalQuery++
-				avgElapsed := time.Duration(totalElapsed / totalQuery)
-				if err != nil {
-					log.Printf(`! %s %s %s %s %v %d %v: %s`,
-						req.kind, tag, nameserver,
-						req.String(),
-						elapsed, totalQuery, avgElapsed, err)
-					continue
-				}
-				if srv.opts.Debug&DebugLevelCache != 0 {
-					if isInserted {
-						log.Printf(`+ %s %s %s %s %v %d %v`,
-							req.kind,
-							tag, nameserver, an.String(),
-							elapsed, totalQuery, avgElapsed)
-					} else {
-						log.Printf(`# %s %s %s %s %v %d %v`,
-							req.kind,
-							tag, nameserver, an.String(),
-							elapsed, totalQuery, avgElapsed)
-					}
-				}
+			case req = <-up.queue:
+				isRunning = srv.forwardTo(up, forwarder, req)
 
 			case <-ticker.C:
 				if srv.opts.Debug&DebugLevelConnPacket != 0 {
-					log.Printf(`%s: alive`, tag)
+					log.Printf(`%s: alive`, up.tag)
 				}
 			case <-stopper:
-				srv.stopForwarder(forwarder)
+				srv.stopForwarder(up, forwarder)
 				return
 			}
 		}
 
-		log.Printf(`%s: reconnect forwarder for %s`, tag, nameserver)
-		srv.stopForwarder(forwarder)
+		log.Printf(`%s: reconnect forwarder for %s`, up.tag, up.nameserver)
+		srv.stopForwarder(up, forwarder)
+		srv.redispatch(fw, up)
 	}
 }
 
-func (srv *Server) stopForwarder(fw Client) {
-	if fw != nil {
-		fw.Close()
+// forwardTo send the query in req to parent name server using client cl
+// of forwarder up, and write the response back to client.
+// It return false if the connection to parent name server is broken and
+// need to be reconnected.
+func (srv *Server) forwardTo(up *upstream, cl Client, req *request) bool {
+	var (
+		queryAt  = time.Now()
+		res, err = cl.Query(req.message)
+		rtt      = time.Since(queryAt)
+	)
+
+	srv.ql.forwarder(req, up.proto, up.nameserver, queryAt, res)
+
+	if err != nil {
+		up.fail(time.Now())
+		log.Printf(`! %s %s %s %s - - -: forward failed %s`,
+			req.kind, up.tag, up.nameserver, req.String(), err)
+		if req.claim(false) {
+			srv.serveStale(req)
+		}
+		return errors.Is(err, errInvalidMessage)
+	}
+
+	up.succeed(rtt)
+	if !req.claim(true) {
+		// The request has been answered by other forwarder.
+		return true
 	}
-	srv.decForwarder()
+
+	an, isInserted, err := srv.processResponse(req, res, cl)
+	elapsed := time.Since(req.startAt)
+	totalQuery, avgElapsed := up.answered(elapsed)
+	if err != nil {
+		log.Printf(`! %s %s %s %s %v %d %v: %s`,
+			req.kind, up.tag, up.nameserver, req.String(),
+			elapsed, totalQuery, avgElapsed, err)
+		return true
+	}
+	if srv.opts.Debug&DebugLevelCache != 0 {
+		if isInserted {
+			log.Printf(`+ %s %s %s %s %v %d %v`,
+				req.kind, up.tag, up.nameserver, an.String(),
+				elapsed, totalQuery, avgElapsed)
+		} else {
+			log.Printf(`# %s %s %s %s %v %d %v`,
+				req.kind, up.tag, up.nameserver, an.String(),
+				elapsed, totalQuery, avgElapsed)
+		}
+	}
+	return true
+}
+
+// redispatch the pending requests on the disconnected forwarder up into
+// the other forwarders.
+// The request that has been forwarded to multiple forwarders is treated
+// as failed on this forwarder.
+func (srv *Server) redispatch(fw *forwarders, up *upstream) {
+	var req *request
+	for _, req = range up.drain() {
+		if req.race != nil {
+			if req.claim(false) {
+				srv.serveStale(req)
+			}
+			continue
+		}
+		if !fw.dispatch(req) && !srv.serveStale(req) {
+			srv.noForwarders(req)
+		}
+	}
+}
+
+func (srv *Server) stopForwarder(up *upstream, cl Client) {
+	if cl != nil {
+		cl.Close()
+	}
+	up.setConnected(false)
+}
+
+// stopSecondaries stop refreshing all of the secondary zones.
+func (srv *Server) stopSecondaries() {
+	srv.secondaryLocker.Lock()
+	for origin, sec := range srv.secondaries {
+		sec.stop()
+		delete(srv.secondaries, origin)
+	}
+	srv.secondaryLocker.Unlock()
 }
 
 // stopAllForwarders stop all forwarder connections.
 func (srv *Server) stopAllForwarders() {
 	var (
-		x int
+		stoppers []chan bool
+		x        int
 	)
-	for x = range len(srv.fwStoppers) {
-		srv.fwStoppers[x] <- true
+
+	srv.fwLocker.Lock()
+	stoppers = srv.fwStoppers
+	srv.fwStoppers = nil
+	srv.fwLocker.Unlock()
+
+	for x = range len(stoppers) {
+		stoppers[x] <- true
 	}
-	for x = range len(srv.fwStoppers) {
-		close(srv.fwStoppers[x])
+	for x = range len(stoppers) {
+		close(stoppers[x])
 	}
 
 	log.Println(`dns: all forwarders has been stopped`)
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"log"
+)
+
+// initEDNS parse the OPT record in the query and validate its DNS
+// cookie, as described in RFC 7873 section 5.2.
+//
+// The server cookie for response is generated if the query does not
+// have it, if the server cookie is invalid, or if its need to be
+// renewed.
+// If the query through UDP does not have valid server cookie and
+// [ServerOptions.CookieRequired] is true, the query is answered with
+// BADCOOKIE.
+//
+// It return false if the query has been answered.
+func (srv *Server) initEDNS(req *request) bool {
+	if req.message.Header.ARCount == 0 {
+		return true
+	}
+
+	var (
+		msg *Message
+		err error
+	)
+
+	msg, err = UnpackMessage(req.message.packet)
+	if err != nil {
+		// Let the other handlers decide how to handle the query.
+		if srv.opts.Debug&DebugLevelConnPacket != 0 {
+			log.Printf(`initEDNS: %s: %s`, req.String(), err)
+		}
+		return true
+	}
+
+	req.opt = msg.opt(false)
+	if req.opt == nil {
+		return true
+	}
+
+	var cookie *EDNSCookie
+
+	cookie, err = req.opt.Cookie()
+	if err != nil {
+		log.Printf(`! %s - - %s - - -: %s`, req.kind, req.String(), err)
+		req.opt = nil
+		req.error(RCodeErrFormat)
+		return false
+	}
+	if cookie == nil {
+		return true
+	}
+
+	var (
+		ip        = req.remoteIP()
+		now       = uint32(timeNow().Unix())
+		ok, renew = isValidServerCookie(srv.opts.cookieSecret, cookie, ip, now)
+		resCookie = &EDNSCookie{
+			Client: cookie.Client,
+			Server: cookie.Server,
+		}
+	)
+
+	if !ok || renew {
+		resCookie.Server = serverCookie(srv.opts.cookieSecret, cookie.Client, ip, now)
+	}
+	req.cookie = resCookie
+
+	if !ok && srv.opts.CookieRequired && req.kind == connTypeUDP {
+		req.error(RCodeBadCookie)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestServer_initEDNS(t *testing.T) {
+	var (
+		opts = &ServerOptions{
+			CookieSecret:   `secret`,
+			CookieRequired: true,
+		}
+		ip     net.IP // The remote IP of request with bytes.Buffer is nil.
+		client = []byte(`12345678`)
+		now    = uint32(testNowEpoch)
+		valid  = serverCookie([]byte(`secret`), client, ip, now-60)
+		old    = serverCookie([]byte(`secret`), client, ip, now-serverCookieRenewAge-1)
+		newc   = serverCookie([]byte(`secret`), client, ip, now)
+		err    error
+	)
+
+	err = opts.init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var srv = &Server{
+		opts: opts,
+	}
+
+	type testCase struct {
+		desc      string
+		kind      string
+		data      []byte
+		expServer []byte
+		expRCode  ResponseCode
+		expOK     bool
+	}
+
+	var listCase = []testCase{{
+		desc:      `With valid server cookie`,
+		kind:      connTypeUDP,
+		data:      append(client, valid...),
+		expOK:     true,
+		expServer: valid,
+	}, {
+		desc:      `With old server cookie`,
+		kind:      connTypeUDP,
+		data:      append(client, old...),
+		expOK:     true,
+		expServer: newc,
+	}, {
+		desc:      `With client cookie only on TCP`,
+		kind:      connTypeTCP,
+		data:      client,
+		expOK:     true,
+		expServer: newc,
+	}, {
+		desc:      `With client cookie only on UDP`,
+		kind:      connTypeUDP,
+		data:      client,
+		expServer: newc,
+		expRCode:  RCodeBadCookie,
+	}, {
+		desc:      `With invalid server cookie`,
+		kind:      connTypeUDP,
+		data:      append(client, []byte(`0123456789abcdef`)...),
+		expServer: newc,
+		expRCode:  RCodeBadCookie,
+	}, {
+		desc:     `With invalid cookie length`,
+		kind:     connTypeUDP,
+		data:     client[:5],
+		expRCode: RCodeErrFormat,
+	}}
+
+	var (
+		buf bytes.Buffer
+		c   testCase
+		req *request
+		res *Message
+		ok  bool
+	)
+	for _, c = range listCase {
+		buf.Reset()
+
+		req = newRequest()
+		req.kind = c.kind
+		req.writer = &buf
+		req.message.Header.ID = 1
+		req.message.Question.Name = `kilabit.info`
+		req.message.Question.Type = RecordTypeA
+		req.message.Question.Class = RecordClassIN
+		req.message.opt(true).set(OptionCodeCookie, c.data)
+		_, err = req.message.Pack()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ok = srv.initEDNS(req)
+		test.Assert(t, c.desc+`: OK`, c.expOK, ok)
+
+		if c.expServer != nil {
+			test.Assert(t, c.desc+`: server cookie`, c.expServer, req.cookie.Server)
+		}
+		if ok {
+			continue
+		}
+
+		res, err = UnpackMessage(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var (
+			opt   = res.opt(false)
+			rcode = res.Header.RCode
+		)
+		if opt != nil {
+			rcode |= ResponseCode(opt.ExtRCode) << 4
+		}
+		test.Assert(t, c.desc+`: RCode`, c.expRCode, rcode)
+	}
+}
+
+func TestServer_EDNS_DoT(t *testing.T) {
+	var (
+		cl  *DoTClient
+		err error
+	)
+
+	cl, err = NewDoTClient(testDoTServerAddress, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = cl.Close() })
+
+	type testCase struct {
+		desc     string
+		qname    string
+		expEDE   []EDNSExtendedError
+		expRCode ResponseCode
+	}
+
+	var listCase = []testCase{{
+		desc:  `With padding and cookie`,
+		qname: `kilabit.info`,
+	}, {
+		desc:     `With extended error`,
+		qname:    `notexist.test`,
+		expRCode: RCodeErrServer,
+		expEDE: []EDNSExtendedError{{
+			InfoCode:  EDENoReachableAuthority,
+			ExtraText: `no active forwarders`,
+		}},
+	}}
+
+	var (
+		client = []byte(`12345678`)
+
+		c   testCase
+		msg *Message
+		res *Message
+	)
+	for _, c = range listCase {
+		msg = &Message{
+			Header: MessageHeader{
+				ID:      1,
+				IsRD:    true,
+				QDCount: 1,
+			},
+			Question: MessageQuestion{
+				Name:  c.qname,
+				Type:  RecordTypeA,
+				Class: RecordClassIN,
+			},
+		}
+		var opt = msg.opt(true)
+		opt.SetCookie(&EDNSCookie{Client: client})
+		opt.SetPadding(0)
+		_, err = msg.Pack()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err = cl.Query(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		test.Assert(t, c.desc+`: RCode`, c.expRCode, res.Header.RCode)
+		test.Assert(t, c.desc+`: padded`, 0, len(res.packet)%paddingBlockSize)
+
+		opt = res.opt(false)
+
+		var cookie *EDNSCookie
+
+		cookie, err = opt.Cookie()
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.Assert(t, c.desc+`: client cookie`, client, cookie.Client)
+		test.Assert(t, c.desc+`: server cookie`, serverCookieSize, len(cookie.Server))
+
+		var gotEDE []EDNSExtendedError
+
+		gotEDE, err = opt.ExtendedErrors()
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.Assert(t, c.desc+`: EDE`, c.expEDE, gotEDE)
+	}
+}
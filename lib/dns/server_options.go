@@ -35,6 +35,19 @@ type ServerOptions struct {
 	primaryDoh []string   // List of parent name server addresses using DoH.
 	primaryDot []string   // List of parent name server addresses using DoT.
 
+	// trustAnchors contains the parsed TrustAnchors indexed by zone
+	// name.
+	trustAnchors map[string][]*RDataDS
+
+	// transferAllow contains the parsed TransferAllow.
+	transferAllow []*net.IPNet
+
+	// rootHints contains the parsed RootHints in "ip:port" format.
+	rootHints []string
+
+	// cookieSecret contains the secret for generating server cookie.
+	cookieSecret []byte
+
 	ip net.IP
 
 	// ListenAddress ip address and port number to serve query.
@@ -50,6 +63,79 @@ type ServerOptions struct {
 	// TLSPrivateKey contains path to certificate private key file.
 	TLSPrivateKey string `ini:"dns:server:tls.private_key"`
 
+	// CookieSecret define the secret for generating and validating the
+	// server cookie, as described in RFC 7873 and RFC 9018.
+	// The server cookie is sent in response to query that contains the
+	// DNS Cookie option.
+	// Multiple servers that share the same secret, for example behind
+	// the same anycast address, accept the server cookie from each
+	// other.
+	// This field is optional, default to random secret generated on
+	// start.
+	CookieSecret string `ini:"dns:server:cookie.secret"`
+
+	// QueryLog define the path to file for logging each query and
+	// response from client, and each query and response to parent name
+	// servers.
+	// If its prefixed with "unix:", for example "unix:/run/dnstap.sock",
+	// the log is written to the Unix socket.
+	// This field is optional, default to empty, which disable the query
+	// logging.
+	QueryLog string `ini:"dns:server:querylog"`
+
+	// QueryLogFormat define the format of QueryLog, one of,
+	//
+	//   - "dnstap": the dnstap protobuf messages in Frame Streams,
+	//     readable by the dnstap tools,
+	//   - "json": one JSON object of [QueryLogEvent] per line, or
+	//   - "text": one line of space separated fields per event.
+	//
+	// This field is optional, default to "dnstap".
+	QueryLogFormat string `ini:"dns:server:querylog.format"`
+
+	// ForwardStrategy define how the query is forwarded when there are
+	// more than one parent name servers, one of,
+	//
+	//   - "fastest": forward to the parent name server with the lowest
+	//     smoothed round-trip time (RTT),
+	//   - "race": forward to the two fastest parent name servers at the
+	//     same time and use the first answer, or
+	//   - "round-robin": forward to each parent name server in turn.
+	//
+	// The parent name server that fail three times in a row is taken
+	// out of rotation for 30 seconds, unless all of them are failing.
+	// The statistics of each parent name server can be queried using
+	// [Server.ForwarderStats].
+	// This field is optional, default to "fastest".
+	ForwardStrategy string `ini:"dns:server:forward.strategy"`
+
+	// AdminAddress define the address, in the format "ip:port", to
+	// serve the administrative HTTP API for managing internal zones,
+	// hosts files, and external caches.
+	// See [Server] for list of its endpoints.
+	// This field is optional, if its empty the administrative API is
+	// disabled.
+	AdminAddress string `ini:"dns:server:admin.listen"`
+
+	// AdminToken define the token that must be sent by client in the
+	// HTTP header "Authorization: Bearer <token>" to access the
+	// administrative API.
+	// This field is required if AdminAddress is set.
+	AdminToken string `ini:"dns:server:admin.token"`
+
+	// AdminZoneDir define the directory where the zone that is created
+	// through the administrative API is stored, using its origin as the
+	// file name.
+	// This field is optional, if its empty the created zone only live
+	// in memory.
+	AdminZoneDir string `ini:"dns:server:admin.zone_dir"`
+
+	// AdminHostsDir define the directory where the hosts file that is
+	// created through the administrative API is stored.
+	// This field is optional, if its empty the hosts file cannot be
+	// created through the administrative API.
+	AdminHostsDir string `ini:"dns:server:admin.hosts_dir"`
+
 	// OnAnswerReceived define the hook to be triggered when server
 	// receive valid answer, before its put to caches.
 	OnAnswerReceived HookFunc `json:"-" ini:"-"`
@@ -79,6 +165,65 @@ type ServerOptions struct {
 	//
 	NameServers []string `ini:"dns:server:parent"`
 
+	// ForwardRules contains list of rules to forward the query on
+	// specific domains to their own parent name servers, instead of
+	// NameServers.
+	// The rule with the longest domain suffix that match with the query
+	// name is used.
+	// See [ForwardRule] for an example of its INI format.
+	ForwardRules []ForwardRule `ini:"dns:forward"`
+
+	// Blocklists contains list of domain names that are blocked, or
+	// allowed, by server.
+	// The query that match with the block list is answered based on
+	// the list action, instead of forwarded to parent name servers.
+	// See [Blocklist] for an example of its INI format.
+	Blocklists []Blocklist `ini:"dns:blocklist"`
+
+	// Views contains list of split-horizon views, each with their own
+	// internal zones, hosts files, and parent name servers, for queries
+	// from specific client networks.
+	// The query from client that does not match with any views is
+	// answered using the server caches and forwarders.
+	// See [View] for an example of its INI format.
+	Views []View `ini:"dns:view"`
+
+	// TrustAnchors contains list of DS records, in zone file format,
+	// that is trusted as the starting point of DNSSEC validation.
+	// This field is used only if DNSSECValidate is true.
+	// If its empty, it will default to the DS records of the root zone
+	// key signing keys.
+	//
+	// Example,
+	//
+	//	. IN DS 20326 8 2 E06D44B80B8F...
+	//	example.com. DS 60485 5 1 2BB183AF5F22...
+	TrustAnchors []string `ini:"dns:server:dnssec.trust_anchor"`
+
+	// TransferAllow contains list of IP address or network, in CIDR
+	// notation, of clients that allowed to request zone transfer (AXFR
+	// or IXFR) of internal zones.
+	// If its empty, all zone transfer requests will be refused.
+	//
+	// Example,
+	//
+	//	127.0.0.1
+	//	10.0.0.0/8
+	//	::1
+	TransferAllow []string `ini:"dns:server:transfer.allow"`
+
+	// RootHints contains list of IP address, with optional port, of the
+	// root name servers.
+	// This field is used only if Recursive is true.
+	// If its empty, it will default to the IPv4 address of the thirteen
+	// root name servers.
+	//
+	// Example,
+	//
+	//	198.41.0.4
+	//	192.0.2.53:5353
+	RootHints []string `ini:"dns:server:root_hint"`
+
 	// The root authority for all zones and records under this server.
 	SOA RDataSOA
 
@@ -103,11 +248,90 @@ type ServerOptions struct {
 	// accessed in the last 1 minute will be removed from cache.
 	PruneThreshold time.Duration `ini:"dns:server:cache.prune_threshold"`
 
+	// NegativeCacheMaxTTL define the maximum time to cache the negative
+	// answer, the name error (NXDOMAIN) or no data (NODATA), received
+	// from parent name servers, as described in RFC 2308.
+	// The TTL of negative answer is the minimum of SOA TTL and SOA
+	// MINIMUM in its authority section, but no more than this value.
+	// This field is optional, default to 0, which disable the negative
+	// caching.
+	NegativeCacheMaxTTL time.Duration `ini:"dns:server:cache.negative_max_ttl"`
+
+	// StaleWindow define how long the expired answer can still be
+	// served to client when the request cannot be forwarded to parent
+	// name servers, as described in RFC 8767.
+	// This field is optional, default to 0, which disable the
+	// serve-stale.
+	// The recommended value is between 1 to 3 days.
+	StaleWindow time.Duration `ini:"dns:server:cache.stale_window"`
+
+	// StaleTTL define the TTL of the stale answer.
+	// This field is optional, default to 30 seconds if the StaleWindow
+	// is set.
+	StaleTTL time.Duration `ini:"dns:server:cache.stale_ttl"`
+
+	// RateLimitWindow define the duration where the rate of responses
+	// to each client is averaged.
+	// The client that send queries more than the limit can only receive
+	// the response again after its rate is below RateLimitResponses in
+	// this window.
+	// This field is optional, default to 15 seconds if the
+	// RateLimitResponses is set.
+	RateLimitWindow time.Duration `ini:"dns:server:rrl.window"`
+
 	// Debug level for server, accept value
 	// [DebugLevelCache], [DebugLevelConnPacket], or any combination of
 	// it.
 	Debug int `ini:"dns:server:debug"`
 
+	// RateLimitResponses define the maximum number of identical
+	// responses per second sent through UDP to the same client network.
+	// The responses are identical if they have the same type (answer,
+	// no data, name error, or other error), query name, and query type.
+	// The response that exceed the limit is dropped, or replaced with
+	// truncated response based on RateLimitSlip, so the server cannot
+	// be used to amplify the spoofed queries.
+	// The responses through TCP, DoT, and DoH are not limited.
+	// This field is optional, default to 0, which disable the response
+	// rate limiting.
+	RateLimitResponses int `ini:"dns:server:rrl.responses_per_second"`
+
+	// RateLimitSlip define the ratio of limited responses that are
+	// replaced with truncated response (TC=1), instead of dropped.
+	// For example, 2 means every second limited response is truncated,
+	// and 1 means all limited responses are truncated.
+	// The truncated response allow the legitimate client, whose address
+	// is spoofed, to retry the query through TCP.
+	// This field is optional, default to 2 if the RateLimitResponses is
+	// set.
+	// Set it to negative value to drop all limited responses.
+	RateLimitSlip int `ini:"dns:server:rrl.slip"`
+
+	// PrefetchThreshold define the fraction of answer TTL, between 0
+	// and 1, where the popular answer is refreshed from parent name
+	// servers before its expired.
+	// For example, 0.1 means the answer is prefetched when one of the
+	// client query it and its remaining TTL is less than 10% of its
+	// original TTL.
+	// This field is optional, default to 0, which disable the cache
+	// prefetching.
+	PrefetchThreshold float64 `ini:"dns:server:cache.prefetch_threshold"`
+
+	// PrefetchMinHits define the minimum number of queries answered by
+	// the cached answer before its can be prefetched, so only the
+	// popular answers are refreshed.
+	// This field is optional, default to 3 if the PrefetchThreshold is
+	// set.
+	PrefetchMinHits int `ini:"dns:server:cache.prefetch_min_hits"`
+
+	// PrefetchMaxConcurrent define the maximum number of prefetching
+	// in progress at the same time.
+	// The answer that need to be prefetched when the limit is reached
+	// is skipped.
+	// This field is optional, default to 8 if the PrefetchThreshold is
+	// set.
+	PrefetchMaxConcurrent int `ini:"dns:server:cache.prefetch_max_concurrent"`
+
 	// HTTPPort port for listening DNS over HTTP (DoH), default to 0.
 	// If its zero, the server will not serve DNS over HTTP.
 	HTTPPort uint16 `ini:"dns:server:http.port"`
@@ -118,6 +342,45 @@ type ServerOptions struct {
 
 	port uint16
 
+	// ClientSubnetPrefixV4 define the maximum source prefix length of
+	// IPv4 address in ECS option, when ClientSubnet is enabled.
+	// This field is optional, default to 24.
+	ClientSubnetPrefixV4 byte `ini:"dns:server:ecs.prefix_v4"`
+
+	// ClientSubnetPrefixV6 define the maximum source prefix length of
+	// IPv6 address in ECS option, when ClientSubnet is enabled.
+	// This field is optional, default to 56.
+	ClientSubnetPrefixV6 byte `ini:"dns:server:ecs.prefix_v6"`
+
+	// RateLimitPrefixV4 define the prefix length of IPv4 address that
+	// group the clients for response rate limiting.
+	// This field is optional, default to 24.
+	RateLimitPrefixV4 byte `ini:"dns:server:rrl.prefix_v4"`
+
+	// RateLimitPrefixV6 define the prefix length of IPv6 address that
+	// group the clients for response rate limiting.
+	// This field is optional, default to 56.
+	RateLimitPrefixV6 byte `ini:"dns:server:rrl.prefix_v6"`
+
+	// ClientSubnet enable the EDNS Client Subnet (ECS) option, as
+	// described in RFC 7871, on the query forwarded to parent name
+	// servers.
+	// The ECS option from client is forwarded with its address
+	// truncated to ClientSubnetPrefixV4 or ClientSubnetPrefixV6.
+	// If the query does not have ECS option, new one is added using
+	// the client address.
+	// The answer with non-zero scope prefix is not cached, since its
+	// only valid for the client network.
+	// If its false, the ECS option from client is removed before
+	// forwarded.
+	ClientSubnet bool `ini:"dns:server:ecs.enable"`
+
+	// CookieRequired define whether the query through UDP that contains
+	// the client cookie but without valid server cookie is answered
+	// with response code BADCOOKIE and new server cookie, as described
+	// in RFC 7873 section 5.2.3.
+	CookieRequired bool `ini:"dns:server:cookie.required"`
+
 	// TLSAllowInsecure option to allow to serve DoH with self-signed
 	// certificate.
 	// This field is optional.
@@ -128,6 +391,29 @@ type ServerOptions struct {
 	// This option allow serving DNS request forwarded by another proxy
 	// server.
 	DoHBehindProxy bool `ini:"dns:server:doh.behind_proxy"`
+
+	// DNSSECValidate enable the DNSSEC validation on answers received
+	// from parent name servers.
+	// When enabled, the forwarded query will have the DNSSEC OK (DO) bit
+	// set and each answer is validated using the chain of
+	// RRSIG, DNSKEY, and DS records start from the TrustAnchors, before
+	// its put to caches.
+	// The bogus answer will be replied with response code
+	// RCodeErrServer (SERVFAIL) and the secure answer will have the
+	// Authentic Data (AD) flag set.
+	DNSSECValidate bool `ini:"dns:server:dnssec.validate"`
+
+	// Recursive enable the iterative resolution of query that does not
+	// have an answer in local caches, start from the RootHints, instead
+	// of forwarding it to NameServers.
+	// The query that match with ForwardRules, or with the View that has
+	// NameServers, is still forwarded to their parent name servers.
+	//
+	// The resolver follow the referrals and CNAME into other zones,
+	// cache the delegations and their glue, skip the lame name servers,
+	// and minimise the query name sent to each name servers, as
+	// described in RFC 9156.
+	Recursive bool `ini:"dns:server:recursive"`
 }
 
 // init initialize the server options.
@@ -152,6 +438,84 @@ func (opts *ServerOptions) init() (err error) {
 	if opts.PruneThreshold.Minutes() > -1 {
 		opts.PruneThreshold = -1 * time.Hour
 	}
+	if opts.StaleWindow > 0 && opts.StaleTTL <= 0 {
+		opts.StaleTTL = defaultStaleTTL
+	}
+	if opts.ClientSubnetPrefixV4 == 0 || opts.ClientSubnetPrefixV4 > 32 {
+		opts.ClientSubnetPrefixV4 = defaultClientSubnetPrefixV4
+	}
+	if opts.ClientSubnetPrefixV6 == 0 || opts.ClientSubnetPrefixV6 > 128 {
+		opts.ClientSubnetPrefixV6 = defaultClientSubnetPrefixV6
+	}
+	if opts.RateLimitResponses > 0 {
+		opts.initRateLimit()
+	}
+	if opts.PrefetchThreshold < 0 || opts.PrefetchThreshold >= 1 {
+		return fmt.Errorf(`dns: invalid prefetch threshold %v`, opts.PrefetchThreshold)
+	}
+	if opts.PrefetchThreshold > 0 {
+		opts.initPrefetch()
+	}
+
+	switch opts.ForwardStrategy {
+	case ``, ForwardStrategyFastest, ForwardStrategyRace, ForwardStrategyRoundRobin:
+	default:
+		return fmt.Errorf(`dns: unknown forward strategy %q`, opts.ForwardStrategy)
+	}
+
+	switch opts.QueryLogFormat {
+	case ``, QueryLogFormatDnstap, QueryLogFormatJSON, QueryLogFormatText:
+	default:
+		return fmt.Errorf(`dns: unknown query log format %q`, opts.QueryLogFormat)
+	}
+
+	if len(opts.CookieSecret) > 0 {
+		opts.cookieSecret = []byte(opts.CookieSecret)
+	}
+
+	if opts.DNSSECValidate {
+		if len(opts.TrustAnchors) == 0 {
+			opts.TrustAnchors = defaultTrustAnchors
+		}
+		opts.trustAnchors, err = parseTrustAnchors(opts.TrustAnchors)
+		if err != nil {
+			return fmt.Errorf(`dns: %w`, err)
+		}
+	}
+
+	if len(opts.AdminAddress) != 0 && len(opts.AdminToken) == 0 {
+		return errors.New(`dns: empty admin token`)
+	}
+
+	err = opts.initTransferAllow()
+	if err != nil {
+		return err
+	}
+
+	if opts.Recursive {
+		if len(opts.RootHints) == 0 {
+			opts.RootHints = defaultRootHints
+		}
+		opts.rootHints, err = parseRootHints(opts.RootHints)
+		if err != nil {
+			return fmt.Errorf(`dns: %w`, err)
+		}
+	}
+
+	err = opts.initForwardRules()
+	if err != nil {
+		return err
+	}
+
+	err = opts.initBlocklists()
+	if err != nil {
+		return err
+	}
+
+	err = opts.initViews()
+	if err != nil {
+		return err
+	}
 
 	if len(opts.NameServers) == 0 {
 		return nil
@@ -194,12 +558,13 @@ func (opts *ServerOptions) getDoTAddress() *net.TCPAddr {
 	}
 }
 
-// parseNameServers parse each name server in NameServers list based on scheme
-// and store the result either in udpAddrs, tcpAddrs, dohAddrs, or dotAddrs.
+// parseNameServers parse each name server in nameServers list based on
+// scheme and return the result as list of addresses for UDP, TCP, DoH,
+// and DoT.
 //
 // If the name server format contains no scheme, it will be assumed to be
 // "udp".
-func (opts *ServerOptions) parseNameServers(nameServers []string) {
+func parseNameServers(nameServers []string) (udpAddrs, tcpAddrs []net.Addr, dohAddrs, dotAddrs []string) {
 	var (
 		dnsURL  *url.URL
 		tcpAddr *net.TCPAddr
@@ -223,14 +588,14 @@ func (opts *ServerOptions) parseNameServers(nameServers []string) {
 				log.Printf("dns: invalid IP address %q", dnsURL.Host)
 				continue
 			}
-			opts.primaryTCP = append(opts.primaryTCP, tcpAddr)
+			tcpAddrs = append(tcpAddrs, tcpAddr)
 
 		case "https":
 			ip = net.ParseIP(dnsURL.Hostname())
 			if ip == nil {
-				opts.primaryDoh = append(opts.primaryDoh, ns)
+				dohAddrs = append(dohAddrs, ns)
 			} else {
-				opts.primaryDot = append(opts.primaryDot, dnsURL.Host)
+				dotAddrs = append(dotAddrs, dnsURL.Host)
 			}
 
 		default:
@@ -243,22 +608,152 @@ func (opts *ServerOptions) parseNameServers(nameServers []string) {
 				log.Printf("dns: invalid IP address %q", ns)
 				continue
 			}
-			opts.primaryUDP = append(opts.primaryUDP, udpAddr)
+			udpAddrs = append(udpAddrs, udpAddr)
 
 			tcpAddr, err = libnet.ParseTCPAddr(ns, DefaultPort)
 			if err != nil {
 				log.Printf("dns: invalid IP address %q", ns)
 				continue
 			}
-			opts.primaryTCP = append(opts.primaryTCP, tcpAddr)
+			tcpAddrs = append(tcpAddrs, tcpAddr)
+		}
+	}
+	return udpAddrs, tcpAddrs, dohAddrs, dotAddrs
+}
+
+// initTransferAllow parse each IP address or network in TransferAllow.
+func (opts *ServerOptions) initTransferAllow() (err error) {
+	var (
+		ipnet *net.IPNet
+		v     string
+	)
+
+	opts.transferAllow = nil
+
+	for _, v = range opts.TransferAllow {
+		ipnet = parseNetwork(v)
+		if ipnet == nil {
+			return fmt.Errorf(`dns: invalid transfer.allow %q`, v)
+		}
+		opts.transferAllow = append(opts.transferAllow, ipnet)
+	}
+	return nil
+}
+
+// parseNetwork parse the IP address or network in CIDR notation.
+// The IP address is converted into network with single address.
+// It will return nil if v is not valid.
+func parseNetwork(v string) (ipnet *net.IPNet) {
+	var err error
+
+	_, ipnet, err = net.ParseCIDR(v)
+	if err == nil {
+		return ipnet
+	}
+
+	var ip = net.ParseIP(v)
+	if ip == nil {
+		return nil
+	}
+	if ip.To4() != nil {
+		ip = ip.To4()
+	}
+	ipnet = &net.IPNet{
+		IP:   ip,
+		Mask: net.CIDRMask(len(ip)*8, len(ip)*8),
+	}
+	return ipnet
+}
+
+// isTransferAllowed return true if the ip address is allowed to request
+// zone transfer.
+func (opts *ServerOptions) isTransferAllowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	var ipnet *net.IPNet
+	for _, ipnet = range opts.transferAllow {
+		if ipnet.Contains(ip) {
+			return true
 		}
 	}
+	return false
 }
 
 func (opts *ServerOptions) initNameServers() {
-	opts.primaryUDP = nil
-	opts.primaryTCP = nil
-	opts.primaryDoh = nil
-	opts.primaryDot = nil
-	opts.parseNameServers(opts.NameServers)
+	opts.primaryUDP, opts.primaryTCP, opts.primaryDoh, opts.primaryDot = parseNameServers(opts.NameServers)
+}
+
+// initForwardRules normalize and parse each rule in ForwardRules.
+func (opts *ServerOptions) initForwardRules() (err error) {
+	var x int
+	for x = range len(opts.ForwardRules) {
+		err = opts.ForwardRules[x].init()
+		if err != nil {
+			return fmt.Errorf(`dns: invalid forward rule %v: %w`,
+				opts.ForwardRules[x].Domains, err)
+		}
+	}
+	return nil
+}
+
+// initBlocklists validate each list in Blocklists.
+func (opts *ServerOptions) initBlocklists() (err error) {
+	var x int
+	for x = range len(opts.Blocklists) {
+		err = opts.Blocklists[x].init()
+		if err != nil {
+			return fmt.Errorf(`dns: invalid blocklist: %w`, err)
+		}
+	}
+	return nil
+}
+
+// initViews validate each view in Views.
+func (opts *ServerOptions) initViews() (err error) {
+	var (
+		names = make(map[string]struct{}, len(opts.Views))
+
+		ok bool
+		x  int
+	)
+	for x = range len(opts.Views) {
+		err = opts.Views[x].init()
+		if err != nil {
+			return fmt.Errorf(`dns: invalid view %q: %w`,
+				opts.Views[x].Name, err)
+		}
+		_, ok = names[opts.Views[x].Name]
+		if ok {
+			return fmt.Errorf(`dns: duplicate view %q`, opts.Views[x].Name)
+		}
+		names[opts.Views[x].Name] = struct{}{}
+	}
+	return nil
+}
+
+// initRateLimit set the default values for response rate limiting.
+func (opts *ServerOptions) initRateLimit() {
+	if opts.RateLimitSlip == 0 {
+		opts.RateLimitSlip = defaultRateLimitSlip
+	}
+	if opts.RateLimitWindow < time.Second {
+		opts.RateLimitWindow = defaultRateLimitWindow
+	}
+	if opts.RateLimitPrefixV4 == 0 || opts.RateLimitPrefixV4 > 32 {
+		opts.RateLimitPrefixV4 = defaultClientSubnetPrefixV4
+	}
+	if opts.RateLimitPrefixV6 == 0 || opts.RateLimitPrefixV6 > 128 {
+		opts.RateLimitPrefixV6 = defaultClientSubnetPrefixV6
+	}
+}
+
+// initPrefetch set the default values for cache prefetching.
+func (opts *ServerOptions) initPrefetch() {
+	if opts.PrefetchMinHits <= 0 {
+		opts.PrefetchMinHits = defaultPrefetchMinHits
+	}
+	if opts.PrefetchMaxConcurrent <= 0 {
+		opts.PrefetchMaxConcurrent = defaultPrefetchMaxConcurrent
+	}
 }
@@ -39,6 +39,9 @@ func TestServerOptionsInit(t *testing.T) {
 			PruneThreshold:  -1 * time.Hour,
 			ip:              ip,
 			port:            53,
+
+			ClientSubnetPrefixV4: defaultClientSubnetPrefixV4,
+			ClientSubnetPrefixV6: defaultClientSubnetPrefixV6,
 		},
 	}, {
 		desc: "With invalid IP address",
@@ -72,6 +75,10 @@ func TestServerOptionsInit(t *testing.T) {
 			PruneThreshold: -1 * time.Hour,
 			ip:             ip,
 			port:           53,
+
+			ClientSubnetPrefixV4: defaultClientSubnetPrefixV4,
+			ClientSubnetPrefixV6: defaultClientSubnetPrefixV6,
+
 			primaryUDP: []net.Addr{
 				&net.UDPAddr{
 					IP:   net.ParseIP("127.0.0.1"),
@@ -185,3 +192,50 @@ func TestServerOptionsParseNameServers(t *testing.T) {
 		test.Assert(t, "primaryDoh", c.expDoHServers, so.primaryDoh)
 	}
 }
+
+func TestServerOptions_isTransferAllowed(t *testing.T) {
+	var (
+		opts = &ServerOptions{
+			TransferAllow: []string{
+				`127.0.0.1`,
+				`10.0.0.0/8`,
+				`::1`,
+			},
+		}
+		err error
+	)
+
+	err = opts.init()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var listCase = []struct {
+		ip  string
+		exp bool
+	}{{
+		ip:  `127.0.0.1`,
+		exp: true,
+	}, {
+		ip: `127.0.0.2`,
+	}, {
+		ip:  `10.1.2.3`,
+		exp: true,
+	}, {
+		ip:  `::1`,
+		exp: true,
+	}, {
+		ip: `192.168.1.1`,
+	}}
+
+	for _, c := range listCase {
+		var got = opts.isTransferAllowed(net.ParseIP(c.ip))
+		test.Assert(t, c.ip, c.exp, got)
+	}
+
+	opts = &ServerOptions{
+		TransferAllow: []string{`10.0.0`},
+	}
+	err = opts.init()
+	test.Assert(t, `invalid`, `dns: invalid transfer.allow "10.0.0"`, err.Error())
+}
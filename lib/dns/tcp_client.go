@@ -18,6 +18,7 @@ import (
 type TCPClient struct {
 	conn         net.Conn
 	addr         *net.TCPAddr
+	tsigKey      *TSIGKey // tsigKey contains the key to sign the query.
 	readTimeout  time.Duration
 	writeTimeout time.Duration
 }
@@ -116,9 +117,21 @@ func (cl *TCPClient) Lookup(q MessageQuestion, allowRecursion bool) (msg *Messag
 }
 
 // Query send DNS query to name server.
-// The addr parameter is unused.
+// If the client has TSIG key, the msg will be signed and the response will
+// be verified using the key.
 func (cl *TCPClient) Query(msg *Message) (res *Message, err error) {
-	var logp = `Query`
+	var (
+		logp = `Query`
+
+		reqMAC []byte
+	)
+
+	if cl.tsigKey != nil {
+		reqMAC, err = cl.tsigKey.Sign(msg, nil)
+		if err != nil {
+			return nil, fmt.Errorf(`%s: %w`, logp, err)
+		}
+	}
 
 	_, err = cl.Write(msg.packet)
 	if err != nil {
@@ -137,6 +150,13 @@ func (cl *TCPClient) Query(msg *Message) (res *Message, err error) {
 		return nil, fmt.Errorf(`%s: %w`, logp, err)
 	}
 
+	if cl.tsigKey != nil {
+		_, err = cl.tsigKey.Verify(res, reqMAC)
+		if err != nil {
+			return res, fmt.Errorf(`%s: %w`, logp, err)
+		}
+	}
+
 	return res, nil
 }
 
@@ -151,12 +171,125 @@ func (cl *TCPClient) SetRemoteAddr(addr string) (err error) {
 	return
 }
 
+// SetTSIGKey set the key to sign the query and verify the response using
+// TSIG, RFC 8945.
+// Set it to nil to disable signing.
+func (cl *TCPClient) SetTSIGKey(key *TSIGKey) {
+	cl.tsigKey = key
+}
+
 // SetTimeout for sending and receiving packet.
 func (cl *TCPClient) SetTimeout(t time.Duration) {
 	cl.readTimeout = t
 	cl.writeTimeout = t
 }
 
+// Transfer request the full zone transfer (AXFR) of origin from the name
+// server and store the received records into new Zone.
+// The returned zone has empty Path, use [Zone.Save] after setting the
+// Path to store it.
+func (cl *TCPClient) Transfer(origin string) (zone *Zone, err error) {
+	var logp = `Transfer`
+
+	if cl.conn == nil {
+		return nil, fmt.Errorf(`%s: no active connection`, logp)
+	}
+
+	zone = NewZone(``, origin)
+
+	var msg = NewMessage()
+
+	msg.Header.ID = getNextID()
+	msg.Header.QDCount = 1
+	msg.Question = MessageQuestion{
+		Name:  zone.Origin,
+		Type:  RecordTypeAXFR,
+		Class: RecordClassIN,
+	}
+
+	_, err = msg.Pack()
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	_, err = cl.Write(msg.packet)
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	var (
+		packet []byte
+		res    *Message
+		rrsigs []ResourceRecord
+		rr     ResourceRecord
+		x      int
+		nsoa   int
+	)
+	for nsoa < 2 {
+		packet, err = cl.recv()
+		if err != nil {
+			return nil, fmt.Errorf(`%s: %w`, logp, err)
+		}
+
+		res, err = UnpackMessage(packet)
+		if err != nil {
+			return nil, fmt.Errorf(`%s: %w`, logp, err)
+		}
+		if res.Header.ID != msg.Header.ID {
+			return nil, fmt.Errorf(`%s: unmatched response ID %d`,
+				logp, res.Header.ID)
+		}
+		if res.Header.RCode != RCodeOK {
+			return nil, fmt.Errorf(`%s: %s: response code %s`, logp,
+				zone.Origin, rcodeNames[res.Header.RCode])
+		}
+
+		for _, rr = range res.Answer {
+			toZoneRecord(&rr)
+
+			if rr.Type == RecordTypeSOA && rr.Name == zone.Origin {
+				if nsoa == 0 {
+					err = zone.add(&rr)
+					if err != nil {
+						return nil, fmt.Errorf(`%s: %w`, logp, err)
+					}
+				}
+				nsoa++
+				continue
+			}
+			if nsoa == 0 {
+				return nil, fmt.Errorf(`%s: %s: missing SOA on the first record`,
+					logp, zone.Origin)
+			}
+			if nsoa > 1 {
+				return nil, fmt.Errorf(`%s: %s: record after the last SOA`,
+					logp, zone.Origin)
+			}
+			if rr.Type == RecordTypeRRSIG {
+				// Add the RRSIG after all records, so it can be
+				// attached to the records that it covered.
+				rrsigs = append(rrsigs, rr)
+				continue
+			}
+			var newrr = rr
+			err = zone.add(&newrr)
+			if err != nil {
+				return nil, fmt.Errorf(`%s: %w`, logp, err)
+			}
+		}
+	}
+	for x = range rrsigs {
+		err = zone.add(&rrsigs[x])
+		if err != nil {
+			return nil, fmt.Errorf(`%s: %w`, logp, err)
+		}
+	}
+
+	zone.packMessages()
+
+	return zone, nil
+}
+
 // Write raw DNS response message on active connection.
 // This method is only used by server to write the response of query to
 // client.
@@ -198,22 +331,27 @@ func (cl *TCPClient) recv() (packet []byte, err error) {
 		}
 	}
 
-	var n int
-
-	packet = make([]byte, maxTCPPacketSize)
+	var prefix = make([]byte, 2)
 
-	n, err = cl.conn.Read(packet)
+	_, err = io.ReadFull(cl.conn, prefix)
 	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
 		return nil, fmt.Errorf(`%s: %w`, logp, err)
 	}
-	if n == 0 {
-		return nil, io.EOF
-	}
-	if n < 2 {
+
+	var size = int(prefix[0])<<8 | int(prefix[1])
+	if size == 0 {
 		return nil, fmt.Errorf(`%s: invalid packet`, logp)
 	}
 
-	packet = packet[2:n]
+	packet = make([]byte, size)
+
+	_, err = io.ReadFull(cl.conn, packet)
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
 
 	return packet, nil
 }
@@ -4,6 +4,7 @@
 package dns
 
 import (
+	"bytes"
 	"testing"
 
 	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
@@ -142,3 +143,51 @@ func TestTCPClientLookup(t *testing.T) {
 		test.Assert(t, "packet", c.exp.packet, got.packet)
 	}
 }
+
+func TestTCPClient_Transfer(t *testing.T) {
+	var (
+		tdata *test.Data
+		zone  *Zone
+		err   error
+	)
+
+	tdata, err = test.LoadData(`testdata/Zone_transfer_test.txt`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zone, err = ParseZone(tdata.Input[`zone`], `transfer.test`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = zone.SetSigner(newTestZoneSigner())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_testServer.Caches.InternalPopulateZone(zone)
+
+	var cl *TCPClient
+
+	cl, err = NewTCPClient(testServerAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Close()
+
+	var got *Zone
+
+	got, err = cl.Transfer(`transfer.test`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var exp, out bytes.Buffer
+
+	_, _ = zone.WriteTo(&exp)
+	_, _ = got.WriteTo(&out)
+	test.Assert(t, `Transfer`, exp.String(), out.String())
+
+	_, err = cl.Transfer(`unknown.test`)
+	test.Assert(t, `Transfer: unknown zone`,
+		`Transfer: unknown.test.: response code ERR_REFUSED`, err.Error())
+}
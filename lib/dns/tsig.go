@@ -0,0 +1,377 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// List of TSIG algorithm names, as registered in [TSIG Algorithm Names].
+//
+// [TSIG Algorithm Names]: https://www.iana.org/assignments/tsig-algorithm-names/tsig-algorithm-names.xhtml
+const (
+	TSIGHmacSHA1   = `hmac-sha1`
+	TSIGHmacSHA256 = `hmac-sha256`
+	TSIGHmacSHA384 = `hmac-sha384`
+	TSIGHmacSHA512 = `hmac-sha512`
+)
+
+// List of TSIG error codes in [RDataTSIG.Error], RFC 8945 section 3.
+const (
+	TSIGErrBadSig  uint16 = 16
+	TSIGErrBadKey  uint16 = 17
+	TSIGErrBadTime uint16 = 18
+)
+
+// defaultTSIGFudge define the default number of seconds of error
+// permitted in TSIG time signed, as recommended by RFC 8945 section 10.
+const defaultTSIGFudge = 300
+
+// List of errors returned by [TSIGKey.Verify].
+var (
+	// ErrTSIGMissing define an error when the message does not
+	// contains TSIG record.
+	ErrTSIGMissing = errors.New(`TSIG: message is not signed`)
+
+	// ErrTSIGBadKey define an error when the key name or algorithm in
+	// TSIG record is not match with the key.
+	ErrTSIGBadKey = errors.New(`TSIG: bad key`)
+
+	// ErrTSIGBadSig define an error when the MAC in TSIG record is
+	// invalid.
+	ErrTSIGBadSig = errors.New(`TSIG: bad signature`)
+
+	// ErrTSIGBadTime define an error when the time signed in TSIG record
+	// is outside of the fudge.
+	ErrTSIGBadTime = errors.New(`TSIG: bad time`)
+)
+
+// TSIGKey contains the shared secret key to sign and verify the DNS
+// message using transaction signature (TSIG), as described in RFC 8945.
+type TSIGKey struct {
+	hash func() hash.Hash
+
+	// Name of the key, in domain name format, for example
+	// "update.example.com".
+	Name string
+
+	// Algorithm of the key, one of the TSIGHmac* constant.
+	// If its empty, default to TSIGHmacSHA256.
+	Algorithm string
+
+	// Secret contains the shared secret between client and server.
+	Secret []byte
+
+	// Fudge define the number of seconds of error permitted between
+	// the time signed and the time when message is verified.
+	// If its zero, default to 300 seconds.
+	Fudge uint16
+}
+
+// NewTSIGKey create new TSIGKey with the name, algorithm, and secret in
+// base64 encoding, as commonly generated by "tsig-keygen".
+func NewTSIGKey(name, algorithm, secret string) (key *TSIGKey, err error) {
+	var logp = `NewTSIGKey`
+
+	key = &TSIGKey{
+		Name:      name,
+		Algorithm: algorithm,
+	}
+
+	key.Secret, err = base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf(`%s: invalid secret: %w`, logp, err)
+	}
+
+	err = key.init()
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+	return key, nil
+}
+
+// init normalize and validate the key fields.
+func (key *TSIGKey) init() (err error) {
+	if key.hash != nil {
+		return nil
+	}
+
+	key.Name = strings.ToLower(strings.TrimSuffix(key.Name, `.`))
+	if len(key.Name) == 0 {
+		return errors.New(`empty TSIG key name`)
+	}
+
+	key.Algorithm = strings.ToLower(strings.TrimSuffix(key.Algorithm, `.`))
+	switch key.Algorithm {
+	case ``:
+		key.Algorithm = TSIGHmacSHA256
+		key.hash = sha256.New
+	case TSIGHmacSHA1:
+		key.hash = sha1.New
+	case TSIGHmacSHA256:
+		key.hash = sha256.New
+	case TSIGHmacSHA384:
+		key.hash = sha512.New384
+	case TSIGHmacSHA512:
+		key.hash = sha512.New
+	default:
+		return fmt.Errorf(`unknown TSIG algorithm %q`, key.Algorithm)
+	}
+
+	if len(key.Secret) == 0 {
+		key.hash = nil
+		return fmt.Errorf(`empty secret for TSIG key %q`, key.Name)
+	}
+	if key.Fudge == 0 {
+		key.Fudge = defaultTSIGFudge
+	}
+	return nil
+}
+
+// Sign the message using the key.
+// Any TSIG record in the Additional section of msg will be replaced with
+// the new one and the msg will be re-packed.
+// For signing response, the requestMAC is the MAC from the request
+// message.
+// On success it will return the MAC of the message, that can be used to
+// verify the response.
+func (key *TSIGKey) Sign(msg *Message, requestMAC []byte) (mac []byte, err error) {
+	var logp = `Sign`
+
+	err = key.init()
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+	mac, err = key.sign(msg, requestMAC, 0)
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+	return mac, nil
+}
+
+// sign the message with TSIG error code.
+func (key *TSIGKey) sign(msg *Message, requestMAC []byte, tsigErr uint16) (mac []byte, err error) {
+	msg.removeTSIG()
+
+	_, err = msg.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		now  = uint64(timeNow().Unix())
+		tsig = &RDataTSIG{
+			Algorithm:  key.Algorithm,
+			TimeSigned: now,
+			Fudge:      key.Fudge,
+			OriginalID: msg.Header.ID,
+			Error:      tsigErr,
+		}
+	)
+	if tsigErr == TSIGErrBadTime {
+		tsig.OtherData = tsig.packTimers(nil)[:6]
+	}
+	tsig.MAC = key.digest(requestMAC, msg.packet, tsig)
+
+	msg.Additional = append(msg.Additional, ResourceRecord{
+		Name:  key.Name,
+		Type:  RecordTypeTSIG,
+		Class: RecordClassANY,
+		Value: tsig,
+	})
+
+	_, err = msg.Pack()
+	if err != nil {
+		return nil, err
+	}
+	return tsig.MAC, nil
+}
+
+// Verify the TSIG record in the message msg, that is unpacked using
+// [UnpackMessage], using the key.
+// For verifying response, the requestMAC is the MAC from the request
+// message.
+// On success, the TSIG record is removed from the Additional section of
+// msg and it will return the MAC of the message.
+//
+// It will return ErrTSIGMissing if msg does not contains TSIG record,
+// ErrTSIGBadKey if the key name or algorithm does not match,
+// ErrTSIGBadSig if the MAC is invalid, or ErrTSIGBadTime if the message is
+// signed outside of permitted fudge, which is the smaller of the key Fudge
+// and the Fudge in TSIG record.
+func (key *TSIGKey) Verify(msg *Message, requestMAC []byte) (mac []byte, err error) {
+	var logp = `Verify`
+
+	err = key.init()
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	var rr, tsig = msg.tsig()
+	if tsig == nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, ErrTSIGMissing)
+	}
+	if !strings.EqualFold(strings.TrimSuffix(rr.Name, `.`), key.Name) {
+		return nil, fmt.Errorf(`%s: %w`, logp, ErrTSIGBadKey)
+	}
+	if !strings.EqualFold(strings.TrimSuffix(tsig.Algorithm, `.`), key.Algorithm) {
+		return nil, fmt.Errorf(`%s: %w`, logp, ErrTSIGBadKey)
+	}
+	switch tsig.Error {
+	case 0:
+	case TSIGErrBadKey:
+		return nil, fmt.Errorf(`%s: %w`, logp, ErrTSIGBadKey)
+	case TSIGErrBadSig:
+		return nil, fmt.Errorf(`%s: %w`, logp, ErrTSIGBadSig)
+	case TSIGErrBadTime:
+		return nil, fmt.Errorf(`%s: %w`, logp, ErrTSIGBadTime)
+	default:
+		return nil, fmt.Errorf(`%s: TSIG error %d`, logp, tsig.Error)
+	}
+
+	var off uint
+
+	off, err = msg.tsigOffset()
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	// The MAC is computed from message before the TSIG is added,
+	// with the original ID and ARCOUNT.
+	var packet = bytes.Clone(msg.packet[:off])
+	binary.BigEndian.PutUint16(packet, tsig.OriginalID)
+	binary.BigEndian.PutUint16(packet[10:], uint16(len(msg.Additional)-1))
+
+	var expMAC = key.digest(requestMAC, packet, tsig)
+	if !hmac.Equal(expMAC, tsig.MAC) {
+		return nil, fmt.Errorf(`%s: %w`, logp, ErrTSIGBadSig)
+	}
+
+	var (
+		now  = timeNow().Unix()
+		diff = now - int64(tsig.TimeSigned)
+
+		// Use the smaller fudge, so the sender cannot extend the
+		// time window permitted by the key.
+		fudge = min(key.Fudge, tsig.Fudge)
+	)
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > int64(fudge) {
+		return tsig.MAC, fmt.Errorf(`%s: %w`, logp, ErrTSIGBadTime)
+	}
+
+	msg.removeTSIG()
+
+	return tsig.MAC, nil
+}
+
+// digest compute the MAC of packet and TSIG variables, as described in
+// RFC 8945 section 4.3.
+func (key *TSIGKey) digest(requestMAC, packet []byte, tsig *RDataTSIG) []byte {
+	var h = hmac.New(key.hash, key.Secret)
+
+	if len(requestMAC) > 0 {
+		var size = binary.BigEndian.AppendUint16(nil, uint16(len(requestMAC)))
+		h.Write(size)
+		h.Write(requestMAC)
+	}
+
+	h.Write(packet)
+
+	var vars = packDomainNameCanonical(key.Name)
+
+	vars = binary.BigEndian.AppendUint16(vars, uint16(RecordClassANY))
+	vars = binary.BigEndian.AppendUint32(vars, 0)
+	vars = append(vars, packDomainNameCanonical(tsig.Algorithm)...)
+	vars = tsig.packTimers(vars)
+	vars = binary.BigEndian.AppendUint16(vars, tsig.Error)
+	vars = binary.BigEndian.AppendUint16(vars, uint16(len(tsig.OtherData)))
+	vars = append(vars, tsig.OtherData...)
+
+	h.Write(vars)
+
+	return h.Sum(nil)
+}
+
+// tsig return the TSIG record in the message, if its exist.
+// The TSIG record must be the last record in the Additional section.
+func (msg *Message) tsig() (rr *ResourceRecord, tsig *RDataTSIG) {
+	if len(msg.Additional) == 0 {
+		return nil, nil
+	}
+	rr = &msg.Additional[len(msg.Additional)-1]
+	if rr.Type != RecordTypeTSIG {
+		return nil, nil
+	}
+	tsig, _ = rr.Value.(*RDataTSIG)
+	if tsig == nil {
+		return nil, nil
+	}
+	return rr, tsig
+}
+
+// tsigOffset return the start index of TSIG record in the packet.
+func (msg *Message) tsigOffset() (off uint, err error) {
+	var (
+		n = len(msg.Answer) + len(msg.Authority) + len(msg.Additional) - 1
+
+		rr ResourceRecord
+	)
+
+	off = uint(sectionHeaderSize)
+	if msg.Header.QDCount > 0 {
+		off += uint(msg.Question.size())
+	}
+	for range n {
+		rr = ResourceRecord{}
+		off, err = rr.unpack(msg.packet, off)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return off, nil
+}
+
+// removeTSIG remove the TSIG record from the Additional section.
+func (msg *Message) removeTSIG() {
+	var rr, _ = msg.tsig()
+	if rr == nil {
+		return
+	}
+	msg.Additional = msg.Additional[:len(msg.Additional)-1]
+	msg.Header.ARCount = uint16(len(msg.Additional))
+}
+
+// tsigError add the unsigned TSIG record with error code into the message,
+// as the response of request that cannot be verified, as described in
+// RFC 8945 section 5.3.2.
+func (msg *Message) tsigError(keyName string, reqTSIG *RDataTSIG, code uint16) (err error) {
+	msg.removeTSIG()
+	msg.Additional = append(msg.Additional, ResourceRecord{
+		Name:  keyName,
+		Type:  RecordTypeTSIG,
+		Class: RecordClassANY,
+		Value: &RDataTSIG{
+			Algorithm:  reqTSIG.Algorithm,
+			TimeSigned: reqTSIG.TimeSigned,
+			Fudge:      reqTSIG.Fudge,
+			OriginalID: msg.Header.ID,
+			Error:      code,
+		},
+	})
+	_, err = msg.Pack()
+	return err
+}
@@ -0,0 +1,261 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"errors"
+	"testing"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func newTestTSIGKey(t *testing.T, name string) (key *TSIGKey) {
+	var err error
+
+	// The secret is base64 of "0123456789abcdef0123456789abcdef".
+	key, err = NewTSIGKey(name, TSIGHmacSHA256,
+		`MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestNewTSIGKey(t *testing.T) {
+	var listCase = []struct {
+		name      string
+		algorithm string
+		secret    string
+		expError  string
+	}{{
+		name:     `key.`,
+		secret:   `c2VjcmV0`,
+		expError: ``,
+	}, {
+		name:     `key`,
+		secret:   `%`,
+		expError: `NewTSIGKey: invalid secret: illegal base64 data at input byte 0`,
+	}, {
+		name:      `key`,
+		algorithm: `hmac-md5`,
+		secret:    `c2VjcmV0`,
+		expError:  `NewTSIGKey: unknown TSIG algorithm "hmac-md5"`,
+	}, {
+		name:     `key`,
+		expError: `NewTSIGKey: empty secret for TSIG key "key"`,
+	}, {
+		secret:   `c2VjcmV0`,
+		expError: `NewTSIGKey: empty TSIG key name`,
+	}}
+
+	var (
+		key *TSIGKey
+		err error
+	)
+	for _, c := range listCase {
+		key, err = NewTSIGKey(c.name, c.algorithm, c.secret)
+		if err != nil {
+			test.Assert(t, c.name+`: error`, c.expError, err.Error())
+			continue
+		}
+		test.Assert(t, `Name`, `key`, key.Name)
+		test.Assert(t, `Algorithm`, TSIGHmacSHA256, key.Algorithm)
+		test.Assert(t, `Fudge`, uint16(defaultTSIGFudge), key.Fudge)
+	}
+}
+
+func TestTSIGKey_Verify(t *testing.T) {
+	var (
+		key   = newTestTSIGKey(t, `update.example.com`)
+		other = newTestTSIGKey(t, `other.example.com`)
+	)
+
+	type testCase struct {
+		expError error
+		sign     func(msg *Message) error
+		desc     string
+	}
+
+	var listCase = []testCase{{
+		desc: `With valid signature`,
+		sign: func(msg *Message) (err error) {
+			_, err = key.Sign(msg, nil)
+			return err
+		},
+	}, {
+		desc:     `With unsigned message`,
+		expError: ErrTSIGMissing,
+		sign: func(msg *Message) (err error) {
+			_, err = msg.Pack()
+			return err
+		},
+	}, {
+		desc:     `With different key name`,
+		expError: ErrTSIGBadKey,
+		sign: func(msg *Message) (err error) {
+			_, err = other.Sign(msg, nil)
+			return err
+		},
+	}, {
+		desc:     `With modified MAC`,
+		expError: ErrTSIGBadSig,
+		sign: func(msg *Message) (err error) {
+			_, err = key.Sign(msg, nil)
+			if err != nil {
+				return err
+			}
+			// Modify the last byte of MAC.
+			msg.packet[len(msg.packet)-7]++
+			return nil
+		},
+	}, {
+		desc:     `With expired time`,
+		expError: ErrTSIGBadTime,
+		sign: func(msg *Message) (err error) {
+			_, err = key.Sign(msg, nil)
+			if err != nil {
+				return err
+			}
+			var _, tsig = msg.tsig()
+			msg.removeTSIG()
+			_, _ = msg.Pack()
+			tsig.TimeSigned -= 301
+			tsig.MAC = key.digest(nil, msg.packet, tsig)
+			msg.Additional = append(msg.Additional, ResourceRecord{
+				Name:  key.Name,
+				Type:  RecordTypeTSIG,
+				Class: RecordClassANY,
+				Value: tsig,
+			})
+			_, err = msg.Pack()
+			return err
+		},
+	}, {
+		desc:     `With large fudge from sender`,
+		expError: ErrTSIGBadTime,
+		sign: func(msg *Message) (err error) {
+			_, err = key.Sign(msg, nil)
+			if err != nil {
+				return err
+			}
+			var _, tsig = msg.tsig()
+			msg.removeTSIG()
+			_, _ = msg.Pack()
+			tsig.TimeSigned -= 3600
+			tsig.Fudge = 7200
+			tsig.MAC = key.digest(nil, msg.packet, tsig)
+			msg.Additional = append(msg.Additional, ResourceRecord{
+				Name:  key.Name,
+				Type:  RecordTypeTSIG,
+				Class: RecordClassANY,
+				Value: tsig,
+			})
+			_, err = msg.Pack()
+			return err
+		},
+	}}
+
+	var (
+		msg *Message
+		got *Message
+		err error
+	)
+	for _, c := range listCase {
+		msg = &Message{
+			Header: MessageHeader{
+				ID:      0x1234,
+				Op:      OpCodeUpdate,
+				QDCount: 1,
+			},
+			Question: MessageQuestion{
+				Name:  `example.com`,
+				Type:  RecordTypeSOA,
+				Class: RecordClassIN,
+			},
+			Authority: []ResourceRecord{{
+				Name:  `www.example.com`,
+				Type:  RecordTypeA,
+				Class: RecordClassIN,
+				TTL:   300,
+				Value: `10.0.0.1`,
+			}},
+		}
+
+		err = c.sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err = UnpackMessage(msg.packet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = key.Verify(got, nil)
+		if c.expError != nil {
+			test.Assert(t, c.desc, true, errors.Is(err, c.expError))
+			continue
+		}
+		if err != nil {
+			t.Fatalf(`%s: %s`, c.desc, err)
+		}
+		test.Assert(t, c.desc+`: Additional`, 0, len(got.Additional))
+	}
+}
+
+func TestTSIGKey_Verify_response(t *testing.T) {
+	var (
+		key = newTestTSIGKey(t, `update.example.com`)
+		req = &Message{
+			Header: MessageHeader{
+				ID:      1,
+				Op:      OpCodeUpdate,
+				QDCount: 1,
+			},
+			Question: MessageQuestion{
+				Name:  `example.com`,
+				Type:  RecordTypeSOA,
+				Class: RecordClassIN,
+			},
+		}
+
+		reqMAC []byte
+		err    error
+	)
+
+	reqMAC, err = key.Sign(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res = &Message{
+		Header: MessageHeader{
+			ID:      1,
+			Op:      OpCodeUpdate,
+			QDCount: 1,
+		},
+		Question: req.Question,
+	}
+
+	_, err = key.Sign(res, reqMAC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got *Message
+
+	got, err = UnpackMessage(res.packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = key.Verify(got, reqMAC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The response verified without request MAC should fail.
+	got, _ = UnpackMessage(res.packet)
+	_, err = key.Verify(got, nil)
+	test.Assert(t, `without request MAC`, true, errors.Is(err, ErrTSIGBadSig))
+}
@@ -20,6 +20,7 @@ import (
 type UDPClient struct {
 	addr    *net.UDPAddr // addr contains address of remote connection.
 	conn    *net.UDPConn
+	tsigKey *TSIGKey // tsigKey contains the key to sign the query.
 	timeout time.Duration
 	sync.Mutex
 }
@@ -111,12 +112,25 @@ func (cl *UDPClient) Lookup(q MessageQuestion, allowRecursion bool) (msg *Messag
 }
 
 // Query send DNS query to name server "ns" and return the unpacked response.
+// If the client has TSIG key, the req will be signed and the response will
+// be verified using the key.
 func (cl *UDPClient) Query(req *Message) (res *Message, err error) {
-	var logp = `Query`
+	var (
+		logp = `Query`
+
+		reqMAC []byte
+	)
 
 	cl.Lock()
 	defer cl.Unlock()
 
+	if cl.tsigKey != nil {
+		reqMAC, err = cl.tsigKey.Sign(req, nil)
+		if err != nil {
+			return nil, fmt.Errorf(`%s: %w`, logp, err)
+		}
+	}
+
 	_, err = cl.Write(req.packet)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", logp, err)
@@ -143,6 +157,13 @@ func (cl *UDPClient) Query(req *Message) (res *Message, err error) {
 		return nil, fmt.Errorf("%s: %w", logp, err)
 	}
 
+	if cl.tsigKey != nil {
+		_, err = cl.tsigKey.Verify(res, reqMAC)
+		if err != nil {
+			return res, fmt.Errorf(`%s: %w`, logp, err)
+		}
+	}
+
 	return res, nil
 }
 
@@ -164,6 +185,13 @@ func (cl *UDPClient) SetRemoteAddr(addr string) (err error) {
 	return
 }
 
+// SetTSIGKey set the key to sign the query and verify the response using
+// TSIG, RFC 8945.
+// Set it to nil to disable signing.
+func (cl *UDPClient) SetTSIGKey(key *TSIGKey) {
+	cl.tsigKey = key
+}
+
 // SetTimeout for sending and receiving packet.
 func (cl *UDPClient) SetTimeout(t time.Duration) {
 	cl.timeout = t
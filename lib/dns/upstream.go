@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// upstreamMaxFails define the number of consecutive failures
+	// before the upstream is taken out of rotation.
+	upstreamMaxFails = 3
+
+	// upstreamDownTime define how long the failing upstream is taken
+	// out of rotation.
+	upstreamDownTime = 30 * time.Second
+
+	// upstreamRTTWeight define the weight of smoothed RTT, the new RTT
+	// sample contributes 1/upstreamRTTWeight to the smoothed RTT.
+	upstreamRTTWeight = 8
+
+	// upstreamQueueSize define the size of queue on each upstream.
+	upstreamQueueSize = 512
+)
+
+// ForwarderStat contains the statistics of single forwarder, the
+// connection to a parent name server.
+type ForwarderStat struct {
+	// DownUntil is the time until the forwarder is taken out of
+	// rotation, after upstreamMaxFails consecutive failures.
+	// It is zero if the forwarder is up.
+	DownUntil time.Time
+
+	// Name of forwarder, for example "UDP-0" or "corp.internal/DoT-0"
+	// for forwarder of [ForwardRule].
+	Name string
+
+	// NameServer is the address of parent name server.
+	NameServer string
+
+	// RTT is the smoothed round-trip time of successful queries.
+	RTT time.Duration
+
+	// Queries is the number of queries sent to parent name server.
+	Queries uint64
+
+	// Failures is the number of queries that failed.
+	Failures uint64
+
+	// IsConnected is true if the forwarder is connected to the parent
+	// name server.
+	IsConnected bool
+}
+
+// FailureRate return the ratio of failed queries to total queries,
+// between 0 and 1.
+func (stat ForwarderStat) FailureRate() float64 {
+	if stat.Queries == 0 {
+		return 0
+	}
+	return float64(stat.Failures) / float64(stat.Queries)
+}
+
+// upstream contains the queue and statistics of single forwarder.
+type upstream struct {
+	downUntil time.Time
+
+	// queue of requests to be forwarded to this upstream.
+	queue chan *request
+
+	tag        string
+	nameserver string
+
+	// proto is the connection type of upstream, one of connType.
+	proto string
+
+	rtt time.Duration
+
+	// totalElapsed is the total elapsed time of forwarded requests,
+	// since its received by server until its answered.
+	totalElapsed time.Duration
+
+	// nanswered is the number of requests answered by this upstream.
+	nanswered int64
+
+	queries  uint64
+	failures uint64
+
+	// nfail is the number of consecutive failures.
+	nfail int
+
+	isConnected bool
+
+	sync.Mutex
+}
+
+func newUpstream(tag, proto, nameserver string) (up *upstream) {
+	up = &upstream{
+		queue:      make(chan *request, upstreamQueueSize),
+		tag:        tag,
+		nameserver: nameserver,
+		proto:      proto,
+	}
+	return up
+}
+
+// isAvailable return true if upstream is connected and not in down time.
+func (up *upstream) isAvailable(now time.Time) (ok bool) {
+	up.Lock()
+	ok = up.isConnected && !now.Before(up.downUntil)
+	up.Unlock()
+	return ok
+}
+
+// connected return true if upstream is connected.
+func (up *upstream) connected() (ok bool) {
+	up.Lock()
+	ok = up.isConnected
+	up.Unlock()
+	return ok
+}
+
+func (up *upstream) setConnected(isConnected bool) {
+	up.Lock()
+	up.isConnected = isConnected
+	up.Unlock()
+}
+
+// smoothedRTT return the smoothed RTT of upstream.
+func (up *upstream) smoothedRTT() (rtt time.Duration) {
+	up.Lock()
+	rtt = up.rtt
+	up.Unlock()
+	return rtt
+}
+
+// fail record the failed query.
+// After upstreamMaxFails consecutive failures, the upstream is taken out
+// of rotation for upstreamDownTime.
+func (up *upstream) fail(now time.Time) {
+	up.Lock()
+	up.queries++
+	up.failures++
+	up.nfail++
+	if up.nfail >= upstreamMaxFails {
+		up.downUntil = now.Add(upstreamDownTime)
+		up.nfail = 0
+	}
+	up.Unlock()
+}
+
+// succeed record the successful query with round-trip time rtt, and put
+// the upstream back to rotation.
+func (up *upstream) succeed(rtt time.Duration) {
+	up.Lock()
+	up.queries++
+	up.nfail = 0
+	up.downUntil = time.Time{}
+	if up.rtt == 0 {
+		up.rtt = rtt
+	} else {
+		up.rtt += (rtt - up.rtt) / upstreamRTTWeight
+	}
+	up.Unlock()
+}
+
+// answered record the elapsed time of answered request, and return the
+// total answered and its average elapsed time.
+func (up *upstream) answered(elapsed time.Duration) (total int64, avg time.Duration) {
+	up.Lock()
+	up.totalElapsed += elapsed
+	up.nanswered++
+	total = up.nanswered
+	avg = up.totalElapsed / time.Duration(total)
+	up.Unlock()
+	return total, avg
+}
+
+// stat return the current statistics of upstream.
+func (up *upstream) stat(now time.Time) (stat ForwarderStat) {
+	up.Lock()
+	stat = ForwarderStat{
+		Name:        up.tag,
+		NameServer:  up.nameserver,
+		RTT:         up.rtt,
+		Queries:     up.queries,
+		Failures:    up.failures,
+		IsConnected: up.isConnected,
+	}
+	if now.Before(up.downUntil) {
+		stat.DownUntil = up.downUntil
+	}
+	up.Unlock()
+	return stat
+}
+
+// enqueue put the request into the queue without blocking.
+// It return false if the queue is full.
+func (up *upstream) enqueue(req *request) bool {
+	select {
+	case up.queue <- req:
+		return true
+	default:
+		return false
+	}
+}
+
+// drain remove all pending requests from the queue.
+func (up *upstream) drain() (reqs []*request) {
+	var req *request
+	for {
+		select {
+		case req = <-up.queue:
+			reqs = append(reqs, req)
+		default:
+			return reqs
+		}
+	}
+}
@@ -0,0 +1,946 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// defaultTrustAnchors contains the DS records of the root zone KSK, as
+// published by IANA in root-anchors.xml.
+var defaultTrustAnchors = []string{
+	`. IN DS 20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D`,
+	`. IN DS 38696 8 2 683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16`,
+}
+
+// errDNSSECInsecure indicates that the name is proven to be under unsigned
+// delegation.
+var errDNSSECInsecure = errors.New(`insecure delegation`)
+
+// nsec3MaxIterations define the maximum NSEC3 iterations that are hashed
+// by validator.
+// The response with NSEC3 above this iterations is treated as insecure, as
+// recommended by RFC 9276 section 3.2.
+const nsec3MaxIterations = 150
+
+// errDNSSECNoCut indicates that the name is not a zone cut.
+var errDNSSECNoCut = errors.New(`not a zone cut`)
+
+// validator verify the DNSSEC chain of trust of the response received from
+// parent name servers, start from the trust anchors down to the RRset
+// signer.
+type validator struct {
+	// anchors contains the trusted DS records indexed by zone name.
+	anchors map[string][]*RDataDS
+
+	// keys contains the verified DNSKEY indexed by zone name.
+	keys map[string]*validatorKeys
+
+	sync.Mutex
+}
+
+// validatorKeys contains the verified DNSKEY of a zone.
+type validatorKeys struct {
+	list []*RDataDNSKEY
+
+	// expiredAt define the time, in seconds since epoch, when the
+	// keys must be verified again.
+	expiredAt int64
+}
+
+// rrset contains the list of RR with the same name and type, including
+// their signatures.
+type rrset struct {
+	// sig contains the RRSIG that verify the RRset, set by
+	// verifyRRSet.
+	sig *RDataRRSIG
+
+	name string
+	list []ResourceRecord
+	sigs []*RDataRRSIG
+
+	rtype RecordType
+}
+
+func newValidator(anchors map[string][]*RDataDS) (val *validator) {
+	val = &validator{
+		anchors: anchors,
+		keys:    make(map[string]*validatorKeys),
+	}
+	return val
+}
+
+// parseTrustAnchors parse the list of DS records in zone file format into
+// map of zone name and its DS records.
+func parseTrustAnchors(list []string) (anchors map[string][]*RDataDS, err error) {
+	var (
+		line   string
+		fields [][]byte
+		x      int
+	)
+
+	anchors = make(map[string][]*RDataDS)
+
+	for _, line = range list {
+		fields = bytes.Fields([]byte(line))
+
+		var idx = -1
+		for x = 1; x < len(fields); x++ {
+			if strings.EqualFold(string(fields[x]), `DS`) {
+				idx = x
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf(`invalid trust anchor %q: missing DS`, line)
+		}
+
+		var ds = &RDataDS{}
+
+		err = ds.parse(fields[idx+1:])
+		if err != nil {
+			return nil, fmt.Errorf(`invalid trust anchor %q: %w`, line, err)
+		}
+
+		var zone = dnssecName(string(fields[0]))
+
+		anchors[zone] = append(anchors[zone], ds)
+	}
+	return anchors, nil
+}
+
+// groupRRSets group the list of RR by their name and type, and attach the
+// RRSIG into RRset that it cover.
+func groupRRSets(list []ResourceRecord) (sets []*rrset) {
+	var (
+		rr  ResourceRecord
+		set *rrset
+	)
+	for _, rr = range list {
+		if rr.Type == RecordTypeOPT || rr.Type == RecordTypeRRSIG {
+			continue
+		}
+		set = findRRSet(sets, rr.Name, rr.Type)
+		if set == nil {
+			set = &rrset{
+				name:  dnssecName(rr.Name),
+				rtype: rr.Type,
+			}
+			sets = append(sets, set)
+		}
+		set.list = append(set.list, rr)
+	}
+	for _, rr = range list {
+		if rr.Type != RecordTypeRRSIG {
+			continue
+		}
+		var rrsig, ok = rr.Value.(*RDataRRSIG)
+		if !ok {
+			continue
+		}
+		set = findRRSet(sets, rr.Name, rrsig.TypeCovered)
+		if set == nil {
+			continue
+		}
+		set.sigs = append(set.sigs, rrsig)
+	}
+	return sets
+}
+
+// findRRSet find the RRset by name and type.
+func findRRSet(sets []*rrset, name string, rtype RecordType) *rrset {
+	var set *rrset
+
+	name = dnssecName(name)
+	for _, set = range sets {
+		if set.name == name && set.rtype == rtype {
+			return set
+		}
+	}
+	return nil
+}
+
+// validate the DNSSEC chain of trust of the response.
+// It will return true if the response is secure, false if the response is
+// insecure (not under trust anchors or under unsigned delegation), or an
+// error if the response is bogus.
+func (val *validator) validate(cl Client, res *Message) (isSecure bool, err error) {
+	var (
+		qname  = dnssecName(res.Question.Name)
+		anchor string
+		ok     bool
+	)
+
+	anchor, ok = val.findAnchor(qname)
+	if !ok {
+		return false, nil
+	}
+
+	var (
+		answer    = groupRRSets(res.Answer)
+		authority = groupRRSets(res.Authority)
+	)
+
+	if !isRRSetsSigned(answer) && !isRRSetsSigned(authority) {
+		err = val.proveInsecure(cl, anchor, qname)
+		if errors.Is(err, errDNSSECInsecure) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var set *rrset
+	for _, set = range answer {
+		err = val.verifyRRSet(cl, anchor, set)
+		if err != nil {
+			if errors.Is(err, errDNSSECInsecure) {
+				return false, nil
+			}
+			return false, err
+		}
+	}
+	for _, set = range authority {
+		if len(set.sigs) == 0 {
+			if set.rtype == RecordTypeNSEC || set.rtype == RecordTypeNSEC3 {
+				return false, fmt.Errorf(`missing RRSIG for %s %s`,
+					set.name, recordTypeName(set.rtype))
+			}
+			// Unsigned delegation NS and glue is allowed.
+			continue
+		}
+		err = val.verifyRRSet(cl, anchor, set)
+		if err != nil {
+			if errors.Is(err, errDNSSECInsecure) {
+				return false, nil
+			}
+			return false, err
+		}
+	}
+
+	if isNSEC3Insecure(authority) {
+		return false, nil
+	}
+
+	// The answer that is expanded from wildcard must be accompanied
+	// by proof that the name itself does not exist, as described in
+	// RFC 4035 section 5.3.4.
+	for _, set = range answer {
+		if int(set.sig.Labels) >= dnssecLabels(set.name) {
+			continue
+		}
+		err = verifyWildcardAnswer(authority, set.name, int(set.sig.Labels))
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if len(answer) == 0 {
+		err = verifyDenial(authority, qname, res.Question.Type, res.Header.RCode)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// findAnchor find the closest trust anchor that enclose the name.
+func (val *validator) findAnchor(name string) (anchor string, ok bool) {
+	for {
+		_, ok = val.anchors[name]
+		if ok {
+			return name, true
+		}
+		name, ok = dnssecParent(name)
+		if !ok {
+			return ``, false
+		}
+	}
+}
+
+// lookup send the query with DNSSEC OK bit to the name server.
+func (val *validator) lookup(cl Client, name string, rtype RecordType) (res *Message, err error) {
+	var msg = NewMessage()
+
+	msg.Header.ID = getNextID()
+	msg.Question.Name = dnssecQueryName(name)
+	msg.Question.Type = rtype
+	msg.Additional = []ResourceRecord{{
+		Type:  RecordTypeOPT,
+		Class: RecordClass(maxUDPPacketSize),
+		Value: &RDataOPT{DO: true},
+	}}
+
+	_, err = msg.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err = cl.Query(msg)
+	if err != nil {
+		return nil, err
+	}
+	if res.Header.IsTC {
+		return nil, fmt.Errorf(`truncated response for %s %s`, msg.Question.Name,
+			recordTypeName(rtype))
+	}
+	if res.Header.RCode != RCodeOK && res.Header.RCode != RCodeErrName {
+		return nil, fmt.Errorf(`response code %s for %s %s`, rcodeNames[res.Header.RCode],
+			msg.Question.Name, recordTypeName(rtype))
+	}
+	return res, nil
+}
+
+// lookupDS lookup and verify the DS records of zone from its parent.
+// It will return errDNSSECInsecure if the zone is proven to be unsigned
+// delegation, or errDNSSECNoCut if the name is not a delegation point.
+func (val *validator) lookupDS(cl Client, anchor, zone string) (list []*RDataDS, err error) {
+	var res *Message
+
+	res, err = val.lookup(cl, zone, RecordTypeDS)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		answer = groupRRSets(res.Answer)
+		set    = findRRSet(answer, zone, RecordTypeDS)
+		rrsig  *RDataRRSIG
+	)
+	if set != nil {
+		for _, rrsig = range set.sigs {
+			if dnssecName(rrsig.SignerName) == zone {
+				return nil, fmt.Errorf(`DS of %q is signed by itself`, zone)
+			}
+		}
+		err = val.verifyRRSet(cl, anchor, set)
+		if err != nil {
+			return nil, err
+		}
+		var rr ResourceRecord
+		for _, rr = range set.list {
+			var ds, ok = rr.Value.(*RDataDS)
+			if ok {
+				list = append(list, ds)
+			}
+		}
+		return list, nil
+	}
+
+	// No DS, the authority must contains signed proof of non-existence.
+	var authority = groupRRSets(res.Authority)
+	for _, set = range authority {
+		if len(set.sigs) == 0 {
+			continue
+		}
+		err = val.verifyRRSet(cl, anchor, set)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if isNSEC3Insecure(authority) {
+		return nil, errDNSSECInsecure
+	}
+
+	var proof = proveName(authority, zone)
+	switch {
+	case proof.isMatch:
+		if slices.Contains(proof.types, RecordTypeDS) {
+			return nil, fmt.Errorf(`missing DS for %q`, zone)
+		}
+		if slices.Contains(proof.types, RecordTypeNS) &&
+			!slices.Contains(proof.types, RecordTypeSOA) {
+			return nil, errDNSSECInsecure
+		}
+		return nil, errDNSSECNoCut
+	case proof.isOptOut:
+		return nil, errDNSSECInsecure
+	case proof.isCover:
+		return nil, errDNSSECNoCut
+	}
+	return nil, fmt.Errorf(`missing proof of non-existence DS for %q`, zone)
+}
+
+// proveInsecure check if the name is under unsigned delegation by walking
+// down the DS of each name from the trust anchor.
+// It will return errDNSSECInsecure if the name is proven as insecure.
+func (val *validator) proveInsecure(cl Client, anchor, name string) (err error) {
+	var (
+		labels = strings.Split(name, `.`)
+		zone   string
+		x      int
+	)
+	if len(name) == 0 {
+		labels = nil
+	}
+
+	for x = len(labels) - 1; x >= 0; x-- {
+		zone = strings.Join(labels[x:], `.`)
+		if !dnssecIsSubdomain(zone, anchor) || zone == anchor {
+			continue
+		}
+		_, err = val.lookupDS(cl, anchor, zone)
+		if err != nil {
+			if errors.Is(err, errDNSSECNoCut) {
+				continue
+			}
+			return err
+		}
+	}
+	return fmt.Errorf(`missing RRSIG for %q`, name)
+}
+
+// verifyRRSet verify the signature of RRset using the verified DNSKEY from
+// its signer.
+func (val *validator) verifyRRSet(cl Client, anchor string, set *rrset) (err error) {
+	if len(set.sigs) == 0 {
+		return fmt.Errorf(`missing RRSIG for %s %s`, set.name, recordTypeName(set.rtype))
+	}
+
+	var (
+		now = uint32(timeNow().Unix())
+
+		rrsig  *RDataRRSIG
+		dnskey *RDataDNSKEY
+		keys   []*RDataDNSKEY
+		signer string
+	)
+
+	err = fmt.Errorf(`no valid RRSIG for %s %s`, set.name, recordTypeName(set.rtype))
+
+	for _, rrsig = range set.sigs {
+		signer = dnssecName(rrsig.SignerName)
+		if !dnssecIsSubdomain(set.name, signer) {
+			continue
+		}
+		if !dnssecIsSubdomain(signer, anchor) {
+			continue
+		}
+		if !rrsig.isValidAt(now) {
+			err = fmt.Errorf(`expired RRSIG for %s %s`, set.name, recordTypeName(set.rtype))
+			continue
+		}
+
+		var errKeys error
+
+		keys, errKeys = val.zoneKeys(cl, anchor, signer)
+		if errKeys != nil {
+			if errors.Is(errKeys, errDNSSECInsecure) {
+				return errKeys
+			}
+			err = errKeys
+			continue
+		}
+
+		for _, dnskey = range keys {
+			if dnskey.KeyTag() != rrsig.KeyTag || dnskey.Algorithm != rrsig.Algorithm {
+				continue
+			}
+			var errVerify = dnssecVerify(rrsig, dnskey, set.list)
+			if errVerify == nil {
+				set.sig = rrsig
+				return nil
+			}
+			err = fmt.Errorf(`%s %s: %w`, set.name, recordTypeName(set.rtype), errVerify)
+		}
+	}
+	return err
+}
+
+// zoneKeys return the verified DNSKEY of zone.
+func (val *validator) zoneKeys(cl Client, anchor, zone string) (keys []*RDataDNSKEY, err error) {
+	var (
+		now = timeNow().Unix()
+
+		cached *validatorKeys
+		ok     bool
+	)
+
+	val.Lock()
+	cached, ok = val.keys[zone]
+	val.Unlock()
+	if ok && cached.expiredAt > now {
+		return cached.list, nil
+	}
+
+	var listDS []*RDataDS
+
+	if zone == anchor {
+		listDS = val.anchors[zone]
+	} else {
+		listDS, err = val.lookupDS(cl, anchor, zone)
+		if err != nil {
+			if errors.Is(err, errDNSSECNoCut) {
+				return nil, fmt.Errorf(`%q is not a zone`, zone)
+			}
+			return nil, err
+		}
+	}
+
+	// Ignore DS with unsupported algorithm or digest type, as described
+	// in RFC 4035 section 5.2.
+	listDS = slices.DeleteFunc(slices.Clone(listDS), func(ds *RDataDS) bool {
+		var _, errHash = dnssecHash(ds.Algorithm)
+		if errHash != nil {
+			return true
+		}
+		switch ds.DigestType {
+		case DSDigestSHA1, DSDigestSHA256, DSDigestSHA384:
+			return false
+		}
+		return true
+	})
+	if len(listDS) == 0 {
+		return nil, errDNSSECInsecure
+	}
+
+	var res *Message
+
+	res, err = val.lookup(cl, zone, RecordTypeDNSKEY)
+	if err != nil {
+		return nil, err
+	}
+
+	var set = findRRSet(groupRRSets(res.Answer), zone, RecordTypeDNSKEY)
+	if set == nil {
+		return nil, fmt.Errorf(`missing DNSKEY for %q`, zone)
+	}
+
+	var (
+		rr     ResourceRecord
+		dnskey *RDataDNSKEY
+		sep    []*RDataDNSKEY
+		ds     *RDataDS
+		ttl    = set.list[0].TTL
+	)
+	for _, rr = range set.list {
+		dnskey, ok = rr.Value.(*RDataDNSKEY)
+		if !ok {
+			continue
+		}
+		if rr.TTL < ttl {
+			ttl = rr.TTL
+		}
+		if !dnskey.IsZoneKey() || dnskey.Flags&DNSKEYFlagRevoke != 0 {
+			continue
+		}
+		keys = append(keys, dnskey)
+		for _, ds = range listDS {
+			if ds.isMatch(zone, dnskey) {
+				sep = append(sep, dnskey)
+				break
+			}
+		}
+	}
+	if len(sep) == 0 {
+		return nil, fmt.Errorf(`no DNSKEY match with DS for %q`, zone)
+	}
+
+	err = fmt.Errorf(`no valid RRSIG for %s DNSKEY`, zone)
+
+	var (
+		rrsig   *RDataRRSIG
+		isValid bool
+	)
+	for _, rrsig = range set.sigs {
+		if dnssecName(rrsig.SignerName) != zone {
+			continue
+		}
+		if !rrsig.isValidAt(uint32(now)) {
+			err = fmt.Errorf(`expired RRSIG for %s DNSKEY`, zone)
+			continue
+		}
+		for _, dnskey = range sep {
+			if dnskey.KeyTag() != rrsig.KeyTag || dnskey.Algorithm != rrsig.Algorithm {
+				continue
+			}
+			if dnssecVerify(rrsig, dnskey, set.list) == nil {
+				isValid = true
+				break
+			}
+		}
+		if isValid {
+			break
+		}
+	}
+	if !isValid {
+		return nil, err
+	}
+
+	val.Lock()
+	val.keys[zone] = &validatorKeys{
+		list:      keys,
+		expiredAt: now + int64(ttl),
+	}
+	val.Unlock()
+
+	return keys, nil
+}
+
+// isRRSetsSigned return true if at least one of RRset has signature.
+func isRRSetsSigned(sets []*rrset) bool {
+	var set *rrset
+	for _, set = range sets {
+		if len(set.sigs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// nameProof contains the result of NSEC or NSEC3 records that prove the
+// existence or non-existence of name.
+type nameProof struct {
+	// closestEncloser contains the longest existing ancestor of name,
+	// derived from the owner and next domain of NSEC that cover the
+	// name.
+	closestEncloser string
+
+	// types contains the list of RR type that exist on the name, only
+	// if isMatch is true.
+	types []RecordType
+
+	// isMatch is true if the NSEC or NSEC3 owner match with name.
+	isMatch bool
+
+	// isCover is true if the name is covered by NSEC or NSEC3, which
+	// means the name does not exist.
+	isCover bool
+
+	// isOptOut is true if the name is covered by NSEC3 with Opt-Out
+	// flag.
+	isOptOut bool
+
+	// isEmptyNonTerminal is true if the name is covered by NSEC and the
+	// next domain is under the name, which means the name exist but
+	// does not have any RR.
+	isEmptyNonTerminal bool
+}
+
+// proveName find the NSEC or NSEC3 in the list of RRset that match or cover
+// the name.
+func proveName(sets []*rrset, name string) (proof nameProof) {
+	var (
+		set *rrset
+		rr  ResourceRecord
+	)
+	for _, set = range sets {
+		for _, rr = range set.list {
+			switch v := rr.Value.(type) {
+			case *RDataNSEC:
+				if set.name == name {
+					proof.isMatch = true
+					proof.types = v.Types
+					return proof
+				}
+				if isNSECCover(set.name, v.NextDomain, name) {
+					proof.isCover = true
+					proof.closestEncloser = nsecClosestEncloser(set.name,
+						v.NextDomain, name)
+					if dnssecName(v.NextDomain) != name &&
+						dnssecIsSubdomain(v.NextDomain, name) {
+						proof.isEmptyNonTerminal = true
+					}
+				}
+
+			case *RDataNSEC3:
+				var hashOwner, zone, _ = strings.Cut(set.name, `.`)
+				if v.HashAlgorithm != NSEC3HashSHA1 || !dnssecIsSubdomain(name, zone) {
+					continue
+				}
+				if v.Iterations > nsec3MaxIterations {
+					continue
+				}
+				var (
+					hashName = strings.ToLower(nsec3Encoding.EncodeToString(
+						nsec3Hash(name, v.Salt, v.Iterations)))
+					hashNext = strings.ToLower(nsec3Encoding.EncodeToString(v.NextHashedOwner))
+				)
+				if hashOwner == hashName {
+					proof.isMatch = true
+					proof.types = v.Types
+					return proof
+				}
+				if isHashCover(hashOwner, hashNext, hashName) {
+					proof.isCover = true
+					if v.IsOptOut() {
+						proof.isOptOut = true
+					}
+				}
+			}
+		}
+	}
+	return proof
+}
+
+// isNSECCover return true if the name is between owner and next in
+// canonical order.
+// If next sort before owner, the NSEC is the last one in the zone.
+func isNSECCover(owner, next, name string) bool {
+	if dnssecCompareName(owner, name) >= 0 {
+		return false
+	}
+	if dnssecCompareName(next, owner) <= 0 {
+		return dnssecIsSubdomain(name, next)
+	}
+	return dnssecCompareName(name, next) < 0
+}
+
+// isHashCover return true if the hash is between owner and next hash.
+func isHashCover(owner, next, hash string) bool {
+	if strings.Compare(next, owner) <= 0 {
+		return hash > owner || hash < next
+	}
+	return hash > owner && hash < next
+}
+
+// nsecClosestEncloser return the closest encloser of name that is covered
+// by NSEC with owner and next domain, which is the longest common ancestor
+// of name with owner or next.
+func nsecClosestEncloser(owner, next, name string) string {
+	var (
+		ceOwner = dnssecCommonAncestor(owner, name)
+		ceNext  = dnssecCommonAncestor(next, name)
+	)
+	if dnssecLabels(ceNext) > dnssecLabels(ceOwner) {
+		return ceNext
+	}
+	return ceOwner
+}
+
+// dnssecCommonAncestor return the longest common ancestor of a and b.
+func dnssecCommonAncestor(a, b string) string {
+	a = dnssecName(a)
+	b = dnssecName(b)
+	if len(a) == 0 || len(b) == 0 {
+		return ``
+	}
+
+	var (
+		labelsA = strings.Split(a, `.`)
+		labelsB = strings.Split(b, `.`)
+		xa      = len(labelsA) - 1
+		xb      = len(labelsB) - 1
+	)
+	for ; xa >= 0 && xb >= 0; xa, xb = xa-1, xb-1 {
+		if labelsA[xa] != labelsB[xb] {
+			break
+		}
+	}
+	return strings.Join(labelsA[xa+1:], `.`)
+}
+
+// dnssecWildcard return the wildcard name at the closest encloser.
+func dnssecWildcard(closestEncloser string) string {
+	if len(closestEncloser) == 0 {
+		return `*`
+	}
+	return `*.` + closestEncloser
+}
+
+// isNSEC3 return true if the list of RRset contains NSEC3.
+func isNSEC3(sets []*rrset) bool {
+	var set *rrset
+	for _, set = range sets {
+		if set.rtype == RecordTypeNSEC3 {
+			return true
+		}
+	}
+	return false
+}
+
+// isNSEC3Insecure return true if the list of RRset contains NSEC3 with
+// iterations above nsec3MaxIterations.
+func isNSEC3Insecure(sets []*rrset) bool {
+	var (
+		set *rrset
+		rr  ResourceRecord
+	)
+	for _, set = range sets {
+		for _, rr = range set.list {
+			var nsec3, ok = rr.Value.(*RDataNSEC3)
+			if ok && nsec3.Iterations > nsec3MaxIterations {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nsec3ClosestEncloser find the closest encloser of name that does not
+// exist, using the closest encloser proof as described in RFC 5155 section
+// 8.3.
+// The proof consist of NSEC3 that match the closest encloser and NSEC3 that
+// cover the next closer name.
+// It will return isOptOut true if the NSEC3 that cover the next closer name
+// has the Opt-Out flag.
+func nsec3ClosestEncloser(sets []*rrset, name string) (closestEncloser string, isOptOut bool, err error) {
+	var (
+		nextCloser = name
+		proof      nameProof
+		ok         bool
+	)
+
+	proof = proveName(sets, name)
+	if proof.isMatch {
+		return ``, false, fmt.Errorf(`%q exist`, name)
+	}
+
+	closestEncloser = name
+	for {
+		closestEncloser, ok = dnssecParent(closestEncloser)
+		if !ok {
+			return ``, false, fmt.Errorf(`missing closest encloser proof for %q`, name)
+		}
+		proof = proveName(sets, closestEncloser)
+		if proof.isMatch {
+			break
+		}
+		nextCloser = closestEncloser
+	}
+
+	if slices.Contains(proof.types, RecordTypeDNAME) ||
+		(slices.Contains(proof.types, RecordTypeNS) &&
+			!slices.Contains(proof.types, RecordTypeSOA)) {
+		return ``, false, fmt.Errorf(`closest encloser %q of %q is a delegation`,
+			closestEncloser, name)
+	}
+
+	proof = proveName(sets, nextCloser)
+	if !proof.isCover {
+		return ``, false, fmt.Errorf(`missing proof of non-existence for next closer %q`,
+			nextCloser)
+	}
+	return closestEncloser, proof.isOptOut, nil
+}
+
+// verifyDenial verify that the authority contains the NSEC or NSEC3 that
+// prove the non-existence of name or its type.
+func verifyDenial(authority []*rrset, name string, rtype RecordType, rcode ResponseCode) error {
+	if isNSEC3(authority) {
+		return verifyDenialNSEC3(authority, name, rtype, rcode)
+	}
+	return verifyDenialNSEC(authority, name, rtype, rcode)
+}
+
+// verifyDenialNSEC verify the non-existence of name or its type using
+// NSEC, as described in RFC 4035 section 5.4.
+// The name error require NSEC that cover the name and NSEC that cover the
+// wildcard at the closest encloser.
+// The no data response require NSEC that match the name, or NSEC that
+// match the wildcard at the closest encloser, without the type.
+func verifyDenialNSEC(authority []*rrset, name string, rtype RecordType, rcode ResponseCode) error {
+	var proof = proveName(authority, name)
+
+	if rcode == RCodeErrName {
+		if proof.isMatch || proof.isEmptyNonTerminal {
+			return fmt.Errorf(`%q exist`, name)
+		}
+		if !proof.isCover {
+			return fmt.Errorf(`missing proof of non-existence for %q`, name)
+		}
+		var wildcard = dnssecWildcard(proof.closestEncloser)
+		proof = proveName(authority, wildcard)
+		if proof.isMatch || !proof.isCover {
+			return fmt.Errorf(`missing proof of non-existence for %q`, wildcard)
+		}
+		return nil
+	}
+
+	if proof.isMatch {
+		return verifyDenialTypes(proof.types, name, rtype)
+	}
+	if proof.isEmptyNonTerminal {
+		return nil
+	}
+	if proof.isCover {
+		var wildcard = dnssecWildcard(proof.closestEncloser)
+		proof = proveName(authority, wildcard)
+		if proof.isMatch {
+			return verifyDenialTypes(proof.types, wildcard, rtype)
+		}
+	}
+	return fmt.Errorf(`missing proof of non-existence for %s %q`, recordTypeName(rtype), name)
+}
+
+// verifyDenialNSEC3 verify the non-existence of name or its type using
+// NSEC3, as described in RFC 5155 section 8.4 until 8.7.
+func verifyDenialNSEC3(authority []*rrset, name string, rtype RecordType, rcode ResponseCode) (err error) {
+	var proof = proveName(authority, name)
+
+	if rcode != RCodeErrName && proof.isMatch {
+		return verifyDenialTypes(proof.types, name, rtype)
+	}
+
+	var (
+		closestEncloser string
+		isOptOut        bool
+	)
+	closestEncloser, isOptOut, err = nsec3ClosestEncloser(authority, name)
+	if err != nil {
+		return err
+	}
+
+	var wildcard = dnssecWildcard(closestEncloser)
+
+	proof = proveName(authority, wildcard)
+
+	if rcode == RCodeErrName {
+		if proof.isMatch || !proof.isCover {
+			return fmt.Errorf(`missing proof of non-existence for %q`, wildcard)
+		}
+		return nil
+	}
+
+	if rtype == RecordTypeDS && isOptOut {
+		return nil
+	}
+	if proof.isMatch {
+		return verifyDenialTypes(proof.types, wildcard, rtype)
+	}
+	return fmt.Errorf(`missing proof of non-existence for %s %q`, recordTypeName(rtype), name)
+}
+
+// verifyDenialTypes verify that the rtype and CNAME does not exist in the
+// list of types of name.
+func verifyDenialTypes(types []RecordType, name string, rtype RecordType) error {
+	if slices.Contains(types, rtype) || slices.Contains(types, RecordTypeCNAME) {
+		return fmt.Errorf(`type %s exist for %q`, recordTypeName(rtype), name)
+	}
+	return nil
+}
+
+// verifyWildcardAnswer verify that the answer of name, that is expanded
+// from wildcard with the number of labels, has the proof that the name
+// does not exist.
+// For NSEC, the authority must contains NSEC that cover the name with the
+// wildcard parent as the closest encloser.
+// For NSEC3, the authority must contains NSEC3 that cover the next closer
+// name, as described in RFC 5155 section 8.8.
+func verifyWildcardAnswer(authority []*rrset, name string, labels int) error {
+	var (
+		list            = strings.Split(name, `.`)
+		closestEncloser = strings.Join(list[len(list)-labels:], `.`)
+		proof           nameProof
+	)
+	if isNSEC3(authority) {
+		var nextCloser = strings.Join(list[len(list)-labels-1:], `.`)
+		proof = proveName(authority, nextCloser)
+		if !proof.isMatch && proof.isCover {
+			return nil
+		}
+	} else {
+		proof = proveName(authority, name)
+		if !proof.isMatch && !proof.isEmptyNonTerminal && proof.isCover &&
+			proof.closestEncloser == closestEncloser {
+			return nil
+		}
+	}
+	return fmt.Errorf(`missing proof of non-existence for wildcard answer %q`, name)
+}
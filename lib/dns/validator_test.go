@@ -0,0 +1,709 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+// testSignedZone contains the private key and DNSKEY of stand-in signed
+// zone.
+type testSignedZone struct {
+	signer crypto.Signer
+	dnskey *RDataDNSKEY
+	name   string
+}
+
+func newTestSignedZone(t *testing.T, name string, signer crypto.Signer) (zone *testSignedZone) {
+	var err error
+
+	zone = &testSignedZone{
+		name:   name,
+		signer: signer,
+	}
+	zone.dnskey, err = newRDataDNSKEY(signer.Public(), DNSKEYFlagZone|DNSKEYFlagSEP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zone
+}
+
+// sign the rrset and return it along with its RRSIG.
+func (zone *testSignedZone) sign(t *testing.T, expiration uint32, rrset ...ResourceRecord) []ResourceRecord {
+	var (
+		now   = uint32(timeNow().Unix())
+		rrsig = &RDataRRSIG{
+			TypeCovered: rrset[0].Type,
+			Algorithm:   zone.dnskey.Algorithm,
+			Labels:      byte(dnssecLabels(rrset[0].Name)),
+			OrigTTL:     rrset[0].TTL,
+			Expiration:  expiration,
+			Inception:   now - 3600,
+			KeyTag:      zone.dnskey.KeyTag(),
+			SignerName:  zone.name,
+		}
+		err error
+	)
+	if expiration == 0 {
+		rrsig.Expiration = now + 86400
+	}
+
+	err = dnssecSign(zone.signer, rrsig, rrset)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return append(rrset, ResourceRecord{
+		Name:  rrset[0].Name,
+		Type:  RecordTypeRRSIG,
+		Class: RecordClassIN,
+		TTL:   rrset[0].TTL,
+		Value: rrsig,
+	})
+}
+
+// testDNSSECClient is the stand-in name server that return the prepared
+// response based on the question name and type.
+type testDNSSECClient struct {
+	responses map[string]*Message
+	UDPClient
+}
+
+func (cl *testDNSSECClient) Query(req *Message) (res *Message, err error) {
+	var key = fmt.Sprintf(`%s %s`, dnssecName(req.Question.Name),
+		recordTypeName(req.Question.Type))
+
+	res = cl.responses[key]
+	if res == nil {
+		return nil, fmt.Errorf(`no response for %s`, key)
+	}
+	return res, nil
+}
+
+func (cl *testDNSSECClient) add(rcode ResponseCode, name string, rtype RecordType,
+	answer, authority []ResourceRecord,
+) {
+	var msg = &Message{
+		Header: MessageHeader{
+			RCode: rcode,
+		},
+		Question: MessageQuestion{
+			Name:  name,
+			Type:  rtype,
+			Class: RecordClassIN,
+		},
+		Answer:    answer,
+		Authority: authority,
+	}
+	cl.responses[fmt.Sprintf(`%s %s`, name, recordTypeName(rtype))] = msg
+}
+
+func newTestRR(name string, rtype RecordType, value any) ResourceRecord {
+	return ResourceRecord{
+		Name:  name,
+		Type:  rtype,
+		Class: RecordClassIN,
+		TTL:   3600,
+		Value: value,
+	}
+}
+
+// newTestWildcardAnswer change the owner of signed wildcard RRset into
+// name, as if the answer is expanded from the wildcard.
+func newTestWildcardAnswer(list []ResourceRecord, name string) []ResourceRecord {
+	var x int
+	for x = range len(list) {
+		list[x].Name = name
+	}
+	return list
+}
+
+// newTestNSEC3 create the NSEC3 record in zone that match the name.
+func newTestNSEC3(zone, name string, types ...RecordType) ResourceRecord {
+	var (
+		hash = nsec3Hash(name, nil, 0)
+		next = slices.Clone(hash)
+	)
+	next[len(next)-1]++
+	return newTestRR(
+		strings.ToLower(nsec3Encoding.EncodeToString(hash))+`.`+zone,
+		RecordTypeNSEC3,
+		&RDataNSEC3{
+			HashAlgorithm:   NSEC3HashSHA1,
+			NextHashedOwner: next,
+			Types:           types,
+		})
+}
+
+// newTestNSEC3Cover create the NSEC3 record in zone that cover only the
+// name.
+func newTestNSEC3Cover(zone, name string, flags byte) ResourceRecord {
+	var (
+		hash = nsec3Hash(name, nil, 0)
+		prev = slices.Clone(hash)
+		next = slices.Clone(hash)
+	)
+	prev[len(prev)-1]--
+	next[len(next)-1]++
+	return newTestRR(
+		strings.ToLower(nsec3Encoding.EncodeToString(prev))+`.`+zone,
+		RecordTypeNSEC3,
+		&RDataNSEC3{
+			HashAlgorithm:   NSEC3HashSHA1,
+			Flags:           flags,
+			NextHashedOwner: next,
+			Types:           []RecordType{RecordTypeA, RecordTypeRRSIG},
+		})
+}
+
+// newTestDNSSECClient create stand-in name server that serve the signed
+// zone "test" with signed child zone "secure.test" and unsigned child
+// zone "insecure.test".
+func newTestDNSSECClient(t *testing.T) (cl *testDNSSECClient, anchor *RDataDS) {
+	var (
+		keyTest   *ecdsa.PrivateKey
+		keySecure ed25519.PrivateKey
+		err       error
+	)
+
+	keyTest, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, keySecure, err = ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		zoneTest   = newTestSignedZone(t, `test`, keyTest)
+		zoneSecure = newTestSignedZone(t, `secure.test`, keySecure)
+		dsSecure   *RDataDS
+	)
+
+	anchor, err = newRDataDS(`test`, zoneTest.dnskey, DSDigestSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dsSecure, err = newRDataDS(`secure.test`, zoneSecure.dnskey, DSDigestSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cl = &testDNSSECClient{
+		responses: map[string]*Message{},
+	}
+
+	cl.add(RCodeOK, `test`, RecordTypeDNSKEY,
+		zoneTest.sign(t, 0, newTestRR(`test`, RecordTypeDNSKEY, zoneTest.dnskey)),
+		nil)
+	cl.add(RCodeOK, `www.test`, RecordTypeA,
+		zoneTest.sign(t, 0, newTestRR(`www.test`, RecordTypeA, `10.0.0.1`)),
+		nil)
+	cl.add(RCodeOK, `www.test`, RecordTypeDS, nil,
+		zoneTest.sign(t, 0, newTestRR(`www.test`, RecordTypeNSEC, &RDataNSEC{
+			NextDomain: `test`,
+			Types:      []RecordType{RecordTypeA, RecordTypeRRSIG, RecordTypeNSEC},
+		})))
+	var nsecApex = zoneTest.sign(t, 0, newTestRR(`test`, RecordTypeNSEC, &RDataNSEC{
+		NextDomain: `insecure.test`,
+		Types: []RecordType{RecordTypeNS, RecordTypeSOA, RecordTypeRRSIG,
+			RecordTypeNSEC, RecordTypeDNSKEY},
+	}))
+	var nsecInsecure = zoneTest.sign(t, 0, newTestRR(`insecure.test`, RecordTypeNSEC, &RDataNSEC{
+		NextDomain: `secure.test`,
+		Types:      []RecordType{RecordTypeNS, RecordTypeRRSIG, RecordTypeNSEC},
+	}))
+	cl.add(RCodeErrName, `nope.test`, RecordTypeA, nil,
+		append(slices.Clone(nsecInsecure), nsecApex...))
+	cl.add(RCodeErrName, `nowild.test`, RecordTypeA, nil, nsecInsecure)
+
+	// NSEC3 with iterations above the limit is treated as insecure.
+	var nsec3Costly = newTestNSEC3Cover(`test`, `costly.test`, 0)
+	nsec3Costly.Value.(*RDataNSEC3).Iterations = nsec3MaxIterations + 1
+	cl.add(RCodeErrName, `costly.test`, RecordTypeA, nil,
+		zoneTest.sign(t, 0, nsec3Costly))
+
+	// Answer expanded from wildcard "*.wild.test".
+	var nsecWild = zoneTest.sign(t, 0, newTestRR(`*.wild.test`, RecordTypeNSEC, &RDataNSEC{
+		NextDomain: `b.wild.test`,
+		Types:      []RecordType{RecordTypeA, RecordTypeRRSIG, RecordTypeNSEC},
+	}))
+	cl.add(RCodeOK, `a.wild.test`, RecordTypeA,
+		newTestWildcardAnswer(zoneTest.sign(t, 0,
+			newTestRR(`*.wild.test`, RecordTypeA, `10.0.0.3`)), `a.wild.test`),
+		nsecWild)
+	cl.add(RCodeOK, `c.wild.test`, RecordTypeA,
+		newTestWildcardAnswer(zoneTest.sign(t, 0,
+			newTestRR(`*.wild.test`, RecordTypeA, `10.0.0.3`)), `c.wild.test`),
+		nsecWild)
+
+	// Signed delegation.
+	cl.add(RCodeOK, `secure.test`, RecordTypeDS,
+		zoneTest.sign(t, 0, newTestRR(`secure.test`, RecordTypeDS, dsSecure)),
+		nil)
+	cl.add(RCodeOK, `secure.test`, RecordTypeDNSKEY,
+		zoneSecure.sign(t, 0, newTestRR(`secure.test`, RecordTypeDNSKEY, zoneSecure.dnskey)),
+		nil)
+	cl.add(RCodeOK, `www.secure.test`, RecordTypeA,
+		zoneSecure.sign(t, 0, newTestRR(`www.secure.test`, RecordTypeA, `10.0.1.1`)),
+		nil)
+
+	var tampered = zoneSecure.sign(t, 0,
+		newTestRR(`tampered.secure.test`, RecordTypeA, `10.0.1.2`))
+	tampered[0].Value = `10.6.6.6`
+	cl.add(RCodeOK, `tampered.secure.test`, RecordTypeA, tampered, nil)
+
+	cl.add(RCodeOK, `expired.secure.test`, RecordTypeA,
+		zoneSecure.sign(t, uint32(timeNow().Unix())-60,
+			newTestRR(`expired.secure.test`, RecordTypeA, `10.0.1.3`)),
+		nil)
+
+	// Unsigned delegation, proven by NSEC with NS and without DS.
+	cl.add(RCodeOK, `insecure.test`, RecordTypeDS, nil,
+		zoneTest.sign(t, 0, newTestRR(`insecure.test`, RecordTypeNSEC, &RDataNSEC{
+			NextDomain: `secure.test`,
+			Types:      []RecordType{RecordTypeNS, RecordTypeRRSIG, RecordTypeNSEC},
+		})))
+	cl.add(RCodeOK, `www.insecure.test`, RecordTypeA,
+		[]ResourceRecord{newTestRR(`www.insecure.test`, RecordTypeA, `10.0.2.1`)},
+		nil)
+
+	// Unsigned answer in signed zone.
+	cl.add(RCodeOK, `stripped.test`, RecordTypeA,
+		[]ResourceRecord{newTestRR(`stripped.test`, RecordTypeA, `10.0.0.2`)},
+		nil)
+	cl.add(RCodeOK, `stripped.test`, RecordTypeDS, nil,
+		zoneTest.sign(t, 0, newTestRR(`stripped.test`, RecordTypeNSEC, &RDataNSEC{
+			NextDomain: `www.test`,
+			Types:      []RecordType{RecordTypeA, RecordTypeRRSIG, RecordTypeNSEC},
+		})))
+
+	cl.add(RCodeOK, `www.example.org`, RecordTypeA,
+		[]ResourceRecord{newTestRR(`www.example.org`, RecordTypeA, `10.0.3.1`)},
+		nil)
+
+	return cl, anchor
+}
+
+func TestValidator_validate(t *testing.T) {
+	type testCase struct {
+		desc     string
+		qname    string
+		expError string
+		qtype    RecordType
+		isSecure bool
+	}
+
+	var (
+		cl, anchor = newTestDNSSECClient(t)
+		val        = newValidator(map[string][]*RDataDS{
+			`test`: {anchor},
+		})
+	)
+
+	var listCase = []testCase{{
+		desc:     `With signed answer`,
+		qname:    `www.test`,
+		qtype:    RecordTypeA,
+		isSecure: true,
+	}, {
+		desc:     `With signed answer on child zone`,
+		qname:    `www.secure.test`,
+		qtype:    RecordTypeA,
+		isSecure: true,
+	}, {
+		desc:     `With signed NXDOMAIN`,
+		qname:    `nope.test`,
+		qtype:    RecordTypeA,
+		isSecure: true,
+	}, {
+		desc:     `With NXDOMAIN without wildcard proof`,
+		qname:    `nowild.test`,
+		qtype:    RecordTypeA,
+		expError: `missing proof of non-existence for "*.test"`,
+	}, {
+		desc:  `With NSEC3 iterations above limit`,
+		qname: `costly.test`,
+		qtype: RecordTypeA,
+	}, {
+		desc:     `With answer expanded from wildcard`,
+		qname:    `a.wild.test`,
+		qtype:    RecordTypeA,
+		isSecure: true,
+	}, {
+		desc:     `With answer expanded from wildcard without proof`,
+		qname:    `c.wild.test`,
+		qtype:    RecordTypeA,
+		expError: `missing proof of non-existence for wildcard answer "c.wild.test"`,
+	}, {
+		desc:  `With unsigned delegation`,
+		qname: `www.insecure.test`,
+		qtype: RecordTypeA,
+	}, {
+		desc:  `With name outside trust anchor`,
+		qname: `www.example.org`,
+		qtype: RecordTypeA,
+	}, {
+		desc:     `With tampered answer`,
+		qname:    `tampered.secure.test`,
+		qtype:    RecordTypeA,
+		expError: `tampered.secure.test A: dnssecVerify: invalid signature`,
+	}, {
+		desc:     `With expired RRSIG`,
+		qname:    `expired.secure.test`,
+		qtype:    RecordTypeA,
+		expError: `expired RRSIG for expired.secure.test A`,
+	}, {
+		desc:     `With stripped RRSIG`,
+		qname:    `stripped.test`,
+		qtype:    RecordTypeA,
+		expError: `missing RRSIG for "stripped.test"`,
+	}}
+
+	var (
+		c        testCase
+		res      *Message
+		isSecure bool
+		err      error
+	)
+	for _, c = range listCase {
+		res, err = cl.Query(&Message{
+			Question: MessageQuestion{
+				Name: c.qname,
+				Type: c.qtype,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		isSecure, err = val.validate(cl, res)
+		if err != nil {
+			test.Assert(t, c.desc+`: error`, c.expError, err.Error())
+			continue
+		}
+		test.Assert(t, c.desc+`: error`, c.expError, ``)
+		test.Assert(t, c.desc, c.isSecure, isSecure)
+	}
+}
+
+func TestVerifyDenial(t *testing.T) {
+	type testCase struct {
+		desc      string
+		name      string
+		expError  string
+		authority []ResourceRecord
+		rtype     RecordType
+		rcode     ResponseCode
+	}
+
+	var (
+		nsec3Apex = newTestNSEC3(`test`, `test`, RecordTypeNS, RecordTypeSOA,
+			RecordTypeRRSIG, RecordTypeDNSKEY, RecordTypeNSEC3PARAM)
+		nsec3Wildcard = newTestNSEC3(`test`, `*.test`, RecordTypeA, RecordTypeRRSIG)
+	)
+
+	var listCase = []testCase{{
+		desc:  `With NSEC, name covered by empty non-terminal`,
+		name:  `x.test`,
+		rtype: RecordTypeA,
+		rcode: RCodeErrName,
+		authority: []ResourceRecord{
+			newTestRR(`w.test`, RecordTypeNSEC, &RDataNSEC{NextDomain: `a.x.test`}),
+		},
+		expError: `"x.test" exist`,
+	}, {
+		desc:  `With NSEC, no data from wildcard`,
+		name:  `a.test`,
+		rtype: RecordTypeAAAA,
+		authority: []ResourceRecord{
+			newTestRR(`*.test`, RecordTypeNSEC, &RDataNSEC{
+				NextDomain: `b.test`,
+				Types:      []RecordType{RecordTypeA},
+			}),
+		},
+	}, {
+		desc:  `With NSEC, type exist on wildcard`,
+		name:  `a.test`,
+		rtype: RecordTypeA,
+		authority: []ResourceRecord{
+			newTestRR(`*.test`, RecordTypeNSEC, &RDataNSEC{
+				NextDomain: `b.test`,
+				Types:      []RecordType{RecordTypeA},
+			}),
+		},
+		expError: `type A exist for "*.test"`,
+	}, {
+		desc:  `With NSEC3, name error`,
+		name:  `nope.test`,
+		rtype: RecordTypeA,
+		rcode: RCodeErrName,
+		authority: []ResourceRecord{
+			nsec3Apex,
+			newTestNSEC3Cover(`test`, `nope.test`, 0),
+			newTestNSEC3Cover(`test`, `*.test`, 0),
+		},
+	}, {
+		desc:  `With NSEC3, name error on next closer`,
+		name:  `a.nope.test`,
+		rtype: RecordTypeA,
+		rcode: RCodeErrName,
+		authority: []ResourceRecord{
+			nsec3Apex,
+			newTestNSEC3Cover(`test`, `nope.test`, 0),
+			newTestNSEC3Cover(`test`, `*.test`, 0),
+		},
+	}, {
+		desc:  `With NSEC3, name error without wildcard proof`,
+		name:  `nope.test`,
+		rtype: RecordTypeA,
+		rcode: RCodeErrName,
+		authority: []ResourceRecord{
+			nsec3Apex,
+			newTestNSEC3Cover(`test`, `nope.test`, 0),
+		},
+		expError: `missing proof of non-existence for "*.test"`,
+	}, {
+		desc:  `With NSEC3, name error without closest encloser`,
+		name:  `nope.test`,
+		rtype: RecordTypeA,
+		rcode: RCodeErrName,
+		authority: []ResourceRecord{
+			newTestNSEC3Cover(`test`, `nope.test`, 0),
+			newTestNSEC3Cover(`test`, `*.test`, 0),
+		},
+		expError: `missing closest encloser proof for "nope.test"`,
+	}, {
+		desc:  `With NSEC3, name error without next closer`,
+		name:  `a.nope.test`,
+		rtype: RecordTypeA,
+		rcode: RCodeErrName,
+		authority: []ResourceRecord{
+			nsec3Apex,
+			newTestNSEC3Cover(`test`, `a.nope.test`, 0),
+			newTestNSEC3Cover(`test`, `*.test`, 0),
+		},
+		expError: `missing proof of non-existence for next closer "nope.test"`,
+	}, {
+		desc:  `With NSEC3, name error on existing name`,
+		name:  `nope.test`,
+		rtype: RecordTypeA,
+		rcode: RCodeErrName,
+		authority: []ResourceRecord{
+			newTestNSEC3(`test`, `nope.test`, RecordTypeA),
+		},
+		expError: `"nope.test" exist`,
+	}, {
+		desc:  `With NSEC3, closest encloser is delegation`,
+		name:  `a.insecure.test`,
+		rtype: RecordTypeA,
+		rcode: RCodeErrName,
+		authority: []ResourceRecord{
+			newTestNSEC3(`test`, `insecure.test`, RecordTypeNS),
+			newTestNSEC3Cover(`test`, `a.insecure.test`, 0),
+			newTestNSEC3Cover(`test`, `*.insecure.test`, 0),
+		},
+		expError: `closest encloser "insecure.test" of "a.insecure.test" is a delegation`,
+	}, {
+		desc:  `With NSEC3, no data DS on opt-out`,
+		name:  `insecure.test`,
+		rtype: RecordTypeDS,
+		authority: []ResourceRecord{
+			nsec3Apex,
+			newTestNSEC3Cover(`test`, `insecure.test`, NSEC3FlagOptOut),
+		},
+	}, {
+		desc:  `With NSEC3, no data from wildcard`,
+		name:  `a.test`,
+		rtype: RecordTypeAAAA,
+		authority: []ResourceRecord{
+			nsec3Apex,
+			newTestNSEC3Cover(`test`, `a.test`, 0),
+			nsec3Wildcard,
+		},
+	}, {
+		desc:  `With NSEC3, type exist on wildcard`,
+		name:  `a.test`,
+		rtype: RecordTypeA,
+		authority: []ResourceRecord{
+			nsec3Apex,
+			newTestNSEC3Cover(`test`, `a.test`, 0),
+			nsec3Wildcard,
+		},
+		expError: `type A exist for "*.test"`,
+	}}
+
+	var (
+		c   testCase
+		err error
+	)
+	for _, c = range listCase {
+		err = verifyDenial(groupRRSets(c.authority), c.name, c.rtype, c.rcode)
+		var gotError string
+		if err != nil {
+			gotError = err.Error()
+		}
+		test.Assert(t, c.desc, c.expError, gotError)
+	}
+}
+
+func TestVerifyWildcardAnswer(t *testing.T) {
+	type testCase struct {
+		desc      string
+		name      string
+		expError  string
+		authority []ResourceRecord
+		labels    int
+	}
+
+	var listCase = []testCase{{
+		desc:   `With NSEC3 that cover next closer`,
+		name:   `a.b.wild.test`,
+		labels: 2,
+		authority: []ResourceRecord{
+			newTestNSEC3Cover(`test`, `b.wild.test`, 0),
+		},
+	}, {
+		desc:   `With NSEC3 that cover name only`,
+		name:   `a.b.wild.test`,
+		labels: 2,
+		authority: []ResourceRecord{
+			newTestNSEC3Cover(`test`, `a.b.wild.test`, 0),
+		},
+		expError: `missing proof of non-existence for wildcard answer "a.b.wild.test"`,
+	}, {
+		desc:   `With NSEC that cover name on different closest encloser`,
+		name:   `a.b.wild.test`,
+		labels: 2,
+		authority: []ResourceRecord{
+			newTestRR(`b.wild.test`, RecordTypeNSEC, &RDataNSEC{
+				NextDomain: `c.wild.test`,
+			}),
+		},
+		expError: `missing proof of non-existence for wildcard answer "a.b.wild.test"`,
+	}}
+
+	var (
+		c   testCase
+		err error
+	)
+	for _, c = range listCase {
+		err = verifyWildcardAnswer(groupRRSets(c.authority), c.name, c.labels)
+		var gotError string
+		if err != nil {
+			gotError = err.Error()
+		}
+		test.Assert(t, c.desc, c.expError, gotError)
+	}
+}
+
+func TestServer_processResponse_validate(t *testing.T) {
+	type testCase struct {
+		desc      string
+		qname     string
+		expError  string
+		expRCode  ResponseCode
+		isCD      bool
+		expIsAD   bool
+		expCached bool
+	}
+
+	var (
+		cl, anchor = newTestDNSSECClient(t)
+		srv        = &Server{
+			opts: &ServerOptions{},
+			validator: newValidator(map[string][]*RDataDS{
+				`test`: {anchor},
+			}),
+		}
+	)
+	srv.Caches.init(time.Hour, -time.Hour, 0)
+
+	var listCase = []testCase{{
+		desc:      `With secure answer`,
+		qname:     `www.secure.test`,
+		expRCode:  RCodeOK,
+		expIsAD:   true,
+		expCached: true,
+	}, {
+		desc:      `With insecure answer`,
+		qname:     `www.insecure.test`,
+		expRCode:  RCodeOK,
+		expCached: true,
+	}, {
+		desc:     `With bogus answer`,
+		qname:    `tampered.secure.test`,
+		expRCode: RCodeErrServer,
+		expError: `bogus response: tampered.secure.test A: dnssecVerify: invalid signature`,
+	}, {
+		desc:     `With bogus answer and checking disabled`,
+		qname:    `tampered.secure.test`,
+		isCD:     true,
+		expRCode: RCodeOK,
+		expError: `bogus response with checking disabled: ` +
+			`tampered.secure.test A: dnssecVerify: invalid signature`,
+	}}
+
+	var (
+		c   testCase
+		req *request
+		res *Message
+		got *Message
+		buf bytes.Buffer
+		err error
+	)
+	for _, c = range listCase {
+		buf.Reset()
+
+		req = newRequest()
+		req.writer = &buf
+		req.message.Question.Name = c.qname
+		req.message.Question.Type = RecordTypeA
+		req.message.Question.Class = RecordClassIN
+		req.message.Header.IsCD = c.isCD
+		_, err = req.message.Pack()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err = cl.Query(req.message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = res.Pack()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, _, err = srv.processResponse(req, res, cl)
+		var gotError string
+		if err != nil {
+			gotError = err.Error()
+		}
+		test.Assert(t, c.desc+`: error`, c.expError, gotError)
+
+		got, err = UnpackMessage(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.Assert(t, c.desc+`: RCode`, c.expRCode, got.Header.RCode)
+		test.Assert(t, c.desc+`: IsAD`, c.expIsAD, got.Header.IsAD)
+
+		var an = srv.Caches.query(req.message)
+		test.Assert(t, c.desc+`: cached`, c.expCached, an != nil)
+	}
+}
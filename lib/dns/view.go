@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// View define the split-horizon view, a distinct set of internal zones,
+// hosts files, and parent name servers, for queries from specific client
+// networks.
+//
+// In the INI format, each view is defined in its own "dns" "view"
+// section,
+//
+//	[dns "view"]
+//	name = office
+//	match = 10.0.0.0/8
+//	match = 192.168.1.0/24
+//	hosts_dir = /etc/rescached/office/hosts.d
+//	zone_dir = /etc/rescached/office/zone.d
+//	parent = udp://10.1.1.1
+//
+// The query from client whose address match with one of the Networks is
+// answered from the view caches, which contains the records from HostsDir
+// and ZoneDir, and the answers from view parent name servers.
+// The records in the server caches, for example the one that populated by
+// [Server.Caches], are not used by the view.
+type View struct {
+	// Name of view, must be unique.
+	// The view forwarders is prefixed with its name, for example
+	// "office/UDP-0".
+	Name string `ini:"::name"`
+
+	// HostsDir define the directory of hosts files that are loaded into
+	// the view caches.
+	// This field is optional.
+	HostsDir string `ini:"::hosts_dir"`
+
+	// ZoneDir define the directory of zone files that are loaded into
+	// the view caches.
+	// This field is optional.
+	ZoneDir string `ini:"::zone_dir"`
+
+	// networks contains the parsed Networks.
+	networks []*net.IPNet
+
+	primaryUDP []net.Addr
+	primaryTCP []net.Addr
+	primaryDoh []string
+	primaryDot []string
+
+	// Networks contains list of client IP address or network, in CIDR
+	// notation, that use this view.
+	// If the client address match with more than one views, the first
+	// view in [ServerOptions.Views] is used.
+	Networks []string `ini:"::match"`
+
+	// NameServers contains list of parent name servers for this view,
+	// using the same URI format as in [ServerOptions.NameServers].
+	// This field is optional, if its empty the query is forwarded using
+	// the server NameServers and ForwardRules, but the answer is cached
+	// in the view caches.
+	NameServers []string `ini:"::parent"`
+}
+
+// init validate the Name and parse the Networks and NameServers.
+func (v *View) init() (err error) {
+	v.Name = strings.TrimSpace(v.Name)
+	if len(v.Name) == 0 {
+		return errors.New(`empty name`)
+	}
+
+	var (
+		ipnet   *net.IPNet
+		network string
+	)
+
+	v.networks = nil
+	for _, network = range v.Networks {
+		ipnet = parseNetwork(strings.TrimSpace(network))
+		if ipnet == nil {
+			return fmt.Errorf(`invalid network %q`, network)
+		}
+		v.networks = append(v.networks, ipnet)
+	}
+	if len(v.networks) == 0 {
+		return errors.New(`empty network`)
+	}
+
+	if len(v.NameServers) == 0 {
+		return nil
+	}
+
+	v.primaryUDP, v.primaryTCP, v.primaryDoh, v.primaryDot = parseNameServers(v.NameServers)
+
+	if len(v.primaryUDP) == 0 && len(v.primaryDoh) == 0 && len(v.primaryDot) == 0 {
+		return errors.New(`no valid name servers`)
+	}
+	return nil
+}
+
+// match return true if the ip address is in one of the view networks.
+func (v *View) match(ip net.IP) bool {
+	var ipnet *net.IPNet
+	for _, ipnet = range v.networks {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// view contains the caches of [View].
+type view struct {
+	opts *View
+
+	caches Caches
+}
+
+// newView create the view and load the hosts and zone files from its
+// HostsDir and ZoneDir.
+func newView(opts *View, srvOpts *ServerOptions) (v *view, err error) {
+	v = &view{
+		opts: opts,
+	}
+	v.caches.init(srvOpts.PruneDelay, srvOpts.PruneThreshold, srvOpts.Debug)
+
+	var hostsFiles map[string]*HostsFile
+
+	hostsFiles, err = LoadHostsDir(opts.HostsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var hfile *HostsFile
+	for _, hfile = range hostsFiles {
+		err = v.caches.InternalPopulateRecords(hfile.Records, hfile.Path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var zoneFiles map[string]*Zone
+
+	zoneFiles, err = LoadZoneDir(opts.ZoneDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var zone *Zone
+	for _, zone = range zoneFiles {
+		v.caches.InternalPopulateZone(zone)
+	}
+	return v, nil
+}
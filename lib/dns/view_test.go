@@ -0,0 +1,260 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/ini"
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestServerOptions_Views(t *testing.T) {
+	var (
+		rawini = []byte(`
+[dns "view"]
+name = office
+match = 10.0.0.0/8
+match = 192.168.1.1
+hosts_dir = /etc/rescached/office/hosts.d
+parent = udp://10.1.1.1
+`)
+		opts ServerOptions
+		err  error
+	)
+
+	err = ini.Unmarshal(rawini, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expViews = []View{{
+		Name:        `office`,
+		HostsDir:    `/etc/rescached/office/hosts.d`,
+		Networks:    []string{`10.0.0.0/8`, `192.168.1.1`},
+		NameServers: []string{`udp://10.1.1.1`},
+	}}
+	test.Assert(t, `Views`, expViews, opts.Views)
+
+	err = opts.init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `networks`, 2, len(opts.Views[0].networks))
+	test.Assert(t, `primaryUDP`, 1, len(opts.Views[0].primaryUDP))
+
+	type testCase struct {
+		desc     string
+		expError string
+		views    []View
+	}
+
+	var listCase = []testCase{{
+		desc: `With empty name`,
+		views: []View{{
+			Networks: []string{`10.0.0.0/8`},
+		}},
+		expError: `dns: invalid view "": empty name`,
+	}, {
+		desc: `With empty network`,
+		views: []View{{
+			Name: `office`,
+		}},
+		expError: `dns: invalid view "office": empty network`,
+	}, {
+		desc: `With invalid network`,
+		views: []View{{
+			Name:     `office`,
+			Networks: []string{`10.0.0`},
+		}},
+		expError: `dns: invalid view "office": invalid network "10.0.0"`,
+	}, {
+		desc: `With no valid name servers`,
+		views: []View{{
+			Name:        `office`,
+			Networks:    []string{`10.0.0.0/8`},
+			NameServers: []string{`udp://router.lan`},
+		}},
+		expError: `dns: invalid view "office": no valid name servers`,
+	}, {
+		desc: `With duplicate name`,
+		views: []View{{
+			Name:     `office`,
+			Networks: []string{`10.0.0.0/8`},
+		}, {
+			Name:     `office`,
+			Networks: []string{`192.168.0.0/16`},
+		}},
+		expError: `dns: duplicate view "office"`,
+	}}
+
+	var c testCase
+	for _, c = range listCase {
+		opts = ServerOptions{
+			Views: c.views,
+		}
+		err = opts.init()
+		test.Assert(t, c.desc, c.expError, err.Error())
+	}
+}
+
+func TestServer_views(t *testing.T) {
+	var (
+		publicZone = []byte(`@ SOA ns1 admin 1 3600 900 604800 300
+api A 203.0.113.1
+`)
+		officeZone = []byte(`@ SOA ns1 admin 1 3600 900 604800 300
+api A 10.0.0.1
+`)
+		hostsDir = t.TempDir()
+
+		upstream *Server
+		zone     *Zone
+		err      error
+	)
+
+	err = os.WriteFile(filepath.Join(hostsDir, `hosts`),
+		[]byte("10.9.9.9 api.example.test\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The parent name server for the office view.
+	zone, err = ParseZone(officeZone, `example.test`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream, err = NewServer(&ServerOptions{
+		ListenAddress: `127.0.0.1:5347`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream.Caches.InternalPopulateZone(zone)
+	go func() {
+		_ = upstream.ListenAndServe()
+	}()
+	t.Cleanup(upstream.Stop)
+
+	var srv *Server
+
+	srv, err = NewServer(&ServerOptions{
+		ListenAddress:  `127.0.0.1:5348`,
+		DoHBehindProxy: true,
+		Views: []View{{
+			Name:        `office`,
+			Networks:    []string{`10.0.0.0/8`},
+			NameServers: []string{`udp://127.0.0.1:5347`},
+		}, {
+			Name:     `local`,
+			Networks: []string{`127.0.0.1`},
+			HostsDir: hostsDir,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zone, err = ParseZone(publicZone, `example.test`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Caches.InternalPopulateZone(zone)
+
+	go func() {
+		_ = srv.ListenAndServe()
+	}()
+	t.Cleanup(srv.Stop)
+
+	var q = MessageQuestion{
+		Name: `api.example.test`,
+		Type: RecordTypeA,
+	}
+
+	// The UDP client from 127.0.0.1 use the local view.
+	var cl *UDPClient
+
+	cl, err = NewUDPClient(`127.0.0.1:5348`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Close()
+
+	var res *Message
+
+	res, err = cl.Lookup(q, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `local view`, `10.9.9.9`, res.Answer[0].Value)
+
+	// The DoH client behind proxy use the address from
+	// X-Forwarded-For.
+	var dohLookup = func(xff string) (res *Message) {
+		var (
+			msg = NewMessage()
+			x   int
+		)
+		msg.Question = q
+		msg.Question.Class = RecordClassIN
+		_, err = msg.Pack()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Wait until the view forwarders are connected.
+		for x = 0; x < 50; x++ {
+			var (
+				httpReq = httptest.NewRequest(http.MethodGet,
+					`/dns-query?dns=`+base64.RawURLEncoding.EncodeToString(msg.packet), nil)
+				rec = httptest.NewRecorder()
+			)
+			httpReq.Header.Set(`Accept`, `application/dns-message`)
+			if len(xff) != 0 {
+				httpReq.Header.Set(`X-Forwarded-For`, xff)
+			}
+			srv.ServeHTTP(rec, httpReq)
+
+			res, err = UnpackMessage(rec.Body.Bytes())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Header.RCode != RCodeErrServer {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		return res
+	}
+
+	res = dohLookup(`10.1.2.3, 192.0.2.1`)
+	test.Assert(t, `office view`, `10.0.0.1`, res.Answer[0].Value)
+
+	res = dohLookup(``)
+	test.Assert(t, `no view`, `203.0.113.1`, res.Answer[0].Value)
+
+	// The office answer is cached in the office view only.
+	test.Assert(t, `office caches`, 1,
+		len(srv.ViewCaches(`office`).ExternalLRU()))
+	test.Assert(t, `server caches`, 0, len(srv.Caches.ExternalLRU()))
+	test.Assert(t, `unknown view`, true, srv.ViewCaches(`home`) == nil)
+
+	var (
+		stat  ForwarderStat
+		found bool
+	)
+	for _, stat = range srv.ForwarderStats() {
+		if stat.Name == `office/UDP-0` {
+			found = true
+			test.Assert(t, `office/UDP-0 Queries`, true, stat.Queries > 0)
+		}
+	}
+	test.Assert(t, `office/UDP-0 found`, true, found)
+}
@@ -25,13 +25,33 @@ type Zone struct {
 	SOA   *RDataSOA
 	rrSOA *ResourceRecord
 
+	// signer contains the key to sign the zone, set by SetSigner.
+	signer *ZoneSigner
+
+	// caches contains the Caches that serve the zone, set when the
+	// zone is populated into it.
+	caches *Caches
+
+	// tsigKeys contains the keys that allowed to update the zone,
+	// indexed by key name.
+	tsigKeys map[string]*TSIGKey
+
 	Path string `json:"-"`
 
+	// Secondaries contains list of secondary name server addresses, in
+	// the format "ip[:port]", that will be notified using NOTIFY message
+	// (RFC 1996) when the zone changes.
+	Secondaries []string `json:"-"`
+
 	// The base domain of zone.
 	// It must be absolute domain, end with period.
 	Origin string
 
 	messages []*Message
+
+	// journal contains the history of changes by Add and Remove, used
+	// to answer the incremental zone transfer (IXFR).
+	journal []*zoneJournal
 }
 
 // NewZone create and initialize new zone.
@@ -177,13 +197,42 @@ func toDomainAbsolute(d string) string {
 }
 
 // Add add new ResourceRecord to Zone.
+// If the zone has been populated into [Caches], the internal answers of the
+// zone are replaced with the updated messages.
 func (zone *Zone) Add(rr *ResourceRecord) (err error) {
-	var logp = `Add`
+	if zone.caches != nil {
+		return zone.caches.internalZoneUpdate(zone, func() error {
+			return zone.doAdd(rr)
+		})
+	}
+	return zone.doAdd(rr)
+}
+
+// doAdd add the rr to zone, re-sign the zone, and record the changes in
+// the journal.
+func (zone *Zone) doAdd(rr *ResourceRecord) (err error) {
+	var (
+		logp     = `Add`
+		serial   = zone.SOA.Serial
+		prevSigs = zone.dnssecRecords()
+	)
 	err = zone.add(rr)
 	if err != nil {
 		return fmt.Errorf(`%s: %w`, logp, err)
 	}
 	zone.onUpdate()
+	if zone.signer != nil {
+		err = zone.sign()
+		if err != nil {
+			return fmt.Errorf(`%s: %w`, logp, err)
+		}
+	}
+	if rr.Type == RecordTypeSOA {
+		zone.journalAdd(serial, prevSigs, nil, nil)
+	} else {
+		zone.journalAdd(serial, prevSigs, nil, []ResourceRecord{*rr})
+	}
+	zone.notify()
 	return nil
 }
 
@@ -204,6 +253,10 @@ func (zone *Zone) add(rr *ResourceRecord) (err error) {
 		zone.recordAdd(rr)
 	}
 
+	if rr.Type == RecordTypeRRSIG {
+		zone.attachRRSIG(rr)
+	}
+
 	for _, msg = range zone.messages {
 		if msg.Question.Name != rr.Name {
 			continue
@@ -234,6 +287,22 @@ func (zone *Zone) add(rr *ResourceRecord) (err error) {
 	return nil
 }
 
+// attachRRSIG add the RRSIG into the answer of message that has the type
+// covered by the RRSIG.
+func (zone *Zone) attachRRSIG(rr *ResourceRecord) {
+	var rrsig, ok = rr.Value.(*RDataRRSIG)
+	if !ok {
+		return
+	}
+
+	var msg = zone.message(rr.Name, rrsig.TypeCovered)
+	if msg == nil {
+		return
+	}
+	msg.Answer = append(msg.Answer, *rr)
+	msg.Header.ANCount = uint16(len(msg.Answer))
+}
+
 // Delete the zone file from storage.
 func (zone *Zone) Delete() (err error) {
 	return os.Remove(zone.Path)
@@ -246,21 +315,64 @@ func (zone *Zone) Messages() []*Message {
 
 // Remove a ResourceRecord from zone file.
 // If the RR is SOA it will reset the value back to default.
+// If the zone has been populated into [Caches], the internal answers of the
+// zone are replaced with the updated messages.
 func (zone *Zone) Remove(rr *ResourceRecord) (err error) {
-	var logp = `Remove`
+	if zone.caches != nil {
+		return zone.caches.internalZoneUpdate(zone, func() error {
+			return zone.doRemove(rr)
+		})
+	}
+	return zone.doRemove(rr)
+}
+
+// doRemove remove the rr from zone, re-sign the zone, record the changes
+// in the journal, and save the zone file.
+func (zone *Zone) doRemove(rr *ResourceRecord) (err error) {
+	var (
+		logp      = `Remove`
+		serial    = zone.SOA.Serial
+		prevSigs  = zone.dnssecRecords()
+		removed   []ResourceRecord
+		isRemoved bool
+	)
 
 	if rr.Type == RecordTypeSOA {
 		zone.SOA = NewRDataSOA(zone.Origin, ``)
-	} else if zone.recordRemove(rr) {
+	} else {
+		isRemoved = zone.recordRemove(rr)
+		if isRemoved {
+			zone.messageRemove(rr)
+			removed = append(removed, *rr)
+		}
+	}
+	zone.onUpdate()
+	if zone.signer != nil {
+		err = zone.sign()
+		if err != nil {
+			return fmt.Errorf(`%s: %w`, logp, err)
+		}
+	}
+	zone.journalAdd(serial, prevSigs, removed, nil)
+	zone.notify()
+	if isRemoved && len(zone.Path) != 0 {
 		err = zone.Save()
 		if err != nil {
 			return fmt.Errorf(`%s: %w`, logp, err)
 		}
 	}
-	zone.onUpdate()
 	return nil
 }
 
+// messageRemove remove the rr from answer of pre-generated message.
+func (zone *Zone) messageRemove(rr *ResourceRecord) {
+	var msg = zone.message(rr.Name, rr.Type)
+	if msg == nil || msg.Question.Class != rr.Class {
+		return
+	}
+	_, _ = msg.RemoveAnswer(rr)
+}
+
 // Save the content of zone records to file defined by Zone.Path.
 // The zone content will be different with original file, since it does not
 // preserve comment and indentation.
@@ -322,7 +434,7 @@ func (zone *Zone) saveListRR(out io.Writer, dname string, listRR []*ResourceReco
 				RecordTypeNames[rr.Type], rr.Value.(string))
 
 		case RecordTypeNS, RecordTypeCNAME, RecordTypeMB,
-			RecordTypeMG, RecordTypeMR:
+			RecordTypeMG, RecordTypeMR, RecordTypeDNAME:
 			v, ok = rr.Value.(string)
 			if !ok {
 				err = errors.New("invalid record value for " +
@@ -435,6 +547,23 @@ func (zone *Zone) saveListRR(out io.Writer, dname string, listRR []*ResourceReco
 			var n64 int64
 			n64, _ = https.WriteTo(out)
 			n = int(n64)
+
+		case RecordTypeDS, RecordTypeRRSIG, RecordTypeNSEC,
+			RecordTypeDNSKEY, RecordTypeNSEC3, RecordTypeNSEC3PARAM,
+			RecordTypeNAPTR, RecordTypeSSHFP, RecordTypeTLSA,
+			RecordTypeURI, RecordTypeCAA:
+			var rdata io.WriterTo
+
+			rdata, ok = rr.Value.(io.WriterTo)
+			if !ok {
+				return total, fmt.Errorf(`%s: expecting io.WriterTo, got %T`, logp, rr.Value)
+			}
+			n, _ = fmt.Fprintf(out, `%s %d IN `, dname, rr.TTL)
+			total += n
+
+			var n64 int64
+			n64, err = rdata.WriteTo(out)
+			n = int(n64)
 		}
 		if err != nil {
 			return total, err
@@ -572,12 +701,13 @@ func (zone *Zone) recordRemove(rr *ResourceRecord) bool {
 func (zone *Zone) soaRecord() (rrsoa *ResourceRecord) {
 	if zone.rrSOA == nil {
 		zone.rrSOA = &ResourceRecord{
-			Value: zone.SOA,
 			Name:  zone.Origin,
 			Type:  RecordTypeSOA,
 			Class: RecordClassIN,
-			TTL:   zone.SOA.Minimum,
 		}
 	}
+	// The SOA may be replaced by Add or Remove.
+	zone.rrSOA.Value = zone.SOA
+	zone.rrSOA.TTL = zone.SOA.Minimum
 	return zone.rrSOA
 }
@@ -0,0 +1,250 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"strings"
+)
+
+// maxCNAMEChain define the maximum number of CNAME that are followed when
+// building the authoritative answer.
+const maxCNAMEChain = 8
+
+// zoneLookup contains the result of looking up the name in the zone, as
+// described in RFC 1034 section 4.3.2.
+type zoneLookup struct {
+	// target contains the CNAME target that need to be followed.
+	target string
+
+	answer     []ResourceRecord
+	authority  []ResourceRecord
+	additional []ResourceRecord
+
+	rcode ResponseCode
+
+	// isReferral is true if the name is at or below the zone cut,
+	// where the authority contains the NS records of the delegated
+	// zone.
+	isReferral bool
+}
+
+// lookup the qname and qtype in the zone.
+// The qname must be the zone origin or one of its sub domains.
+//
+// If the qname is at or below the delegation point, it return the
+// referral with NS records in authority and their address (glue) in
+// additional.
+// If the qname does not exist, the wildcard record "*" in its closest
+// encloser is used to synthesize the answer, as described in RFC 4592.
+// If the qname exist but does not have the qtype, it return the CNAME
+// record, with the target that need to be followed, or no data with SOA
+// in authority.
+// Otherwise, it return name error with SOA in authority.
+func (zone *Zone) lookup(qname string, qtype RecordType) (zl zoneLookup) {
+	var (
+		name  = strings.ToLower(toDomainAbsolute(qname))
+		rrsNS []ResourceRecord
+	)
+
+	rrsNS = zone.delegation(name, qtype)
+	if len(rrsNS) != 0 {
+		zl.isReferral = true
+		zl.authority = rrsNS
+		zl.additional = zone.glue(rrsNS)
+		return zl
+	}
+
+	if zone.isNameExist(name) {
+		zone.answerAt(&zl, name, name, qtype)
+		return zl
+	}
+
+	var wildcard = `*.` + zone.existingEncloser(name)
+	if len(zone.Records[wildcard]) != 0 {
+		zone.answerAt(&zl, wildcard, name, qtype)
+		return zl
+	}
+
+	zl.rcode = RCodeErrName
+	zl.authority = []ResourceRecord{*zone.soaRecord()}
+	return zl
+}
+
+// answerAt set the answer for qname and qtype from the records of owner.
+// The owner is equal to qname, or the wildcard name that match with
+// qname.
+func (zone *Zone) answerAt(zl *zoneLookup, owner, qname string, qtype RecordType) {
+	var msg = zone.message(owner, qtype)
+	if msg != nil && len(msg.Answer) != 0 {
+		zl.answer = synthesize(msg.Answer, owner, qname)
+		zl.additional = zone.glue(zl.answer)
+		return
+	}
+
+	if qtype != RecordTypeCNAME {
+		msg = zone.message(owner, RecordTypeCNAME)
+		if msg != nil && len(msg.Answer) != 0 {
+			zl.answer = synthesize(msg.Answer, owner, qname)
+			zl.target, _ = msg.Answer[0].Value.(string)
+			return
+		}
+	}
+
+	// The name exist but does not have the requested type.
+	zl.authority = []ResourceRecord{*zone.soaRecord()}
+}
+
+// existingEncloser return the longest ancestor of name that exist in the
+// zone, the closest encloser as defined in RFC 4592 section 3.3.1.
+func (zone *Zone) existingEncloser(name string) string {
+	var x int
+	for name != zone.Origin {
+		x = strings.IndexByte(name, '.')
+		if x < 0 || x == len(name)-1 {
+			break
+		}
+		name = name[x+1:]
+		if zone.isNameExist(name) {
+			return name
+		}
+	}
+	return zone.Origin
+}
+
+// delegation return the NS records of the zone cut at or above the name,
+// excluding the zone origin.
+// The DS query at the zone cut is answered by this zone, so its not
+// delegated.
+func (zone *Zone) delegation(name string, qtype RecordType) (rrs []ResourceRecord) {
+	var (
+		cut = name
+		msg *Message
+		x   int
+	)
+	for cut != zone.Origin && dnssecIsSubdomain(cut, zone.Origin) {
+		if zone.hasType(cut, RecordTypeNS) {
+			if cut == name && qtype == RecordTypeDS {
+				return nil
+			}
+			msg = zone.message(cut, RecordTypeNS)
+			if msg != nil {
+				return filterType(msg.Answer, RecordTypeNS)
+			}
+		}
+		x = strings.IndexByte(cut, '.')
+		if x < 0 || x == len(cut)-1 {
+			break
+		}
+		cut = cut[x+1:]
+	}
+	return nil
+}
+
+// glue return the A and AAAA records, inside the zone, of the name
+// servers, mail exchanges, and service targets in rrs.
+func (zone *Zone) glue(rrs []ResourceRecord) (glue []ResourceRecord) {
+	var (
+		seen = make(map[string]struct{})
+
+		rr     *ResourceRecord
+		in     *ResourceRecord
+		target string
+		x      int
+		ok     bool
+	)
+	for x = range len(rrs) {
+		rr = &rrs[x]
+		switch rr.Type {
+		case RecordTypeNS:
+			target, _ = rr.Value.(string)
+		case RecordTypeMX:
+			var mx *RDataMX
+			mx, ok = rr.Value.(*RDataMX)
+			if !ok {
+				continue
+			}
+			target = mx.Exchange
+		case RecordTypeSRV:
+			var srv *RDataSRV
+			srv, ok = rr.Value.(*RDataSRV)
+			if !ok {
+				continue
+			}
+			target = srv.Target
+		default:
+			continue
+		}
+
+		target = strings.ToLower(toDomainAbsolute(target))
+		_, ok = seen[target]
+		if ok {
+			continue
+		}
+		seen[target] = struct{}{}
+
+		for _, in = range zone.Records[target] {
+			if in.Type == RecordTypeA || in.Type == RecordTypeAAAA {
+				glue = append(glue, *in)
+			}
+		}
+	}
+	return glue
+}
+
+// hasType return true if the name has record with type rtype.
+func (zone *Zone) hasType(name string, rtype RecordType) bool {
+	var rr *ResourceRecord
+	for _, rr = range zone.Records[name] {
+		if rr.Type == rtype {
+			return true
+		}
+	}
+	return false
+}
+
+// isNameExist return true if the name has records in the zone, or its an
+// empty non-terminal, the name that does not have records but one of its
+// sub domain does.
+func (zone *Zone) isNameExist(name string) bool {
+	if name == zone.Origin || len(zone.Records[name]) != 0 {
+		return true
+	}
+	var (
+		suffix = `.` + name
+		owner  string
+		listRR []*ResourceRecord
+	)
+	for owner, listRR = range zone.Records {
+		if len(listRR) != 0 && strings.HasSuffix(owner, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterType return the records in rrs that has type rtype.
+func filterType(rrs []ResourceRecord, rtype RecordType) (out []ResourceRecord) {
+	var rr ResourceRecord
+	for _, rr = range rrs {
+		if rr.Type == rtype {
+			out = append(out, rr)
+		}
+	}
+	return out
+}
+
+// synthesize return the copy of records in rrs with the owner name
+// replaced by qname, if the owner is wildcard.
+func synthesize(rrs []ResourceRecord, owner, qname string) (out []ResourceRecord) {
+	out = make([]ResourceRecord, len(rrs))
+	copy(out, rrs)
+	if owner == qname {
+		return out
+	}
+	var x int
+	for x = range out {
+		out[x].Name = qname
+	}
+	return out
+}
@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"fmt"
+	"testing"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestCaches_authoritative(t *testing.T) {
+	type testCase struct {
+		desc          string
+		qname         string
+		expAnswer     []string
+		expAuthority  []string
+		expAdditional []string
+		qtype         RecordType
+		expRCode      ResponseCode
+		expAA         bool
+	}
+
+	var (
+		zoneData = []byte(`@ SOA ns1 admin 1 3600 900 604800 300
+@ NS ns1
+@ MX 10 mail
+ns1 A 10.0.0.53
+mail A 10.0.0.25
+api A 10.0.0.1
+www CNAME web
+web CNAME api
+loop CNAME loop
+ext CNAME www.example.net.
+*.dev A 10.0.1.1
+host.ent TXT "in ent"
+sub NS ns.sub
+ns.sub A 10.0.2.53
+`)
+		caches = &Caches{}
+
+		zone *Zone
+		err  error
+	)
+
+	zone, err = ParseZone(zoneData, `example.test`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caches.init(0, 0, 0)
+	caches.InternalPopulateZone(zone)
+
+	var listCase = []testCase{{
+		desc:      `With exact name`,
+		qname:     `api.example.test`,
+		qtype:     RecordTypeA,
+		expAA:     true,
+		expAnswer: []string{`api.example.test. A 10.0.0.1`},
+	}, {
+		desc:  `With CNAME chain`,
+		qname: `www.example.test`,
+		qtype: RecordTypeA,
+		expAA: true,
+		expAnswer: []string{
+			`www.example.test. CNAME web.example.test.`,
+			`web.example.test. CNAME api.example.test.`,
+			`api.example.test. A 10.0.0.1`,
+		},
+	}, {
+		desc:      `With CNAME loop`,
+		qname:     `loop.example.test`,
+		qtype:     RecordTypeA,
+		expAA:     true,
+		expAnswer: []string{`loop.example.test. CNAME loop.example.test.`},
+	}, {
+		desc:      `With CNAME outside zone`,
+		qname:     `ext.example.test`,
+		qtype:     RecordTypeA,
+		expAA:     true,
+		expAnswer: []string{`ext.example.test. CNAME www.example.net.`},
+	}, {
+		desc:      `With wildcard`,
+		qname:     `a.b.dev.example.test`,
+		qtype:     RecordTypeA,
+		expAA:     true,
+		expAnswer: []string{`a.b.dev.example.test. A 10.0.1.1`},
+	}, {
+		desc:         `With wildcard and no data`,
+		qname:        `a.dev.example.test`,
+		qtype:        RecordTypeAAAA,
+		expAA:        true,
+		expAuthority: []string{`example.test. SOA`},
+	}, {
+		desc:         `With no data`,
+		qname:        `api.example.test`,
+		qtype:        RecordTypeAAAA,
+		expAA:        true,
+		expAuthority: []string{`example.test. SOA`},
+	}, {
+		desc:         `With empty non-terminal`,
+		qname:        `ent.example.test`,
+		qtype:        RecordTypeA,
+		expAA:        true,
+		expAuthority: []string{`example.test. SOA`},
+	}, {
+		desc:         `With name not exist`,
+		qname:        `none.example.test`,
+		qtype:        RecordTypeA,
+		expAA:        true,
+		expRCode:     RCodeErrName,
+		expAuthority: []string{`example.test. SOA`},
+	}, {
+		desc:         `With apex no data`,
+		qname:        `example.test`,
+		qtype:        RecordTypeTXT,
+		expAA:        true,
+		expAuthority: []string{`example.test. SOA`},
+	}, {
+		desc:          `With MX glue`,
+		qname:         `example.test`,
+		qtype:         RecordTypeMX,
+		expAA:         true,
+		expAnswer:     []string{`example.test. MX`},
+		expAdditional: []string{`mail.example.test. A 10.0.0.25`},
+	}, {
+		desc:          `With referral`,
+		qname:         `www.sub.example.test`,
+		qtype:         RecordTypeA,
+		expAuthority:  []string{`sub.example.test. NS ns.sub.example.test.`},
+		expAdditional: []string{`ns.sub.example.test. A 10.0.2.53`},
+	}}
+
+	var (
+		c   testCase
+		an  *Answer
+		got []string
+	)
+	for _, c = range listCase {
+		an = caches.query(&Message{
+			Question: MessageQuestion{
+				Name:  c.qname,
+				Type:  c.qtype,
+				Class: RecordClassIN,
+			},
+		})
+		if an == nil {
+			t.Fatalf(`%s: expecting answer, got nil`, c.desc)
+		}
+
+		test.Assert(t, c.desc+`: RCode`, c.expRCode, an.Message.Header.RCode)
+		test.Assert(t, c.desc+`: IsAA`, c.expAA, an.Message.Header.IsAA)
+
+		got = testRecordsString(an.Message.Answer)
+		test.Assert(t, c.desc+`: Answer`, c.expAnswer, got)
+
+		got = testRecordsString(an.Message.Authority)
+		test.Assert(t, c.desc+`: Authority`, c.expAuthority, got)
+
+		got = testRecordsString(an.Message.Additional)
+		test.Assert(t, c.desc+`: Additional`, c.expAdditional, got)
+	}
+}
+
+// testRecordsString return the name, type, and value of each record in
+// rrs.
+// The value of SOA and MX is not included.
+func testRecordsString(rrs []ResourceRecord) (out []string) {
+	var rr ResourceRecord
+	for _, rr = range rrs {
+		switch rr.Type {
+		case RecordTypeSOA, RecordTypeMX:
+			out = append(out, fmt.Sprintf(`%s %s`, rr.Name,
+				RecordTypeNames[rr.Type]))
+		default:
+			out = append(out, fmt.Sprintf(`%s %s %v`, rr.Name,
+				RecordTypeNames[rr.Type], rr.Value))
+		}
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"log"
+)
+
+// maxNotifyRetry define the maximum number of NOTIFY message sent to each
+// secondary until its acknowledged.
+const maxNotifyRetry = 3
+
+// notify send the NOTIFY message (RFC 1996) with the current SOA to all of
+// the zone Secondaries, in the background.
+func (zone *Zone) notify() {
+	var (
+		addr string
+		msg  *Message
+		err  error
+	)
+	for _, addr = range zone.Secondaries {
+		msg, err = zone.notifyMessage()
+		if err != nil {
+			log.Printf(`dns: notify %s: %s`, zone.Origin, err)
+			return
+		}
+		go notifySecondary(addr, msg)
+	}
+}
+
+// notifyMessage create new packed NOTIFY message for zone.
+func (zone *Zone) notifyMessage() (msg *Message, err error) {
+	msg = &Message{
+		Header: MessageHeader{
+			ID:      getNextID(),
+			IsQuery: true,
+			Op:      OpCodeNotify,
+			IsAA:    true,
+			QDCount: 1,
+		},
+		Question: MessageQuestion{
+			Name:  zone.Origin,
+			Type:  RecordTypeSOA,
+			Class: RecordClassIN,
+		},
+		Answer: []ResourceRecord{*zone.soaRecord()},
+	}
+	_, err = msg.Pack()
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// notifySecondary send the NOTIFY message msg to the secondary name server
+// at addr, until its acknowledged or after maxNotifyRetry.
+func notifySecondary(addr string, msg *Message) {
+	var (
+		origin = msg.Question.Name
+
+		cl  *UDPClient
+		res *Message
+		err error
+		x   int
+	)
+
+	cl, err = NewUDPClient(addr)
+	if err != nil {
+		log.Printf(`dns: notify %s to %s: %s`, origin, addr, err)
+		return
+	}
+
+	for x = 0; x < maxNotifyRetry; x++ {
+		res, err = cl.Query(msg)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		log.Printf(`dns: notify %s to %s: %s`, origin, addr, err)
+	} else if res.Header.RCode != RCodeOK {
+		log.Printf(`dns: notify %s to %s: response code %s`, origin,
+			addr, rcodeNames[res.Header.RCode])
+	}
+
+	err = cl.Close()
+	if err != nil {
+		log.Printf(`dns: notify %s to %s: %s`, origin, addr, err)
+	}
+}
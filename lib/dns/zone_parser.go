@@ -6,6 +6,7 @@ package dns
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -498,7 +499,8 @@ func (m *zoneParser) parseRRData(rr *ResourceRecord, tok []byte, c byte) (err er
 		rr.Value = string(tok)
 		err = m.skipLine(c)
 
-	case RecordTypeNS, RecordTypeCNAME, RecordTypeMB, RecordTypeMG, RecordTypeMR, RecordTypePTR:
+	case RecordTypeNS, RecordTypeCNAME, RecordTypeMB, RecordTypeMG, RecordTypeMR, RecordTypePTR,
+		RecordTypeDNAME:
 		rr.Value = m.generateDomainName(tok)
 		err = m.skipLine(c)
 
@@ -536,6 +538,14 @@ func (m *zoneParser) parseRRData(rr *ResourceRecord, tok []byte, c byte) (err er
 	case RecordTypeHTTPS:
 		err = m.parseHTTPS(rr, tok)
 
+	case RecordTypeDS, RecordTypeRRSIG, RecordTypeNSEC, RecordTypeDNSKEY,
+		RecordTypeNSEC3, RecordTypeNSEC3PARAM:
+		err = m.parseDNSSEC(rr, tok, c)
+
+	case RecordTypeNAPTR, RecordTypeSSHFP, RecordTypeTLSA, RecordTypeURI,
+		RecordTypeCAA:
+		err = m.parseRData(rr, tok, c)
+
 	default:
 		err = fmt.Errorf(`%s: unknown record type %d`, logp, rr.Type)
 	}
@@ -750,16 +760,13 @@ func (m *zoneParser) parseHTTPS(rr *ResourceRecord, tok []byte) (err error) {
 		logp = `parseHTTPS`
 		stok = string(tok)
 
-		priority int64
+		priority uint64
 	)
 
-	priority, err = strconv.ParseInt(stok, 10, 64)
+	priority, err = strconv.ParseUint(stok, 10, 16)
 	if err != nil {
 		return fmt.Errorf(`%s: invalid SvcPriority %q: %w`, logp, stok, err)
 	}
-	if priority != 0 {
-		return fmt.Errorf(`%s: expecting 0, got %q`, logp, stok)
-	}
 
 	err = m.next()
 	if err != nil {
@@ -768,6 +775,7 @@ func (m *zoneParser) parseHTTPS(rr *ResourceRecord, tok []byte) (err error) {
 
 	var https = &RDataHTTPS{
 		RDataSVCB: RDataSVCB{
+			Priority:   uint16(priority),
 			TargetName: m.generateDomainName(m.token),
 			Params:     map[int][]string{},
 		},
@@ -778,11 +786,212 @@ func (m *zoneParser) parseHTTPS(rr *ResourceRecord, tok []byte) (err error) {
 		return fmt.Errorf(`%s: %w`, logp, err)
 	}
 
+	err = https.RDataSVCB.validate()
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+
 	rr.Value = https
 
 	return nil
 }
 
+// parseDNSSEC parse the RDATA of DNSSEC records: DS, RRSIG, NSEC, DNSKEY,
+// NSEC3, and NSEC3PARAM.
+func (m *zoneParser) parseDNSSEC(rr *ResourceRecord, tok []byte, c byte) (err error) {
+	var (
+		logp = `parseDNSSEC`
+
+		fields [][]byte
+	)
+
+	fields, err = m.readRDataTokens(c)
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+	fields = append([][]byte{tok}, fields...)
+
+	switch rr.Type {
+	case RecordTypeDS:
+		var ds = &RDataDS{}
+		err = ds.parse(fields)
+		rr.Value = ds
+	case RecordTypeRRSIG:
+		var rrsig = &RDataRRSIG{}
+		err = rrsig.parse(m, fields)
+		rr.Value = rrsig
+	case RecordTypeNSEC:
+		var nsec = &RDataNSEC{}
+		err = nsec.parse(m, fields)
+		rr.Value = nsec
+	case RecordTypeDNSKEY:
+		var dnskey = &RDataDNSKEY{}
+		err = dnskey.parse(fields)
+		rr.Value = dnskey
+	case RecordTypeNSEC3:
+		var nsec3 = &RDataNSEC3{}
+		err = nsec3.parse(fields)
+		rr.Value = nsec3
+	case RecordTypeNSEC3PARAM:
+		var param = &RDataNSEC3PARAM{}
+		err = param.parse(fields)
+		rr.Value = param
+	}
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+	return nil
+}
+
+// parseRData parse the RDATA of records that contains list of fields:
+// NAPTR, SSHFP, TLSA, URI, and CAA.
+func (m *zoneParser) parseRData(rr *ResourceRecord, tok []byte, c byte) (err error) {
+	var (
+		logp = `parseRData`
+
+		fields [][]byte
+	)
+
+	fields, err = m.readRDataTokens(c)
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+	fields = append([][]byte{tok}, fields...)
+
+	switch rr.Type {
+	case RecordTypeNAPTR:
+		var naptr = &RDataNAPTR{}
+		err = naptr.parse(m, fields)
+		rr.Value = naptr
+	case RecordTypeSSHFP:
+		var sshfp = &RDataSSHFP{}
+		err = sshfp.parse(fields)
+		rr.Value = sshfp
+	case RecordTypeTLSA:
+		var tlsa = &RDataTLSA{}
+		err = tlsa.parse(fields)
+		rr.Value = tlsa
+	case RecordTypeURI:
+		var uri = &RDataURI{}
+		err = uri.parse(fields)
+		rr.Value = uri
+	case RecordTypeCAA:
+		var caa = &RDataCAA{}
+		err = caa.parse(fields)
+		rr.Value = caa
+	}
+	if err != nil {
+		return fmt.Errorf(`%s: line %d: %w`, logp, m.lineno, err)
+	}
+	return nil
+}
+
+// isQuoteOpen return true if the tok start with double quote but does not
+// end with the closing one.
+func isQuoteOpen(tok []byte) bool {
+	if len(tok) == 0 || tok[0] != '"' {
+		return false
+	}
+	return len(tok) == 1 || !isQuoteClosed(tok[1:])
+}
+
+// isQuoteClosed return true if the tok end with unescaped double quote.
+func isQuoteClosed(tok []byte) bool {
+	var (
+		size = len(tok)
+
+		nesc int
+		x    int
+	)
+	if size == 0 || tok[size-1] != '"' {
+		return false
+	}
+	// Count the backslash before the quote.
+	for x = size - 2; x >= 0; x-- {
+		if tok[x] != '\\' {
+			break
+		}
+		nesc++
+	}
+	return nesc%2 == 0
+}
+
+// readQuoted read the rest of quoted string in the current token,
+// including the spaces and parentheses, until the closing double quote.
+func (m *zoneParser) readQuoted() (err error) {
+	var (
+		delims = m.parser.Delimiters()
+
+		tok []byte
+		c   byte
+	)
+
+	m.token = append(m.token, m.delim)
+	m.parser.SetDelimiters([]byte{'"'})
+	for {
+		tok, c = m.parser.Read()
+		m.token = append(m.token, tok...)
+		if c != '"' {
+			m.parser.SetDelimiters(delims)
+			return fmt.Errorf(`line %d: missing closing '"'`, m.lineno)
+		}
+		m.token = append(m.token, '"')
+		if isQuoteClosed(m.token[1:]) {
+			break
+		}
+	}
+	m.parser.SetDelimiters(delims)
+
+	// Read the delimiter after the closing quote.
+	tok, m.delim = m.parser.Read()
+	m.token = append(m.token, tok...)
+
+	return nil
+}
+
+// readRDataTokens read the rest of tokens in the current record, including
+// the tokens inside the multiline parentheses.
+// The c is the delimiter after the first RDATA token.
+func (m *zoneParser) readRDataTokens(c byte) (fields [][]byte, err error) {
+	switch c {
+	case 0:
+		return nil, nil
+	case '\n':
+		m.lineno++
+		return nil, nil
+	case ';':
+		m.parser.SkipLine()
+		m.lineno++
+		return nil, nil
+	case '(':
+		m.isMultiline = true
+	}
+
+	m.delim = ' '
+	for {
+		err = m.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if len(m.token) != 0 {
+			fields = append(fields, bytes.Clone(m.token))
+		}
+		if m.delim == 0 {
+			break
+		}
+		if m.delim == '\n' && !m.isMultiline {
+			break
+		}
+	}
+	if m.isMultiline {
+		return nil, errors.New(`missing ')'`)
+	}
+	return fields, nil
+}
+
 func (m *zoneParser) parseMInfo(rr *ResourceRecord, tok []byte) (err error) {
 	var (
 		logp    = `parseMInfo`
@@ -1176,6 +1385,12 @@ func (m *zoneParser) next() (err error) {
 
 	for {
 		m.token, m.delim = m.parser.ReadNoSpace()
+		if isQuoteOpen(m.token) && m.delim != '\n' && m.delim != 0 {
+			err = m.readQuoted()
+			if err != nil {
+				return err
+			}
+		}
 		switch m.delim {
 		case ';':
 			m.delim = m.parser.SkipLine()
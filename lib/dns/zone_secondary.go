@@ -0,0 +1,278 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	libnet "git.sr.ht/~shulhan/pakakeh.go/lib/net"
+)
+
+// defaultSoaExpire define the expire time, in seconds, for secondary zone
+// if the SOA Expire is zero, one week as recommended by RFC 1912
+// section 2.2.
+const defaultSoaExpire int32 = 7 * 24 * 60 * 60
+
+// secondaryZone maintain the secondary copy of zone that is transferred
+// from the primary name server.
+type secondaryZone struct {
+	caches *Caches
+
+	// zone contains the current copy of zone.
+	// It is nil if the zone has not been transferred or expired.
+	zone *Zone
+
+	notifyq chan struct{}
+	stopq   chan struct{}
+
+	origin string
+
+	// primary contains the address of primary name server, in the
+	// format "ip:port".
+	primary string
+
+	// path contains the file to load and save the zone.
+	path string
+
+	// primaryIP contains the IP address of primary name server.
+	primaryIP net.IP
+
+	// expireAt contains the time, in epoch, when the zone is no longer
+	// authoritative.
+	expireAt int64
+}
+
+// newSecondaryZone create new secondary zone for origin from primary name
+// server at address "ip[:port]".
+// If file is exist, the zone is loaded from it and served until its
+// expired or refreshed.
+func newSecondaryZone(caches *Caches, origin, primary, file string) (sec *secondaryZone, err error) {
+	var (
+		logp = `newSecondaryZone`
+
+		ip   net.IP
+		port uint16
+	)
+
+	_, ip, port = libnet.ParseIPPort(primary, DefaultPort)
+	if ip == nil {
+		return nil, fmt.Errorf(`%s: invalid primary address %q`, logp, primary)
+	}
+
+	sec = &secondaryZone{
+		caches:    caches,
+		notifyq:   make(chan struct{}, 1),
+		stopq:     make(chan struct{}),
+		primaryIP: ip,
+		origin:    strings.ToLower(toDomainAbsolute(origin)),
+		primary:   net.JoinHostPort(ip.String(), strconv.Itoa(int(port))),
+		path:      file,
+	}
+
+	if len(file) == 0 {
+		return sec, nil
+	}
+
+	var zone *Zone
+
+	zone, err = ParseZoneFile(file, sec.origin, 0)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return sec, nil
+		}
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	sec.caches.internalZoneReplace(nil, zone)
+	sec.zone = zone
+	sec.expireAt = timeNow().Unix() + int64(sec.expire())
+
+	return sec, nil
+}
+
+// isSerialNewer return true if the serial a is greater than b using the
+// serial number arithmetic in RFC 1982.
+func isSerialNewer(a, b uint32) bool {
+	return a != b && int32(a-b) > 0
+}
+
+// run refresh the zone on the timers in its SOA, or when receiving
+// NOTIFY, until stopped.
+func (sec *secondaryZone) run() {
+	var timer = time.NewTimer(sec.refresh())
+
+	for {
+		select {
+		case <-timer.C:
+		case <-sec.notifyq:
+			timer.Stop()
+		case <-sec.stopq:
+			timer.Stop()
+			return
+		}
+		timer.Reset(sec.refresh())
+	}
+}
+
+// notified signal the secondary to refresh the zone immediately.
+func (sec *secondaryZone) notified() {
+	select {
+	case sec.notifyq <- struct{}{}:
+	default:
+		// The refresh is already scheduled.
+	}
+}
+
+// stop refreshing the secondary zone.
+func (sec *secondaryZone) stop() {
+	close(sec.stopq)
+}
+
+// refresh check the SOA serial in the primary and transfer the zone if
+// its has been changed, as described in RFC 1034 section 4.3.5.
+// It will return the duration to wait before next refresh.
+func (sec *secondaryZone) refresh() (wait time.Duration) {
+	var (
+		now = timeNow().Unix()
+
+		zone   *Zone
+		serial uint32
+		err    error
+	)
+
+	if sec.zone != nil && now >= sec.expireAt {
+		log.Printf(`dns: secondary %s: zone expired`, sec.origin)
+		sec.caches.internalZoneReplace(sec.zone, nil)
+		sec.zone = nil
+	}
+
+	serial, err = sec.primarySerial()
+	if err != nil {
+		log.Printf(`dns: secondary %s: %s`, sec.origin, err)
+		return sec.retry(now)
+	}
+	if sec.zone != nil && !isSerialNewer(serial, sec.zone.SOA.Serial) {
+		sec.expireAt = now + int64(sec.expire())
+		return time.Duration(sec.zone.SOA.Refresh) * time.Second
+	}
+
+	zone, err = sec.transfer()
+	if err != nil {
+		log.Printf(`dns: secondary %s: %s`, sec.origin, err)
+		return sec.retry(now)
+	}
+
+	sec.caches.internalZoneReplace(sec.zone, zone)
+	sec.zone = zone
+	sec.expireAt = now + int64(sec.expire())
+
+	if len(sec.path) != 0 {
+		zone.Path = sec.path
+		err = zone.Save()
+		if err != nil {
+			log.Printf(`dns: secondary %s: %s`, sec.origin, err)
+		}
+	}
+	log.Printf(`dns: secondary %s: transferred serial %d`, sec.origin,
+		zone.SOA.Serial)
+
+	return time.Duration(zone.SOA.Refresh) * time.Second
+}
+
+// retry return the duration to wait before retrying the failed refresh.
+// The duration will not exceed the expire time of the zone.
+func (sec *secondaryZone) retry(now int64) time.Duration {
+	var retry = int64(DefaultSoaRetry)
+	if sec.zone == nil {
+		return time.Duration(retry) * time.Second
+	}
+	retry = int64(sec.zone.SOA.Retry)
+	if now+retry > sec.expireAt {
+		retry = sec.expireAt - now
+	}
+	return time.Duration(retry) * time.Second
+}
+
+// expire return the SOA Expire of zone or defaultSoaExpire if its not
+// set.
+func (sec *secondaryZone) expire() int32 {
+	if sec.zone == nil || sec.zone.SOA.Expire <= 0 {
+		return defaultSoaExpire
+	}
+	return sec.zone.SOA.Expire
+}
+
+// primarySerial query the SOA serial of zone in the primary.
+func (sec *secondaryZone) primarySerial() (serial uint32, err error) {
+	var (
+		logp = `primarySerial`
+
+		cl  *UDPClient
+		res *Message
+		rr  ResourceRecord
+	)
+
+	cl, err = NewUDPClient(sec.primary)
+	if err != nil {
+		return 0, fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	res, err = cl.Lookup(MessageQuestion{
+		Name:  sec.origin,
+		Type:  RecordTypeSOA,
+		Class: RecordClassIN,
+	}, false)
+
+	var errClose = cl.Close()
+	if err != nil {
+		return 0, fmt.Errorf(`%s: %w`, logp, err)
+	}
+	if errClose != nil {
+		return 0, fmt.Errorf(`%s: %w`, logp, errClose)
+	}
+	if res.Header.RCode != RCodeOK {
+		return 0, fmt.Errorf(`%s: response code %s`, logp,
+			rcodeNames[res.Header.RCode])
+	}
+
+	for _, rr = range res.Answer {
+		var soa, ok = rr.Value.(*RDataSOA)
+		if ok {
+			return soa.Serial, nil
+		}
+	}
+	return 0, fmt.Errorf(`%s: missing SOA in answer`, logp)
+}
+
+// transfer the zone from primary using AXFR.
+func (sec *secondaryZone) transfer() (zone *Zone, err error) {
+	var (
+		logp = `transfer`
+
+		cl *TCPClient
+	)
+
+	cl, err = NewTCPClient(sec.primary)
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	zone, err = cl.Transfer(sec.origin)
+
+	var errClose = cl.Close()
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+	if errClose != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, errClose)
+	}
+	return zone, nil
+}
@@ -0,0 +1,300 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestIsSerialNewer(t *testing.T) {
+	var listCase = []struct {
+		a   uint32
+		b   uint32
+		exp bool
+	}{{
+		a: 2, b: 1, exp: true,
+	}, {
+		a: 1, b: 1,
+	}, {
+		a: 1, b: 2,
+	}, {
+		// The serial is wrapped.
+		a: 1, b: 0xFFFFFFF0, exp: true,
+	}, {
+		a: 0xFFFFFFF0, b: 1,
+	}}
+
+	for _, c := range listCase {
+		test.Assert(t, `isSerialNewer`, c.exp, isSerialNewer(c.a, c.b))
+	}
+}
+
+func TestSecondaryZone_refresh(t *testing.T) {
+	var (
+		tdata   *test.Data
+		primary *Zone
+		err     error
+	)
+
+	tdata, err = test.LoadData(`testdata/Zone_secondary_test.txt`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	primary, err = ParseZone(tdata.Input[`zone`], `secondary.test`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_testServer.Caches.InternalPopulateZone(primary)
+
+	var (
+		caches = &Caches{}
+		file   = filepath.Join(t.TempDir(), `secondary.test`)
+
+		sec *secondaryZone
+	)
+
+	caches.init(0, 0, 0)
+
+	sec, err = newSecondaryZone(caches, `secondary.test`, testServerAddress, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wait = sec.refresh()
+	test.Assert(t, `wait`, time.Hour, wait)
+	test.Assert(t, `expireAt`, int64(testNowEpoch+604800), sec.expireAt)
+	test.Assert(t, `answer www`, true, caches.internal[`www.secondary.test`] != nil)
+
+	var content []byte
+
+	content, err = os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `saved zone`, string(tdata.Output[`secondary.test`]),
+		string(content))
+
+	// Update the zone in primary.
+	_, err = _testServer.Caches.updateZone(primary, &Message{
+		Authority: []ResourceRecord{{
+			Name:  `new.secondary.test`,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+			TTL:   300,
+			Value: `10.0.0.3`,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wait = sec.refresh()
+	test.Assert(t, `wait`, time.Hour, wait)
+	test.Assert(t, `serial`, primary.SOA.Serial, sec.zone.SOA.Serial)
+	test.Assert(t, `answer new`, true, caches.internal[`new.secondary.test`] != nil)
+}
+
+func TestSecondaryZone_refresh_expired(t *testing.T) {
+	var (
+		tdata *test.Data
+		zone  *Zone
+		err   error
+	)
+
+	tdata, err = test.LoadData(`testdata/Zone_secondary_test.txt`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The primary does not have the zone, so each refresh will fail.
+	zone, err = ParseZone(tdata.Input[`zone`], `unknown.test`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		caches = &Caches{}
+
+		sec *secondaryZone
+	)
+
+	caches.init(0, 0, 0)
+
+	sec, err = newSecondaryZone(caches, `unknown.test`, testServerAddress, ``)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caches.internalZoneReplace(nil, zone)
+	sec.zone = zone
+	sec.expireAt = testNowEpoch + 10
+
+	// The retry should not exceed the expire time.
+	var wait = sec.refresh()
+	test.Assert(t, `wait`, 10*time.Second, wait)
+	test.Assert(t, `answer www`, true, caches.internal[`www.unknown.test`] != nil)
+
+	sec.expireAt = testNowEpoch
+
+	wait = sec.refresh()
+	test.Assert(t, `wait`, time.Hour, wait)
+	test.Assert(t, `expired zone`, true, sec.zone == nil)
+	test.Assert(t, `expired answer`, true, caches.internal[`www.unknown.test`] == nil)
+	test.Assert(t, `expired caches zone`, true, caches.zone[`unknown.test.`] == nil)
+}
+
+func TestServer_serveNotify(t *testing.T) {
+	var (
+		key = newTestTSIGKey(t, `notify.key`)
+
+		tdata *test.Data
+		zone  *Zone
+		err   error
+	)
+
+	tdata, err = test.LoadData(`testdata/Zone_secondary_test.txt`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zone, err = ParseZone(tdata.Input[`zone`], `notify.test`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zone.Secondaries = []string{testServerAddress}
+
+	err = zone.SetTSIGKeys(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Run the primary server.
+	var primary *Server
+
+	primary, err = NewServer(&ServerOptions{
+		ListenAddress: `127.0.0.1:5310`,
+		TransferAllow: []string{`127.0.0.1`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary.Caches.InternalPopulateZone(zone)
+
+	go func() {
+		_ = primary.ListenAndServe()
+	}()
+	t.Cleanup(primary.Stop)
+
+	err = _testServer.AddSecondaryZone(`notify.test`, `127.0.0.1:5310`, ``)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cl *UDPClient
+
+	cl, err = NewUDPClient(testServerAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Close()
+
+	waitLookup(t, cl, `www.notify.test`)
+
+	// Update the zone in primary, which trigger NOTIFY to secondary.
+	var clPrimary *UDPClient
+
+	clPrimary, err = NewUDPClient(`127.0.0.1:5310`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clPrimary.Close()
+
+	clPrimary.SetTSIGKey(key)
+
+	var (
+		req = &Message{
+			Header: MessageHeader{
+				ID:      getNextID(),
+				IsQuery: true,
+				Op:      OpCodeUpdate,
+				QDCount: 1,
+			},
+			Question: MessageQuestion{
+				Name:  `notify.test`,
+				Type:  RecordTypeSOA,
+				Class: RecordClassIN,
+			},
+			Authority: []ResourceRecord{{
+				Name:  `new.notify.test`,
+				Type:  RecordTypeA,
+				Class: RecordClassIN,
+				TTL:   300,
+				Value: `10.0.0.3`,
+			}},
+		}
+		res *Message
+	)
+
+	res, err = clPrimary.Query(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `update RCode`, RCodeOK, res.Header.RCode)
+
+	waitLookup(t, cl, `new.notify.test`)
+
+	// NOTIFY for zone that is not secondary.
+	req = &Message{
+		Header: MessageHeader{
+			ID:      getNextID(),
+			IsQuery: true,
+			Op:      OpCodeNotify,
+			IsAA:    true,
+			QDCount: 1,
+		},
+		Question: MessageQuestion{
+			Name:  `unknown.test`,
+			Type:  RecordTypeSOA,
+			Class: RecordClassIN,
+		},
+	}
+	_, err = req.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = cl.Query(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `unknown zone RCode`, RCodeNotAuth, res.Header.RCode)
+}
+
+// waitLookup query the A record of name until its found or timeout.
+func waitLookup(t *testing.T, cl *UDPClient, name string) {
+	var (
+		q = MessageQuestion{
+			Name: name,
+			Type: RecordTypeA,
+		}
+
+		res *Message
+		err error
+		x   int
+	)
+	for x = 0; x < 50; x++ {
+		res, err = cl.Lookup(q, false)
+		if err == nil && len(res.Answer) != 0 {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf(`waitLookup %s: not found`, name)
+}
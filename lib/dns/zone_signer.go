@@ -0,0 +1,855 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultSignatureValidity define the default duration of RRSIG validity
+// generated by ZoneSigner.
+const defaultSignatureValidity = 30 * 24 * time.Hour
+
+// ZoneSigner contains the private key and options to sign the Zone online.
+//
+// The key is used as Combined Signing Key (CSK), it sign all of RRset in
+// the zone including the DNSKEY itself.
+type ZoneSigner struct {
+	// Key contains the private key to sign the zone.
+	// The supported key is *ecdsa.PrivateKey with P-256 curve or
+	// ed25519.PrivateKey.
+	Key crypto.Signer
+
+	dnskey *RDataDNSKEY
+
+	// sigs contains the generated RRSIG and its signed data, indexed by
+	// owner name and type covered.
+	// It is used to skip signing the RRset that does not changes.
+	sigs map[string]*zoneSignature
+
+	// soaSig contains the RRSIG of zone SOA, used on negative answer.
+	soaSig *ResourceRecord
+
+	// chain contains the NSEC or NSEC3 records with its RRSIG, ordered
+	// by owner name or owner hash.
+	chain []*zoneChain
+
+	// NSEC3Salt contains the salt for hashing the owner name.
+	// This field is used only if UseNSEC3 is true.
+	NSEC3Salt []byte
+
+	// Validity define the duration of the signature validity.
+	// This field is optional, default to 30 days.
+	// The signature will be renewed after half of its validity.
+	Validity time.Duration
+
+	// refreshAt define the time, in seconds since epoch, when the zone
+	// must be re-signed to renew the signatures.
+	refreshAt int64
+
+	// NSEC3Iterations define the number of additional hash iterations
+	// for NSEC3.
+	// This field is used only if UseNSEC3 is true.
+	// RFC 9276 recommend to set this value to 0.
+	NSEC3Iterations uint16
+
+	// UseNSEC3 if true the zone use NSEC3 for authenticated denial of
+	// existence, instead of NSEC.
+	UseNSEC3 bool
+}
+
+// zoneSignature contains the RRSIG and the data that it signed.
+type zoneSignature struct {
+	rrsig *RDataRRSIG
+	data  []byte
+}
+
+// zoneChain contains single NSEC or NSEC3 record and its RRSIG.
+type zoneChain struct {
+	rr    *ResourceRecord
+	rrsig *ResourceRecord
+
+	// name contains the original owner name in lower case and without
+	// trailing dot.
+	name string
+
+	// hash contains the owner hash in base32hex lower case, for NSEC3.
+	hash string
+}
+
+// init validate the key and generate the DNSKEY.
+func (signer *ZoneSigner) init() (err error) {
+	switch key := signer.Key.(type) {
+	case nil:
+		return errors.New(`empty signing key`)
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return fmt.Errorf(`unsupported ECDSA curve %s`, key.Curve.Params().Name)
+		}
+	case ed25519.PrivateKey:
+	default:
+		return fmt.Errorf(`unsupported signing key %T`, signer.Key)
+	}
+
+	signer.dnskey, err = newRDataDNSKEY(signer.Key.Public(), DNSKEYFlagZone|DNSKEYFlagSEP)
+	if err != nil {
+		return err
+	}
+	if signer.Validity <= 0 {
+		signer.Validity = defaultSignatureValidity
+	}
+	signer.sigs = make(map[string]*zoneSignature)
+	return nil
+}
+
+// SetSigner attach the signer to the zone and sign all of its records.
+// Each authoritative RRset in the zone will have the RRSIG record, each
+// name will be chained by NSEC or NSEC3 record, and the DNSKEY of signer is
+// added to the zone origin.
+//
+// Once the signer is attached, calling Add or Remove will re-sign the
+// affected records, and the negative answer for name under the zone will
+// contains the proof of non-existence.
+// The signed records are written along with other records when the zone
+// is saved.
+//
+// Setting the signer to nil remove all of the DNSSEC records from zone.
+//
+// If the zone has been populated into [Caches], the internal answers of the
+// zone are replaced with the new records, otherwise call
+// [Caches.InternalPopulateZone] after this method to serve them.
+func (zone *Zone) SetSigner(signer *ZoneSigner) (err error) {
+	if zone.caches != nil {
+		return zone.caches.internalZoneUpdate(zone, func() error {
+			return zone.setSigner(signer)
+		})
+	}
+	return zone.setSigner(signer)
+}
+
+func (zone *Zone) setSigner(signer *ZoneSigner) (err error) {
+	var logp = `SetSigner`
+
+	zone.unsign()
+	zone.signer = nil
+
+	if signer == nil {
+		zone.packMessages()
+		return nil
+	}
+
+	err = signer.init()
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	zone.signer = signer
+
+	err = zone.sign()
+	if err != nil {
+		return fmt.Errorf(`%s: %w`, logp, err)
+	}
+	return nil
+}
+
+// DS return the DS record of the zone signer key, to be published in the
+// parent zone.
+// The digestType is one of DSDigestSHA1, DSDigestSHA256, or DSDigestSHA384.
+func (zone *Zone) DS(digestType byte) (rr *ResourceRecord, err error) {
+	var logp = `DS`
+
+	if zone.signer == nil {
+		return nil, fmt.Errorf(`%s: zone %s is not signed`, logp, zone.Origin)
+	}
+
+	var ds *RDataDS
+
+	ds, err = newRDataDS(zone.Origin, zone.signer.dnskey, digestType)
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+
+	rr = &ResourceRecord{
+		Name:  zone.Origin,
+		Type:  RecordTypeDS,
+		Class: RecordClassIN,
+		TTL:   zone.SOA.Minimum,
+		Value: ds,
+	}
+	return rr, nil
+}
+
+// isDNSSECRecord return true if the rr is generated by signer.
+func (zone *Zone) isDNSSECRecord(rr *ResourceRecord) bool {
+	switch rr.Type {
+	case RecordTypeRRSIG, RecordTypeNSEC, RecordTypeNSEC3, RecordTypeNSEC3PARAM:
+		return true
+	case RecordTypeDNSKEY:
+		if zone.signer == nil {
+			return false
+		}
+		var dnskey, ok = rr.Value.(*RDataDNSKEY)
+		if !ok {
+			return false
+		}
+		return dnskey.Algorithm == zone.signer.dnskey.Algorithm &&
+			bytes.Equal(dnskey.PublicKey, zone.signer.dnskey.PublicKey)
+	}
+	return false
+}
+
+// unsign remove all of the DNSSEC records from zone records and messages.
+// The message that only contains DNSSEC records is kept, with empty
+// answer, so the caches that hold it still can be refreshed by sign.
+func (zone *Zone) unsign() {
+	var (
+		name   string
+		listRR []*ResourceRecord
+	)
+	for name, listRR = range zone.Records {
+		listRR = slices.DeleteFunc(listRR, zone.isDNSSECRecord)
+		if len(listRR) == 0 {
+			delete(zone.Records, name)
+			continue
+		}
+		zone.Records[name] = listRR
+	}
+
+	var msg *Message
+	for _, msg = range zone.messages {
+		msg.Answer = slices.DeleteFunc(msg.Answer, func(rr ResourceRecord) bool {
+			return zone.isDNSSECRecord(&rr)
+		})
+	}
+}
+
+// sign generate the DNSKEY, NSEC or NSEC3 chain, and RRSIG of all RRset
+// in the zone.
+// The RRSIG of RRset that does not changes since the last sign is reused,
+// as long as it has not pass the half of its validity.
+func (zone *Zone) sign() (err error) {
+	var (
+		signer = zone.signer
+		now    = timeNow().Unix()
+		sigs   = make(map[string]*zoneSignature, len(signer.sigs))
+	)
+
+	zone.unsign()
+	zone.refreshSOA()
+
+	err = zone.add(&ResourceRecord{
+		Name:  zone.Origin,
+		Type:  RecordTypeDNSKEY,
+		Class: RecordClassIN,
+		TTL:   zone.SOA.Minimum,
+		Value: signer.dnskey,
+	})
+	if err != nil {
+		return err
+	}
+	if signer.UseNSEC3 {
+		err = zone.add(&ResourceRecord{
+			Name:  zone.Origin,
+			Type:  RecordTypeNSEC3PARAM,
+			Class: RecordClassIN,
+			TTL:   zone.SOA.Minimum,
+			Value: &RDataNSEC3PARAM{
+				HashAlgorithm: NSEC3HashSHA1,
+				Iterations:    signer.NSEC3Iterations,
+				Salt:          signer.NSEC3Salt,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var (
+		nameTypes = zone.authoritativeTypes()
+		listRR    []*ResourceRecord
+		rr        *ResourceRecord
+	)
+	if signer.UseNSEC3 {
+		listRR = zone.nsec3Chain(nameTypes)
+	} else {
+		listRR = zone.nsecChain(nameTypes)
+	}
+	for _, rr = range listRR {
+		err = zone.add(rr)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Sign all authoritative RRset, including the NSEC/NSEC3 that we
+	// just added.
+	var (
+		msg    *Message
+		rrsig  *ResourceRecord
+		rrsigs []*ResourceRecord
+		minExp = now + int64(signer.Validity.Seconds())
+	)
+	signer.soaSig = nil
+	for _, msg = range zone.messages {
+		if len(msg.Answer) == 0 || msg.Question.Type == RecordTypeRRSIG {
+			continue
+		}
+		if !zone.isAuthoritative(nameTypes, msg.Question.Name, msg.Question.Type) {
+			continue
+		}
+		rrsig, err = zone.signRRSet(msg.Answer, now, sigs)
+		if err != nil {
+			return fmt.Errorf(`%s %s: %w`, msg.Question.Name,
+				recordTypeName(msg.Question.Type), err)
+		}
+		rrsigs = append(rrsigs, rrsig)
+
+		var exp = int64(rrsig.Value.(*RDataRRSIG).Expiration)
+		if exp < minExp {
+			minExp = exp
+		}
+		if msg.Question.Type == RecordTypeSOA {
+			signer.soaSig = rrsig
+		}
+	}
+	for _, rrsig = range rrsigs {
+		err = zone.add(rrsig)
+		if err != nil {
+			return err
+		}
+	}
+
+	signer.sigs = sigs
+	signer.refreshAt = minExp - int64(signer.Validity.Seconds())/2
+	zone.initChain(listRR, rrsigs)
+	zone.packMessages()
+
+	return nil
+}
+
+// refreshSOA set the answer of SOA message to the current zone SOA.
+func (zone *Zone) refreshSOA() {
+	var (
+		rrSOA = zone.soaRecord()
+		msg   = zone.message(zone.Origin, RecordTypeSOA)
+	)
+	if msg == nil {
+		msg = &Message{
+			Header: MessageHeader{
+				IsAA:    true,
+				QDCount: 1,
+			},
+			Question: MessageQuestion{
+				Name:  rrSOA.Name,
+				Type:  rrSOA.Type,
+				Class: rrSOA.Class,
+			},
+		}
+		zone.messages = append(zone.messages, msg)
+	}
+	msg.Answer = []ResourceRecord{*rrSOA}
+}
+
+// message return the pre-generated message by its question name and type.
+func (zone *Zone) message(name string, rtype RecordType) *Message {
+	var msg *Message
+	for _, msg = range zone.messages {
+		if msg.Question.Type != rtype {
+			continue
+		}
+		if strings.EqualFold(msg.Question.Name, name) {
+			return msg
+		}
+	}
+	return nil
+}
+
+// packMessages remove the empty DNSSEC messages and pack the rest.
+func (zone *Zone) packMessages() {
+	zone.messages = slices.DeleteFunc(zone.messages, func(msg *Message) bool {
+		if len(msg.Answer) != 0 {
+			return false
+		}
+		switch msg.Question.Type {
+		case RecordTypeRRSIG, RecordTypeNSEC, RecordTypeNSEC3,
+			RecordTypeNSEC3PARAM, RecordTypeDNSKEY:
+			return true
+		}
+		return false
+	})
+
+	var (
+		msg *Message
+		err error
+	)
+	for _, msg = range zone.messages {
+		msg.Header.ANCount = uint16(len(msg.Answer))
+		msg.Header.NSCount = uint16(len(msg.Authority))
+		msg.Header.ARCount = uint16(len(msg.Additional))
+
+		_, err = msg.Pack()
+		if err != nil {
+			msg.Header.ANCount = 0
+		}
+	}
+}
+
+// authoritativeTypes return the list of record types for each owner name
+// in the zone, excluding the names under delegation (glue).
+// The owner name is in lower case and without trailing dot.
+func (zone *Zone) authoritativeTypes() (nameTypes map[string][]RecordType) {
+	var (
+		origin = dnssecName(zone.Origin)
+		cuts   []string
+		msg    *Message
+		name   string
+	)
+
+	nameTypes = make(map[string][]RecordType)
+
+	for _, msg = range zone.messages {
+		if len(msg.Answer) == 0 {
+			continue
+		}
+		name = dnssecName(msg.Question.Name)
+		if !dnssecIsSubdomain(name, origin) {
+			continue
+		}
+		nameTypes[name] = append(nameTypes[name], msg.Question.Type)
+		if msg.Question.Type == RecordTypeNS && name != origin {
+			cuts = append(cuts, name)
+		}
+	}
+
+	var cut string
+	for name = range nameTypes {
+		for _, cut = range cuts {
+			if name != cut && dnssecIsSubdomain(name, cut) {
+				delete(nameTypes, name)
+				break
+			}
+		}
+	}
+	return nameTypes
+}
+
+// isAuthoritative return true if the RRset with owner name and type
+// should be signed.
+// At the delegation point, only DS and NSEC are signed.
+func (zone *Zone) isAuthoritative(nameTypes map[string][]RecordType, name string, rtype RecordType) bool {
+	name = dnssecName(name)
+
+	var types, ok = nameTypes[name]
+	if !ok {
+		// NSEC3 records owned by the hashed name.
+		return rtype == RecordTypeNSEC3
+	}
+	if name == dnssecName(zone.Origin) || !slices.Contains(types, RecordTypeNS) {
+		return true
+	}
+	return rtype == RecordTypeDS || rtype == RecordTypeNSEC
+}
+
+// nsecChain generate the NSEC records for each owner name.
+func (zone *Zone) nsecChain(nameTypes map[string][]RecordType) (listRR []*ResourceRecord) {
+	var (
+		names = sortedNames(nameTypes)
+		name  string
+		x     int
+	)
+	for x, name = range names {
+		var types = slices.Clone(nameTypes[name])
+		types = append(types, RecordTypeRRSIG, RecordTypeNSEC)
+		slices.Sort(types)
+		types = slices.Compact(types)
+
+		listRR = append(listRR, &ResourceRecord{
+			Name:  toDomainAbsolute(name),
+			Type:  RecordTypeNSEC,
+			Class: RecordClassIN,
+			TTL:   zone.SOA.Minimum,
+			Value: &RDataNSEC{
+				NextDomain: toDomainAbsolute(names[(x+1)%len(names)]),
+				Types:      types,
+			},
+		})
+	}
+	return listRR
+}
+
+// nsec3Chain generate the NSEC3 records for each owner name and empty
+// non-terminal.
+func (zone *Zone) nsec3Chain(nameTypes map[string][]RecordType) (listRR []*ResourceRecord) {
+	var (
+		signer = zone.signer
+		origin = dnssecName(zone.Origin)
+		all    = make(map[string][]RecordType, len(nameTypes))
+
+		name   string
+		parent string
+		types  []RecordType
+		ok     bool
+	)
+
+	for name, types = range nameTypes {
+		all[name] = types
+
+		// Add the empty non-terminals between name and origin.
+		parent = name
+		for parent != origin {
+			parent, ok = dnssecParent(parent)
+			if !ok {
+				break
+			}
+			_, ok = all[parent]
+			if !ok {
+				all[parent] = nil
+			}
+		}
+	}
+
+	type hashedName struct {
+		name string
+		hash []byte
+	}
+
+	var list = make([]hashedName, 0, len(all))
+	for name = range all {
+		list = append(list, hashedName{
+			name: name,
+			hash: nsec3Hash(name, signer.NSEC3Salt, signer.NSEC3Iterations),
+		})
+	}
+	slices.SortFunc(list, func(a, b hashedName) int {
+		return bytes.Compare(a.hash, b.hash)
+	})
+
+	var x int
+	for x = range list {
+		types = slices.Clone(all[list[x].name])
+		if len(types) > 0 && !isUnsignedDelegation(types) {
+			types = append(types, RecordTypeRRSIG)
+		}
+		slices.Sort(types)
+		types = slices.Compact(types)
+
+		listRR = append(listRR, &ResourceRecord{
+			Name: strings.ToLower(nsec3Encoding.EncodeToString(list[x].hash)) +
+				`.` + zone.Origin,
+			Type:  RecordTypeNSEC3,
+			Class: RecordClassIN,
+			TTL:   zone.SOA.Minimum,
+			Value: &RDataNSEC3{
+				HashAlgorithm:   NSEC3HashSHA1,
+				Iterations:      signer.NSEC3Iterations,
+				Salt:            signer.NSEC3Salt,
+				NextHashedOwner: list[(x+1)%len(list)].hash,
+				Types:           types,
+			},
+		})
+	}
+	return listRR
+}
+
+// isUnsignedDelegation return true if the types contains NS without SOA
+// and DS.
+func isUnsignedDelegation(types []RecordType) bool {
+	return slices.Contains(types, RecordTypeNS) &&
+		!slices.Contains(types, RecordTypeSOA) &&
+		!slices.Contains(types, RecordTypeDS)
+}
+
+// sortedNames return the names in canonical order.
+func sortedNames(nameTypes map[string][]RecordType) (names []string) {
+	names = make([]string, 0, len(nameTypes))
+	var name string
+	for name = range nameTypes {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, dnssecCompareName)
+	return names
+}
+
+// signRRSet generate the RRSIG for RRset.
+// If the previous RRSIG exist, the RRset does not changes, and the
+// signature still valid more than half of its validity, it will be reused.
+func (zone *Zone) signRRSet(rrset []ResourceRecord, now int64, sigs map[string]*zoneSignature) (rr *ResourceRecord, err error) {
+	var (
+		signer = zone.signer
+		first  = rrset[0]
+		key    = fmt.Sprintf(`%s %d`, dnssecName(first.Name), first.Type)
+		prev   = signer.sigs[key]
+
+		rrsig *RDataRRSIG
+		data  []byte
+	)
+
+	if prev != nil && int64(prev.rrsig.Expiration)-now > int64(signer.Validity.Seconds())/2 {
+		data, err = dnssecSignedData(prev.rrsig, rrset)
+		if err == nil && bytes.Equal(data, prev.data) {
+			rrsig = prev.rrsig
+		}
+	}
+	if rrsig == nil {
+		rrsig = &RDataRRSIG{
+			TypeCovered: first.Type,
+			Algorithm:   signer.dnskey.Algorithm,
+			Labels:      byte(dnssecLabels(first.Name)),
+			OrigTTL:     first.TTL,
+			Expiration:  uint32(now + int64(signer.Validity.Seconds())),
+			// Allow one hour of clock skew between us and the
+			// validator.
+			Inception:  uint32(now - 3600),
+			KeyTag:     signer.dnskey.KeyTag(),
+			SignerName: zone.Origin,
+		}
+		err = dnssecSign(signer.Key, rrsig, rrset)
+		if err != nil {
+			return nil, err
+		}
+		data, err = dnssecSignedData(rrsig, rrset)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sigs[key] = &zoneSignature{
+		rrsig: rrsig,
+		data:  data,
+	}
+
+	rr = &ResourceRecord{
+		Name:  first.Name,
+		Type:  RecordTypeRRSIG,
+		Class: first.Class,
+		TTL:   first.TTL,
+		Value: rrsig,
+	}
+	return rr, nil
+}
+
+// initChain store the NSEC or NSEC3 records along with its RRSIG, used
+// to generate the proof of non-existence on the fly.
+func (zone *Zone) initChain(listRR, rrsigs []*ResourceRecord) {
+	var (
+		signer = zone.signer
+		rr     *ResourceRecord
+		rrsig  *ResourceRecord
+	)
+
+	signer.chain = signer.chain[:0]
+	for _, rr = range listRR {
+		var link = &zoneChain{
+			rr:   rr,
+			name: dnssecName(rr.Name),
+		}
+		if rr.Type == RecordTypeNSEC3 {
+			link.hash, _, _ = strings.Cut(link.name, `.`)
+		}
+		for _, rrsig = range rrsigs {
+			if rrsig.Name == rr.Name && rrsig.Value.(*RDataRRSIG).TypeCovered == rr.Type {
+				link.rrsig = rrsig
+				break
+			}
+		}
+		signer.chain = append(signer.chain, link)
+	}
+}
+
+// addDenial add the signed proof of non-existence of question name or
+// type into the Authority of negative answer msg.
+// If the name exist in the zone the response code will be set to
+// RCodeOK (NODATA), otherwise it will be set to RCodeErrName (NXDOMAIN).
+func (zone *Zone) addDenial(msg *Message) (err error) {
+	var (
+		signer = zone.signer
+		qname  = dnssecName(msg.Question.Name)
+		proofs []*zoneChain
+		exist  bool
+	)
+
+	if signer.UseNSEC3 {
+		proofs, exist = zone.proveNSEC3(qname)
+	} else {
+		proofs, exist = zone.proveNSEC(qname)
+	}
+
+	if signer.soaSig != nil {
+		msg.Authority = append(msg.Authority, *signer.soaSig)
+	}
+
+	var link *zoneChain
+	for _, link = range proofs {
+		msg.Authority = append(msg.Authority, *link.rr)
+		if link.rrsig != nil {
+			msg.Authority = append(msg.Authority, *link.rrsig)
+		}
+	}
+	msg.Header.NSCount = uint16(len(msg.Authority))
+
+	if exist {
+		msg.Header.RCode = RCodeOK
+	} else {
+		msg.Header.RCode = RCodeErrName
+	}
+
+	_, err = msg.Pack()
+	return err
+}
+
+// proveNSEC return the NSEC records that prove the non-existence of name,
+// and the closest encloser wildcard.
+// If the name exist, or its an empty non-terminal, it will return true.
+func (zone *Zone) proveNSEC(name string) (proofs []*zoneChain, exist bool) {
+	var (
+		signer = zone.signer
+		link   *zoneChain
+		nsec   *RDataNSEC
+	)
+
+	for _, link = range signer.chain {
+		if link.name == name {
+			return []*zoneChain{link}, true
+		}
+	}
+
+	var cover = func(name string) *zoneChain {
+		var l *zoneChain
+		for _, l = range signer.chain {
+			var next = l.rr.Value.(*RDataNSEC).NextDomain
+			if isNSECCover(l.name, next, name) {
+				return l
+			}
+		}
+		return nil
+	}
+
+	link = cover(name)
+	if link == nil {
+		return nil, false
+	}
+	proofs = append(proofs, link)
+
+	nsec = link.rr.Value.(*RDataNSEC)
+	if dnssecName(nsec.NextDomain) != name &&
+		dnssecIsSubdomain(nsec.NextDomain, name) {
+		// Empty non-terminal.
+		return proofs, true
+	}
+
+	// Prove that the wildcard at the closest encloser does not exist.
+	var (
+		encloser = zone.closestEncloser(name)
+		wildcard = `*.` + encloser
+	)
+	if len(encloser) == 0 {
+		wildcard = `*`
+	}
+	var wlink = cover(wildcard)
+	if wlink != nil && wlink != proofs[0] {
+		proofs = append(proofs, wlink)
+	}
+	return proofs, false
+}
+
+// proveNSEC3 return the NSEC3 records that prove the non-existence of name
+// using the closest encloser proof, as described in RFC 5155 section
+// 7.2.1.
+// If the name exist, or its an empty non-terminal, it will return true.
+func (zone *Zone) proveNSEC3(name string) (proofs []*zoneChain, exist bool) {
+	var (
+		signer = zone.signer
+		link   *zoneChain
+	)
+
+	var find = func(name string) (match, cover *zoneChain) {
+		var (
+			hash = strings.ToLower(nsec3Encoding.EncodeToString(
+				nsec3Hash(name, signer.NSEC3Salt, signer.NSEC3Iterations)))
+			next string
+		)
+		var l *zoneChain
+		for _, l = range signer.chain {
+			if l.hash == hash {
+				return l, nil
+			}
+			next = strings.ToLower(nsec3Encoding.EncodeToString(
+				l.rr.Value.(*RDataNSEC3).NextHashedOwner))
+			if isHashCover(l.hash, next, hash) {
+				cover = l
+			}
+		}
+		return nil, cover
+	}
+
+	var match, cover = find(name)
+	if match != nil {
+		return []*zoneChain{match}, true
+	}
+
+	var (
+		origin     = dnssecName(zone.Origin)
+		nextCloser = name
+		encloser   string
+		ok         bool
+	)
+	for {
+		encloser, ok = dnssecParent(nextCloser)
+		if !ok || !dnssecIsSubdomain(encloser, origin) {
+			return nil, false
+		}
+		match, _ = find(encloser)
+		if match != nil {
+			break
+		}
+		nextCloser = encloser
+	}
+	proofs = append(proofs, match)
+
+	if nextCloser != name {
+		_, cover = find(nextCloser)
+	}
+	if cover != nil {
+		proofs = append(proofs, cover)
+	}
+
+	var wildcard = `*.` + encloser
+	if len(encloser) == 0 {
+		wildcard = `*`
+	}
+	_, link = find(wildcard)
+	if link != nil && !slices.Contains(proofs, link) {
+		proofs = append(proofs, link)
+	}
+	return proofs, false
+}
+
+// closestEncloser return the longest existing ancestor of name in the
+// zone.
+func (zone *Zone) closestEncloser(name string) string {
+	var (
+		origin = dnssecName(zone.Origin)
+		link   *zoneChain
+		ok     bool
+	)
+	for {
+		name, ok = dnssecParent(name)
+		if !ok || name == origin {
+			return origin
+		}
+		for _, link = range zone.signer.chain {
+			if dnssecIsSubdomain(link.name, name) {
+				return name
+			}
+		}
+	}
+}
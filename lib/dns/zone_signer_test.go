@@ -0,0 +1,280 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+// testZoneClient is the stand-in name server that answer the query from
+// the Caches.
+type testZoneClient struct {
+	caches *Caches
+	UDPClient
+}
+
+func (cl *testZoneClient) Query(req *Message) (res *Message, err error) {
+	var msg = &Message{
+		Header:   req.Header,
+		Question: req.Question,
+	}
+	msg.Question.Class = RecordClassIN
+
+	_, err = msg.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	var an = cl.caches.query(msg)
+	if an == nil {
+		msg.SetResponseCode(RCodeErrName)
+		return msg, nil
+	}
+	return UnpackMessage(an.Message.packet)
+}
+
+func newTestZoneSigner() *ZoneSigner {
+	var seed = bytes.Repeat([]byte{'s'}, ed25519.SeedSize)
+	return &ZoneSigner{
+		Key: ed25519.NewKeyFromSeed(seed),
+	}
+}
+
+func TestZone_SetSigner(t *testing.T) {
+	var (
+		tdata *test.Data
+		err   error
+	)
+
+	tdata, err = test.LoadData(`testdata/Zone_SetSigner_test.txt`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var listCase = []struct {
+		tag      string
+		useNSEC3 bool
+	}{{
+		tag: `NSEC`,
+	}, {
+		tag:      `NSEC3`,
+		useNSEC3: true,
+	}}
+
+	var (
+		zone   *Zone
+		signer *ZoneSigner
+		out    bytes.Buffer
+	)
+	for _, c := range listCase {
+		zone, err = ParseZone(tdata.Input[`zone`], `example.com`, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signer = newTestZoneSigner()
+		signer.UseNSEC3 = c.useNSEC3
+
+		err = zone.SetSigner(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out.Reset()
+		_, err = zone.WriteTo(&out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.Assert(t, c.tag, string(tdata.Output[c.tag]), out.String())
+
+		// Signing the zone again should produce the same records.
+		err = zone.SetSigner(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out.Reset()
+		_, _ = zone.WriteTo(&out)
+		test.Assert(t, c.tag+`: re-sign`, string(tdata.Output[c.tag]), out.String())
+	}
+
+	var rrDS *ResourceRecord
+
+	rrDS, err = zone.DS(DSDigestSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	_, _ = rrDS.Value.(*RDataDS).WriteTo(&out)
+	test.Assert(t, `DS`, string(tdata.Output[`DS`]), out.String())
+
+	// Removing the signer should revert the zone to its original
+	// records.
+	err = zone.SetSigner(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = zone.DS(DSDigestSHA256)
+	test.Assert(t, `DS: unsigned`, `DS: zone example.com. is not signed`, err.Error())
+}
+
+func TestZone_SetSigner_validate(t *testing.T) {
+	type testCase struct {
+		desc     string
+		qname    string
+		qtype    RecordType
+		expRCode ResponseCode
+	}
+
+	var listCase = []testCase{{
+		desc:     `With positive answer`,
+		qname:    `www.example.com`,
+		qtype:    RecordTypeA,
+		expRCode: RCodeOK,
+	}, {
+		desc:     `With NXDOMAIN`,
+		qname:    `nope.example.com`,
+		qtype:    RecordTypeA,
+		expRCode: RCodeErrName,
+	}, {
+		desc:     `With NODATA`,
+		qname:    `www.example.com`,
+		qtype:    RecordTypeAAAA,
+		expRCode: RCodeOK,
+	}, {
+		desc:     `With empty non-terminal`,
+		qname:    `b.example.com`,
+		qtype:    RecordTypeA,
+		expRCode: RCodeOK,
+	}, {
+		desc:     `With added record`,
+		qname:    `new.example.com`,
+		qtype:    RecordTypeA,
+		expRCode: RCodeOK,
+	}, {
+		desc:     `With record added after populated into caches`,
+		qname:    `added.example.com`,
+		qtype:    RecordTypeA,
+		expRCode: RCodeOK,
+	}}
+
+	var (
+		tdata *test.Data
+		err   error
+	)
+
+	tdata, err = test.LoadData(`testdata/Zone_SetSigner_test.txt`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, useNSEC3 := range []bool{false, true} {
+		var (
+			zone   *Zone
+			signer = newTestZoneSigner()
+		)
+
+		zone, err = ParseZone(tdata.Input[`zone`], `example.com`, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zone.Path = t.TempDir() + `/example.com`
+
+		signer.UseNSEC3 = useNSEC3
+		err = zone.SetSigner(signer)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var sigWWW = signer.sigs[`www.example.com 1`].rrsig
+
+		err = zone.Add(&ResourceRecord{
+			Name:  `new.example.com.`,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+			TTL:   300,
+			Value: `10.0.0.3`,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The RRSIG of unchanged RRset should be reused.
+		test.Assert(t, `reuse RRSIG`, true,
+			sigWWW == signer.sigs[`www.example.com 1`].rrsig)
+
+		var (
+			caches Caches
+			ds     *ResourceRecord
+		)
+		caches.init(time.Hour, -time.Hour, 0)
+		caches.InternalPopulateZone(zone)
+
+		// The record added after the zone populated into caches is
+		// served along with the new signatures and NSEC or NSEC3.
+		err = zone.Add(&ResourceRecord{
+			Name:  `added.example.com.`,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+			TTL:   300,
+			Value: `10.0.0.4`,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var _, isPublished = caches.internal[`added.example.com`]
+		test.Assert(t, `published into caches`, true, isPublished)
+
+		ds, err = zone.DS(DSDigestSHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var (
+			cl  = &testZoneClient{caches: &caches}
+			val = newValidator(map[string][]*RDataDS{
+				`example.com`: {ds.Value.(*RDataDS)},
+			})
+		)
+
+		var (
+			c        testCase
+			res      *Message
+			isSecure bool
+			x        int
+		)
+		for x = range 2 {
+			if x == 1 {
+				// Force the signatures to be refreshed and
+				// validate the answers again.
+				signer.refreshAt = 0
+				caches.refreshSignatures()
+				test.Assert(t, `refreshAt`, true, signer.refreshAt > 0)
+			}
+			for _, c = range listCase {
+				res, err = cl.Query(&Message{
+					Question: MessageQuestion{
+						Name: c.qname,
+						Type: c.qtype,
+					},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				test.Assert(t, c.desc+`: RCode`, c.expRCode, res.Header.RCode)
+
+				isSecure, err = val.validate(cl, res)
+				if err != nil {
+					t.Fatalf(`%s: NSEC3=%t: %s`, c.desc, useNSEC3, err)
+				}
+				test.Assert(t, c.desc+`: isSecure`, true, isSecure)
+			}
+		}
+	}
+}
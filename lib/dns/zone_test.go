@@ -7,6 +7,7 @@ package dns
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"strconv"
 	"testing"
 
@@ -164,6 +165,169 @@ func TestParseZone_SVCB(t *testing.T) {
 	}
 }
 
+func TestParseZone_DNSSEC(t *testing.T) {
+	var (
+		logp = `TestParseZone_DNSSEC`
+
+		tdata *test.Data
+		err   error
+	)
+
+	tdata, err = test.LoadData(`testdata/ParseZone_DNSSEC_test.txt`)
+	if err != nil {
+		t.Fatal(logp, err)
+	}
+
+	var listCase = []string{
+		`DNSKEY`,
+		`DS`,
+		`RRSIG`,
+		`NSEC`,
+		`NSEC3`,
+		`NSEC3PARAM`,
+		`FailureMode:MissingParenthesis`,
+	}
+
+	var (
+		origin        = `example.com`
+		ttl    uint32 = 60
+
+		name   string
+		stream []byte
+		zone   *Zone
+		out    bytes.Buffer
+
+		tag    string
+		msg    *Message
+		gotMsg *Message
+		x      int
+	)
+
+	for _, name = range listCase {
+		stream = tdata.Input[name]
+		if len(stream) == 0 {
+			t.Fatalf(`%s: %s: empty input`, logp, name)
+		}
+
+		zone, err = ParseZone(stream, origin, ttl)
+		if err != nil {
+			tag = name + `:error`
+			test.Assert(t, tag, string(tdata.Output[tag]), err.Error())
+			continue
+		}
+
+		out.Reset()
+
+		_, _ = zone.WriteTo(&out)
+		stream = tdata.Output[name]
+		test.Assert(t, name, string(stream), out.String())
+
+		for x, msg = range zone.messages {
+			out.Reset()
+			hexdump.PrettyPrint(&out, msg.Question.String(), msg.packet)
+
+			tag = fmt.Sprintf(`%s:message_%d.hex`, name, x)
+			stream = tdata.Output[tag]
+			test.Assert(t, tag, string(stream), out.String())
+
+			// Make sure the packed message can be unpacked back.
+			gotMsg, err = UnpackMessage(msg.packet)
+			if err != nil {
+				t.Fatalf(`%s: %s: %s`, logp, tag, err)
+			}
+			var (
+				exp = msg.Answer[0].Value.(io.WriterTo)
+				got = gotMsg.Answer[0].Value.(io.WriterTo)
+
+				expOut bytes.Buffer
+				gotOut bytes.Buffer
+			)
+			_, _ = exp.WriteTo(&expOut)
+			_, _ = got.WriteTo(&gotOut)
+			test.Assert(t, tag+`:unpack`, expOut.String(), gotOut.String())
+		}
+	}
+}
+
+func TestParseZone_RData(t *testing.T) {
+	var (
+		logp = `TestParseZone_RData`
+
+		tdata *test.Data
+		err   error
+	)
+
+	tdata, err = test.LoadData(`testdata/ParseZone_RData_test.txt`)
+	if err != nil {
+		t.Fatal(logp, err)
+	}
+
+	var listCase = []string{
+		`CAA`,
+		`TLSA`,
+		`SSHFP`,
+		`NAPTR`,
+		`URI`,
+		`DNAME`,
+		`FailureMode:InvalidFields`,
+	}
+
+	var (
+		origin        = `example.com`
+		ttl    uint32 = 60
+
+		name   string
+		stream []byte
+		zone   *Zone
+		out    bytes.Buffer
+
+		tag    string
+		msg    *Message
+		gotMsg *Message
+		x      int
+		y      int
+	)
+
+	for _, name = range listCase {
+		stream = tdata.Input[name]
+		if len(stream) == 0 {
+			t.Fatalf(`%s: %s: empty input`, logp, name)
+		}
+
+		zone, err = ParseZone(stream, origin, ttl)
+		if err != nil {
+			tag = name + `:error`
+			test.Assert(t, tag, string(tdata.Output[tag]), err.Error())
+			continue
+		}
+
+		out.Reset()
+
+		_, _ = zone.WriteTo(&out)
+		stream = tdata.Output[name]
+		test.Assert(t, name, string(stream), out.String())
+
+		for x, msg = range zone.messages {
+			if msg.Question.Type == RecordTypeSOA {
+				continue
+			}
+
+			// Make sure the packed message can be unpacked back.
+			gotMsg, err = UnpackMessage(msg.packet)
+			if err != nil {
+				t.Fatalf(`%s: %s: %d: %s`, logp, name, x, err)
+			}
+			test.Assert(t, name+`:unpack:len`, len(msg.Answer), len(gotMsg.Answer))
+
+			for y = range gotMsg.Answer {
+				toZoneRecord(&gotMsg.Answer[y])
+				test.Assert(t, name+`:unpack`, msg.Answer[y].Value,
+					gotMsg.Answer[y].Value)
+			}
+		}
+	}
+}
+
 func TestZoneParseDirectiveOrigin(t *testing.T) {
 	type testCase struct {
 		desc   string
@@ -0,0 +1,275 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxZoneJournal define the maximum number of changes stored in the zone
+// journal.
+// The IXFR request with serial older than the first journal will be
+// answered with full zone transfer.
+const maxZoneJournal = 256
+
+// maxTransferMessageSize define the size of message, in bytes, where the
+// records in zone transfer is splitted into next message.
+const maxTransferMessageSize = 16384
+
+// zoneJournal contains the changes on zone from one serial to another.
+type zoneJournal struct {
+	removed []ResourceRecord
+	added   []ResourceRecord
+
+	fromSerial uint32
+	toSerial   uint32
+}
+
+// journalAdd record the changes from serial into the current serial.
+// The prevSigs contains the DNSSEC records before the changes, so the
+// records that are removed and added by re-signing the zone also recorded.
+func (zone *Zone) journalAdd(fromSerial uint32, prevSigs map[string]*ResourceRecord, removed, added []ResourceRecord) {
+	if fromSerial == zone.SOA.Serial {
+		return
+	}
+
+	var sigRemoved, sigAdded = zone.dnssecChanges(prevSigs)
+
+	var journal = &zoneJournal{
+		fromSerial: fromSerial,
+		toSerial:   zone.SOA.Serial,
+		removed:    append(removed, sigRemoved...),
+		added:      append(added, sigAdded...),
+	}
+	if len(zone.journal) >= maxZoneJournal {
+		copy(zone.journal, zone.journal[1:])
+		zone.journal = zone.journal[:len(zone.journal)-1]
+	}
+	zone.journal = append(zone.journal, journal)
+}
+
+// dnssecRecords return all of the DNSSEC records in the zone indexed by
+// its owner, type, and RDATA.
+// It is used to record the changes of re-signing the zone in journal.
+func (zone *Zone) dnssecRecords() (list map[string]*ResourceRecord) {
+	if zone.signer == nil {
+		return nil
+	}
+
+	list = make(map[string]*ResourceRecord)
+
+	var (
+		listRR []*ResourceRecord
+		rr     *ResourceRecord
+	)
+	for _, listRR = range zone.Records {
+		for _, rr = range listRR {
+			if zone.isDNSSECRecord(rr) {
+				list[recordKey(rr)] = rr
+			}
+		}
+	}
+	return list
+}
+
+// dnssecChanges return the DNSSEC records that has been removed and added
+// since the previous state.
+func (zone *Zone) dnssecChanges(prev map[string]*ResourceRecord) (removed, added []ResourceRecord) {
+	var (
+		next = zone.dnssecRecords()
+
+		key string
+		rr  *ResourceRecord
+		ok  bool
+	)
+	for key, rr = range prev {
+		_, ok = next[key]
+		if !ok {
+			removed = append(removed, *rr)
+		}
+	}
+	for key, rr = range next {
+		_, ok = prev[key]
+		if !ok {
+			added = append(added, *rr)
+		}
+	}
+	sortRecords(removed)
+	sortRecords(added)
+	return removed, added
+}
+
+// recordKey return the unique key of rr based on its owner, type, and
+// RDATA.
+func recordKey(rr *ResourceRecord) string {
+	return fmt.Sprintf(`%s %d %x`, strings.ToLower(rr.Name), rr.Type, canonicalRData(rr))
+}
+
+// sortRecords sort the list of RR by its name and type, so the changes in
+// the journal are in stable order.
+func sortRecords(list []ResourceRecord) {
+	sort.SliceStable(list, func(x, y int) bool {
+		var c = dnssecCompareName(list[x].Name, list[y].Name)
+		if c != 0 {
+			return c < 0
+		}
+		return list[x].Type < list[y].Type
+	})
+}
+
+// soaRecordAt return the copy of zone SOA record with specific serial.
+func (zone *Zone) soaRecordAt(serial uint32) ResourceRecord {
+	var (
+		rr  = *zone.soaRecord()
+		soa = *zone.SOA
+	)
+	soa.Serial = serial
+	rr.Value = &soa
+	return rr
+}
+
+// transferRecords return all of records in the zone for AXFR, start and
+// end with the zone SOA, as described in RFC 5936 section 2.2.
+func (zone *Zone) transferRecords() (list []ResourceRecord) {
+	var (
+		soa   = zone.soaRecordAt(zone.SOA.Serial)
+		names = make([]string, 0, len(zone.Records))
+
+		listRR []*ResourceRecord
+		rr     *ResourceRecord
+		name   string
+	)
+
+	list = append(list, soa)
+
+	for name = range zone.Records {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(x, y int) bool {
+		return dnssecCompareName(names[x], names[y]) < 0
+	})
+
+	for _, name = range names {
+		listRR = zone.Records[name]
+		for _, rr = range listRR {
+			if rr.Type == RecordTypeSOA {
+				continue
+			}
+			list = append(list, *rr)
+		}
+	}
+
+	list = append(list, soa)
+
+	return list
+}
+
+// incrementalRecords return the records for IXFR from the serial to the
+// current serial, as described in RFC 1995 section 4.
+// It will return false if the serial is not found in the journal.
+func (zone *Zone) incrementalRecords(serial uint32) (list []ResourceRecord, ok bool) {
+	var soa = zone.soaRecordAt(zone.SOA.Serial)
+
+	if serial == zone.SOA.Serial {
+		// The client already up to date.
+		return []ResourceRecord{soa}, true
+	}
+
+	var (
+		journal *zoneJournal
+		x       int
+	)
+	for x, journal = range zone.journal {
+		if journal.fromSerial == serial {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+
+	list = append(list, soa)
+	for _, journal = range zone.journal[x:] {
+		list = append(list, zone.soaRecordAt(journal.fromSerial))
+		list = append(list, journal.removed...)
+		list = append(list, zone.soaRecordAt(journal.toSerial))
+		list = append(list, journal.added...)
+	}
+	list = append(list, soa)
+
+	return list, true
+}
+
+// transferMessages split the list of records into one or more messages
+// as the answer of zone transfer request.
+func transferMessages(req *Message, list []ResourceRecord) (msgs []*Message, err error) {
+	var (
+		msg *Message
+		x   int
+	)
+
+	for x = range list {
+		if msg == nil {
+			msg = &Message{
+				Header: MessageHeader{
+					ID:      req.Header.ID,
+					Op:      req.Header.Op,
+					IsAA:    true,
+					QDCount: 1,
+				},
+				Question: req.Question,
+			}
+		}
+		msg.Answer = append(msg.Answer, list[x])
+
+		_, err = msg.Pack()
+		if err != nil {
+			return nil, err
+		}
+		if len(msg.packet) < maxTransferMessageSize {
+			continue
+		}
+		msgs = append(msgs, msg)
+		msg = nil
+	}
+	if msg != nil {
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+// toZoneRecord convert the owner and domain names in RDATA of rr, that
+// is received from network, into absolute domain names, so it can be
+// stored and saved in the Zone.
+func toZoneRecord(rr *ResourceRecord) {
+	rr.Name = toDomainAbsolute(rr.Name)
+
+	switch v := rr.Value.(type) {
+	case string:
+		switch rr.Type {
+		case RecordTypeNS, RecordTypeCNAME, RecordTypeMB, RecordTypeMG,
+			RecordTypeMR, RecordTypePTR, RecordTypeDNAME:
+			rr.Value = toDomainAbsolute(v)
+		}
+	case *RDataSOA:
+		v.MName = toDomainAbsolute(v.MName)
+		v.RName = toDomainAbsolute(v.RName)
+	case *RDataMX:
+		v.Exchange = toDomainAbsolute(v.Exchange)
+	case *RDataMINFO:
+		v.RMailBox = toDomainAbsolute(v.RMailBox)
+		v.EmailBox = toDomainAbsolute(v.EmailBox)
+	case *RDataSRV:
+		v.Target = toDomainAbsolute(v.Target)
+	case *RDataNAPTR:
+		v.Replacement = toDomainAbsolute(v.Replacement)
+	case *RDataRRSIG:
+		v.SignerName = toDomainAbsolute(v.SignerName)
+	case *RDataNSEC:
+		v.NextDomain = toDomainAbsolute(v.NextDomain)
+	}
+}
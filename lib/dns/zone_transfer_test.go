@@ -0,0 +1,236 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+// dumpRecords write the list of records in zone format, one record per
+// line, with the SOA printed only with its serial.
+func dumpRecords(t *testing.T, zone *Zone, list []ResourceRecord) string {
+	var (
+		out bytes.Buffer
+		x   int
+		err error
+	)
+	for x = range list {
+		if list[x].Type == RecordTypeSOA {
+			var soa = list[x].Value.(*RDataSOA)
+			fmt.Fprintf(&out, "%s SOA %d\n", list[x].Name, soa.Serial)
+			continue
+		}
+		_, err = zone.saveListRR(&out, list[x].Name,
+			[]*ResourceRecord{&list[x]})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return out.String()
+}
+
+func TestZone_incrementalRecords(t *testing.T) {
+	var (
+		tdata *test.Data
+		zone  *Zone
+		list  []ResourceRecord
+		ok    bool
+		err   error
+	)
+
+	tdata, err = test.LoadData(`testdata/Zone_transfer_test.txt`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zone, err = ParseZone(tdata.Input[`zone`], `example.com`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zone.Path = t.TempDir() + `/example.com`
+
+	var got = dumpRecords(t, zone, zone.transferRecords())
+	test.Assert(t, `AXFR`, string(tdata.Output[`AXFR`]), got)
+
+	err = zone.Add(&ResourceRecord{
+		Name:  `new.example.com.`,
+		Type:  RecordTypeA,
+		Class: RecordClassIN,
+		TTL:   300,
+		Value: `10.0.0.3`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var serial = zone.SOA.Serial
+
+	err = zone.Remove(&ResourceRecord{
+		Name:  `www.example.com.`,
+		Type:  RecordTypeA,
+		Class: RecordClassIN,
+		TTL:   300,
+		Value: `10.0.0.2`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, ok = zone.incrementalRecords(1)
+	test.Assert(t, `IXFR: ok`, true, ok)
+	test.Assert(t, `IXFR`, string(tdata.Output[`IXFR`]),
+		dumpRecords(t, zone, list))
+
+	list, ok = zone.incrementalRecords(serial)
+	test.Assert(t, `IXFR:partial: ok`, true, ok)
+	test.Assert(t, `IXFR:partial`, string(tdata.Output[`IXFR:partial`]),
+		dumpRecords(t, zone, list))
+
+	list, ok = zone.incrementalRecords(zone.SOA.Serial)
+	test.Assert(t, `IXFR: up to date`, true, ok)
+	test.Assert(t, `IXFR: up to date: len`, 1, len(list))
+
+	_, ok = zone.incrementalRecords(2)
+	test.Assert(t, `IXFR: unknown serial`, false, ok)
+}
+
+func TestZone_incrementalRecords_signed(t *testing.T) {
+	var (
+		tdata *test.Data
+		zone  *Zone
+		err   error
+	)
+
+	tdata, err = test.LoadData(`testdata/Zone_transfer_test.txt`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zone, err = ParseZone(tdata.Input[`zone`], `example.com`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = zone.SetSigner(newTestZoneSigner())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var serial = zone.SOA.Serial
+
+	err = zone.Add(&ResourceRecord{
+		Name:  `new.example.com.`,
+		Type:  RecordTypeA,
+		Class: RecordClassIN,
+		TTL:   300,
+		Value: `10.0.0.3`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var list, ok = zone.incrementalRecords(serial)
+	test.Assert(t, `ok`, true, ok)
+
+	// The changes should contains the new record, its RRSIG, and the
+	// NSEC chain that cover it.
+	var (
+		added = map[string]bool{}
+		x     int
+	)
+	for x = range zone.journal[0].added {
+		var rr = &zone.journal[0].added[x]
+		added[rr.Name+` `+RecordTypeNames[rr.Type]] = true
+	}
+	test.Assert(t, `added A`, true, added[`new.example.com. A`])
+	test.Assert(t, `added RRSIG`, true, added[`new.example.com. RRSIG`])
+	test.Assert(t, `added NSEC`, true, added[`new.example.com. NSEC`])
+	test.Assert(t, `removed`, true, len(zone.journal[0].removed) > 0)
+
+	// First and last record must be the current SOA.
+	test.Assert(t, `first SOA`, zone.SOA.Serial,
+		list[0].Value.(*RDataSOA).Serial)
+	test.Assert(t, `last SOA`, zone.SOA.Serial,
+		list[len(list)-1].Value.(*RDataSOA).Serial)
+}
+
+func TestServer_serveTransfer_udp(t *testing.T) {
+	var (
+		tdata *test.Data
+		zone  *Zone
+		err   error
+	)
+
+	tdata, err = test.LoadData(`testdata/Zone_transfer_test.txt`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zone, err = ParseZone(tdata.Input[`zone`], `udp.transfer.test`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rrSOA = *zone.soaRecord()
+
+	_testServer.Caches.InternalPopulateZone(zone)
+
+	var cl *UDPClient
+
+	cl, err = NewUDPClient(testServerAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Close()
+
+	var listCase = []struct {
+		desc     string
+		qtype    RecordType
+		expRCode ResponseCode
+		expCount int
+	}{{
+		desc:     `With AXFR`,
+		qtype:    RecordTypeAXFR,
+		expRCode: RCodeRefused,
+	}, {
+		desc:     `With IXFR`,
+		qtype:    RecordTypeIXFR,
+		expRCode: RCodeOK,
+		expCount: 1,
+	}}
+
+	var (
+		req *Message
+		res *Message
+	)
+	for _, c := range listCase {
+		req = &Message{
+			Header: MessageHeader{
+				ID:      getNextID(),
+				QDCount: 1,
+			},
+			Question: MessageQuestion{
+				Name:  zone.Origin,
+				Type:  c.qtype,
+				Class: RecordClassIN,
+			},
+			Authority: []ResourceRecord{rrSOA},
+		}
+		_, err = req.Pack()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err = cl.Query(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.Assert(t, c.desc+`: RCode`, c.expRCode, res.Header.RCode)
+		test.Assert(t, c.desc+`: answer`, c.expCount, len(res.Answer))
+	}
+}
@@ -0,0 +1,469 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/reflect"
+)
+
+// SetTSIGKeys set the list of TSIG keys that are allowed to update the
+// zone using dynamic update (RFC 2136).
+// If keys is empty, all dynamic update requests for the zone will be
+// refused.
+func (zone *Zone) SetTSIGKeys(keys ...*TSIGKey) (err error) {
+	var (
+		logp = `SetTSIGKeys`
+		list = make(map[string]*TSIGKey, len(keys))
+
+		key *TSIGKey
+	)
+	for _, key = range keys {
+		err = key.init()
+		if err != nil {
+			return fmt.Errorf(`%s: %w`, logp, err)
+		}
+		list[key.Name] = key
+	}
+	zone.tsigKeys = list
+	return nil
+}
+
+// tsigKey return the TSIG key for update by its name.
+func (zone *Zone) tsigKey(name string) *TSIGKey {
+	return zone.tsigKeys[strings.ToLower(strings.TrimSuffix(name, `.`))]
+}
+
+// update apply the prerequisites in the Answer section and the updates in
+// the Authority section of UPDATE message msg, as described in RFC 2136
+// section 3.2 until 3.4.
+// All of the prerequisites and updates are checked first before applying
+// the changes, so either all of updates are applied or none of them.
+// If one of the update, signing, or saving the zone failed, all of the
+// applied changes are rolled back.
+// On success the zone is saved into its Path, if its not empty.
+func (zone *Zone) update(msg *Message) (rcode ResponseCode, err error) {
+	var logp = `update`
+
+	rcode = zone.updateCheckPrereq(msg.Answer)
+	if rcode != RCodeOK {
+		return rcode, nil
+	}
+	rcode = zone.updatePrescan(msg.Authority)
+	if rcode != RCodeOK {
+		return rcode, nil
+	}
+
+	var (
+		soa      = *zone.SOA
+		records  = zone.cloneRecords()
+		prevSigs = zone.dnssecRecords()
+
+		removed []ResourceRecord
+		added   []ResourceRecord
+		list    []ResourceRecord
+		rr      ResourceRecord
+		isSOA   bool
+		ok      bool
+	)
+
+	for _, rr = range msg.Authority {
+		toZoneRecord(&rr)
+
+		switch rr.Class {
+		case RecordClassANY:
+			list = zone.updateDelete(rr.Name, rr.Type)
+			removed = append(removed, list...)
+
+		case RecordClassNONE:
+			rr.Class = RecordClassIN
+			rr, ok = zone.updateRemove(&rr)
+			if ok {
+				removed = append(removed, rr)
+			}
+
+		default:
+			if rr.Type == RecordTypeSOA {
+				ok, err = zone.updateSOA(&rr)
+				if err != nil {
+					break
+				}
+				if ok {
+					isSOA = true
+				}
+				continue
+			}
+			list, err = zone.updateAdd(&rr)
+			if err != nil || len(list) == 0 {
+				break
+			}
+			// The CNAME replace the existing CNAME.
+			removed = append(removed, list[1:]...)
+			added = append(added, list[0])
+		}
+		if err != nil {
+			zone.updateRollback(soa, records, removed, added)
+			return RCodeErrServer, fmt.Errorf(`%s: %w`, logp, err)
+		}
+	}
+
+	if len(removed) == 0 && len(added) == 0 && !isSOA {
+		return RCodeOK, nil
+	}
+
+	if !isSOA {
+		zone.onUpdate()
+	}
+	err = zone.updateMessages()
+	if err == nil && len(zone.Path) != 0 {
+		err = zone.Save()
+	}
+	if err != nil {
+		zone.updateRollback(soa, records, removed, added)
+		return RCodeErrServer, fmt.Errorf(`%s: %w`, logp, err)
+	}
+	zone.journalAdd(soa.Serial, prevSigs, removed, added)
+	return RCodeOK, nil
+}
+
+// updateMessages re-sign the zone, or refresh the SOA and pack the
+// pre-generated messages if the zone is not signed, after the records in
+// zone has been changed.
+func (zone *Zone) updateMessages() (err error) {
+	if zone.signer != nil {
+		return zone.sign()
+	}
+	zone.refreshSOA()
+	zone.packMessages()
+	return nil
+}
+
+// cloneRecords return the copy of zone Records.
+func (zone *Zone) cloneRecords() (records map[string][]*ResourceRecord) {
+	var (
+		name   string
+		listRR []*ResourceRecord
+	)
+	records = make(map[string][]*ResourceRecord, len(zone.Records))
+	for name, listRR = range zone.Records {
+		records[name] = slices.Clone(listRR)
+	}
+	return records
+}
+
+// updateRollback revert the records that has been removed and added by
+// update, restore the Records and SOA, and rebuild the messages of zone.
+func (zone *Zone) updateRollback(soa RDataSOA, records map[string][]*ResourceRecord, removed, added []ResourceRecord) {
+	var x int
+	for x = len(added) - 1; x >= 0; x-- {
+		zone.messageRemove(&added[x])
+	}
+	for x = range removed {
+		var rr = removed[x]
+		var err = zone.add(&rr)
+		if err != nil {
+			log.Printf(`dns: update: rollback %s: %s`, rr.String(), err)
+		}
+	}
+	// Restore the records to keep their original order.
+	zone.Records = records
+	zone.SOA = &soa
+
+	var err = zone.updateMessages()
+	if err != nil {
+		log.Printf(`dns: update: rollback %s: %s`, zone.Origin, err)
+	}
+}
+
+// isInZone return true if the name is the zone origin or its sub domain.
+func (zone *Zone) isInZone(name string) bool {
+	name = strings.ToLower(toDomainAbsolute(name))
+	return name == zone.Origin || strings.HasSuffix(name, `.`+zone.Origin)
+}
+
+// rrset return the list of records in zone by name and type.
+func (zone *Zone) rrset(name string, rtype RecordType) (list []*ResourceRecord) {
+	var rr *ResourceRecord
+	if rtype == RecordTypeSOA && name == zone.Origin {
+		return []*ResourceRecord{zone.soaRecord()}
+	}
+	for _, rr = range zone.Records[name] {
+		if rr.Type == rtype || rtype == RecordTypeANY {
+			list = append(list, rr)
+		}
+	}
+	return list
+}
+
+// updateCheckPrereq check the prerequisites of update, as described in
+// RFC 2136 section 3.2.
+func (zone *Zone) updateCheckPrereq(prereqs []ResourceRecord) ResponseCode {
+	var (
+		// sets contains the RRset that must be exist with the same
+		// values, indexed by name and type.
+		sets = map[string][]ResourceRecord{}
+
+		rr  ResourceRecord
+		key string
+	)
+
+	for _, rr = range prereqs {
+		if rr.TTL != 0 {
+			return RCodeErrFormat
+		}
+		if !zone.isInZone(rr.Name) {
+			return RCodeNotZone
+		}
+		toZoneRecord(&rr)
+
+		switch rr.Class {
+		case RecordClassANY:
+			if rr.Value != nil {
+				return RCodeErrFormat
+			}
+			if len(zone.rrset(rr.Name, rr.Type)) != 0 {
+				continue
+			}
+			if rr.Type == RecordTypeANY {
+				return RCodeErrName
+			}
+			return RCodeNXRRSet
+
+		case RecordClassNONE:
+			if rr.Value != nil {
+				return RCodeErrFormat
+			}
+			if len(zone.rrset(rr.Name, rr.Type)) == 0 {
+				continue
+			}
+			if rr.Type == RecordTypeANY {
+				return RCodeYXDomain
+			}
+			return RCodeYXRRSet
+
+		case RecordClassIN:
+			key = fmt.Sprintf(`%s %d`, rr.Name, rr.Type)
+			sets[key] = append(sets[key], rr)
+
+		default:
+			return RCodeErrFormat
+		}
+	}
+
+	var list []ResourceRecord
+	for _, list = range sets {
+		if !zone.isRRSetEqual(list) {
+			return RCodeNXRRSet
+		}
+	}
+	return RCodeOK
+}
+
+// isRRSetEqual return true if the RRset in zone has the same values with
+// the list.
+func (zone *Zone) isRRSetEqual(list []ResourceRecord) bool {
+	var (
+		rrset = zone.rrset(list[0].Name, list[0].Type)
+
+		rr    *ResourceRecord
+		x     int
+		found bool
+	)
+
+	for _, rr = range rrset {
+		found = false
+		for x = range list {
+			if reflect.IsEqual(rr.Value, list[x].Value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for x = range list {
+		found = false
+		for _, rr = range rrset {
+			if reflect.IsEqual(rr.Value, list[x].Value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// updatePrescan check the records in update section, as described in
+// RFC 2136 section 3.4.1.
+func (zone *Zone) updatePrescan(updates []ResourceRecord) ResponseCode {
+	var rr ResourceRecord
+
+	for _, rr = range updates {
+		if !zone.isInZone(rr.Name) {
+			return RCodeNotZone
+		}
+		if rr.Type == RecordTypeAXFR || rr.Type == RecordTypeIXFR ||
+			rr.Type == RecordTypeMAILA || rr.Type == RecordTypeMAILB {
+			return RCodeErrFormat
+		}
+		if zone.signer != nil && zone.isDNSSECRecord(&rr) {
+			// The DNSSEC records are managed by the signer.
+			return RCodeRefused
+		}
+
+		switch rr.Class {
+		case RecordClassIN:
+			if rr.Type == RecordTypeANY || rr.Value == nil {
+				return RCodeErrFormat
+			}
+			var err = rr.initAndValidate()
+			if err != nil {
+				return RCodeErrFormat
+			}
+
+		case RecordClassANY:
+			if rr.TTL != 0 || rr.Value != nil {
+				return RCodeErrFormat
+			}
+
+		case RecordClassNONE:
+			if rr.TTL != 0 || rr.Type == RecordTypeANY || rr.Value == nil {
+				return RCodeErrFormat
+			}
+
+		default:
+			return RCodeErrFormat
+		}
+	}
+	return RCodeOK
+}
+
+// updateSOA replace the zone SOA with the one in rr, only if its serial
+// is greater than the current serial.
+func (zone *Zone) updateSOA(rr *ResourceRecord) (ok bool, err error) {
+	if rr.Name != zone.Origin {
+		return false, nil
+	}
+	var soa, _ = rr.Value.(*RDataSOA)
+	if soa == nil || soa.Serial <= zone.SOA.Serial {
+		return false, nil
+	}
+	err = zone.add(rr)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// updateAdd add the rr into zone.
+// It will return the added record and the CNAME that is replaced, if any.
+// The record is ignored if its already exist, or if its conflict with
+// existing CNAME.
+func (zone *Zone) updateAdd(rr *ResourceRecord) (list []ResourceRecord, err error) {
+	var (
+		in      *ResourceRecord
+		cname   *ResourceRecord
+		isOther bool
+	)
+	for _, in = range zone.Records[rr.Name] {
+		if in.Type == RecordTypeCNAME {
+			cname = in
+		} else if !zone.isDNSSECRecord(in) {
+			isOther = true
+		}
+		if in.Type == rr.Type && in.Class == rr.Class &&
+			reflect.IsEqual(in.Value, rr.Value) {
+			return nil, nil
+		}
+	}
+
+	if rr.Type == RecordTypeCNAME {
+		if isOther {
+			return nil, nil
+		}
+	} else if cname != nil && !zone.isDNSSECRecord(rr) {
+		return nil, nil
+	}
+
+	var newrr = *rr
+
+	err = zone.add(&newrr)
+	if err != nil {
+		// The record may already inserted before the message
+		// failed to be updated.
+		if zone.recordRemove(&newrr) {
+			zone.messageRemove(&newrr)
+		}
+		return nil, err
+	}
+
+	list = append(list, newrr)
+	if cname != nil && rr.Type == RecordTypeCNAME {
+		var old = *cname
+		zone.recordRemove(&old)
+		zone.messageRemove(&old)
+		list = append(list, old)
+	}
+	return list, nil
+}
+
+// updateDelete delete the RRset by name and type from zone, or all RRsets
+// if the type is ANY.
+// The SOA and NS records at the zone origin are never deleted.
+func (zone *Zone) updateDelete(name string, rtype RecordType) (removed []ResourceRecord) {
+	var (
+		rrset = zone.rrset(name, rtype)
+
+		rr *ResourceRecord
+	)
+	for _, rr = range rrset {
+		if rr.Type == RecordTypeSOA {
+			continue
+		}
+		if name == zone.Origin && rr.Type == RecordTypeNS {
+			continue
+		}
+		if zone.isDNSSECRecord(rr) {
+			continue
+		}
+		var old = *rr
+		if zone.recordRemove(&old) {
+			zone.messageRemove(&old)
+			removed = append(removed, old)
+		}
+	}
+	return removed
+}
+
+// updateRemove remove the specific rr from zone.
+// It will return the removed record in zone, with its original TTL.
+// The SOA and the last NS at the zone origin are never removed.
+func (zone *Zone) updateRemove(rr *ResourceRecord) (old ResourceRecord, ok bool) {
+	if rr.Type == RecordTypeSOA {
+		return old, false
+	}
+	if rr.Name == zone.Origin && rr.Type == RecordTypeNS &&
+		len(zone.rrset(rr.Name, RecordTypeNS)) <= 1 {
+		return old, false
+	}
+	var in *ResourceRecord
+	for _, in = range zone.rrset(rr.Name, rr.Type) {
+		if in.Class != rr.Class || !reflect.IsEqual(in.Value, rr.Value) {
+			continue
+		}
+		old = *in
+		zone.recordRemove(&old)
+		zone.messageRemove(&old)
+		return old, true
+	}
+	return old, false
+}
@@ -0,0 +1,409 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package dns
+
+import (
+	"bytes"
+	"testing"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestZone_update(t *testing.T) {
+	type testCase struct {
+		desc     string
+		prereq   []ResourceRecord
+		updates  []ResourceRecord
+		expRCode ResponseCode
+	}
+
+	var listCase = []testCase{{
+		desc: `With name not in use`,
+		prereq: []ResourceRecord{{
+			Name:  `nope.example.com`,
+			Type:  RecordTypeANY,
+			Class: RecordClassANY,
+		}},
+		expRCode: RCodeErrName,
+	}, {
+		desc: `With name in use`,
+		prereq: []ResourceRecord{{
+			Name:  `www.example.com`,
+			Type:  RecordTypeANY,
+			Class: RecordClassNONE,
+		}},
+		expRCode: RCodeYXDomain,
+	}, {
+		desc: `With RRset exists`,
+		prereq: []ResourceRecord{{
+			Name:  `www.example.com`,
+			Type:  RecordTypeA,
+			Class: RecordClassNONE,
+		}},
+		expRCode: RCodeYXRRSet,
+	}, {
+		desc: `With RRset does not exist`,
+		prereq: []ResourceRecord{{
+			Name:  `www.example.com`,
+			Type:  RecordTypeAAAA,
+			Class: RecordClassANY,
+		}},
+		expRCode: RCodeNXRRSet,
+	}, {
+		desc: `With RRset value does not match`,
+		prereq: []ResourceRecord{{
+			Name:  `www.example.com`,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+			Value: `10.0.0.1`,
+		}},
+		expRCode: RCodeNXRRSet,
+	}, {
+		desc: `With prerequisite TTL`,
+		prereq: []ResourceRecord{{
+			Name:  `www.example.com`,
+			Type:  RecordTypeA,
+			Class: RecordClassANY,
+			TTL:   1,
+		}},
+		expRCode: RCodeErrFormat,
+	}, {
+		desc: `With name outside zone`,
+		updates: []ResourceRecord{{
+			Name:  `www.example.org`,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+			Value: `10.0.0.1`,
+		}},
+		expRCode: RCodeNotZone,
+	}, {
+		desc: `With invalid update`,
+		updates: []ResourceRecord{{
+			Name:  `www.example.com`,
+			Type:  RecordTypeA,
+			Class: RecordClassANY,
+			Value: `10.0.0.1`,
+		}},
+		expRCode: RCodeErrFormat,
+	}, {
+		desc: `With updates`,
+		prereq: []ResourceRecord{{
+			Name:  `www.example.com`,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+			Value: `10.0.0.1`,
+		}, {
+			Name:  `www.example.com`,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+			Value: `10.0.0.2`,
+		}},
+		updates: []ResourceRecord{{
+			Name:  `mail.example.com`,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+			TTL:   600,
+			Value: `10.0.0.25`,
+		}, {
+			Name:  `www.example.com`,
+			Type:  RecordTypeA,
+			Class: RecordClassNONE,
+			Value: `10.0.0.2`,
+		}, {
+			Name:  `www.example.com`,
+			Type:  RecordTypeTXT,
+			Class: RecordClassANY,
+		}, {
+			Name:  `ftp.example.com`,
+			Type:  RecordTypeCNAME,
+			Class: RecordClassIN,
+			TTL:   300,
+			Value: `mail.example.com`,
+		}},
+		expRCode: RCodeOK,
+	}, {
+		desc: `With ignored updates`,
+		updates: []ResourceRecord{{
+			Name:  `example.com`,
+			Type:  RecordTypeNS,
+			Class: RecordClassANY,
+		}, {
+			Name:  `example.com`,
+			Type:  RecordTypeSOA,
+			Class: RecordClassANY,
+		}, {
+			Name:  `ftp.example.com`,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+			TTL:   300,
+			Value: `10.0.0.3`,
+		}, {
+			Name:  `www.example.com`,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+			TTL:   300,
+			Value: `10.0.0.1`,
+		}},
+		expRCode: RCodeOK,
+	}}
+
+	var (
+		tdata *test.Data
+		zone  *Zone
+		msg   *Message
+		rcode ResponseCode
+		out   bytes.Buffer
+		err   error
+	)
+
+	tdata, err = test.LoadData(`testdata/Zone_update_test.txt`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range listCase {
+		zone, err = ParseZone(tdata.Input[`zone`], `example.com`, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zone.Path = t.TempDir() + `/example.com`
+
+		msg = &Message{
+			Header: MessageHeader{
+				Op:      OpCodeUpdate,
+				QDCount: 1,
+			},
+			Question: MessageQuestion{
+				Name:  `example.com`,
+				Type:  RecordTypeSOA,
+				Class: RecordClassIN,
+			},
+			Answer:    c.prereq,
+			Authority: c.updates,
+		}
+
+		// Pack and unpack the message to simulate receiving it from
+		// network.
+		_, err = msg.Pack()
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err = UnpackMessage(msg.packet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rcode, err = zone.update(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.Assert(t, c.desc, c.expRCode, rcode)
+
+		var exp = tdata.Output[c.desc]
+		if exp == nil {
+			continue
+		}
+
+		out.Reset()
+		_, _ = zone.WriteTo(&out)
+		test.Assert(t, c.desc+`: zone`, string(exp), out.String())
+	}
+}
+
+func TestZone_update_rollback(t *testing.T) {
+	var (
+		tdata *test.Data
+		zone  *Zone
+		err   error
+	)
+
+	tdata, err = test.LoadData(`testdata/Zone_update_test.txt`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zone, err = ParseZone(tdata.Input[`zone`], `example.com`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Make the Save failed by using non-existent directory.
+	zone.Path = t.TempDir() + `/notexist/example.com`
+
+	var (
+		before bytes.Buffer
+		after  bytes.Buffer
+		serial = zone.SOA.Serial
+	)
+	_, _ = zone.WriteTo(&before)
+
+	var msg = &Message{
+		Header: MessageHeader{
+			Op:      OpCodeUpdate,
+			QDCount: 1,
+		},
+		Question: MessageQuestion{
+			Name:  `example.com`,
+			Type:  RecordTypeSOA,
+			Class: RecordClassIN,
+		},
+		Authority: []ResourceRecord{{
+			Name:  `mail.example.com`,
+			Type:  RecordTypeA,
+			Class: RecordClassIN,
+			TTL:   600,
+			Value: `10.0.0.25`,
+		}, {
+			Name:  `www.example.com`,
+			Type:  RecordTypeA,
+			Class: RecordClassNONE,
+			Value: `10.0.0.2`,
+		}, {
+			Name:  `ftp.example.com`,
+			Type:  RecordTypeCNAME,
+			Class: RecordClassIN,
+			TTL:   300,
+			Value: `mail.example.com`,
+		}},
+	}
+	_, err = msg.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err = UnpackMessage(msg.packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rcode ResponseCode
+
+	rcode, err = zone.update(msg)
+	if err == nil {
+		t.Fatal(`expecting error on Save`)
+	}
+	test.Assert(t, `rcode`, RCodeErrServer, rcode)
+	test.Assert(t, `SOA serial`, serial, zone.SOA.Serial)
+
+	_, _ = zone.WriteTo(&after)
+	test.Assert(t, `zone`, before.String(), after.String())
+
+	var answer = zone.message(`mail.example.com.`, RecordTypeA)
+	if answer != nil && len(answer.Answer) != 0 {
+		t.Fatalf(`expecting no answer for mail.example.com., got %v`,
+			answer.Answer)
+	}
+	answer = zone.message(`ftp.example.com.`, RecordTypeCNAME)
+	if answer == nil || len(answer.Answer) != 1 {
+		t.Fatal(`expecting one answer for ftp.example.com.`)
+	}
+	test.Assert(t, `ftp CNAME`, `www.example.com.`, answer.Answer[0].Value)
+}
+
+func TestServer_serveUpdate(t *testing.T) {
+	var (
+		key   = newTestTSIGKey(t, `update.key`)
+		other = newTestTSIGKey(t, `other.key`)
+
+		tdata *test.Data
+		zone  *Zone
+		err   error
+	)
+
+	tdata, err = test.LoadData(`testdata/Zone_update_test.txt`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zone, err = ParseZone(tdata.Input[`zone`], `update.test`, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zone.Path = t.TempDir() + `/update.test`
+
+	err = zone.SetTSIGKeys(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_testServer.Caches.InternalPopulateZone(zone)
+
+	var cl *UDPClient
+
+	cl, err = NewUDPClient(testServerAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Close()
+
+	var listCase = []struct {
+		key      *TSIGKey
+		desc     string
+		expError string
+		expRCode ResponseCode
+	}{{
+		desc:     `Without TSIG`,
+		expRCode: RCodeRefused,
+	}, {
+		desc:     `With unknown key`,
+		key:      other,
+		expError: `Query: Verify: TSIG: bad key`,
+		expRCode: RCodeNotAuth,
+	}, {
+		desc:     `With valid key`,
+		key:      key,
+		expRCode: RCodeOK,
+	}}
+
+	var (
+		req *Message
+		res *Message
+	)
+	for _, c := range listCase {
+		req = &Message{
+			Header: MessageHeader{
+				ID:      getNextID(),
+				Op:      OpCodeUpdate,
+				QDCount: 1,
+			},
+			Question: MessageQuestion{
+				Name:  `update.test`,
+				Type:  RecordTypeSOA,
+				Class: RecordClassIN,
+			},
+			Authority: []ResourceRecord{{
+				Name:  `new.update.test`,
+				Type:  RecordTypeA,
+				Class: RecordClassIN,
+				TTL:   300,
+				Value: `10.0.0.3`,
+			}},
+		}
+		_, err = req.Pack()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cl.SetTSIGKey(c.key)
+
+		res, err = cl.Query(req)
+		if err != nil {
+			test.Assert(t, c.desc+`: error`, c.expError, err.Error())
+		}
+		if res == nil {
+			t.Fatalf(`%s: empty response`, c.desc)
+		}
+		test.Assert(t, c.desc+`: RCode`, c.expRCode, res.Header.RCode)
+	}
+
+	cl.SetTSIGKey(nil)
+
+	res, err = cl.Lookup(MessageQuestion{
+		Name:  `new.update.test`,
+		Type:  RecordTypeA,
+		Class: RecordClassIN,
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `Lookup: answer`, 1, len(res.Answer))
+	test.Assert(t, `Lookup: value`, `10.0.0.3`, res.Answer[0].Value)
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package http
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+)
+
+// List of common value for response header Cache-Control.
+const (
+	// CacheControlImmutable for resource that never changes, for
+	// example asset with fingerprint or hash in its file name.
+	CacheControlImmutable = `public, max-age=31536000, immutable`
+
+	// CacheControlNoCache for resource that can be stored by client but
+	// must be revalidated before being used, using the ETag or
+	// Last-Modified.
+	CacheControlNoCache = `no-cache`
+
+	// CacheControlNoStore for resource that must not be stored by
+	// client.
+	CacheControlNoStore = `no-store`
+)
+
+// CacheControl define the policy for setting the response header
+// Cache-Control based on the request path.
+type CacheControl struct {
+	re *regexp.Regexp
+
+	// Path define the regular expression to match with the request
+	// path, after the [ServerOptions.BasePath] has been removed.
+	// For example, `^/assets/.*\.[0-9a-f]{8}\.(css|js)$` to match the
+	// fingerprinted assets.
+	Path string
+
+	// Value of the header Cache-Control, for example
+	// [CacheControlImmutable].
+	Value string
+}
+
+// initCacheControl compile the Path in each CacheControl.
+// The list is cloned so the caller slice is not modified.
+func initCacheControl(list []CacheControl) (out []CacheControl, err error) {
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	out = make([]CacheControl, len(list))
+
+	var x int
+	for x = range len(list) {
+		out[x] = list[x]
+		out[x].re, err = regexp.Compile(list[x].Path)
+		if err != nil {
+			return nil, fmt.Errorf(`invalid CacheControl Path %q: %w`, list[x].Path, err)
+		}
+	}
+	return out, nil
+}
+
+// findCacheControl return the value of Cache-Control for the first
+// policy that match with path.
+// It will return empty string if no policy match.
+func findCacheControl(list []CacheControl, path string) string {
+	var cc CacheControl
+	for _, cc = range list {
+		if cc.re.MatchString(path) {
+			return cc.Value
+		}
+	}
+	return ``
+}
+
+// cacheControlWriter wrap the [http.ResponseWriter] to set the response
+// header Cache-Control once the status code is known.
+// The header is only set for successful response and 304 Not Modified,
+// and only if the handler does not set it.
+type cacheControlWriter struct {
+	http.ResponseWriter
+
+	value string
+
+	isWroteHeader bool
+}
+
+// WriteHeader set the header Cache-Control and write the status code.
+func (ccw *cacheControlWriter) WriteHeader(code int) {
+	if !ccw.isWroteHeader {
+		ccw.isWroteHeader = true
+
+		var header = ccw.Header()
+		if len(header.Get(HeaderCacheControl)) == 0 &&
+			((code >= http.StatusOK && code < http.StatusMultipleChoices) ||
+				code == http.StatusNotModified) {
+			header.Set(HeaderCacheControl, ccw.value)
+		}
+	}
+	ccw.ResponseWriter.WriteHeader(code)
+}
+
+// Write the response body, with implicit status 200 OK if WriteHeader has
+// not been called.
+func (ccw *cacheControlWriter) Write(b []byte) (int, error) {
+	if !ccw.isWroteHeader {
+		ccw.WriteHeader(http.StatusOK)
+	}
+	return ccw.ResponseWriter.Write(b)
+}
+
+// Flush the underlying ResponseWriter.
+func (ccw *cacheControlWriter) Flush() {
+	if !ccw.isWroteHeader {
+		ccw.WriteHeader(http.StatusOK)
+	}
+	var flusher, ok = ccw.ResponseWriter.(http.Flusher)
+	if ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack the underlying connection, if the underlying ResponseWriter
+// support it.
+func (ccw *cacheControlWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	var hijacker, ok = ccw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New(`http.ResponseWriter is not http.Hijacker`)
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap return the underlying ResponseWriter, for
+// [http.ResponseController].
+func (ccw *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return ccw.ResponseWriter
+}
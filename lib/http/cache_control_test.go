@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/memfs"
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestServer_CacheControl(t *testing.T) {
+	var (
+		dir = t.TempDir()
+
+		mfs *memfs.MemFS
+		err error
+	)
+
+	err = os.WriteFile(filepath.Join(dir, `main.0123abcd.js`), []byte(`main`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, `index.html`), []byte(`index`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mfs, err = memfs.New(&memfs.Options{
+		Root: dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		listCacheControl = []CacheControl{{
+			Path:  `\.[0-9a-f]{8}\.js$`,
+			Value: CacheControlImmutable,
+		}, {
+			Path:  `^/api/custom$`,
+			Value: CacheControlNoCache,
+		}, {
+			Path:  `^/`,
+			Value: CacheControlNoCache,
+		}}
+
+		srv *Server
+	)
+
+	srv, err = NewServer(ServerOptions{
+		Memfs:        mfs,
+		CacheControl: listCacheControl,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = srv.RegisterEndpoint(Endpoint{
+		Path:         `/api/custom`,
+		ResponseType: ResponseTypePlain,
+		Call: func(epr *EndpointRequest) ([]byte, error) {
+			epr.HTTPWriter.Header().Set(HeaderCacheControl, CacheControlNoStore)
+			return []byte(`custom`), nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var etag = mfs.MustGet(`/main.0123abcd.js`).ETag()
+
+	var listCase = []struct {
+		desc        string
+		method      string
+		target      string
+		ifNoneMatch string
+		exp         string
+		expCode     int
+	}{{
+		desc:    `fingerprinted asset`,
+		method:  http.MethodGet,
+		target:  `/main.0123abcd.js`,
+		exp:     CacheControlImmutable,
+		expCode: http.StatusOK,
+	}, {
+		desc:        `fingerprinted asset not modified`,
+		method:      http.MethodGet,
+		target:      `/main.0123abcd.js`,
+		ifNoneMatch: etag,
+		exp:         CacheControlImmutable,
+		expCode:     http.StatusNotModified,
+	}, {
+		desc:    `HEAD`,
+		method:  http.MethodHead,
+		target:  `/index.html`,
+		exp:     CacheControlNoCache,
+		expCode: http.StatusOK,
+	}, {
+		desc:    `not found`,
+		method:  http.MethodGet,
+		target:  `/notfound.js`,
+		expCode: http.StatusNotFound,
+	}, {
+		desc:    `set by handler`,
+		method:  http.MethodGet,
+		target:  `/api/custom`,
+		exp:     CacheControlNoStore,
+		expCode: http.StatusOK,
+	}}
+
+	for _, c := range listCase {
+		var (
+			httpReq = httptest.NewRequest(c.method, c.target, nil)
+			rec     = httptest.NewRecorder()
+		)
+		if len(c.ifNoneMatch) != 0 {
+			httpReq.Header.Set(HeaderIfNoneMatch, c.ifNoneMatch)
+		}
+
+		srv.ServeHTTP(rec, httpReq)
+
+		test.Assert(t, c.desc+`: status`, c.expCode, rec.Code)
+		test.Assert(t, c.desc+`: Cache-Control`, c.exp,
+			rec.Header().Get(HeaderCacheControl))
+	}
+
+	// The caller slice is not modified.
+	test.Assert(t, `CacheControl.re`, true, listCacheControl[0].re == nil)
+
+	_, err = NewServer(ServerOptions{
+		CacheControl: []CacheControl{{
+			Path: `(`,
+		}},
+	})
+	test.Assert(t, `invalid Path`,
+		"NewServer: invalid CacheControl Path \"(\": error parsing regexp: missing closing ): `(`",
+		err.Error())
+}
@@ -29,11 +29,16 @@ type CallbackErrorHandler func(epr *EndpointRequest)
 // and write the response body as JSON format,
 //
 //	{"code":<HTTP_STATUS_CODE>, "message":<err.Error()>}
+//
+// If the error is instance of *[EndpointResponse], the whole
+// EndpointResponse is written, including its Data.
 func DefaultErrorHandler(epr *EndpointRequest) {
 	var (
 		logp        = "DefaultErrorHandler"
 		errInternal = &liberrors.E{}
+		errResponse = &EndpointResponse{}
 
+		body  any
 		jsonb []byte
 		err   error
 	)
@@ -48,11 +53,15 @@ func DefaultErrorHandler(epr *EndpointRequest) {
 
 		errInternal = liberrors.Internal(epr.Error)
 	}
+	body = errInternal
+	if errors.As(epr.Error, &errResponse) {
+		body = errResponse
+	}
 
 	epr.HTTPWriter.Header().Set(HeaderContentType, ContentTypeJSON)
 	epr.HTTPWriter.WriteHeader(errInternal.Code)
 
-	jsonb, err = json.Marshal(errInternal)
+	jsonb, err = json.Marshal(body)
 	if err != nil {
 		mlog.Errf("%s: json.Marshal: %s", logp, err)
 		return
@@ -10,6 +10,7 @@ import (
 	"compress/flate"
 	"compress/gzip"
 	"compress/lzw"
+	"compress/zlib"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -36,6 +37,7 @@ var (
 // body.
 type Client struct {
 	flateReader io.ReadCloser
+	zlibReader  io.ReadCloser
 	gzipReader  *gzip.Reader
 
 	*http.Client
@@ -526,6 +528,20 @@ func (client *Client) uncompress(res *http.Response, body []byte) (
 		dec = lzw.NewReader(in, lzw.MSB, 8)
 
 	case ContentEncodingDeflate:
+		// The "deflate" encoding use the zlib format, but some
+		// servers send the raw deflate format, so try the zlib first
+		// and fall back to raw deflate if the zlib header is invalid.
+		if client.zlibReader == nil {
+			client.zlibReader, err = zlib.NewReader(in)
+		} else {
+			err = client.zlibReader.(zlib.Resetter).Reset(in, nil)
+		}
+		if err == nil {
+			dec = client.zlibReader
+			break
+		}
+
+		in = bytes.NewReader(body)
 		if client.flateReader == nil {
 			client.flateReader = flate.NewReader(in)
 		} else {
@@ -534,6 +550,7 @@ func (client *Client) uncompress(res *http.Response, body []byte) (
 				return body, err
 			}
 		}
+		err = nil
 		dec = client.flateReader
 
 	case ContentEncodingGzip:
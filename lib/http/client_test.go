@@ -5,7 +5,10 @@ package http
 
 import (
 	"bytes"
+	"compress/flate"
+	"compress/zlib"
 	"fmt"
+	"net/http"
 	"testing"
 
 	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
@@ -90,3 +93,69 @@ func TestClient_Download(t *testing.T) {
 		test.Assert(t, c.desc, testDownloadBody, out.Bytes())
 	}
 }
+
+func TestClient_uncompress_deflate(t *testing.T) {
+	var (
+		client = NewClient(ClientOptions{})
+		exp    = []byte(`Hello, deflate`)
+
+		bufZlib  bytes.Buffer
+		bufFlate bytes.Buffer
+		err      error
+	)
+
+	var zw = zlib.NewWriter(&bufZlib)
+	_, err = zw.Write(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = zw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fw *flate.Writer
+
+	fw, err = flate.NewWriter(&bufFlate, flate.DefaultCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = fw.Write(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var listCase = []struct {
+		desc string
+		body []byte
+	}{{
+		desc: `With zlib format`,
+		body: bufZlib.Bytes(),
+	}, {
+		desc: `With raw deflate format`,
+		body: bufFlate.Bytes(),
+	}, {
+		// Decode the zlib again after the fallback to raw deflate.
+		desc: `With zlib format after raw deflate`,
+		body: bufZlib.Bytes(),
+	}}
+
+	var res = &http.Response{
+		Header: http.Header{},
+	}
+	res.Header.Set(HeaderContentType, ContentTypePlain)
+	res.Header.Set(HeaderContentEncoding, ContentEncodingDeflate)
+
+	var got []byte
+	for _, c := range listCase {
+		got, err = client.uncompress(res, c.body)
+		if err != nil {
+			t.Fatalf(`%s: %s`, c.desc, err)
+		}
+		test.Assert(t, c.desc, exp, got)
+	}
+}
@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package http
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// defCompressMinSize define the default minimum size of response body to
+// be compressed.
+const defCompressMinSize = 1024
+
+// defCompressContentTypes define the default list of media type that will
+// be compressed.
+var defCompressContentTypes = []string{
+	`text/`,
+	`application/javascript`,
+	`application/json`,
+	`application/manifest+json`,
+	`application/wasm`,
+	`application/xml`,
+	`image/svg+xml`,
+}
+
+// Encoder define the function to create new writer that compress the
+// response body using specific content encoding and write it into w.
+type Encoder func(w io.Writer) (io.WriteCloser, error)
+
+// CompressOptions define the options for server to compress the response
+// body based on the request header "Accept-Encoding".
+//
+// The compression is applied to the response from [Endpoint] and
+// [Server.HandleFS], except for response with status 206 Partial Content
+// and response that already has header "Content-Encoding".
+type CompressOptions struct {
+	// Encoders define the custom function to compress the response
+	// for content encoding other than "gzip" and "deflate", for
+	// example "br" or "zstd".
+	// The standard library does not provide the encoder for "br" and
+	// "zstd", so the caller must register them here, using third party
+	// module, before adding them to Encodings.
+	// The custom Encoder for "gzip" or "deflate" take precedence over
+	// the default one.
+	Encoders map[string]Encoder
+
+	// Encodings define the list of content encoding that server can
+	// use, in the order of preference when the client accept more than
+	// one encoding with the same quality.
+	// Each encoding other than "gzip" and "deflate" must be defined in
+	// Encoders.
+	// This field is optional, default to "gzip" and "deflate".
+	Encodings []string
+
+	// ContentTypes define the list of media type to be compressed.
+	// The media type that end with "/", for example "text/", match all
+	// of its sub types.
+	// This field is optional, default to "text/",
+	// "application/javascript", "application/json",
+	// "application/manifest+json", "application/wasm",
+	// "application/xml", and "image/svg+xml".
+	ContentTypes []string
+
+	// MinSize define the minimum size of response body, in bytes, to
+	// be compressed.
+	// This field is optional, default to 1024.
+	MinSize int
+
+	// Enable the response compression.
+	Enable bool
+}
+
+func (opts *CompressOptions) init() (err error) {
+	if !opts.Enable {
+		return nil
+	}
+	if len(opts.Encodings) == 0 {
+		opts.Encodings = []string{ContentEncodingGzip, ContentEncodingDeflate}
+	}
+	if len(opts.ContentTypes) == 0 {
+		opts.ContentTypes = defCompressContentTypes
+	}
+	if opts.MinSize <= 0 {
+		opts.MinSize = defCompressMinSize
+	}
+
+	var name string
+	for _, name = range opts.Encodings {
+		if opts.encoder(name) == nil {
+			return fmt.Errorf(`unknown content encoding %q in Compress.Encodings`, name)
+		}
+	}
+	return nil
+}
+
+// encoder return the Encoder for content encoding.
+func (opts *CompressOptions) encoder(encoding string) Encoder {
+	var enc = opts.Encoders[encoding]
+	if enc != nil {
+		return enc
+	}
+	switch encoding {
+	case ContentEncodingDeflate:
+		return encodeDeflate
+	case ContentEncodingGzip:
+		return encodeGzip
+	}
+	return nil
+}
+
+// isCompressible return true if the media type in contentType match with
+// one of ContentTypes.
+func (opts *CompressOptions) isCompressible(contentType string) bool {
+	var mediaType, _, err = mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	var ct string
+	for _, ct = range opts.ContentTypes {
+		if strings.HasSuffix(ct, `/`) {
+			if strings.HasPrefix(mediaType, ct) {
+				return true
+			}
+		} else if mediaType == ct {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeDeflate(w io.Writer) (io.WriteCloser, error) {
+	return zlib.NewWriter(w), nil
+}
+
+func encodeGzip(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+// negotiateEncoding return the content encoding from encodings that is
+// acceptable based on the value of request header "Accept-Encoding".
+// If the client accept more than one encoding with the same quality,
+// the first one in encodings is selected.
+// It will return empty string if none of encodings is acceptable.
+func negotiateEncoding(acceptEncoding string, encodings []string) (encoding string) {
+	if len(acceptEncoding) == 0 || len(encodings) == 0 {
+		return ``
+	}
+
+	var (
+		listQuality = map[string]float64{}
+
+		token string
+	)
+	for token = range strings.SplitSeq(acceptEncoding, `,`) {
+		var name, params, _ = strings.Cut(token, `;`)
+
+		name = strings.ToLower(strings.TrimSpace(name))
+		if len(name) == 0 {
+			continue
+		}
+
+		var quality = 1.0
+
+		params = strings.TrimSpace(params)
+		if strings.HasPrefix(params, `q=`) {
+			var err error
+			quality, err = strconv.ParseFloat(params[2:], 64)
+			if err != nil {
+				continue
+			}
+		}
+		listQuality[name] = quality
+	}
+
+	var (
+		maxQuality float64
+		name       string
+	)
+	for _, name = range encodings {
+		var quality, ok = listQuality[name]
+		if !ok {
+			quality = listQuality[`*`]
+		}
+		if quality > maxQuality {
+			maxQuality = quality
+			encoding = name
+		}
+	}
+	return encoding
+}
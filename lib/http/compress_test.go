@@ -0,0 +1,302 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/memfs"
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	var encodings = []string{`br`, `gzip`, `deflate`}
+
+	var listCase = []struct {
+		acceptEncoding string
+		exp            string
+	}{{
+		acceptEncoding: ``,
+	}, {
+		acceptEncoding: `identity`,
+	}, {
+		acceptEncoding: `gzip`,
+		exp:            `gzip`,
+	}, {
+		acceptEncoding: `deflate, gzip`,
+		exp:            `gzip`,
+	}, {
+		acceptEncoding: `GZIP;q=0.5, deflate;q=0.8`,
+		exp:            `deflate`,
+	}, {
+		acceptEncoding: `gzip;q=0, deflate;q=0`,
+	}, {
+		acceptEncoding: `*`,
+		exp:            `br`,
+	}, {
+		acceptEncoding: `br;q=0, *;q=0.1`,
+		exp:            `gzip`,
+	}, {
+		acceptEncoding: `gzip;q=x, deflate`,
+		exp:            `deflate`,
+	}}
+
+	for _, c := range listCase {
+		var got = negotiateEncoding(c.acceptEncoding, encodings)
+		test.Assert(t, c.acceptEncoding, c.exp, got)
+	}
+}
+
+func TestServer_compress(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		content = strings.Repeat(`body { margin: 0; }`+"\n", 8)
+
+		mfs *memfs.MemFS
+		err error
+	)
+
+	err = os.WriteFile(filepath.Join(dir, `index.css`), []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mfs, err = memfs.New(&memfs.Options{
+		Root:        dir,
+		Precompress: []string{memfs.EncodingGzip},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var srv *Server
+
+	srv, err = NewServer(ServerOptions{
+		Memfs: mfs,
+		Compress: CompressOptions{
+			Enable:    true,
+			Encodings: []string{`x-upper`, ContentEncodingGzip, ContentEncodingDeflate},
+			Encoders: map[string]Encoder{
+				`x-upper`: func(w io.Writer) (io.WriteCloser, error) {
+					return &testUpperWriter{w: w}, nil
+				},
+			},
+			MinSize: 64,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		bodyJSON   = `{"data":"` + strings.Repeat(`a`, 64) + `"}`
+		bodyBinary = strings.Repeat("\x00", 64)
+	)
+
+	var listEndpoint = []Endpoint{{
+		Path:         `/json`,
+		ResponseType: ResponseTypeJSON,
+		Call: func(_ *EndpointRequest) ([]byte, error) {
+			return []byte(bodyJSON), nil
+		},
+	}, {
+		Path:         `/plain`,
+		ResponseType: ResponseTypePlain,
+		Call: func(_ *EndpointRequest) ([]byte, error) {
+			return []byte(`small`), nil
+		},
+	}, {
+		Path:         `/binary`,
+		ResponseType: ResponseTypeBinary,
+		Call: func(_ *EndpointRequest) ([]byte, error) {
+			return []byte(bodyBinary), nil
+		},
+	}}
+	var ep Endpoint
+	for _, ep = range listEndpoint {
+		err = srv.RegisterEndpoint(ep)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type testCase struct {
+		desc           string
+		target         string
+		acceptEncoding string
+		reqRange       string
+		expEncoding    string
+		expVary        string
+		expBody        string
+		expCode        int
+	}
+
+	var listCase = []testCase{{
+		desc:           `JSON with gzip`,
+		target:         `/json`,
+		acceptEncoding: `gzip`,
+		expEncoding:    ContentEncodingGzip,
+		expVary:        HeaderAcceptEncoding,
+		expBody:        bodyJSON,
+		expCode:        http.StatusOK,
+	}, {
+		desc:           `JSON with deflate`,
+		target:         `/json`,
+		acceptEncoding: `gzip;q=0.5, deflate`,
+		expEncoding:    ContentEncodingDeflate,
+		expVary:        HeaderAcceptEncoding,
+		expBody:        bodyJSON,
+		expCode:        http.StatusOK,
+	}, {
+		desc:           `JSON with custom encoder`,
+		target:         `/json`,
+		acceptEncoding: `*`,
+		expEncoding:    `x-upper`,
+		expVary:        HeaderAcceptEncoding,
+		expBody:        bodyJSON,
+		expCode:        http.StatusOK,
+	}, {
+		desc:    `JSON without Accept-Encoding`,
+		target:  `/json`,
+		expBody: bodyJSON,
+		expCode: http.StatusOK,
+	}, {
+		desc:           `Plain below MinSize`,
+		target:         `/plain`,
+		acceptEncoding: `gzip`,
+		expVary:        HeaderAcceptEncoding,
+		expBody:        `small`,
+		expCode:        http.StatusOK,
+	}, {
+		desc:           `Binary is not compressed`,
+		target:         `/binary`,
+		acceptEncoding: `gzip`,
+		expBody:        bodyBinary,
+		expCode:        http.StatusOK,
+	}, {
+		desc:           `Precompressed file`,
+		target:         `/index.css`,
+		acceptEncoding: `deflate, gzip`,
+		expEncoding:    ContentEncodingGzip,
+		expVary:        HeaderAcceptEncoding,
+		expBody:        content,
+		expCode:        http.StatusOK,
+	}, {
+		desc:           `Precompressed file compressed on the fly`,
+		target:         `/index.css`,
+		acceptEncoding: `deflate`,
+		expEncoding:    ContentEncodingDeflate,
+		expVary:        HeaderAcceptEncoding,
+		expBody:        content,
+		expCode:        http.StatusOK,
+	}, {
+		desc:           `Precompressed file with range`,
+		target:         `/index.css`,
+		acceptEncoding: `gzip`,
+		reqRange:       `bytes=0-3`,
+		expVary:        HeaderAcceptEncoding,
+		expBody:        content[:4],
+		expCode:        http.StatusPartialContent,
+	}}
+
+	var c testCase
+	for _, c = range listCase {
+		var (
+			httpReq = httptest.NewRequest(http.MethodGet, c.target, nil)
+			rec     = httptest.NewRecorder()
+		)
+		if len(c.acceptEncoding) != 0 {
+			httpReq.Header.Set(HeaderAcceptEncoding, c.acceptEncoding)
+		}
+		if len(c.reqRange) != 0 {
+			httpReq.Header.Set(HeaderRange, c.reqRange)
+		}
+
+		srv.ServeHTTP(rec, httpReq)
+
+		var (
+			httpRes = rec.Result()
+			gotBody []byte
+		)
+		test.Assert(t, c.desc+`: status`, c.expCode, httpRes.StatusCode)
+		test.Assert(t, c.desc+`: Content-Encoding`, c.expEncoding,
+			httpRes.Header.Get(HeaderContentEncoding))
+		test.Assert(t, c.desc+`: Vary`, c.expVary,
+			strings.Join(httpRes.Header.Values(HeaderVary), `, `))
+
+		gotBody, err = testDecodeBody(c.expEncoding, rec.Body.Bytes())
+		if err != nil {
+			t.Fatalf(`%s: %s`, c.desc, err)
+		}
+		test.Assert(t, c.desc+`: body`, c.expBody, string(gotBody))
+	}
+
+	// The precompressed content is served as is.
+
+	var (
+		httpReq = httptest.NewRequest(http.MethodGet, `/index.css`, nil)
+		rec     = httptest.NewRecorder()
+	)
+	httpReq.Header.Set(HeaderAcceptEncoding, `gzip`)
+	srv.ServeHTTP(rec, httpReq)
+	test.Assert(t, `precompressed content`,
+		mfs.MustGet(`/index.css`).EncodedContent(memfs.EncodingGzip),
+		rec.Body.Bytes())
+	test.Assert(t, `precompressed ETag`,
+		etagEncoded(mfs.MustGet(`/index.css`).ETag(), memfs.EncodingGzip),
+		rec.Header().Get(HeaderETag))
+}
+
+func TestNewServer_compressUnknownEncoding(t *testing.T) {
+	var _, err = NewServer(ServerOptions{
+		Compress: CompressOptions{
+			Enable:    true,
+			Encodings: []string{`br`},
+		},
+	})
+	test.Assert(t, `error`,
+		`NewServer: unknown content encoding "br" in Compress.Encodings`,
+		err.Error())
+}
+
+// testUpperWriter convert the content to upper case, as an example of
+// custom Encoder.
+type testUpperWriter struct {
+	w io.Writer
+}
+
+func (uw *testUpperWriter) Write(b []byte) (int, error) {
+	return uw.w.Write(bytes.ToUpper(b))
+}
+
+func (uw *testUpperWriter) Close() error {
+	return nil
+}
+
+func testDecodeBody(encoding string, body []byte) (out []byte, err error) {
+	var r io.Reader
+	switch encoding {
+	case ContentEncodingGzip:
+		r, err = gzip.NewReader(bytes.NewReader(body))
+	case ContentEncodingDeflate:
+		r, err = zlib.NewReader(bytes.NewReader(body))
+	case `x-upper`:
+		return bytes.ToLower(body), nil
+	default:
+		return body, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
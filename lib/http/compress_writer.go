@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package http
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/mlog"
+)
+
+// List of state in compressWriter.
+const (
+	compressStateUndecided = iota
+	compressStatePassthrough
+	compressStateCompress
+	compressStateHijacked
+)
+
+// compressWriter wrap the [http.ResponseWriter] to compress the response
+// body using the negotiated content encoding.
+//
+// The response body is buffered until its size reach the
+// [CompressOptions.MinSize], before deciding to compress it or not.
+// If the response status code, Content-Type, or Content-Encoding is not
+// applicable for compression, the response is written as is.
+type compressWriter struct {
+	http.ResponseWriter
+
+	opts *CompressOptions
+	enc  io.WriteCloser
+
+	encoding string
+
+	buf []byte
+
+	code  int
+	state int
+}
+
+func newCompressWriter(res http.ResponseWriter, opts *CompressOptions, encoding string) *compressWriter {
+	return &compressWriter{
+		ResponseWriter: res,
+		opts:           opts,
+		encoding:       encoding,
+	}
+}
+
+// WriteHeader store the status code and decide whether the response
+// can be compressed or not.
+// If the response can be compressed, the status code is written later
+// once the body is written.
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.state != compressStateUndecided {
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if cw.code != 0 {
+		// Superfluous call, ignore it.
+		return
+	}
+	cw.code = code
+	if !cw.isApplicable() {
+		cw.passthrough()
+	}
+}
+
+// Write the response body into buffer, compressor, or underlying
+// ResponseWriter, based on the current state.
+func (cw *compressWriter) Write(b []byte) (n int, err error) {
+	if cw.state == compressStateUndecided && cw.code == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	switch cw.state {
+	case compressStatePassthrough:
+		return cw.ResponseWriter.Write(b)
+	case compressStateCompress:
+		return cw.enc.Write(b)
+	case compressStateHijacked:
+		return 0, http.ErrHijacked
+	}
+
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) >= cw.opts.MinSize {
+		err = cw.compress()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush write the buffered response and flush the underlying
+// ResponseWriter.
+func (cw *compressWriter) Flush() {
+	switch cw.state {
+	case compressStateUndecided:
+		cw.passthrough()
+	case compressStateCompress:
+		var flusher, ok = cw.enc.(interface{ Flush() error })
+		if ok {
+			var err = flusher.Flush()
+			if err != nil {
+				mlog.Errf(`compressWriter.Flush: %s`, err)
+			}
+		}
+	case compressStateHijacked:
+		return
+	}
+
+	var flusher, ok = cw.ResponseWriter.(http.Flusher)
+	if ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack the underlying connection, if the underlying ResponseWriter
+// support it.
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	var hijacker, ok = cw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New(`http.ResponseWriter is not http.Hijacker`)
+	}
+	cw.state = compressStateHijacked
+	return hijacker.Hijack()
+}
+
+// Unwrap return the underlying ResponseWriter, for
+// [http.ResponseController].
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// close write the remaining buffered response, or close the compressor.
+func (cw *compressWriter) close() {
+	switch cw.state {
+	case compressStateUndecided:
+		if cw.code == 0 && len(cw.buf) == 0 {
+			// Nothing has been written by handler.
+			return
+		}
+		cw.passthrough()
+	case compressStateCompress:
+		var err = cw.enc.Close()
+		if err != nil {
+			mlog.Errf(`compressWriter.close: %s`, err)
+		}
+	}
+}
+
+// isApplicable return true if the response can be compressed based on
+// the status code and response header.
+func (cw *compressWriter) isApplicable() bool {
+	switch {
+	case cw.code < http.StatusOK,
+		cw.code == http.StatusNoContent,
+		cw.code == http.StatusPartialContent,
+		cw.code == http.StatusNotModified:
+		return false
+	}
+
+	var header = cw.Header()
+	if len(header.Get(HeaderContentEncoding)) != 0 {
+		return false
+	}
+	if !cw.opts.isCompressible(header.Get(HeaderContentType)) {
+		return false
+	}
+	if !slices.Contains(header.Values(HeaderVary), HeaderAcceptEncoding) {
+		header.Add(HeaderVary, HeaderAcceptEncoding)
+	}
+	return true
+}
+
+// compress write the header and buffered response into the compressor.
+func (cw *compressWriter) compress() (err error) {
+	var (
+		logp   = `compressWriter`
+		header = cw.Header()
+	)
+
+	cw.enc, err = cw.opts.encoder(cw.encoding)(cw.ResponseWriter)
+	if err != nil {
+		mlog.Errf(`%s: %s: %s`, logp, cw.encoding, err)
+		cw.passthrough()
+		return nil
+	}
+
+	header.Set(HeaderContentEncoding, cw.encoding)
+	header.Del(HeaderContentLength)
+
+	// The strong entity tag must be different for each content
+	// encoding.
+	var etag = header.Get(HeaderETag)
+	if len(etag) != 0 && !strings.HasPrefix(etag, `W/`) {
+		header.Set(HeaderETag, etagEncoded(etag, cw.encoding))
+	}
+	cw.state = compressStateCompress
+	cw.ResponseWriter.WriteHeader(cw.code)
+
+	_, err = cw.enc.Write(cw.buf)
+	cw.buf = nil
+	return err
+}
+
+// passthrough write the header and buffered response as is.
+func (cw *compressWriter) passthrough() {
+	cw.state = compressStatePassthrough
+	if cw.code != 0 {
+		cw.ResponseWriter.WriteHeader(cw.code)
+	}
+	if len(cw.buf) != 0 {
+		var _, err = cw.ResponseWriter.Write(cw.buf)
+		if err != nil {
+			mlog.Errf(`compressWriter: %s`, err)
+		}
+		cw.buf = nil
+	}
+}
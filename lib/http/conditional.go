@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// etagEncoded return the entity tag for the content that has been
+// compressed with the content encoding, by appending the encoding name
+// into the etag.
+// For example, the etag `"abc"` with encoding "gzip" become
+// `"abc-gzip"`.
+func etagEncoded(etag, encoding string) string {
+	if !strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+	return strings.TrimSuffix(etag, `"`) + `-` + encoding + `"`
+}
+
+// etagModTime return the weak entity tag generated from modification
+// time and size, for file that is not loaded into memory.
+func etagModTime(modtime time.Time, size int64) string {
+	return `W/"` + strconv.FormatInt(modtime.Unix(), 16) + `-` +
+		strconv.FormatInt(size, 16) + `"`
+}
+
+// etagMatch return true if one of the entity tag in the list match with
+// the etag, using the weak comparison.
+// The entity tag in the list also match if its equal to the etag with one
+// of the content encodings, as returned by [etagEncoded], since the
+// response compressed with that encoding is send with that entity tag.
+func etagMatch(list, etag string, encodings []string) bool {
+	if len(etag) == 0 {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, `W/`)
+
+	var tag string
+	for tag = range strings.SplitSeq(list, `,`) {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), `W/`)
+		if tag == `*` || tag == etag {
+			return true
+		}
+		var encoding string
+		for _, encoding = range encodings {
+			if tag == etagEncoded(etag, encoding) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isNotModified return true if the request header If-None-Match match with
+// etag, or if If-None-Match is empty, the request header
+// If-Modified-Since is equal or after modtime.
+// The encodings contains the list of content encoding that the server may
+// use to compress the response, see [etagMatch].
+func isNotModified(req *http.Request, etag string, modtime time.Time, encodings []string) bool {
+	var ifNoneMatch = req.Header.Get(HeaderIfNoneMatch)
+	if len(ifNoneMatch) != 0 {
+		return etagMatch(ifNoneMatch, etag, encodings)
+	}
+
+	var ifModifiedSince = req.Header.Get(HeaderIfModifiedSince)
+	if len(ifModifiedSince) == 0 || modtime.IsZero() {
+		return false
+	}
+
+	var since, err = http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	return !modtime.Truncate(time.Second).After(since)
+}
+
+// isNotModifiedHeader return true if the request is not modified based on
+// the response header ETag and Last-Modified.
+func isNotModifiedHeader(req *http.Request, header http.Header, encodings []string) bool {
+	var (
+		etag    = header.Get(HeaderETag)
+		modtime time.Time
+	)
+
+	var lastModified = header.Get(HeaderLastModified)
+	if len(lastModified) != 0 {
+		modtime, _ = http.ParseTime(lastModified)
+	}
+	if len(etag) == 0 && modtime.IsZero() {
+		return false
+	}
+	return isNotModified(req, etag, modtime, encodings)
+}
+
+// isRangeValid return true if the request header If-Range is empty or
+// match with the strong etag or modtime, which means the Range request
+// can be served.
+// Otherwise, the full content should be served.
+func isRangeValid(req *http.Request, etag string, modtime time.Time) bool {
+	var ifRange = strings.TrimSpace(req.Header.Get(HeaderIfRange))
+	if len(ifRange) == 0 {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) || strings.HasPrefix(ifRange, `W/`) {
+		// The If-Range use strong comparison.
+		return !strings.HasPrefix(etag, `W/`) && ifRange == etag
+	}
+
+	var date, err = http.ParseTime(ifRange)
+	if err != nil {
+		return false
+	}
+	return modtime.Truncate(time.Second).Equal(date)
+}
@@ -0,0 +1,278 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/memfs"
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestEtagMatch(t *testing.T) {
+	var (
+		encodings = []string{`gzip`, `deflate`}
+		listCase  = []struct {
+			list      string
+			etag      string
+			encodings []string
+			exp       bool
+		}{{
+			list: `"abc"`,
+			etag: `"abc"`,
+			exp:  true,
+		}, {
+			list: `"xyz", W/"abc"`,
+			etag: `"abc"`,
+			exp:  true,
+		}, {
+			list: `"abc"`,
+			etag: `W/"abc"`,
+			exp:  true,
+		}, {
+			list:      `"abc-gzip"`,
+			etag:      `"abc"`,
+			encodings: encodings,
+			exp:       true,
+		}, {
+			list:      `"xyz", "abc-deflate"`,
+			etag:      `"abc"`,
+			encodings: encodings,
+			exp:       true,
+		}, {
+			// The server does not compress the content.
+			list: `"abc-gzip"`,
+			etag: `"abc"`,
+		}, {
+			// The encoding is not used by server.
+			list:      `"abc-br"`,
+			etag:      `"abc"`,
+			encodings: encodings,
+		}, {
+			list:      `"abc-gzip-x"`,
+			etag:      `"abc"`,
+			encodings: encodings,
+		}, {
+			list: `*`,
+			etag: `"abc"`,
+			exp:  true,
+		}, {
+			list: `"abcd"`,
+			etag: `"abc"`,
+		}, {
+			list: `*`,
+		}}
+	)
+
+	for _, c := range listCase {
+		var got = etagMatch(c.list, c.etag, c.encodings)
+		test.Assert(t, c.list+` `+c.etag, c.exp, got)
+	}
+}
+
+func TestServer_HandleFS_conditional(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		content = `0123456789`
+		modtime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		mfs *memfs.MemFS
+		err error
+	)
+
+	err = os.WriteFile(filepath.Join(dir, `a.txt`), []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chtimes(filepath.Join(dir, `a.txt`), modtime, modtime)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mfs, err = memfs.New(&memfs.Options{
+		Root: dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var srv *Server
+
+	srv, err = NewServer(ServerOptions{
+		Memfs: mfs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		etag         = mfs.MustGet(`/a.txt`).ETag()
+		lastModified = modtime.Format(http.TimeFormat)
+	)
+
+	type testCase struct {
+		header  http.Header
+		desc    string
+		expBody string
+		expCode int
+	}
+
+	var listCase = []testCase{{
+		desc:    `without condition`,
+		expBody: content,
+		expCode: http.StatusOK,
+	}, {
+		desc: `If-None-Match match`,
+		header: http.Header{
+			HeaderIfNoneMatch: []string{`"x", ` + etag},
+		},
+		expCode: http.StatusNotModified,
+	}, {
+		// The server does not compress the response, so the
+		// ETag with encoding never send to client.
+		desc: `If-None-Match with encoding not used by server`,
+		header: http.Header{
+			HeaderIfNoneMatch: []string{etagEncoded(etag, ContentEncodingGzip)},
+		},
+		expBody: content,
+		expCode: http.StatusOK,
+	}, {
+		desc: `If-None-Match not match`,
+		header: http.Header{
+			HeaderIfNoneMatch:     []string{`"x"`},
+			HeaderIfModifiedSince: []string{lastModified},
+		},
+		expBody: content,
+		expCode: http.StatusOK,
+	}, {
+		desc: `If-Modified-Since equal`,
+		header: http.Header{
+			HeaderIfModifiedSince: []string{lastModified},
+		},
+		expCode: http.StatusNotModified,
+	}, {
+		desc: `If-Modified-Since before`,
+		header: http.Header{
+			HeaderIfModifiedSince: []string{
+				modtime.Add(-time.Second).Format(http.TimeFormat),
+			},
+		},
+		expBody: content,
+		expCode: http.StatusOK,
+	}, {
+		desc: `Range with If-Range ETag match`,
+		header: http.Header{
+			HeaderRange:   []string{`bytes=0-3`},
+			HeaderIfRange: []string{etag},
+		},
+		expBody: content[:4],
+		expCode: http.StatusPartialContent,
+	}, {
+		desc: `Range with If-Range date match`,
+		header: http.Header{
+			HeaderRange:   []string{`bytes=0-3`},
+			HeaderIfRange: []string{lastModified},
+		},
+		expBody: content[:4],
+		expCode: http.StatusPartialContent,
+	}, {
+		desc: `Range with If-Range not match`,
+		header: http.Header{
+			HeaderRange:   []string{`bytes=0-3`},
+			HeaderIfRange: []string{`"x"`},
+		},
+		expBody: content,
+		expCode: http.StatusOK,
+	}, {
+		desc: `Range with If-Range weak ETag`,
+		header: http.Header{
+			HeaderRange:   []string{`bytes=0-3`},
+			HeaderIfRange: []string{`W/` + etag},
+		},
+		expBody: content,
+		expCode: http.StatusOK,
+	}}
+
+	var c testCase
+	for _, c = range listCase {
+		var (
+			httpReq = httptest.NewRequest(http.MethodGet, `/a.txt`, nil)
+			rec     = httptest.NewRecorder()
+		)
+		if c.header != nil {
+			httpReq.Header = c.header
+		}
+
+		srv.ServeHTTP(rec, httpReq)
+
+		var httpRes = rec.Result()
+		test.Assert(t, c.desc+`: status`, c.expCode, httpRes.StatusCode)
+		test.Assert(t, c.desc+`: ETag`, etag, httpRes.Header.Get(HeaderETag))
+		test.Assert(t, c.desc+`: Last-Modified`, lastModified,
+			httpRes.Header.Get(HeaderLastModified))
+		test.Assert(t, c.desc+`: body`, c.expBody, rec.Body.String())
+	}
+}
+
+func TestEndpoint_call_notModified(t *testing.T) {
+	var (
+		etag = `"v1"`
+
+		srv *Server
+		err error
+	)
+
+	srv, err = NewServer(ServerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = srv.RegisterEndpoint(Endpoint{
+		Path:         `/version`,
+		ResponseType: ResponseTypePlain,
+		Call: func(epr *EndpointRequest) ([]byte, error) {
+			epr.HTTPWriter.Header().Set(HeaderETag, etag)
+			return []byte(`v1`), nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var listCase = []struct {
+		ifNoneMatch string
+		expBody     string
+		expCode     int
+	}{{
+		expBody: `v1`,
+		expCode: http.StatusOK,
+	}, {
+		ifNoneMatch: etag,
+		expCode:     http.StatusNotModified,
+	}, {
+		ifNoneMatch: `"v0"`,
+		expBody:     `v1`,
+		expCode:     http.StatusOK,
+	}}
+
+	for _, c := range listCase {
+		var (
+			httpReq = httptest.NewRequest(http.MethodGet, `/version`, nil)
+			rec     = httptest.NewRecorder()
+		)
+		if len(c.ifNoneMatch) != 0 {
+			httpReq.Header.Set(HeaderIfNoneMatch, c.ifNoneMatch)
+		}
+
+		srv.ServeHTTP(rec, httpReq)
+
+		test.Assert(t, c.ifNoneMatch+`: status`, c.expCode, rec.Code)
+		test.Assert(t, c.ifNoneMatch+`: body`, c.expBody, rec.Body.String())
+	}
+}
@@ -26,8 +26,33 @@ type Endpoint struct {
 	Eval Evaluator
 
 	// Call is the main process of route.
+	//
+	// On GET request, if the Call set the response header ETag or
+	// Last-Modified through [EndpointRequest.HTTPWriter] and the
+	// request header If-None-Match or If-Modified-Since match with them,
+	// server will response with 304 Not Modified without body.
+	// Since the ETag and Last-Modified is known only after the Call
+	// return, the Call is always executed on conditional request.
+	// To skip the expensive process, the Call can check the
+	// conditional request headers by itself.
 	Call Callback
 
+	// Request define the optional value of request parameters or body,
+	// used to generate the schema in OpenAPI document.
+	// See [Server.OpenAPI] for more information.
+	Request any
+
+	// Response define the optional value of response body, used to
+	// generate the schema in OpenAPI document.
+	Response any
+
+	// group where the endpoint registered, nil if its registered
+	// directly to Server.
+	group *RouteGroup
+
+	// Description of the endpoint in the OpenAPI document.
+	Description string
+
 	// Method contains HTTP method, default to GET.
 	Method RequestMethod
 
@@ -39,6 +64,9 @@ type Endpoint struct {
 
 	// ResponseType contains type of request, default to ResponseTypeNone.
 	ResponseType ResponseType
+
+	// isOpenAPI is true if the endpoint serve the OpenAPI document.
+	isOpenAPI bool
 }
 
 func (ep *Endpoint) call(
@@ -46,6 +74,7 @@ func (ep *Endpoint) call(
 	req *http.Request,
 	evaluators []Evaluator,
 	vals map[string]string,
+	encodings []string,
 ) {
 	var (
 		logp = "Endpoint.call"
@@ -115,6 +144,12 @@ func (ep *Endpoint) call(
 		return
 	}
 
+	if ep.ResponseType != ResponseTypeNone && req.Method == http.MethodGet &&
+		isNotModifiedHeader(req, res.Header(), encodings) {
+		res.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	switch ep.ResponseType {
 	case ResponseTypeNone:
 		return
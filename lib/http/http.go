@@ -296,12 +296,15 @@ const (
 	HeaderHost               = `Host`
 	HeaderIfModifiedSince    = `If-Modified-Since`
 	HeaderIfNoneMatch        = `If-None-Match`
+	HeaderIfRange            = `If-Range`
 	HeaderLastEventID        = `Last-Event-ID`
+	HeaderLastModified       = `Last-Modified`
 	HeaderLocation           = `Location`
 	HeaderOrigin             = `Origin`
 	HeaderRange              = `Range`
 	HeaderSetCookie          = `Set-Cookie`
 	HeaderUserAgent          = `User-Agent`
+	HeaderVary               = `Vary`
 	HeaderXForwardedFor      = `X-Forwarded-For` // https://en.wikipedia.org/wiki/X-Forwarded-For
 	HeaderXRealIP            = `X-Real-Ip`
 )
@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package http
+
+import (
+	"net/http"
+)
+
+// Middleware wrap the next handler to run some process before and/or after
+// it, for example measuring the request duration, recovering from panic,
+// setting response headers, or wrapping the request in a transaction.
+//
+// The middleware may stop the request by writing the response without
+// calling next.
+// If the middleware wrap the [http.ResponseWriter] for the
+// [SSEEndpoint], the wrapper must implement [http.Hijacker].
+type Middleware func(next http.Handler) http.Handler
+
+// wrapMiddlewares wrap the handler h with list of middleware.
+// The first middleware in the list is the outermost one, which means it
+// is called first before the request and last after the response.
+func wrapMiddlewares(h http.Handler, list []Middleware) http.Handler {
+	var x int
+	for x = len(list) - 1; x >= 0; x-- {
+		h = list[x](h)
+	}
+	return h
+}
@@ -0,0 +1,501 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	libreflect "git.sr.ht/~shulhan/pakakeh.go/lib/reflect"
+	libstrings "git.sr.ht/~shulhan/pakakeh.go/lib/strings"
+)
+
+// openapiVersion define the version of OpenAPI specification that is
+// generated by [Server.OpenAPI].
+const openapiVersion = `3.1.0`
+
+// List of default values for [OpenAPIOptions].
+const (
+	defOpenAPITitle   = `API`
+	defOpenAPIVersion = `0.0.0`
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// OpenAPIOptions define the options for generating and serving the
+// OpenAPI document from the registered endpoints.
+type OpenAPIOptions struct {
+	// Path define the path where the OpenAPI document is served, in
+	// JSON format.
+	// This field is optional, if its empty the document is not served
+	// but it still can be generated using [Server.OpenAPI].
+	Path string
+
+	// Title of the API.
+	// This field is optional, default to "API".
+	Title string
+
+	// Description of the API.
+	// This field is optional.
+	Description string
+
+	// Version of the API.
+	// This field is optional, default to "0.0.0".
+	Version string
+}
+
+func (opts *OpenAPIOptions) init() {
+	if len(opts.Title) == 0 {
+		opts.Title = defOpenAPITitle
+	}
+	if len(opts.Version) == 0 {
+		opts.Version = defOpenAPIVersion
+	}
+}
+
+// OpenAPIDocument define the root object of the OpenAPI document.
+type OpenAPIDocument struct {
+	// Paths contains the operations of each path, indexed by path
+	// template, for example "/book/{id}".
+	Paths map[string]OpenAPIPathItem `json:"paths"`
+
+	Components *OpenAPIComponents `json:"components,omitempty"`
+
+	OpenAPI string `json:"openapi"`
+
+	Info OpenAPIInfo `json:"info"`
+
+	Servers []OpenAPIServer `json:"servers,omitempty"`
+}
+
+// OpenAPIInfo define the metadata about the API.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIServer define the server that serve the API.
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+// OpenAPIPathItem contains the operations on single path, indexed by the
+// HTTP method in lower case.
+type OpenAPIPathItem map[string]*OpenAPIOperation
+
+// OpenAPIOperation define single API operation on a path.
+type OpenAPIOperation struct {
+	RequestBody *OpenAPIRequestBody `json:"requestBody,omitempty"`
+
+	// Responses contains the response indexed by HTTP status code.
+	Responses map[string]*OpenAPIResponse `json:"responses"`
+
+	Description string `json:"description,omitempty"`
+
+	Parameters []*OpenAPIParameter `json:"parameters,omitempty"`
+}
+
+// OpenAPIParameter define single parameter of the operation.
+type OpenAPIParameter struct {
+	Schema *OpenAPISchema `json:"schema,omitempty"`
+
+	Name string `json:"name"`
+
+	// In define the location of parameter, "path" or "query".
+	In string `json:"in"`
+
+	Required bool `json:"required,omitempty"`
+}
+
+// OpenAPIRequestBody define the request body of the operation.
+type OpenAPIRequestBody struct {
+	// Content contains the body schema indexed by its media type.
+	Content map[string]*OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse define single response of the operation.
+type OpenAPIResponse struct {
+	// Content contains the body schema indexed by its media type.
+	Content map[string]*OpenAPIMediaType `json:"content,omitempty"`
+
+	Description string `json:"description"`
+}
+
+// OpenAPIMediaType define the schema of request or response body.
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema,omitempty"`
+}
+
+// OpenAPIComponents contains the named schemas that are referenced by
+// the operations.
+type OpenAPIComponents struct {
+	Schemas map[string]*OpenAPISchema `json:"schemas,omitempty"`
+}
+
+// OpenAPISchema define the subset of JSON Schema that is generated from
+// Go type.
+type OpenAPISchema struct {
+	Properties map[string]*OpenAPISchema `json:"properties,omitempty"`
+
+	Items *OpenAPISchema `json:"items,omitempty"`
+
+	AdditionalProperties *OpenAPISchema `json:"additionalProperties,omitempty"`
+
+	// Ref contains the reference to the named schema in the
+	// components, for example "#/components/schemas/main.Book".
+	Ref string `json:"$ref,omitempty"`
+
+	Type string `json:"type,omitempty"`
+
+	Format string `json:"format,omitempty"`
+}
+
+// OpenAPI generate the OpenAPI document from the registered [Endpoint]
+// and [SSEEndpoint].
+//
+// Each key in the path, for example ":id" in "/book/:id", become the
+// path parameter "{id}".
+// If the [Endpoint.Request] is set and the [Endpoint.RequestType] is
+// [RequestTypeQuery], each of its fields become the query parameter,
+// with the name from the tag "form", or the field name in lower case.
+// For other request types, the type of [Endpoint.Request] is converted
+// into JSON Schema of the request body, using the tag "json" as the
+// property name, as well as the type of [Endpoint.Response] for the
+// response body.
+// The named struct types are stored in the components and referenced
+// by their package and type name, for example "main.Book".
+func (srv *Server) OpenAPI() (doc *OpenAPIDocument) {
+	var opts = &srv.Options.OpenAPI
+
+	doc = &OpenAPIDocument{
+		OpenAPI: openapiVersion,
+		Info: OpenAPIInfo{
+			Title:       opts.Title,
+			Description: opts.Description,
+			Version:     opts.Version,
+		},
+		Paths: make(map[string]OpenAPIPathItem),
+	}
+	if len(srv.Options.BasePath) != 0 {
+		doc.Servers = []OpenAPIServer{{URL: srv.Options.BasePath}}
+	}
+
+	var (
+		gen = &openapiGenerator{
+			schemas: make(map[string]*OpenAPISchema),
+		}
+		listRoutes = [][]*route{
+			srv.routeDeletes,
+			srv.routeGets,
+			srv.routePatches,
+			srv.routePosts,
+			srv.routePuts,
+		}
+
+		routes []*route
+		rute   *route
+	)
+	for _, routes = range listRoutes {
+		for _, rute = range routes {
+			if rute.endpoint != nil && rute.endpoint.isOpenAPI {
+				continue
+			}
+
+			var (
+				path   = openapiPath(rute.String())
+				method string
+				op     *OpenAPIOperation
+			)
+			if rute.kind == routeKindSSE {
+				method = http.MethodGet
+				op = gen.operationSSE(rute)
+			} else {
+				method = string(rute.endpoint.Method)
+				op = gen.operation(rute)
+			}
+
+			var item = doc.Paths[path]
+			if item == nil {
+				item = OpenAPIPathItem{}
+				doc.Paths[path] = item
+			}
+			item[strings.ToLower(method)] = op
+		}
+	}
+
+	if len(gen.schemas) != 0 {
+		doc.Components = &OpenAPIComponents{
+			Schemas: gen.schemas,
+		}
+	}
+	return doc
+}
+
+// handleOpenAPI serve the OpenAPI document.
+func (srv *Server) handleOpenAPI(_ *EndpointRequest) ([]byte, error) {
+	return json.Marshal(srv.OpenAPI())
+}
+
+// openapiPath convert the route path, for example "/book/:id", into
+// the OpenAPI path template "/book/{id}".
+func openapiPath(rpath string) string {
+	var (
+		paths = strings.Split(rpath, `/`)
+		x     int
+	)
+	for x = range len(paths) {
+		if strings.HasPrefix(paths[x], `:`) {
+			paths[x] = `{` + paths[x][1:] + `}`
+		}
+	}
+	rpath = strings.Join(paths, `/`)
+	if len(rpath) == 0 {
+		return `/`
+	}
+	return rpath
+}
+
+// openapiGenerator contains the named schemas that are collected while
+// generating the operations.
+type openapiGenerator struct {
+	schemas map[string]*OpenAPISchema
+}
+
+func (gen *openapiGenerator) operation(rute *route) (op *OpenAPIOperation) {
+	var ep = rute.endpoint
+
+	op = &OpenAPIOperation{
+		Description: ep.Description,
+		Parameters:  pathParameters(rute),
+		Responses: map[string]*OpenAPIResponse{
+			`200`: {Description: http.StatusText(http.StatusOK)},
+		},
+	}
+
+	if ep.Request != nil {
+		var rtype = reflect.TypeOf(ep.Request)
+		if ep.RequestType == RequestTypeQuery {
+			op.Parameters = append(op.Parameters,
+				gen.queryParameters(rtype)...)
+		} else {
+			op.RequestBody = &OpenAPIRequestBody{
+				Content: map[string]*OpenAPIMediaType{
+					gen.requestMediaType(ep.RequestType): {
+						Schema: gen.schemaOf(rtype),
+					},
+				},
+			}
+		}
+	}
+
+	var contentType = ep.ResponseType.String()
+	if len(contentType) != 0 {
+		var mediaType = &OpenAPIMediaType{}
+		if ep.Response != nil {
+			mediaType.Schema = gen.schemaOf(reflect.TypeOf(ep.Response))
+		}
+		op.Responses[`200`].Content = map[string]*OpenAPIMediaType{
+			contentType: mediaType,
+		}
+	}
+	return op
+}
+
+func (gen *openapiGenerator) operationSSE(rute *route) (op *OpenAPIOperation) {
+	op = &OpenAPIOperation{
+		Parameters: pathParameters(rute),
+		Responses: map[string]*OpenAPIResponse{
+			`200`: {
+				Description: http.StatusText(http.StatusOK),
+				Content: map[string]*OpenAPIMediaType{
+					ContentTypeEventStream: {},
+				},
+			},
+		},
+	}
+	return op
+}
+
+// requestMediaType return the media type of request body based on the
+// request type.
+func (gen *openapiGenerator) requestMediaType(reqtype RequestType) string {
+	var contentType = reqtype.String()
+	if len(contentType) == 0 {
+		return ContentTypeBinary
+	}
+	return contentType
+}
+
+// queryParameters return the fields of struct rtype as the query
+// parameters.
+func (gen *openapiGenerator) queryParameters(rtype reflect.Type) (params []*OpenAPIParameter) {
+	for rtype.Kind() == reflect.Pointer {
+		rtype = rtype.Elem()
+	}
+	if rtype.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var field reflect.StructField
+	for _, field = range reflect.VisibleFields(rtype) {
+		if field.Anonymous {
+			continue
+		}
+		var key, _, hasTag = libreflect.Tag(field, structTagKey)
+		if len(key) == 0 {
+			continue
+		}
+		if !hasTag {
+			key = strings.ToLower(key)
+		}
+		params = append(params, &OpenAPIParameter{
+			Name:   key,
+			In:     `query`,
+			Schema: gen.schemaOf(field.Type),
+		})
+	}
+	return params
+}
+
+// schemaOf convert the Go type into JSON Schema.
+// The named struct is stored in the components and referenced.
+func (gen *openapiGenerator) schemaOf(rtype reflect.Type) (schema *OpenAPISchema) {
+	for rtype.Kind() == reflect.Pointer {
+		rtype = rtype.Elem()
+	}
+	if rtype == timeType {
+		return &OpenAPISchema{Type: `string`, Format: `date-time`}
+	}
+
+	switch rtype.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: `boolean`}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint,
+		reflect.Uint8, reflect.Uint16:
+		return &OpenAPISchema{Type: `integer`}
+	case reflect.Int32, reflect.Uint32:
+		return &OpenAPISchema{Type: `integer`, Format: `int32`}
+	case reflect.Int64, reflect.Uint64:
+		return &OpenAPISchema{Type: `integer`, Format: `int64`}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: `number`, Format: `float`}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: `number`, Format: `double`}
+	case reflect.String:
+		return &OpenAPISchema{Type: `string`}
+	case reflect.Slice, reflect.Array:
+		if rtype.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: `string`, Format: `byte`}
+		}
+		return &OpenAPISchema{
+			Type:  `array`,
+			Items: gen.schemaOf(rtype.Elem()),
+		}
+	case reflect.Map:
+		return &OpenAPISchema{
+			Type:                 `object`,
+			AdditionalProperties: gen.schemaOf(rtype.Elem()),
+		}
+	case reflect.Struct:
+		if len(rtype.Name()) == 0 {
+			return gen.schemaStruct(rtype)
+		}
+		var name = openapiSchemaName(rtype)
+		var ref = &OpenAPISchema{Ref: `#/components/schemas/` + name}
+		if _, ok := gen.schemas[name]; ok {
+			return ref
+		}
+		// Store the placeholder first to handle recursive type.
+		gen.schemas[name] = nil
+		gen.schemas[name] = gen.schemaStruct(rtype)
+		return ref
+	}
+	// Interface, function, channel, and other types accept any value.
+	return &OpenAPISchema{}
+}
+
+// schemaStruct convert the struct fields into object properties, using
+// the tag "json" as the property name.
+func (gen *openapiGenerator) schemaStruct(rtype reflect.Type) (schema *OpenAPISchema) {
+	schema = &OpenAPISchema{
+		Type:       `object`,
+		Properties: make(map[string]*OpenAPISchema),
+	}
+
+	var field reflect.StructField
+	for _, field = range reflect.VisibleFields(rtype) {
+		var key, opts, hasTag = libreflect.Tag(field, `json`)
+		if len(key) == 0 {
+			continue
+		}
+		if field.Anonymous && !hasTag {
+			// The fields of embedded struct is promoted.
+			continue
+		}
+		if len(field.Index) > 1 && !isPromoted(rtype, field) {
+			continue
+		}
+
+		var fschema = gen.schemaOf(field.Type)
+		if libstrings.IsContain(opts, `string`) {
+			fschema = &OpenAPISchema{Type: `string`}
+		}
+		schema.Properties[key] = fschema
+	}
+	return schema
+}
+
+// isPromoted return true if the field inside embedded struct is promoted
+// into rtype, where all of the embedded struct in its path does not have
+// tag "json".
+func isPromoted(rtype reflect.Type, field reflect.StructField) bool {
+	var (
+		x  int
+		sf reflect.StructField
+	)
+	for x = range len(field.Index) - 1 {
+		for rtype.Kind() == reflect.Pointer {
+			rtype = rtype.Elem()
+		}
+		sf = rtype.Field(field.Index[x])
+		var _, _, hasTag = libreflect.Tag(sf, `json`)
+		if hasTag {
+			return false
+		}
+		rtype = sf.Type
+	}
+	return true
+}
+
+// openapiSchemaName return the name of schema from the package and type
+// name, with characters other than letters, digits, ".", "-", and "_"
+// replaced with "_".
+func openapiSchemaName(rtype reflect.Type) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z',
+			r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, rtype.String())
+}
+
+// pathParameters return the keys in route as the path parameters.
+func pathParameters(rute *route) (params []*OpenAPIParameter) {
+	var key string
+	for _, key = range rute.Keys() {
+		params = append(params, &OpenAPIParameter{
+			Name:     key,
+			In:       `path`,
+			Required: true,
+			Schema:   &OpenAPISchema{Type: `string`},
+		})
+	}
+	return params
+}
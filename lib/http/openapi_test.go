@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+type testOpenAPIAuthor struct {
+	Name  string             `json:"name"`
+	Books []*testOpenAPIBook `json:"books,omitempty"`
+}
+
+type testOpenAPIBase struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id,string"`
+}
+
+type testOpenAPIBook struct {
+	testOpenAPIBase
+
+	Author *testOpenAPIAuthor `json:"author"`
+	Meta   map[string]any     `json:"meta"`
+	Title  string             `json:"title"`
+	Hidden string             `json:"-"`
+	Cover  []byte             `json:"cover"`
+	Price  float64            `json:"price"`
+}
+
+type testOpenAPIQuery struct {
+	Title  string `form:"title"`
+	Limit  int
+	Sorted bool `form:"sorted"`
+}
+
+func TestServer_OpenAPI(t *testing.T) {
+	var (
+		opts = ServerOptions{
+			BasePath: `/api`,
+			OpenAPI: OpenAPIOptions{
+				Path:        `/openapi.json`,
+				Title:       `Library`,
+				Description: `Library of books.`,
+				Version:     `1.0.0`,
+			},
+		}
+
+		srv *Server
+		err error
+	)
+
+	srv, err = NewServer(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var listEndpoint = []Endpoint{{
+		Method:       RequestMethodGet,
+		Path:         `/book`,
+		ResponseType: ResponseTypeJSON,
+		Description:  `Search books.`,
+		Request:      testOpenAPIQuery{},
+		Response:     []testOpenAPIBook{},
+	}, {
+		Method:       RequestMethodGet,
+		Path:         `/book/:id`,
+		ResponseType: ResponseTypeJSON,
+		Response:     &testOpenAPIBook{},
+	}, {
+		Method:       RequestMethodPost,
+		Path:         `/book`,
+		RequestType:  RequestTypeJSON,
+		ResponseType: ResponseTypeJSON,
+		Request:      &testOpenAPIBook{},
+		Response:     &testOpenAPIBook{},
+	}, {
+		Method:       RequestMethodDelete,
+		Path:         `/book/:id`,
+		ResponseType: ResponseTypeNone,
+	}}
+
+	var ep Endpoint
+	for _, ep = range listEndpoint {
+		ep.Call = func(_ *EndpointRequest) ([]byte, error) {
+			return nil, nil
+		}
+		err = srv.RegisterEndpoint(ep)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = srv.RegisterSSE(SSEEndpoint{
+		Path: `/book/:id/events`,
+		Call: func(_ *SSEConn) {},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tdata *test.Data
+
+	tdata, err = test.LoadData(`testdata/Server_OpenAPI_test.txt`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []byte
+
+	got, err = json.MarshalIndent(srv.OpenAPI(), ``, `  `)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `OpenAPI`, string(tdata.Output[`document`]), string(got))
+
+	// The document is served on the OpenAPI.Path.
+
+	var (
+		httpReq = httptest.NewRequest(http.MethodGet, `/api/openapi.json`, nil)
+		rec     = httptest.NewRecorder()
+		gotDoc  OpenAPIDocument
+	)
+
+	srv.ServeHTTP(rec, httpReq)
+	test.Assert(t, `status`, http.StatusOK, rec.Code)
+
+	err = json.Unmarshal(rec.Body.Bytes(), &gotDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `served document`, *srv.OpenAPI(), gotDoc)
+}
+
+func TestOpenapiPath(t *testing.T) {
+	var listCase = []struct {
+		rpath string
+		exp   string
+	}{{
+		rpath: ``,
+		exp:   `/`,
+	}, {
+		rpath: `/`,
+		exp:   `/`,
+	}, {
+		rpath: `/book/:id`,
+		exp:   `/book/{id}`,
+	}, {
+		rpath: `/:a/b/:c`,
+		exp:   `/{a}/b/{c}`,
+	}}
+	for _, c := range listCase {
+		test.Assert(t, c.rpath, c.exp, openapiPath(c.rpath))
+	}
+}
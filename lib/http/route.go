@@ -20,6 +20,9 @@ type route struct {
 	endpoint    *Endpoint    // endpoint of route.
 	endpointSSE *SSEEndpoint // Endpoint for SSE.
 
+	// group of route, nil if the route registered directly to Server.
+	group *RouteGroup
+
 	kind int
 }
 
@@ -34,6 +37,7 @@ type route struct {
 func newRoute(ep *Endpoint) (rute *route, err error) {
 	rute = &route{
 		endpoint: ep,
+		group:    ep.group,
 	}
 	if ep.ErrorHandler == nil {
 		ep.ErrorHandler = DefaultErrorHandler
@@ -49,6 +53,7 @@ func newRoute(ep *Endpoint) (rute *route, err error) {
 func newRouteSSE(ep *SSEEndpoint) (rute *route, err error) {
 	rute = &route{
 		endpointSSE: ep,
+		group:       ep.group,
 		kind:        routeKindSSE,
 	}
 	rute.Route, err = libpath.NewRoute(ep.Path)
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package http
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+)
+
+// RouteGroup define a group of routes that share the same path prefix,
+// evaluators, and middlewares.
+//
+// The evaluators in the group are called after the server evaluators and
+// before the [Endpoint.Eval].
+// The middlewares in the group wrap the Endpoint, SSEEndpoint, and the
+// request to [Server.HandleFS] under the group prefix, after the server
+// middlewares.
+//
+// A RouteGroup is created using [Server.Group] or [RouteGroup.Group].
+type RouteGroup struct {
+	srv    *Server
+	parent *RouteGroup
+
+	prefix string
+
+	evals []Evaluator
+	mws   []Middleware
+}
+
+// Group create new sub group with the path prefix relative to this group
+// prefix.
+// The sub group inherit the evaluators and middlewares from this group.
+func (group *RouteGroup) Group(prefix string, mws ...Middleware) *RouteGroup {
+	var sub = group.srv.newRouteGroup(path.Join(group.prefix, prefix), mws)
+	sub.parent = group
+	return sub
+}
+
+// Prefix return the full path prefix of the group.
+func (group *RouteGroup) Prefix() string {
+	return group.prefix
+}
+
+// RegisterEndpoint register the [Endpoint] with path prefixed by the group
+// prefix.
+// See [Server.RegisterEndpoint] for more information.
+func (group *RouteGroup) RegisterEndpoint(ep Endpoint) (err error) {
+	ep.Path = group.path(ep.Path)
+	ep.group = group
+
+	err = group.srv.RegisterEndpoint(ep)
+	if err != nil {
+		return fmt.Errorf(`RouteGroup: %w`, err)
+	}
+	return nil
+}
+
+// RegisterEvaluator register the evaluator that will be called after the
+// server and parent group evaluators, for each Endpoint and SSEEndpoint in
+// this group and its sub groups.
+func (group *RouteGroup) RegisterEvaluator(eval Evaluator) {
+	group.evals = append(group.evals, eval)
+}
+
+// RegisterHandleFunc register a pattern with a handler, with path
+// prefixed by the group prefix.
+// See [Server.RegisterHandleFunc] for more information.
+//
+// If the METHOD and/or PATH is already registered it will panic.
+func (group *RouteGroup) RegisterHandleFunc(
+	pattern string,
+	handler func(http.ResponseWriter, *http.Request),
+) {
+	var (
+		logp = `RouteGroup.RegisterHandleFunc`
+		ep   = newHandleFuncEndpoint(pattern, handler)
+		err  = group.RegisterEndpoint(ep)
+	)
+	if err != nil {
+		log.Panicf(`%s: %s %q`, logp, err, group.path(ep.Path))
+	}
+}
+
+// RegisterMiddleware register the middleware that wrap each Endpoint,
+// SSEEndpoint, and HandleFS under this group and its sub groups.
+// The middleware is called after the server and parent group
+// middlewares, in the order they are registered.
+func (group *RouteGroup) RegisterMiddleware(mw Middleware) {
+	group.mws = append(group.mws, mw)
+}
+
+// RegisterSSE register the [SSEEndpoint] with path prefixed by the group
+// prefix.
+// See [Server.RegisterSSE] for more information.
+func (group *RouteGroup) RegisterSSE(ep SSEEndpoint) (err error) {
+	ep.Path = group.path(ep.Path)
+	ep.group = group
+
+	err = group.srv.RegisterSSE(ep)
+	if err != nil {
+		return fmt.Errorf(`RouteGroup: %w`, err)
+	}
+	return nil
+}
+
+// evaluators return the list of evaluators from server, parent groups,
+// and this group.
+func (group *RouteGroup) evaluators(evals []Evaluator) []Evaluator {
+	if group == nil {
+		return evals
+	}
+	return slices.Concat(group.parent.evaluators(evals), group.evals)
+}
+
+// isMatch return true if the request path is equal to the group prefix or
+// inside it.
+func (group *RouteGroup) isMatch(reqPath string) bool {
+	if group.prefix == `/` {
+		return true
+	}
+	if !strings.HasPrefix(reqPath, group.prefix) {
+		return false
+	}
+	return len(reqPath) == len(group.prefix) || reqPath[len(group.prefix)] == '/'
+}
+
+// path return the p prefixed with the group prefix.
+func (group *RouteGroup) path(p string) string {
+	return path.Join(group.prefix, `/`+p)
+}
+
+// wrap the handler h with the middlewares in this group and its parent.
+func (group *RouteGroup) wrap(h http.Handler) http.Handler {
+	if group == nil {
+		return h
+	}
+	h = wrapMiddlewares(h, group.mws)
+	return group.parent.wrap(h)
+}
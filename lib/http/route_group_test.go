@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	liberrors "git.sr.ht/~shulhan/pakakeh.go/lib/errors"
+	"git.sr.ht/~shulhan/pakakeh.go/lib/memfs"
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestServer_Group(t *testing.T) {
+	var (
+		dir = t.TempDir()
+
+		mfs *memfs.MemFS
+		err error
+	)
+
+	err = os.MkdirAll(filepath.Join(dir, `admin`), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, `admin`, `index.html`), []byte(`admin`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, `index.html`), []byte(`index`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mfs, err = memfs.New(&memfs.Options{
+		Root: dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var srv *Server
+
+	srv, err = NewServer(ServerOptions{
+		Memfs: mfs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The trace record the order of middlewares and handler being
+	// called.
+	var trace []string
+
+	var newTraceMiddleware = func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+				trace = append(trace, name+`:before`)
+				next.ServeHTTP(res, req)
+				trace = append(trace, name+`:after`)
+			})
+		}
+	}
+
+	srv.RegisterMiddleware(newTraceMiddleware(`server`))
+
+	var (
+		groupAPI   = srv.Group(`/api`, newTraceMiddleware(`api`))
+		groupAdmin = groupAPI.Group(`admin`)
+	)
+
+	groupAdmin.RegisterMiddleware(newTraceMiddleware(`admin`))
+	groupAdmin.RegisterEvaluator(func(req *http.Request, _ []byte) error {
+		trace = append(trace, `admin:eval`)
+		if req.Header.Get(`X-Admin`) != `1` {
+			return &liberrors.E{
+				Code:    http.StatusForbidden,
+				Message: `forbidden`,
+			}
+		}
+		return nil
+	})
+
+	var callUser = func(_ *EndpointRequest) ([]byte, error) {
+		trace = append(trace, `call`)
+		return []byte(`ok`), nil
+	}
+
+	err = groupAPI.RegisterEndpoint(Endpoint{
+		Path:         `/user`,
+		ResponseType: ResponseTypePlain,
+		Call:         callUser,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = groupAdmin.RegisterEndpoint(Endpoint{
+		Method:       RequestMethodPost,
+		Path:         `/user`,
+		ResponseType: ResponseTypePlain,
+		Call:         callUser,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	groupAdmin.RegisterHandleFunc(`GET /stat`, func(res http.ResponseWriter, _ *http.Request) {
+		trace = append(trace, `call`)
+		res.WriteHeader(http.StatusNoContent)
+	})
+
+	// Register the same endpoint through group return an error.
+	err = groupAPI.RegisterEndpoint(Endpoint{
+		Path: `user`,
+		Call: callUser,
+	})
+	test.Assert(t, `ambiguous`,
+		`RouteGroup: RegisterEndpoint: ambigous endpoint`, err.Error())
+
+	test.Assert(t, `Prefix`, `/api/admin`, groupAdmin.Prefix())
+
+	// The SSE endpoint is also wrapped by the group middlewares.
+	err = groupAPI.RegisterSSE(SSEEndpoint{
+		Path: `/events`,
+		Call: func(_ *SSEConn) {},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Request to HandleFS under /admin is wrapped by the group
+	// middlewares.
+	srv.Group(`/admin`, newTraceMiddleware(`fs`))
+
+	type testCase struct {
+		header   http.Header
+		desc     string
+		method   string
+		target   string
+		expTrace string
+		expCode  int
+	}
+
+	var listCase = []testCase{{
+		desc:     `GET /api/user`,
+		method:   http.MethodGet,
+		target:   `/api/user`,
+		expTrace: `server:before api:before call api:after server:after`,
+		expCode:  http.StatusOK,
+	}, {
+		desc:     `HEAD /api/user`,
+		method:   http.MethodHead,
+		target:   `/api/user`,
+		expTrace: `server:before api:before api:after server:after`,
+		expCode:  http.StatusOK,
+	}, {
+		desc:   `POST /api/admin/user`,
+		method: http.MethodPost,
+		target: `/api/admin/user`,
+		header: http.Header{
+			`X-Admin`: []string{`1`},
+		},
+		expTrace: `server:before api:before admin:before admin:eval call ` +
+			`admin:after api:after server:after`,
+		expCode: http.StatusOK,
+	}, {
+		desc:   `POST /api/admin/user without access`,
+		method: http.MethodPost,
+		target: `/api/admin/user`,
+		expTrace: `server:before api:before admin:before admin:eval ` +
+			`admin:after api:after server:after`,
+		expCode: http.StatusForbidden,
+	}, {
+		desc:   `GET /api/admin/stat`,
+		method: http.MethodGet,
+		target: `/api/admin/stat`,
+		header: http.Header{
+			`X-Admin`: []string{`1`},
+		},
+		expTrace: `server:before api:before admin:before admin:eval call ` +
+			`admin:after api:after server:after`,
+		expCode: http.StatusNoContent,
+	}, {
+		// The httptest.ResponseRecorder does not implement
+		// http.Hijacker.
+		desc:     `GET /api/events`,
+		method:   http.MethodGet,
+		target:   `/api/events`,
+		expTrace: `server:before api:before api:after server:after`,
+		expCode:  http.StatusInternalServerError,
+	}, {
+		desc:     `GET /admin/ from HandleFS`,
+		method:   http.MethodGet,
+		target:   `/admin/`,
+		expTrace: `server:before fs:before fs:after server:after`,
+		expCode:  http.StatusOK,
+	}, {
+		desc:     `GET /administrator not in group`,
+		method:   http.MethodGet,
+		target:   `/administrator`,
+		expTrace: `server:before server:after`,
+		expCode:  http.StatusNotFound,
+	}, {
+		desc:     `GET /api/notfound`,
+		method:   http.MethodGet,
+		target:   `/api/notfound`,
+		expTrace: `server:before api:before api:after server:after`,
+		expCode:  http.StatusNotFound,
+	}}
+
+	var c testCase
+	for _, c = range listCase {
+		var (
+			httpReq = httptest.NewRequest(c.method, c.target, nil)
+			rec     = httptest.NewRecorder()
+		)
+		if c.header != nil {
+			httpReq.Header = c.header
+		}
+		trace = nil
+
+		srv.ServeHTTP(rec, httpReq)
+
+		test.Assert(t, c.desc+`: status`, c.expCode, rec.Code)
+		test.Assert(t, c.desc+`: trace`, c.expTrace, strings.Join(trace, ` `))
+	}
+}
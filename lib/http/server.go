@@ -14,6 +14,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -36,6 +37,8 @@ type Server struct {
 	shutdownIdleTimer *time.Timer
 
 	evals        []Evaluator
+	mws          []Middleware
+	groups       []*RouteGroup
 	routeDeletes []*route
 	routeGets    []*route
 	routePatches []*route
@@ -75,10 +78,48 @@ func NewServer(opts ServerOptions) (srv *Server, err error) {
 			return nil, fmt.Errorf("NewServer: %w", err)
 		}
 	}
+	err = srv.Options.Compress.init()
+	if err != nil {
+		return nil, fmt.Errorf("NewServer: %w", err)
+	}
+	srv.Options.CacheControl, err = initCacheControl(srv.Options.CacheControl)
+	if err != nil {
+		return nil, fmt.Errorf("NewServer: %w", err)
+	}
+	if len(srv.Options.OpenAPI.Path) != 0 {
+		err = srv.registerGet(&Endpoint{
+			Path:         srv.Options.OpenAPI.Path,
+			ResponseType: ResponseTypeJSON,
+			Call:         srv.handleOpenAPI,
+			isOpenAPI:    true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("NewServer: %w", err)
+		}
+	}
 
 	return srv, nil
 }
 
+// Group create new [RouteGroup] with the path prefix and optional list of
+// middlewares.
+// Each Endpoint and SSEEndpoint registered through the group has its path
+// prefixed with the prefix, and the request to [Server.HandleFS] under the
+// prefix is wrapped with the group middlewares.
+func (srv *Server) Group(prefix string, mws ...Middleware) *RouteGroup {
+	return srv.newRouteGroup(path.Join(`/`, prefix), mws)
+}
+
+func (srv *Server) newRouteGroup(prefix string, mws []Middleware) (group *RouteGroup) {
+	group = &RouteGroup{
+		srv:    srv,
+		prefix: prefix,
+		mws:    slices.Clone(mws),
+	}
+	srv.groups = append(srv.groups, group)
+	return group
+}
+
 // RedirectTemp make the request to temporary redirect (307) to new URL.
 func (srv *Server) RedirectTemp(res http.ResponseWriter, redirectURL string) {
 	if len(redirectURL) == 0 {
@@ -158,25 +199,34 @@ func (srv *Server) RegisterHandleFunc(
 	handler func(http.ResponseWriter, *http.Request),
 ) {
 	var (
-		logp       = `RegisterHandleFunc`
-		methodPath = strings.Fields(pattern)
-		ep         = Endpoint{
-			Call: func(epr *EndpointRequest) (resp []byte, err error) {
-				handler(epr.HTTPWriter, epr.HTTPRequest)
-				return nil, nil
-			},
-		}
+		logp = `RegisterHandleFunc`
+		ep   = newHandleFuncEndpoint(pattern, handler)
+		err  = srv.RegisterEndpoint(ep)
 	)
+	if err != nil {
+		log.Panicf(`%s: %s %q`, logp, err, ep.Path)
+	}
+}
+
+// newHandleFuncEndpoint create new Endpoint from pattern and handler.
+// See [Server.RegisterHandleFunc] for the format of pattern.
+func newHandleFuncEndpoint(
+	pattern string,
+	handler func(http.ResponseWriter, *http.Request),
+) (ep Endpoint) {
+	var methodPath = strings.Fields(pattern)
+
+	ep.Call = func(epr *EndpointRequest) (resp []byte, err error) {
+		handler(epr.HTTPWriter, epr.HTTPRequest)
+		return nil, nil
+	}
 	if len(methodPath) == 1 {
 		ep.Path = methodPath[0]
 	} else if len(methodPath) > 1 {
 		ep.Method = RequestMethod(strings.ToUpper(methodPath[0]))
 		ep.Path = methodPath[1]
 	}
-	var err = srv.RegisterEndpoint(ep)
-	if err != nil {
-		log.Panicf(`%s: %s %q`, logp, err, ep.Path)
-	}
+	return ep
 }
 
 // RegisterSSE register Server-Sent Events endpoint.
@@ -240,6 +290,14 @@ func (srv *Server) RegisterEvaluator(eval Evaluator) {
 	srv.evals = append(srv.evals, eval)
 }
 
+// RegisterMiddleware register the middleware that wrap all request to
+// server, including the request that does not match with any route.
+// The middleware is called in the order they are registered, before the
+// middlewares in [RouteGroup].
+func (srv *Server) RegisterMiddleware(mw Middleware) {
+	srv.mws = append(srv.mws, mw)
+}
+
 // registerGet register HTTP method GET with callback to handle it.
 func (srv *Server) registerGet(ep *Endpoint) (err error) {
 	ep.Method = RequestMethodGet
@@ -335,6 +393,37 @@ func (srv *Server) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 
 	req.URL.Path = strings.TrimPrefix(req.URL.Path, srv.Options.BasePath)
 
+	if len(srv.mws) == 0 {
+		srv.dispatch(res, req)
+		return
+	}
+	wrapMiddlewares(http.HandlerFunc(srv.dispatch), srv.mws).ServeHTTP(res, req)
+}
+
+// dispatch the request to the handler based on the request method.
+func (srv *Server) dispatch(res http.ResponseWriter, req *http.Request) {
+	if srv.Options.Compress.Enable && req.Method != http.MethodHead &&
+		req.Method != http.MethodOptions {
+		var encoding = negotiateEncoding(
+			req.Header.Get(HeaderAcceptEncoding),
+			srv.Options.Compress.Encodings)
+		if len(encoding) != 0 {
+			var cw = newCompressWriter(res, &srv.Options.Compress, encoding)
+			defer cw.close()
+			res = cw
+		}
+	}
+	if len(srv.Options.CacheControl) != 0 &&
+		(req.Method == http.MethodGet || req.Method == http.MethodHead) {
+		var value = findCacheControl(srv.Options.CacheControl, req.URL.Path)
+		if len(value) != 0 {
+			res = &cacheControlWriter{
+				ResponseWriter: res,
+				value:          value,
+			}
+		}
+	}
+
 	switch req.Method {
 	case http.MethodDelete:
 		srv.handleDelete(res, req)
@@ -502,7 +591,7 @@ func (srv *Server) handleDelete(res http.ResponseWriter, req *http.Request) {
 	for _, rute := range srv.routeDeletes {
 		vals, ok := rute.Parse(req.URL.Path)
 		if ok {
-			rute.endpoint.call(res, req, srv.evals, vals)
+			srv.handleRoute(res, req, rute, vals)
 			return
 		}
 	}
@@ -520,6 +609,15 @@ func (srv *Server) handleDelete(res http.ResponseWriter, req *http.Request) {
 // header Content-Type set accordingly to the detected file type and the
 // response body set to the content of file.
 //
+// The response header ETag is set to the strong entity tag of node content,
+// or the weak entity tag generated from modification time and size if the
+// content is not loaded into memory, and the header Last-Modified is set to
+// the node modification time.
+// If the request header If-None-Match or If-Modified-Since match with them,
+// it will return 304 Not Modified without body.
+// If the request has header Range with If-Range that does not match, the
+// full content is returned instead.
+//
 // If the request Method is HEAD, only the header will be sent back to client.
 //
 // If the request Path is not exist it will return 404 Not Found.
@@ -550,38 +648,59 @@ func (srv *Server) HandleFS(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	res.Header().Set(HeaderContentType, node.ContentType)
-
-	var nodeModtime = node.ModTime().Unix()
-
 	var (
-		responseETag = strconv.FormatInt(nodeModtime, 10)
-		requestETag  = req.Header.Get(HeaderIfNoneMatch)
+		header  = res.Header()
+		modtime = node.ModTime()
+		etag    = node.ETag()
 	)
-	if !node.IsDir() && requestETag == responseETag {
-		res.WriteHeader(http.StatusNotModified)
-		return
+	if len(etag) == 0 {
+		etag = etagModTime(modtime, node.Size())
 	}
 
-	var ifModifiedSince = req.Header.Get(HeaderIfModifiedSince)
-	if len(ifModifiedSince) != 0 {
-		timeModsince, err := time.Parse(time.RFC1123, ifModifiedSince)
-		if err == nil {
-			if nodeModtime <= timeModsince.Unix() {
-				res.WriteHeader(http.StatusNotModified)
-				return
-			}
-		}
+	header.Set(HeaderContentType, node.ContentType)
+	header.Set(HeaderETag, etag)
+	if !modtime.IsZero() {
+		header.Set(HeaderLastModified, modtime.UTC().Format(http.TimeFormat))
+	}
+
+	// The client may have the entity tag of precompressed content or
+	// the content that is compressed on the fly.
+	var etagEncodings = slices.Concat(node.Encodings(), srv.compressEncodings())
+
+	if isNotModified(req, etag, modtime, etagEncodings) {
+		res.WriteHeader(http.StatusNotModified)
+		return
 	}
 
 	var (
+		reqRange   = req.Header.Get(HeaderRange)
 		bodyReader io.ReadSeeker
 		size       int64
 	)
+	if len(reqRange) != 0 && !isRangeValid(req, etag, modtime) {
+		// The content has been changed, send the full content
+		// instead.
+		reqRange = ``
+	}
 
 	if len(node.Content) > 0 {
 		bodyReader = bytes.NewReader(node.Content)
 		size = node.Size()
+
+		// Serve the precompressed content, if the client accept one
+		// of them and its not a range request.
+		var encodings = node.Encodings()
+		if len(encodings) != 0 {
+			header.Add(HeaderVary, HeaderAcceptEncoding)
+		}
+		var encoding = negotiateEncoding(req.Header.Get(HeaderAcceptEncoding), encodings)
+		if len(encoding) != 0 && len(reqRange) == 0 {
+			var content = node.EncodedContent(encoding)
+			header.Set(HeaderContentEncoding, encoding)
+			header.Set(HeaderETag, etagEncoded(etag, encoding))
+			bodyReader = bytes.NewReader(content)
+			size = int64(len(content))
+		}
 	} else {
 		f, err := os.Open(node.SysPath)
 		if err != nil {
@@ -601,17 +720,14 @@ func (srv *Server) HandleFS(res http.ResponseWriter, req *http.Request) {
 		size = fstat.Size()
 	}
 
-	res.Header().Set(HeaderETag, responseETag)
-
 	if req.Method == http.MethodHead {
 		var sizeStr = strconv.FormatInt(size, 10)
-		res.Header().Set(HeaderContentLength, sizeStr)
-		res.Header().Set(HeaderAcceptRanges, AcceptRangesBytes)
+		header.Set(HeaderContentLength, sizeStr)
+		header.Set(HeaderAcceptRanges, AcceptRangesBytes)
 		res.WriteHeader(http.StatusOK)
 		return
 	}
 
-	var reqRange = req.Header.Get(HeaderRange)
 	if len(reqRange) != 0 {
 		handleRange(res, req, bodyReader, ``, reqRange)
 		return
@@ -633,18 +749,74 @@ func (srv *Server) handleGet(res http.ResponseWriter, req *http.Request) {
 		if !ok {
 			continue
 		}
-		if rute.kind == routeKindHTTP {
-			rute.endpoint.call(res, req, srv.evals, vals)
-			return
-		}
-		if rute.kind == routeKindSSE {
-			rute.endpointSSE.call(res, req, srv.evals, vals)
+		if rute.kind == routeKindHTTP || rute.kind == routeKindSSE {
+			srv.handleRoute(res, req, rute, vals)
 			return
 		}
 		// Unknown kind will be handled by HandleFS.
 	}
 
-	srv.HandleFS(res, req)
+	srv.handleFS(res, req)
+}
+
+// handleFS call the HandleFS wrapped with the middlewares of group that
+// match with the request path.
+func (srv *Server) handleFS(res http.ResponseWriter, req *http.Request) {
+	var group = srv.findGroup(req.URL.Path)
+	if group == nil {
+		srv.HandleFS(res, req)
+		return
+	}
+	group.wrap(http.HandlerFunc(srv.HandleFS)).ServeHTTP(res, req)
+}
+
+// compressEncodings return the list of content encoding that the server
+// use to compress the response on the fly, or nil if the compression is
+// not enabled.
+func (srv *Server) compressEncodings() []string {
+	if !srv.Options.Compress.Enable {
+		return nil
+	}
+	return srv.Options.Compress.Encodings
+}
+
+// findGroup return the group with the longest prefix that match with the
+// request path, or nil if none of them match.
+func (srv *Server) findGroup(reqPath string) (found *RouteGroup) {
+	var group *RouteGroup
+	for _, group = range srv.groups {
+		if !group.isMatch(reqPath) {
+			continue
+		}
+		if found == nil || len(group.prefix) > len(found.prefix) {
+			found = group
+		}
+	}
+	return found
+}
+
+// handleRoute call the endpoint in route, wrapped with the middlewares of
+// the route group.
+func (srv *Server) handleRoute(
+	res http.ResponseWriter,
+	req *http.Request,
+	rute *route,
+	vals map[string]string,
+) {
+	var (
+		evals   = rute.group.evaluators(srv.evals)
+		handler http.Handler
+	)
+	if rute.kind == routeKindSSE {
+		handler = http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			rute.endpointSSE.call(res, req, evals, vals)
+		})
+	} else {
+		handler = http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			rute.endpoint.call(res, req, evals, vals, srv.compressEncodings())
+		})
+	}
+	rute.group.wrap(handler).ServeHTTP(res, req)
 }
 
 // handleHead handle HTTP method [HEAD] request.
@@ -664,11 +836,19 @@ func (srv *Server) handleHead(res http.ResponseWriter, req *http.Request) {
 		}
 	}
 	if !ok {
-		srv.HandleFS(res, req)
+		srv.handleFS(res, req)
 		return
 	}
 
-	switch rute.endpoint.ResponseType {
+	rute.group.wrap(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		writeHeadResponse(res, rute.endpoint.ResponseType)
+	})).ServeHTTP(res, req)
+}
+
+// writeHeadResponse write the response header for HEAD request based on
+// the endpoint response type.
+func writeHeadResponse(res http.ResponseWriter, responseType ResponseType) {
+	switch responseType {
 	case ResponseTypeNone:
 		res.WriteHeader(http.StatusNoContent)
 		return
@@ -771,7 +951,7 @@ func (srv *Server) handlePatch(res http.ResponseWriter, req *http.Request) {
 	for _, rute := range srv.routePatches {
 		vals, ok := rute.Parse(req.URL.Path)
 		if ok {
-			rute.endpoint.call(res, req, srv.evals, vals)
+			srv.handleRoute(res, req, rute, vals)
 			return
 		}
 	}
@@ -784,7 +964,7 @@ func (srv *Server) handlePost(res http.ResponseWriter, req *http.Request) {
 	for _, rute := range srv.routePosts {
 		vals, ok := rute.Parse(req.URL.Path)
 		if ok {
-			rute.endpoint.call(res, req, srv.evals, vals)
+			srv.handleRoute(res, req, rute, vals)
 			return
 		}
 	}
@@ -797,7 +977,7 @@ func (srv *Server) handlePut(res http.ResponseWriter, req *http.Request) {
 	for _, rute := range srv.routePuts {
 		vals, ok := rute.Parse(req.URL.Path)
 		if ok {
-			rute.endpoint.call(res, req, srv.evals, vals)
+			srv.handleRoute(res, req, rute, vals)
 			return
 		}
 	}
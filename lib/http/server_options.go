@@ -36,6 +36,17 @@ type ServerOptions struct {
 	// See FSHandler for more information.
 	HandleFS FSHandler
 
+	// CacheControl define the list of policy for setting the response
+	// header Cache-Control on GET and HEAD request, based on the
+	// request path.
+	// The first policy that match with the request path is used.
+	// The header is set only for successful or 304 Not Modified
+	// response, and only if the handler does not set it.
+	CacheControl []CacheControl
+
+	// The options for generating and serving the OpenAPI document.
+	OpenAPI OpenAPIOptions
+
 	// Address define listen address, using ip:port format.
 	// This field is optional, default to ":80".
 	Address string
@@ -64,6 +75,9 @@ type ServerOptions struct {
 	// The options for Cross-Origin Resource Sharing.
 	CORS CORSOptions
 
+	// The options for compressing the response body.
+	Compress CompressOptions
+
 	// ShutdownIdleDuration define the duration where the server will
 	// automatically stop accepting new connection and then shutting down
 	// the server.
@@ -95,4 +109,5 @@ func (opts *ServerOptions) init() {
 	}
 
 	opts.CORS.init()
+	opts.OpenAPI.init()
 }
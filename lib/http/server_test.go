@@ -942,7 +942,7 @@ func TestServer_HandleFS(t *testing.T) {
 		}
 
 		var got []byte
-		got, err = simres.DumpResponse([]string{HeaderETag})
+		got, err = simres.DumpResponse([]string{HeaderETag, HeaderLastModified})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -970,7 +970,7 @@ func TestServer_HandleFS(t *testing.T) {
 		}
 
 		exp := string(tdata.Output[tt.Name()])
-		got, err := simres.DumpResponse([]string{HeaderETag})
+		got, err := simres.DumpResponse([]string{HeaderETag, HeaderLastModified})
 		if err != nil {
 			tt.Fatal(err)
 		}
@@ -1152,7 +1152,7 @@ func TestServer_handleRange(t *testing.T) {
 			ServerURL: testServerURL,
 		}
 		cl          = NewClient(clOpts)
-		skipHeaders = []string{HeaderDate, HeaderETag}
+		skipHeaders = []string{HeaderDate, HeaderETag, HeaderLastModified}
 
 		listTestData []*test.Data
 		tdata        *test.Data
@@ -1265,7 +1265,7 @@ func TestServer_handleRange_HEAD(t *testing.T) {
 	}
 
 	var (
-		skipHeaders = []string{HeaderDate, HeaderETag}
+		skipHeaders = []string{HeaderDate, HeaderETag, HeaderLastModified}
 		got         = dumpHTTPResponse(httpRes, skipHeaders)
 		tag         = `http_headers`
 		exp         = tdata.Output[tag]
@@ -21,6 +21,10 @@ type SSEEndpoint struct {
 	// Call handler that will called when request to Path accepted.
 	Call SSECallback
 
+	// group where the endpoint registered, nil if its registered
+	// directly to Server.
+	group *RouteGroup
+
 	// Path where server accept the request for SSE.
 	Path string
 
@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package http
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	liberrors "git.sr.ht/~shulhan/pakakeh.go/lib/errors"
+)
+
+// errNameInvalidInput define the error name when the request cannot be
+// decoded or validated.
+const errNameInvalidInput = `ERR_INVALID_INPUT`
+
+// TypedCallback define the handler for endpoint created using
+// [NewTypedEndpoint].
+//
+// The req contains the request that has been decoded and validated.
+// The returned res will be encoded based on the
+// [Endpoint.ResponseType].
+// The returned error follow the same rules as in [Callback].
+type TypedCallback[Req, Res any] func(epr *EndpointRequest, req *Req) (res Res, err error)
+
+// NewTypedEndpoint create new [Endpoint] that decode the request into
+// Req, validate it, and pass it to call.
+// The fields in ep is used as is, except Call that is replaced with
+// handler that call the call function.
+//
+// The request is decoded in the following order,
+//
+//   - If the RequestType is [RequestTypeJSON], the request body is
+//     decoded into Req using [json.Unmarshal],
+//   - The path values, query, and form are decoded into Req using
+//     [UnmarshalForm].
+//     This means the path value, for example ":id" in "/book/:id",
+//     override the same field from request body.
+//
+// Once decoded, each field in Req is validated based on the rules in the
+// struct tag "validate", separated by comma.
+// The following rules are supported,
+//
+//   - required: the field must not be empty or zero.
+//   - min=N: for number, the value must be greater or equal to N; for
+//     string, slice, or map, the length must be greater or equal to N.
+//   - max=N: for number, the value must be less or equal to N; for
+//     string, slice, or map, the length must be less or equal to N.
+//   - enum=A|B|C: the value must be one of A, B, or C.
+//   - pattern=REGEX: the value must match with regular expression REGEX.
+//     Since the REGEX may contains comma, this rule must be the last
+//     rule.
+//
+// The rules "min" and "max" are applied even if the field is empty or
+// zero, while "enum" and "pattern" are applied only if the field is not
+// empty.
+// The field with nil pointer is treated as missing, so only "required" is
+// applied, for example to make the "min" and "max" optional.
+// For example,
+//
+//	type BookRequest struct {
+//		Title  string `json:"title" validate:"required,max=128"`
+//		Status string `json:"status" validate:"enum=draft|published"`
+//		ISBN   string `json:"isbn" validate:"pattern=^[0-9-]{10,17}$"`
+//		Year   *int   `json:"year" validate:"min=1900,max=2100"`
+//	}
+//
+// If the request cannot be decoded or validated, the server response
+// with [http.StatusBadRequest] and [EndpointResponse] in JSON, with Data
+// contains list of [FieldError], one for each invalid field.
+// The field name in FieldError is taken from the tag "json" if the
+// RequestType is RequestTypeJSON, otherwise from the tag "form".
+//
+// The res returned from call is encoded based on the ResponseType:
+// [json.Marshal] for [ResponseTypeJSON], [xml.Marshal] for
+// [ResponseTypeXML], and for other types, res is written as is if its
+// []byte or string, using [encoding.BinaryMarshaler] for
+// [ResponseTypeBinary], or using [encoding.TextMarshaler] or [fmt.Sprint]
+// for [ResponseTypeHTML] and [ResponseTypePlain].
+//
+// If the ep.Request or ep.Response is nil, it will be set to zero value
+// of Req and Res for generating the OpenAPI document.
+//
+// It will return an error if the tag "validate" in Req contains invalid
+// rule.
+func NewTypedEndpoint[Req, Res any](ep Endpoint, call TypedCallback[Req, Res]) (
+	_ Endpoint, err error,
+) {
+	var (
+		logp    = `NewTypedEndpoint`
+		reqType = reflect.TypeFor[Req]()
+		tagKey  = structTagKey
+
+		validators []*fieldValidator
+	)
+	if ep.RequestType == RequestTypeJSON {
+		tagKey = `json`
+	}
+
+	validators, err = newValidators(reqType, tagKey, ``, nil)
+	if err != nil {
+		return ep, fmt.Errorf(`%s: %s: %w`, logp, reqType, err)
+	}
+
+	var isForm = reqType.Kind() == reflect.Struct ||
+		reqType.Kind() == reflect.Pointer &&
+			reqType.Elem().Kind() == reflect.Struct
+
+	if ep.Request == nil {
+		var req Req
+		ep.Request = req
+	}
+	if ep.Response == nil {
+		var res Res
+		ep.Response = res
+	}
+
+	ep.Call = func(epr *EndpointRequest) (resBody []byte, err error) {
+		var req = new(Req)
+
+		if epr.Endpoint.RequestType == RequestTypeJSON &&
+			len(epr.RequestBody) != 0 {
+			err = json.Unmarshal(epr.RequestBody, req)
+			if err != nil {
+				return nil, newInvalidInputResponse(
+					`invalid request body: `+err.Error(), nil)
+			}
+		}
+		if isForm && epr.HTTPRequest.Form != nil {
+			err = UnmarshalForm(epr.HTTPRequest.Form, req)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var listErr = validateFields(validators, reflect.ValueOf(req))
+		if len(listErr) != 0 {
+			return nil, newInvalidInputResponse(`invalid input`, listErr)
+		}
+
+		var res Res
+
+		res, err = call(epr, req)
+		if err != nil {
+			return nil, err
+		}
+		return encodeResponse(epr.Endpoint.ResponseType, res)
+	}
+	return ep, nil
+}
+
+// newInvalidInputResponse create the [EndpointResponse] for request that
+// cannot be decoded or validated.
+func newInvalidInputResponse(msg string, listErr []FieldError) *EndpointResponse {
+	var res = &EndpointResponse{
+		E: liberrors.E{
+			Code:    http.StatusBadRequest,
+			Message: msg,
+			Name:    errNameInvalidInput,
+		},
+	}
+	if len(listErr) != 0 {
+		res.Data = listErr
+	}
+	return res
+}
+
+// encodeResponse encode the res based on the response type.
+func encodeResponse(restype ResponseType, res any) (resBody []byte, err error) {
+	var logp = `encodeResponse`
+
+	switch restype {
+	case ResponseTypeNone:
+		return nil, nil
+	case ResponseTypeJSON:
+		resBody, err = json.Marshal(res)
+	case ResponseTypeXML:
+		resBody, err = xml.Marshal(res)
+	default:
+		switch v := res.(type) {
+		case []byte:
+			return v, nil
+		case string:
+			return []byte(v), nil
+		}
+		if restype == ResponseTypeBinary {
+			var bm, ok = res.(encoding.BinaryMarshaler)
+			if !ok {
+				return nil, fmt.Errorf(`%s: cannot encode %T as binary`, logp, res)
+			}
+			resBody, err = bm.MarshalBinary()
+		} else if tm, ok := res.(encoding.TextMarshaler); ok {
+			resBody, err = tm.MarshalText()
+		} else {
+			resBody = fmt.Append(nil, res)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+	return resBody, nil
+}
@@ -0,0 +1,305 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+type testTypedAuthor struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type testTypedBook struct {
+	Author *testTypedAuthor `json:"author"`
+	Title  string           `json:"title" validate:"required,max=8"`
+	Status string           `json:"status" validate:"enum=draft|published"`
+	ISBN   string           `json:"isbn" validate:"pattern=^[0-9]{3},[0-9]+$"`
+	Tags   []string         `json:"tags" validate:"max=2"`
+	ID     int              `json:"id" form:"id"`
+	Year   *int             `json:"year" validate:"min=1900,max=2100"`
+}
+
+type testTypedQuery struct {
+	Sort  string `form:"sort" validate:"enum=asc|desc"`
+	Limit int    `form:"limit" validate:"required,min=1,max=100"`
+}
+
+func TestNewTypedEndpoint(t *testing.T) {
+	var (
+		srv *Server
+		err error
+	)
+
+	srv, err = NewServer(ServerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ep Endpoint
+
+	ep, err = NewTypedEndpoint(Endpoint{
+		Method:       RequestMethodPut,
+		Path:         `/book/:id`,
+		RequestType:  RequestTypeJSON,
+		ResponseType: ResponseTypeJSON,
+	}, func(_ *EndpointRequest, req *testTypedBook) (*testTypedBook, error) {
+		return req, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = srv.RegisterEndpoint(ep)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ep, err = NewTypedEndpoint(Endpoint{
+		Method:       RequestMethodGet,
+		Path:         `/book`,
+		ResponseType: ResponseTypePlain,
+	}, func(_ *EndpointRequest, req *testTypedQuery) (string, error) {
+		return `limit=` + strings.Repeat(`*`, req.Limit) + ` sort=` + req.Sort, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = srv.RegisterEndpoint(ep)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		desc    string
+		method  string
+		target  string
+		body    string
+		expBody string
+		expCode int
+	}
+
+	var listCase = []testCase{{
+		desc:    `JSON: valid`,
+		method:  http.MethodPut,
+		target:  `/book/10`,
+		body:    `{"id":1,"title":"Go","status":"draft","isbn":"123,45","year":2026,"author":{"name":"Me"}}`,
+		expCode: http.StatusOK,
+		expBody: `{"author":{"name":"Me"},"title":"Go","status":"draft","isbn":"123,45","tags":null,"id":10,"year":2026}`,
+	}, {
+		desc:    `JSON: invalid body`,
+		method:  http.MethodPut,
+		target:  `/book/10`,
+		body:    `{"title":1}`,
+		expCode: http.StatusBadRequest,
+		expBody: `{"message":"invalid request body: json: cannot unmarshal number into Go struct field testTypedBook.title of type string","name":"ERR_INVALID_INPUT","code":400}`,
+	}, {
+		desc:    `JSON: invalid fields`,
+		method:  http.MethodPut,
+		target:  `/book/10`,
+		body:    `{"status":"x","isbn":"12,3","tags":["a","b","c"],"year":1800,"author":{}}`,
+		expCode: http.StatusBadRequest,
+		expBody: `{"data":[` +
+			`{"field":"author.name","message":"is required"},` +
+			`{"field":"title","message":"is required"},` +
+			`{"field":"status","message":"must be one of draft, published"},` +
+			`{"field":"isbn","message":"must match pattern ^[0-9]{3},[0-9]+$"},` +
+			`{"field":"tags","message":"must have maximum length 2"},` +
+			`{"field":"year","message":"must be greater or equal to 1900"}` +
+			`],"message":"invalid input","name":"ERR_INVALID_INPUT","code":400}`,
+	}, {
+		desc:    `JSON: title too long`,
+		method:  http.MethodPut,
+		target:  `/book/10`,
+		body:    `{"title":"Go is fun!"}`,
+		expCode: http.StatusBadRequest,
+		expBody: `{"data":[{"field":"title","message":"must have maximum length 8"}],"message":"invalid input","name":"ERR_INVALID_INPUT","code":400}`,
+	}, {
+		desc:    `Query: valid`,
+		method:  http.MethodGet,
+		target:  `/book?limit=3&sort=asc`,
+		expCode: http.StatusOK,
+		expBody: `limit=*** sort=asc`,
+	}, {
+		desc:    `Query: invalid`,
+		method:  http.MethodGet,
+		target:  `/book?limit=101&sort=up`,
+		expCode: http.StatusBadRequest,
+		expBody: `{"data":[` +
+			`{"field":"sort","message":"must be one of asc, desc"},` +
+			`{"field":"limit","message":"must be less or equal to 100"}` +
+			`],"message":"invalid input","name":"ERR_INVALID_INPUT","code":400}`,
+	}, {
+		desc:    `Query: missing required`,
+		method:  http.MethodGet,
+		target:  `/book`,
+		expCode: http.StatusBadRequest,
+		expBody: `{"data":[{"field":"limit","message":"is required"}],"message":"invalid input","name":"ERR_INVALID_INPUT","code":400}`,
+	}}
+
+	var c testCase
+	for _, c = range listCase {
+		var (
+			httpReq = httptest.NewRequest(c.method, c.target, strings.NewReader(c.body))
+			rec     = httptest.NewRecorder()
+		)
+		if len(c.body) != 0 {
+			httpReq.Header.Set(HeaderContentType, ContentTypeJSON)
+		}
+
+		srv.ServeHTTP(rec, httpReq)
+
+		test.Assert(t, c.desc+`: status`, c.expCode, rec.Code)
+		test.Assert(t, c.desc+`: body`, c.expBody, rec.Body.String())
+	}
+}
+
+func TestNewValidators_name(t *testing.T) {
+	type testCase struct {
+		tagKey  string
+		expName []string
+	}
+
+	type testRequest struct {
+		Title     string `json:"title" form:"title" validate:"required"`
+		Publisher string `validate:"required"`
+	}
+
+	var listCase = []testCase{{
+		tagKey:  `json`,
+		expName: []string{`title`, `Publisher`},
+	}, {
+		tagKey:  structTagKey,
+		expName: []string{`title`, `publisher`},
+	}}
+
+	var (
+		c          testCase
+		validators []*fieldValidator
+		fv         *fieldValidator
+		err        error
+	)
+	for _, c = range listCase {
+		validators, err = newValidators(reflect.TypeFor[testRequest](), c.tagKey, ``, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var gotName []string
+		for _, fv = range validators {
+			gotName = append(gotName, fv.name)
+		}
+		test.Assert(t, c.tagKey, c.expName, gotName)
+	}
+}
+
+func TestNewTypedEndpoint_invalidRule(t *testing.T) {
+	type invalidMin struct {
+		Limit int `validate:"min=x"`
+	}
+	type unknownRule struct {
+		Name string `form:"name" validate:"required,lowercase"`
+	}
+	type invalidPattern struct {
+		Name string `json:"name" validate:"pattern=[a-"`
+	}
+
+	var call = func(_ *EndpointRequest, _ *struct{}) (any, error) {
+		return nil, nil
+	}
+	var err error
+
+	_, err = NewTypedEndpoint(Endpoint{}, func(_ *EndpointRequest, _ *invalidMin) (any, error) {
+		return nil, nil
+	})
+	test.Assert(t, `invalid min`,
+		`NewTypedEndpoint: http.invalidMin: limit: invalid min "x"`, err.Error())
+
+	_, err = NewTypedEndpoint(Endpoint{}, func(_ *EndpointRequest, _ *unknownRule) (any, error) {
+		return nil, nil
+	})
+	test.Assert(t, `unknown rule`,
+		`NewTypedEndpoint: http.unknownRule: name: unknown rule "lowercase"`, err.Error())
+
+	_, err = NewTypedEndpoint(Endpoint{RequestType: RequestTypeJSON},
+		func(_ *EndpointRequest, _ *invalidPattern) (any, error) {
+			return nil, nil
+		})
+	test.Assert(t, `invalid pattern`,
+		"NewTypedEndpoint: http.invalidPattern: name: invalid pattern \"[a-\": error parsing regexp: missing closing ]: `[a-`",
+		err.Error())
+
+	_, err = NewTypedEndpoint(Endpoint{}, call)
+	test.Assert(t, `empty struct`, nil, err)
+}
+
+func TestValidateFields_zero(t *testing.T) {
+	type testRequest struct {
+		Page  *int   `json:"page" validate:"min=1"`
+		Name  string `json:"name" validate:"min=1"`
+		Count int    `json:"count" validate:"min=1"`
+	}
+
+	type testCase struct {
+		desc   string
+		req    testRequest
+		expErr []FieldError
+	}
+
+	var (
+		zero = 0
+		one  = 1
+	)
+
+	var listCase = []testCase{{
+		desc: `With zero values`,
+		req: testRequest{
+			Page: &zero,
+		},
+		expErr: []FieldError{{
+			Field:   `page`,
+			Message: `must be greater or equal to 1`,
+		}, {
+			Field:   `name`,
+			Message: `must have minimum length 1`,
+		}, {
+			Field:   `count`,
+			Message: `must be greater or equal to 1`,
+		}},
+	}, {
+		desc: `With nil pointer`,
+		req: testRequest{
+			Name:  `a`,
+			Count: 1,
+		},
+	}, {
+		desc: `With valid values`,
+		req: testRequest{
+			Page:  &one,
+			Name:  `a`,
+			Count: 1,
+		},
+	}}
+
+	var (
+		validators []*fieldValidator
+		err        error
+	)
+
+	validators, err = newValidators(reflect.TypeFor[testRequest](), `json`, ``, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var c testCase
+	for _, c = range listCase {
+		var got = validateFields(validators, reflect.ValueOf(c.req))
+		test.Assert(t, c.desc, c.expErr, got)
+	}
+}
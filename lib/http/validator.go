@@ -0,0 +1,301 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package http
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	libreflect "git.sr.ht/~shulhan/pakakeh.go/lib/reflect"
+)
+
+// validateTagKey define the struct tag that contains the validation
+// rules.
+const validateTagKey = `validate`
+
+// FieldError define the validation error on single field.
+type FieldError struct {
+	// Field contains the name of field in the request.
+	// The field in nested struct is separated by dot, for example
+	// "author.name".
+	Field string `json:"field"`
+
+	// Message contains the reason why the field is invalid.
+	Message string `json:"message"`
+}
+
+// fieldValidator contains the validation rules for single struct field,
+// parsed from the tag "validate".
+type fieldValidator struct {
+	pattern *regexp.Regexp
+
+	name string
+
+	// index of field in the struct, as in [reflect.StructField.Index].
+	index []int
+
+	enum []string
+
+	// fields contains the validator for the fields inside nested
+	// struct.
+	fields []*fieldValidator
+
+	min float64
+	max float64
+
+	required bool
+	hasMin   bool
+	hasMax   bool
+}
+
+// newValidators parse the tag "validate" from each field in struct
+// rtype.
+// The tagKey define the struct tag that used as the field name in the
+// error.
+// If the field does not have the tag, the field name is used as is for
+// tag "json", following the [encoding/json], or in lower case for other
+// tag.
+// The parents contains the list of struct types that contains rtype, to
+// prevent parsing recursive type.
+func newValidators(
+	rtype reflect.Type, tagKey, prefix string, parents []reflect.Type,
+) (list []*fieldValidator, err error) {
+	for rtype.Kind() == reflect.Pointer {
+		rtype = rtype.Elem()
+	}
+	if rtype.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	parents = append(parents, rtype)
+
+	var field reflect.StructField
+	for _, field = range reflect.VisibleFields(rtype) {
+		if field.Anonymous || !field.IsExported() {
+			continue
+		}
+
+		var name, _, hasTag = libreflect.Tag(field, tagKey)
+		if len(name) == 0 {
+			continue
+		}
+		if !hasTag && tagKey != `json` {
+			name = strings.ToLower(name)
+		}
+
+		var fv = &fieldValidator{
+			name:  prefix + name,
+			index: field.Index,
+		}
+
+		err = fv.parse(field.Tag.Get(validateTagKey))
+		if err != nil {
+			return nil, fmt.Errorf(`%s: %w`, fv.name, err)
+		}
+
+		var ftype = field.Type
+		for ftype.Kind() == reflect.Pointer {
+			ftype = ftype.Elem()
+		}
+		if ftype.Kind() == reflect.Struct && ftype != timeType &&
+			!slices.Contains(parents, ftype) {
+			fv.fields, err = newValidators(ftype, tagKey,
+				fv.name+`.`, parents)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if !fv.isEmpty() {
+			list = append(list, fv)
+		}
+	}
+	return list, nil
+}
+
+// parse the validation rules, separated by comma.
+// The rule "pattern" must be the last rule, since the regular expression
+// may contains comma.
+func (fv *fieldValidator) parse(tag string) (err error) {
+	var rule, val string
+
+	for len(tag) != 0 {
+		if strings.HasPrefix(tag, `pattern=`) {
+			val = strings.TrimPrefix(tag, `pattern=`)
+			fv.pattern, err = regexp.Compile(val)
+			if err != nil {
+				return fmt.Errorf(`invalid pattern %q: %w`, val, err)
+			}
+			return nil
+		}
+
+		rule, tag, _ = strings.Cut(tag, `,`)
+		rule, val, _ = strings.Cut(strings.TrimSpace(rule), `=`)
+
+		switch rule {
+		case ``:
+			// Allow empty rule, for example "required,,min=1".
+		case `required`:
+			fv.required = true
+		case `min`:
+			fv.min, err = strconv.ParseFloat(val, 64)
+			if err != nil {
+				return fmt.Errorf(`invalid min %q`, val)
+			}
+			fv.hasMin = true
+		case `max`:
+			fv.max, err = strconv.ParseFloat(val, 64)
+			if err != nil {
+				return fmt.Errorf(`invalid max %q`, val)
+			}
+			fv.hasMax = true
+		case `enum`:
+			fv.enum = strings.Split(val, `|`)
+		default:
+			return fmt.Errorf(`unknown rule %q`, rule)
+		}
+	}
+	return nil
+}
+
+func (fv *fieldValidator) isEmpty() bool {
+	return !fv.required && !fv.hasMin && !fv.hasMax &&
+		len(fv.enum) == 0 && fv.pattern == nil && len(fv.fields) == 0
+}
+
+// validateFields validate the struct value v using the list of
+// validators, and return all of the field errors.
+func validateFields(list []*fieldValidator, v reflect.Value) (listErr []FieldError) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v = reflect.Zero(v.Type().Elem())
+			break
+		}
+		v = v.Elem()
+	}
+
+	var fv *fieldValidator
+	for _, fv = range list {
+		var fval, err = v.FieldByIndexErr(fv.index)
+		if err != nil {
+			// One of the embedded struct pointer is nil.
+			fval = reflect.Value{}
+		}
+		listErr = append(listErr, fv.validate(fval)...)
+	}
+	return listErr
+}
+
+// validate the field value.
+// The rules min and max are applied even if the value is zero, while the
+// rules enum and pattern are applied only if the value is not zero.
+// The nil pointer is treated as missing value, so only the rule required
+// is applied.
+// The fields in nested struct are validated only if the struct is not
+// nil.
+func (fv *fieldValidator) validate(fval reflect.Value) (listErr []FieldError) {
+	for fval.IsValid() && fval.Kind() == reflect.Pointer {
+		if fval.IsNil() {
+			fval = reflect.Value{}
+			break
+		}
+		fval = fval.Elem()
+	}
+	if !fval.IsValid() {
+		if fv.required {
+			return []FieldError{fv.newError(`is required`)}
+		}
+		return nil
+	}
+
+	var msg string
+	if fval.IsZero() {
+		if fv.required {
+			return []FieldError{fv.newError(`is required`)}
+		}
+		msg = fv.validateRange(fval)
+	} else {
+		msg = fv.validateValue(fval)
+	}
+	if len(msg) != 0 {
+		listErr = append(listErr, fv.newError(msg))
+	}
+	if len(fv.fields) != 0 {
+		listErr = append(listErr, validateFields(fv.fields, fval)...)
+	}
+	return listErr
+}
+
+// validateRange return the error message if the value does not match
+// with the min or max rules.
+// For string, the min and max rules define the number of characters.
+// For slice, array, and map, the min and max rules define the number of
+// items.
+// For number, the min and max rules define the value range.
+func (fv *fieldValidator) validateRange(fval reflect.Value) string {
+	var (
+		num      float64
+		isLength bool
+	)
+	switch fval.Kind() {
+	case reflect.String:
+		num = float64(utf8.RuneCountInString(fval.String()))
+		isLength = true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		num = float64(fval.Len())
+		isLength = true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		num = float64(fval.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		num = float64(fval.Uint())
+	case reflect.Float32, reflect.Float64:
+		num = fval.Float()
+	}
+
+	if fv.hasMin && num < fv.min {
+		if isLength {
+			return fmt.Sprintf(`must have minimum length %v`, fv.min)
+		}
+		return fmt.Sprintf(`must be greater or equal to %v`, fv.min)
+	}
+	if fv.hasMax && num > fv.max {
+		if isLength {
+			return fmt.Sprintf(`must have maximum length %v`, fv.max)
+		}
+		return fmt.Sprintf(`must be less or equal to %v`, fv.max)
+	}
+	return ``
+}
+
+// validateValue return the error message if the value does not match
+// with one of the rules.
+func (fv *fieldValidator) validateValue(fval reflect.Value) string {
+	var msg = fv.validateRange(fval)
+	if len(msg) != 0 {
+		return msg
+	}
+
+	var str = fmt.Sprint(fval.Interface())
+
+	if len(fv.enum) != 0 && !slices.Contains(fv.enum, str) {
+		return fmt.Sprintf(`must be one of %s`, strings.Join(fv.enum, `, `))
+	}
+	if fv.pattern != nil && !fv.pattern.MatchString(str) {
+		return fmt.Sprintf(`must match pattern %s`, fv.pattern)
+	}
+	return ``
+}
+
+func (fv *fieldValidator) newError(msg string) FieldError {
+	return FieldError{
+		Field:   fv.name,
+		Message: msg,
+	}
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package memfs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+)
+
+// List of content encoding that can be used in [Options.Precompress]
+// without custom [Compressor].
+const (
+	EncodingDeflate = `deflate` // Using zlib.
+	EncodingGzip    = `gzip`
+)
+
+// Compressor define the function to compress the content using specific
+// content encoding.
+type Compressor func(content []byte) (out []byte, err error)
+
+// nodeEncoding contains the content of node compressed with the content
+// encoding name.
+type nodeEncoding struct {
+	name    string
+	content []byte
+}
+
+// compressor return the Compressor for content encoding.
+// The custom Compressors take precedence over the default one.
+func (opts *Options) compressor(encoding string) Compressor {
+	var compress = opts.Compressors[encoding]
+	if compress != nil {
+		return compress
+	}
+	switch encoding {
+	case EncodingDeflate:
+		return compressDeflate
+	case EncodingGzip:
+		return compressGzip
+	}
+	return nil
+}
+
+// precompress compress the content of node using each content encoding
+// in [Options.Precompress].
+// The compressed content is stored only if its smaller than the
+// original content.
+// This method does nothing if the node is directory, has no content, or
+// has been compressed.
+func (mfs *MemFS) precompress(node *Node) (err error) {
+	if len(mfs.Opts.Precompress) == 0 {
+		return nil
+	}
+	if node.IsDir() || len(node.Content) == 0 || node.encodings != nil {
+		return nil
+	}
+
+	var (
+		logp      = `precompress`
+		encodings = []nodeEncoding{}
+
+		name     string
+		compress Compressor
+		out      []byte
+	)
+	for _, name = range mfs.Opts.Precompress {
+		compress = mfs.Opts.compressor(name)
+		if compress == nil {
+			return fmt.Errorf(`%s: unknown content encoding %q`, logp, name)
+		}
+		out, err = compress(node.Content)
+		if err != nil {
+			return fmt.Errorf(`%s %s: %s: %w`, logp, node.Path, name, err)
+		}
+		if len(out) >= len(node.Content) {
+			continue
+		}
+		encodings = append(encodings, nodeEncoding{
+			name:    name,
+			content: out,
+		})
+	}
+	node.encodings = encodings
+	return nil
+}
+
+// precompressAll compress the content of all nodes.
+func (mfs *MemFS) precompressAll() (err error) {
+	if len(mfs.Opts.Precompress) == 0 {
+		return nil
+	}
+	var node *Node
+	for _, node = range mfs.PathNodes.Nodes() {
+		err = mfs.precompress(node)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func compressDeflate(content []byte) (out []byte, err error) {
+	var (
+		buf bytes.Buffer
+		w   *zlib.Writer
+	)
+	w, err = zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(content)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func compressGzip(content []byte) (out []byte, err error) {
+	var (
+		buf bytes.Buffer
+		w   *gzip.Writer
+	)
+	w, err = gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(content)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
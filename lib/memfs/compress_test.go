@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: BSD-3-Clause
+// SPDX-FileCopyrightText: 2026 M. Shulhan <ms@kilabit.info>
+
+package memfs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~shulhan/pakakeh.go/lib/test"
+)
+
+func TestMemFS_precompress(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		content = strings.Repeat(`body { margin: 0; }`+"\n", 64)
+
+		err error
+	)
+
+	err = os.WriteFile(filepath.Join(dir, `big.css`), []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, `small.txt`), []byte(`a`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var opts = &Options{
+		Root:        dir,
+		Precompress: []string{EncodingGzip, `x-upper`, EncodingDeflate},
+		Compressors: map[string]Compressor{
+			`x-upper`: func(in []byte) ([]byte, error) {
+				return bytes.ToUpper(in[:1]), nil
+			},
+		},
+	}
+
+	var mfs *MemFS
+
+	mfs, err = New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var node = mfs.MustGet(`/big.css`)
+
+	test.Assert(t, `Encodings`,
+		[]string{EncodingGzip, `x-upper`, EncodingDeflate}, node.Encodings())
+	test.Assert(t, `x-upper`, []byte(`B`), node.EncodedContent(`x-upper`))
+	test.Assert(t, `unknown encoding`, []byte(nil), node.EncodedContent(`br`))
+
+	var (
+		gzipReader io.Reader
+		got        []byte
+	)
+	gzipReader, err = gzip.NewReader(bytes.NewReader(node.EncodedContent(EncodingGzip)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = io.ReadAll(gzipReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `gzip`, content, string(got))
+
+	var zlibReader io.Reader
+	zlibReader, err = zlib.NewReader(bytes.NewReader(node.EncodedContent(EncodingDeflate)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = io.ReadAll(zlibReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `deflate`, content, string(got))
+
+	// The compressed content that is not smaller than the original is
+	// not stored.
+	node = mfs.MustGet(`/small.txt`)
+	test.Assert(t, `small.txt: Encodings`, []string(nil), node.Encodings())
+
+	// Updating the content reset the compressed content.
+	node = mfs.MustGet(`/big.css`)
+	err = node.Save([]byte(`b`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, `Save: Encodings`, []string(nil), node.Encodings())
+
+	// Unknown content encoding without Compressor.
+	opts = &Options{
+		Root:        dir,
+		Precompress: []string{`br`},
+	}
+	_, err = New(opts)
+	test.Assert(t, `unknown encoding`,
+		`New: Init: unknown content encoding "br" in Precompress`,
+		err.Error())
+}
+
+func TestMemFS_GoEmbed_precompress(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		content = strings.Repeat(`a`, 64)
+
+		err error
+	)
+
+	err = os.WriteFile(filepath.Join(dir, `a.txt`), []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var opts = &Options{
+		Root:        dir,
+		Precompress: []string{`x-first`},
+		Compressors: map[string]Compressor{
+			`x-first`: func(in []byte) ([]byte, error) {
+				return in[:1], nil
+			},
+		},
+		Embed: EmbedOptions{
+			GoFileName:     filepath.Join(t.TempDir(), `embed.go`),
+			WithoutModTime: true,
+		},
+	}
+
+	var mfs *MemFS
+
+	mfs, err = New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mfs.GoEmbed()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []byte
+
+	got, err = os.ReadFile(opts.Embed.GoFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var exp = "\tnode.SetSize(64)\n" +
+		"\tnode.SetETag(\"\\\"ffe054fe7ae0cb6dc65c3af9b61d5209\\\"\")\n" +
+		"\tnode.SetEncodedContent(\"x-first\", []byte(\"\\x61\"))\n" +
+		"\treturn node\n"
+	if !bytes.Contains(got, []byte(exp)) {
+		t.Fatalf("GoEmbed: expecting generated code contains:\n%s\ngot:\n%s", exp, got)
+	}
+}
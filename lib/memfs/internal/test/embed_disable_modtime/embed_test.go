@@ -69,6 +69,7 @@ func generate_testdata_direct_add_file() *memfs.Node {
 	node.SetMode(0o644)
 	node.SetName("file")
 	node.SetSize(22)
+	node.SetETag("\"f6e1044e5734f7a31d423e4890ec918a\"")
 	return node
 }
 
@@ -83,6 +84,7 @@ func generate_testdata_direct_add_file2() *memfs.Node {
 	node.SetMode(0o644)
 	node.SetName("file2")
 	node.SetSize(24)
+	node.SetETag("\"fd31d1584d6e93988928be31b955128d\"")
 	return node
 }
 
@@ -127,6 +129,7 @@ func generate_testdata_exclude_index_link_css() *memfs.Node {
 	node.SetMode(0o644)
 	node.SetName("index-link.css")
 	node.SetSize(9)
+	node.SetETag("\"9a6fea7cf564e6a95bf79907b393c71e\"")
 	return node
 }
 
@@ -141,6 +144,7 @@ func generate_testdata_exclude_index_link_html() *memfs.Node {
 	node.SetMode(0o644)
 	node.SetName("index-link.html")
 	node.SetSize(14)
+	node.SetETag("\"b0693dc92f76e08bf1485b3dd9b514a2\"")
 	return node
 }
 
@@ -155,6 +159,7 @@ func generate_testdata_exclude_index_link_js() *memfs.Node {
 	node.SetMode(0o644)
 	node.SetName("index-link.js")
 	node.SetSize(16)
+	node.SetETag("\"bc74e9ee7421fb7449d803d9a10dc26d\"")
 	return node
 }
 
@@ -199,6 +204,7 @@ func generate_testdata_include_index_css() *memfs.Node {
 	node.SetMode(0o644)
 	node.SetName("index.css")
 	node.SetSize(9)
+	node.SetETag("\"9a6fea7cf564e6a95bf79907b393c71e\"")
 	return node
 }
 
@@ -213,6 +219,7 @@ func generate_testdata_include_index_html() *memfs.Node {
 	node.SetMode(0o644)
 	node.SetName("index.html")
 	node.SetSize(14)
+	node.SetETag("\"b0693dc92f76e08bf1485b3dd9b514a2\"")
 	return node
 }
 
@@ -227,6 +234,7 @@ func generate_testdata_include_index_js() *memfs.Node {
 	node.SetMode(0o644)
 	node.SetName("index.js")
 	node.SetSize(16)
+	node.SetETag("\"bc74e9ee7421fb7449d803d9a10dc26d\"")
 	return node
 }
 
@@ -241,6 +249,7 @@ func generate_testdata_index_css() *memfs.Node {
 	node.SetMode(0o644)
 	node.SetName("index.css")
 	node.SetSize(9)
+	node.SetETag("\"9a6fea7cf564e6a95bf79907b393c71e\"")
 	return node
 }
 
@@ -255,6 +264,7 @@ func generate_testdata_index_html() *memfs.Node {
 	node.SetMode(0o644)
 	node.SetName("index.html")
 	node.SetSize(14)
+	node.SetETag("\"b0693dc92f76e08bf1485b3dd9b514a2\"")
 	return node
 }
 
@@ -269,6 +279,7 @@ func generate_testdata_index_js() *memfs.Node {
 	node.SetMode(0o644)
 	node.SetName("index.js")
 	node.SetSize(16)
+	node.SetETag("\"bc74e9ee7421fb7449d803d9a10dc26d\"")
 	return node
 }
 
@@ -283,6 +294,7 @@ func generate_testdata_plain() *memfs.Node {
 	node.SetMode(0o644)
 	node.SetName("plain")
 	node.SetSize(22)
+	node.SetETag("\"65976868bec4c168c0f00b63923a7e4a\"")
 	return node
 }
 
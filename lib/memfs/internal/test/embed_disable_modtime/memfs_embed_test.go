@@ -38,6 +38,7 @@ func TestGeneratePathNode(t *testing.T) {
 	expExcludeIndexHTML.SetMode(0644)
 	expExcludeIndexHTML.SetName("index-link.html")
 	expExcludeIndexHTML.SetSize(14)
+	expExcludeIndexHTML.SetETag(`"b0693dc92f76e08bf1485b3dd9b514a2"`)
 
 	cases := []struct {
 		path     string
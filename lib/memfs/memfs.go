@@ -90,6 +90,11 @@ func (mfs *MemFS) AddChild(parent *Node, fi os.FileInfo) (child *Node, err error
 
 	mfs.PathNodes.Set(child.Path, child)
 
+	err = mfs.precompress(child)
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %w`, logp, err)
+	}
+
 	return child, nil
 }
 
@@ -167,6 +172,11 @@ func (mfs *MemFS) AddFile(internalPath, externalPath string) (node *Node, err er
 		return nil, fmt.Errorf("%s: %w", logp, err)
 	}
 
+	err = mfs.precompress(node)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+
 	parent.Childs = append(parent.Childs, node)
 	mfs.PathNodes.Set(node.Path, node)
 
@@ -193,6 +203,7 @@ func (mfs *MemFS) Get(path string) (node *Node, err error) {
 	if node != nil {
 		if mfs.Opts.TryDirect {
 			_ = node.Update(nil, mfs.Opts.MaxFileSize)
+			_ = mfs.precompress(node)
 
 			// Ignore error if the file is not exist in storage.
 			// Use case: the node maybe have been result of embed and the
@@ -268,6 +279,15 @@ func (mfs *MemFS) Init() (err error) {
 		return fmt.Errorf("%s: %w", logp, err)
 	}
 
+	// The content of node that is added manually may be set directly,
+	// without computing its entity tag.
+	var node *Node
+	for _, node = range mfs.PathNodes.Nodes() {
+		if len(node.etag) == 0 && len(node.Content) != 0 {
+			node.updateETag()
+		}
+	}
+
 	return nil
 }
 
@@ -406,6 +426,12 @@ func (mfs *MemFS) Update(node *Node, newInfo os.FileInfo) {
 	err = node.Update(newInfo, mfs.Opts.MaxFileSize)
 	if err != nil {
 		log.Printf("%s %s: %s", logp, node.SysPath, err)
+		return
+	}
+
+	err = mfs.precompress(node)
+	if err != nil {
+		log.Printf("%s: %s", logp, err)
 	}
 }
 
@@ -466,6 +492,11 @@ func (mfs *MemFS) mount() (err error) {
 		return fmt.Errorf("%s: %w", logp, err)
 	}
 
+	err = mfs.precompressAll()
+	if err != nil {
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+
 	return nil
 }
 
@@ -585,6 +616,11 @@ func (mfs *MemFS) refresh(url string) (node *Node, err error) {
 		return nil, err
 	}
 
+	err = mfs.precompressAll()
+	if err != nil {
+		return nil, err
+	}
+
 	node = mfs.PathNodes.Get(url)
 	if node == nil {
 		return nil, fs.ErrNotExist
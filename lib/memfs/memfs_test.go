@@ -230,6 +230,7 @@ func TestMemFS_AddFile(t *testing.T) {
 			ContentType: "text/plain; charset=utf-8",
 			size:        22,
 			Content:     []byte("Test direct add file.\n"),
+			etag:        `"f6e1044e5734f7a31d423e4890ec918a"`,
 			GenFuncName: "generate_internal_file",
 		},
 	}, {
@@ -243,6 +244,7 @@ func TestMemFS_AddFile(t *testing.T) {
 			ContentType: "text/plain; charset=utf-8",
 			size:        24,
 			Content:     []byte("Test direct add file 2.\n"),
+			etag:        `"fd31d1584d6e93988928be31b955128d"`,
 			GenFuncName: "generate_internal_file2",
 		},
 	}}
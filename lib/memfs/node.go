@@ -6,7 +6,9 @@ package memfs
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -54,9 +56,14 @@ type Node struct {
 	name        string // File name.
 	ContentType string // File type per MIME, for example "application/json".
 	GenFuncName string // The function name for embedded Go code.
+	etag        string // The strong entity tag of Content.
 
 	Childs []*Node // List of files in directory.
 
+	// encodings contains the Content compressed with specific content
+	// encoding.
+	encodings []nodeEncoding
+
 	Content []byte // Content of file.
 	plainv  []byte // Content of file in plain text.
 	lowerv  []byte // Content of file in lower cases.
@@ -185,6 +192,40 @@ func (node *Node) GenerateIndexHTML() {
 	node.ContentType = `text/html; charset=utf-8`
 	node.size = int64(buf.Len())
 	node.Content = slices.Clone(buf.Bytes())
+	node.encodings = nil
+	node.updateETag()
+}
+
+// EncodedContent return the Content compressed with the content encoding,
+// for example "gzip".
+// It will return nil if the Content has not been compressed with that
+// encoding.
+func (node *Node) EncodedContent(encoding string) []byte {
+	var enc nodeEncoding
+	for _, enc = range node.encodings {
+		if enc.name == encoding {
+			return enc.content
+		}
+	}
+	return nil
+}
+
+// Encodings return the list of content encoding that the Content has been
+// compressed with, in the order of [Options.Precompress].
+func (node *Node) Encodings() (list []string) {
+	var enc nodeEncoding
+	for _, enc = range node.encodings {
+		list = append(list, enc.name)
+	}
+	return list
+}
+
+// ETag return the strong entity tag of the Content, as quoted string,
+// computed from the SHA-256 hash of the Content when its loaded into
+// memory.
+// It will return empty string if the content is not loaded into memory.
+func (node *Node) ETag() string {
+	return node.etag
 }
 
 // IsDir return true if the node is a directory.
@@ -306,6 +347,8 @@ func (node *Node) Save(content []byte) (err error) {
 	}
 
 	node.Content = content
+	node.encodings = nil
+	node.updateETag()
 	node.modTime = time.Now()
 	node.size = int64(len(content))
 	return nil
@@ -333,6 +376,34 @@ func (node *Node) Seek(offset int64, whence int) (int64, error) {
 	return node.off, nil
 }
 
+// SetETag set the entity tag of the Content.
+// This method is used by the Go code generated from GoEmbed, so the entity
+// tag does not need to be computed again on start.
+func (node *Node) SetETag(etag string) {
+	node.etag = etag
+}
+
+// SetEncodedContent set the Content that has been compressed with the
+// content encoding.
+// This method is used by the Go code generated from GoEmbed, or to store
+// the Content that has been compressed externally.
+func (node *Node) SetEncodedContent(encoding string, content []byte) {
+	var (
+		encodings = make([]nodeEncoding, 0, len(node.encodings)+1)
+		enc       nodeEncoding
+	)
+	for _, enc = range node.encodings {
+		if enc.name != encoding {
+			encodings = append(encodings, enc)
+		}
+	}
+	encodings = append(encodings, nodeEncoding{
+		name:    encoding,
+		content: content,
+	})
+	node.encodings = encodings
+}
+
 // SetModTime set the file modification time.
 func (node *Node) SetModTime(modTime time.Time) {
 	node.modTime = modTime
@@ -550,8 +621,10 @@ func (node *Node) UpdateContent(maxFileSize int64) (err error) {
 	if node.size > maxFileSize {
 		return nil
 	}
+	node.encodings = nil
 	if node.size == 0 {
 		node.Content = nil
+		node.updateETag()
 		return nil
 	}
 
@@ -562,6 +635,7 @@ func (node *Node) UpdateContent(maxFileSize int64) (err error) {
 		}
 		return fmt.Errorf("UpdateContent: %w", err)
 	}
+	node.updateETag()
 
 	return nil
 }
@@ -694,3 +768,9 @@ func (node *Node) updateContentType() error {
 
 	return nil
 }
+
+// updateETag compute the entity tag from the Content.
+func (node *Node) updateETag() {
+	var sum = sha256.Sum256(node.Content)
+	node.etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+}
@@ -4,6 +4,7 @@
 package memfs
 
 import (
+	"fmt"
 	"os"
 	"regexp"
 	"strings"
@@ -30,6 +31,23 @@ type Options struct {
 	Includes []string
 	Excludes []string
 
+	// Precompress define the list of content encoding, for example
+	// "gzip" or "deflate", to compress each file content once its
+	// mapped into memory.
+	// The compressed content is stored in the Node only if its smaller
+	// than the original content, and can be retrieved using
+	// [Node.EncodedContent].
+	// When embedded using GoEmbed, the compressed content is also
+	// embedded, so it does not need to be compressed again.
+	Precompress []string
+
+	// Compressors define the custom function to compress the content
+	// for content encoding in Precompress other than "gzip" and
+	// "deflate", for example "br" or "zstd".
+	// The standard library does not provide the compressor for "br"
+	// and "zstd", so the caller must register them here.
+	Compressors map[string]Compressor
+
 	incRE []*regexp.Regexp
 	excRE []*regexp.Regexp
 
@@ -80,6 +98,11 @@ func (opts *Options) init() (err error) {
 		}
 		opts.excRE = append(opts.excRE, re)
 	}
+	for _, v = range opts.Precompress {
+		if opts.compressor(v) == nil {
+			return fmt.Errorf(`unknown content encoding %q in Precompress`, v)
+		}
+	}
 	return nil
 }
 
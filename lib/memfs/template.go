@@ -64,6 +64,12 @@ func {{ .Node.GenFuncName}}() *memfs.Node {
 {{- end }}
 	node.SetName("{{.Node.Name}}")
 	node.SetSize({{.Node.Size}})
+{{- if .Node.ETag }}
+	node.SetETag({{printf "%q" .Node.ETag}})
+{{- end }}
+	{{- range $enc := .Node.Encodings}}
+	node.SetEncodedContent("{{$enc}}", []byte("{{range $c := $.Node.EncodedContent $enc}}{{ printf "\\x%02X" $c }}{{end}}"))
+	{{- end}}
 	{{- range $x, $child := .Node.Childs}}
 		{{- if $child.GenFuncName}}
 	node.AddChild(_{{$varname}}_getNode({{$varname}}, "{{.Path}}", {{$child.GenFuncName}}))